@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE comment_visibility_enum AS ENUM (
+    'public',   -- visible to the founder, unlocks fields and blocks resubmission until resolved
+    'internal'  -- reviewer-only note, never shown to the founder
+);
+
+ALTER TABLE IF EXISTS project_comments
+ADD COLUMN visibility comment_visibility_enum NOT NULL DEFAULT 'public';
+
+CREATE INDEX IF NOT EXISTS idx_project_comments_project_visibility ON project_comments(project_id, visibility);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_project_comments_project_visibility;
+
+ALTER TABLE IF EXISTS project_comments
+DROP COLUMN visibility;
+
+DROP TYPE IF EXISTS comment_visibility_enum;
+-- +goose StatementEnd
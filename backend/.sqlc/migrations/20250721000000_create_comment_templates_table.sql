@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comment_templates (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    title varchar NOT NULL,
+    body text NOT NULL, -- may contain placeholders such as {{company_name}} or {{question}}
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_templates;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS project_comments
+ADD COLUMN anchor_snapshot_id UUID DEFAULT NULL REFERENCES project_snapshots(id) ON DELETE SET NULL,
+ADD COLUMN anchor_start INTEGER DEFAULT NULL,
+ADD COLUMN anchor_end INTEGER DEFAULT NULL,
+ADD COLUMN anchor_text TEXT DEFAULT NULL;
+
+-- A text range is optional, but when present it must be complete and non-empty
+ALTER TABLE IF EXISTS project_comments
+ADD CONSTRAINT chk_project_comments_anchor_range CHECK (
+    (anchor_start IS NULL AND anchor_end IS NULL AND anchor_text IS NULL)
+    OR (anchor_start >= 0 AND anchor_end > anchor_start AND anchor_text IS NOT NULL)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS project_comments
+DROP CONSTRAINT IF EXISTS chk_project_comments_anchor_range;
+
+ALTER TABLE IF EXISTS project_comments
+DROP COLUMN anchor_text,
+DROP COLUMN anchor_end,
+DROP COLUMN anchor_start,
+DROP COLUMN anchor_snapshot_id;
+-- +goose StatementEnd
@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE project_activity_type AS ENUM (
+    'status_changed',
+    'snapshot_created',
+    'comment_created',
+    'comment_resolved',
+    'comment_unresolved',
+    'document_uploaded',
+    'document_deleted',
+    'team_member_added',
+    'team_member_updated',
+    'team_member_removed',
+    'transaction_created'
+);
+
+-- append-only log of everything that happens to a project
+CREATE TABLE IF NOT EXISTS project_activities (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    actor_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    activity_type project_activity_type NOT NULL,
+    internal boolean NOT NULL DEFAULT false, -- only visible to users that can view all projects
+    payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+    seq bigserial NOT NULL, -- keeps insertion order for activities created within the same second
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_activities_project_created ON project_activities(project_id, created_at DESC, seq DESC);
+
+-- backfill the activities that can be reconstructed from existing data
+INSERT INTO project_activities (project_id, actor_id, activity_type, payload, created_at)
+SELECT ps.project_id, NULL, 'snapshot_created',
+    jsonb_build_object('snapshot_id', ps.id::text, 'version_number', ps.version_number),
+    ps.created_at
+FROM project_snapshots ps;
+
+INSERT INTO project_activities (project_id, actor_id, activity_type, internal, payload, created_at)
+SELECT pc.project_id, pc.commenter_id, 'comment_created', pc.visibility = 'internal',
+    jsonb_build_object('comment_id', pc.id::text, 'target_id', pc.target_id::text),
+    pc.created_at
+FROM project_comments pc;
+
+INSERT INTO project_activities (project_id, actor_id, activity_type, payload, created_at)
+SELECT pd.project_id, NULL, 'document_uploaded',
+    jsonb_build_object('document_id', pd.id::text, 'question_id', pd.question_id::text, 'name', pd.name),
+    pd.created_at
+FROM project_documents pd;
+
+INSERT INTO project_activities (project_id, actor_id, activity_type, payload, created_at)
+SELECT p.id, NULL, 'team_member_added',
+    jsonb_build_object('team_member_id', tm.id::text, 'first_name', tm.first_name, 'last_name', tm.last_name, 'title', tm.title),
+    tm.created_at
+FROM team_members tm
+JOIN projects p ON p.company_id = tm.company_id;
+
+INSERT INTO project_activities (project_id, actor_id, activity_type, payload, created_at)
+SELECT t.project_id, t.created_by, 'transaction_created',
+    jsonb_build_object('transaction_id', t.id::text, 'tx_hash', t.tx_hash, 'from_address', t.from_address,
+        'to_address', t.to_address, 'value_amount', t.value_amount::text),
+    t.created_at
+FROM transactions t;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_activities;
+
+DROP TYPE IF EXISTS project_activity_type;
+-- +goose StatementEnd
@@ -0,0 +1,115 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- all searchable text of a project, used to build the search vector and the highlighted snippets
+CREATE OR REPLACE FUNCTION project_search_text(pid uuid)
+RETURNS text AS $$
+    SELECT concat_ws(' ',
+        p.title,
+        c.name,
+        p.description,
+        (
+            SELECT string_agg(concat_ws(' ', pa.answer, array_to_string(pa.choices, ' ')), ' ')
+            FROM project_answers pa
+            WHERE pa.project_id = p.id
+        )
+    )
+    FROM projects p
+    LEFT JOIN companies c ON c.id = p.company_id
+    WHERE p.id = pid;
+$$ LANGUAGE sql STABLE;
+
+-- titles and company names rank highest, then descriptions, then answers
+CREATE OR REPLACE FUNCTION project_search_vector(pid uuid)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('english', coalesce(p.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(c.name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(p.description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce((
+            SELECT string_agg(concat_ws(' ', pa.answer, array_to_string(pa.choices, ' ')), ' ')
+            FROM project_answers pa
+            WHERE pa.project_id = p.id
+        ), '')), 'C')
+    FROM projects p
+    LEFT JOIN companies c ON c.id = p.company_id
+    WHERE p.id = pid;
+$$ LANGUAGE sql STABLE;
+
+-- one search document per project, kept apart from projects so the search vector
+-- doesn't show up in every project query
+CREATE TABLE IF NOT EXISTS project_search_index (
+    project_id uuid PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    search_vector tsvector NOT NULL,
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_search_index_vector ON project_search_index USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION refresh_project_search_index(pid uuid)
+RETURNS void AS $$
+BEGIN
+    -- the project might be gone already when its answers are removed by a cascading delete
+    INSERT INTO project_search_index (project_id, search_vector)
+    SELECT p.id, project_search_vector(p.id) FROM projects p WHERE p.id = pid
+    ON CONFLICT (project_id) DO UPDATE
+    SET search_vector = EXCLUDED.search_vector,
+        updated_at = extract(epoch from now());
+END;
+$$ LANGUAGE plpgsql;
+
+-- keep the search index in sync with its sources
+CREATE OR REPLACE FUNCTION refresh_project_search_index_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    pid uuid;
+BEGIN
+    IF TG_TABLE_NAME = 'projects' THEN
+        PERFORM refresh_project_search_index(NEW.id);
+    ELSIF TG_TABLE_NAME = 'companies' THEN
+        FOR pid IN SELECT id FROM projects WHERE company_id = NEW.id LOOP
+            PERFORM refresh_project_search_index(pid);
+        END LOOP;
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_project_search_index(OLD.project_id);
+    ELSE
+        PERFORM refresh_project_search_index(NEW.project_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER refresh_projects_search_index
+AFTER INSERT OR UPDATE OF title, description, company_id ON projects
+FOR EACH ROW EXECUTE FUNCTION refresh_project_search_index_trigger();
+
+CREATE TRIGGER refresh_project_answers_search_index
+AFTER INSERT OR UPDATE OF answer, choices OR DELETE ON project_answers
+FOR EACH ROW EXECUTE FUNCTION refresh_project_search_index_trigger();
+
+CREATE TRIGGER refresh_companies_search_index
+AFTER UPDATE OF name ON companies
+FOR EACH ROW EXECUTE FUNCTION refresh_project_search_index_trigger();
+
+INSERT INTO project_search_index (project_id, search_vector)
+SELECT id, project_search_vector(id) FROM projects;
+
+-- filters used together with the search
+CREATE INDEX IF NOT EXISTS idx_projects_status_created ON projects(status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_projects_status_created;
+
+DROP TRIGGER IF EXISTS refresh_companies_search_index ON companies;
+DROP TRIGGER IF EXISTS refresh_project_answers_search_index ON project_answers;
+DROP TRIGGER IF EXISTS refresh_projects_search_index ON projects;
+DROP FUNCTION IF EXISTS refresh_project_search_index_trigger();
+DROP FUNCTION IF EXISTS refresh_project_search_index(uuid);
+
+DROP TABLE IF EXISTS project_search_index;
+
+DROP FUNCTION IF EXISTS project_search_vector(uuid);
+DROP FUNCTION IF EXISTS project_search_text(uuid);
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE dossier_format AS ENUM ('html', 'pdf');
+
+-- Rendered dossiers of a project snapshot. Snapshots never change, so an export only has to be
+-- rendered again when the renderer itself changes, which bumps renderer_version, or when the
+-- company it was rendered with is renamed.
+CREATE TABLE IF NOT EXISTS project_dossier_exports (
+    snapshot_id uuid NOT NULL REFERENCES project_snapshots(id) ON DELETE CASCADE,
+    format dossier_format NOT NULL,
+    renderer_version int NOT NULL,
+    company_name varchar NOT NULL DEFAULT '',
+    content bytea NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    PRIMARY KEY (snapshot_id, format, renderer_version)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_dossier_exports;
+DROP TYPE IF EXISTS dossier_format;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE export_format AS ENUM ('csv', 'json', 'xlsx');
+
+CREATE TYPE export_job_status AS ENUM ('pending', 'running', 'completed', 'failed');
+
+-- Program wide exports requested by admins. The export runs in the background and the
+-- generated file is kept with the job until it is downloaded or the job is deleted.
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format export_format NOT NULL,
+    statuses project_status[] NOT NULL DEFAULT '{}', -- empty exports projects of any status
+    status export_job_status NOT NULL DEFAULT 'pending',
+    row_count int,
+    file_name text,
+    content bytea,
+    error text,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    started_at bigint,
+    completed_at bigint
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_created ON export_jobs(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_export_jobs_created;
+DROP TABLE IF EXISTS export_jobs;
+DROP TYPE IF EXISTS export_job_status;
+DROP TYPE IF EXISTS export_format;
+-- +goose StatementEnd
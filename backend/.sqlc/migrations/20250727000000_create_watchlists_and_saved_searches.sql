@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE notification_type AS ENUM (
+    'saved_search_match',
+    'watched_project_status',
+    'watched_project_funding'
+);
+
+-- Projects bookmarked by investors. The last status and funding total are the state the
+-- investor was last notified about, the alert matcher compares them to the current state.
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    note text NOT NULL DEFAULT '',
+    last_status project_status NOT NULL,
+    last_funding_total decimal(65,18) NOT NULL DEFAULT 0,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    UNIQUE (user_id, project_id)
+);
+
+-- Project catalogue filters saved by investors
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name varchar NOT NULL,
+    industry varchar,
+    stage varchar,
+    notify boolean NOT NULL DEFAULT true,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+-- Verified projects a saved search was notified about, so each match is only notified once
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id uuid NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    PRIMARY KEY (saved_search_id, project_id)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type NOT NULL,
+    project_id uuid REFERENCES projects(id) ON DELETE CASCADE,
+    message text NOT NULL,
+    read_at bigint,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_items_project ON watchlist_items(project_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notifications_user;
+DROP INDEX IF EXISTS idx_saved_searches_user;
+DROP INDEX IF EXISTS idx_watchlist_items_project;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
+DROP TABLE IF EXISTS watchlist_items;
+DROP TYPE IF EXISTS notification_type;
+-- +goose StatementEnd
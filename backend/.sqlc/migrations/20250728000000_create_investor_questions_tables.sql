@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE qa_visibility AS ENUM ('private', 'public');
+
+-- Due diligence questions investors ask founders about a project. They are kept apart from
+-- review comments, so they never affect allow_edit or resubmission. A thread is private to the
+-- asker, the founder and admins until the founder makes it public.
+CREATE TABLE IF NOT EXISTS investor_questions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    asker_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body text NOT NULL,
+    visibility qa_visibility NOT NULL DEFAULT 'private',
+    answered_at bigint,
+    hidden_at bigint,
+    hidden_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE TABLE IF NOT EXISTS investor_question_replies (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    question_id uuid NOT NULL REFERENCES investor_questions(id) ON DELETE CASCADE,
+    author_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body text NOT NULL,
+    hidden_at bigint,
+    hidden_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_investor_questions_project ON investor_questions(project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_investor_question_replies_question ON investor_question_replies(question_id, created_at);
+
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'qa_question_asked';
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'qa_question_answered';
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'qa_follow_up';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE type IN ('qa_question_asked', 'qa_question_answered', 'qa_follow_up');
+ALTER TYPE notification_type RENAME TO notification_type_old;
+CREATE TYPE notification_type AS ENUM (
+    'saved_search_match',
+    'watched_project_status',
+    'watched_project_funding'
+);
+ALTER TABLE notifications ALTER COLUMN type TYPE notification_type USING type::text::notification_type;
+DROP TYPE notification_type_old;
+
+DROP INDEX IF EXISTS idx_investor_question_replies_question;
+DROP INDEX IF EXISTS idx_investor_questions_project;
+DROP TABLE IF EXISTS investor_question_replies;
+DROP TABLE IF EXISTS investor_questions;
+DROP TYPE IF EXISTS qa_visibility;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Direct messages between founders, investors and admins. A conversation can be about a project,
+-- participants only see the conversations they are part of.
+CREATE TABLE IF NOT EXISTS conversations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid REFERENCES projects(id) ON DELETE SET NULL,
+    subject varchar(255) NOT NULL DEFAULT '',
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    last_message_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+-- last_read_at is the read receipt of the participant, every message sent up to then has been read.
+CREATE TABLE IF NOT EXISTS conversation_participants (
+    conversation_id uuid NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_at bigint,
+    joined_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id uuid NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body text NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE TABLE IF NOT EXISTS message_attachments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id uuid NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    storage_key text NOT NULL,
+    url text NOT NULL,
+    mime_type varchar(255) NOT NULL,
+    size bigint NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+-- Every time an admin reads a conversation they are not part of, e.g. to settle a dispute.
+CREATE TABLE IF NOT EXISTS conversation_admin_reads (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id uuid NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    admin_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason text NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_user ON conversation_participants(user_id);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_message_attachments_message ON message_attachments(message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_message_attachments_message;
+DROP INDEX IF EXISTS idx_messages_conversation;
+DROP INDEX IF EXISTS idx_conversation_participants_user;
+DROP TABLE IF EXISTS conversation_admin_reads;
+DROP TABLE IF EXISTS message_attachments;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversation_participants;
+DROP TABLE IF EXISTS conversations;
+-- +goose StatementEnd
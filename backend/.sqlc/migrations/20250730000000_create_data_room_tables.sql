@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Confidential documents are only shared with the investors the founder granted access to.
+-- Documents were only seen by the founder and the reviewers before the data room, so every
+-- document starts out confidential and the founder decides which ones to share.
+ALTER TABLE project_documents ADD COLUMN IF NOT EXISTS confidential boolean NOT NULL DEFAULT true;
+
+-- A grant gives one investor, or every investor with a transaction on the project, access to
+-- one confidential document or to all of them when document_id is NULL.
+CREATE TABLE IF NOT EXISTS data_room_grants (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    document_id uuid REFERENCES project_documents(id) ON DELETE CASCADE,
+    investor_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    all_committed boolean NOT NULL DEFAULT false,
+    expires_at bigint,
+    granted_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    CHECK ((investor_id IS NOT NULL) <> all_committed)
+);
+
+CREATE TYPE data_room_action AS ENUM ('view', 'download');
+
+-- The document name is kept so the log stays readable after the document is deleted.
+CREATE TABLE IF NOT EXISTS data_room_access_logs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    document_id uuid REFERENCES project_documents(id) ON DELETE SET NULL,
+    document_name varchar NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action data_room_action NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_room_grants_project ON data_room_grants(project_id);
+CREATE INDEX IF NOT EXISTS idx_data_room_access_logs_project ON data_room_access_logs(project_id, created_at DESC);
+
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'data_room_access_granted';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE type = 'data_room_access_granted';
+ALTER TYPE notification_type RENAME TO notification_type_old;
+CREATE TYPE notification_type AS ENUM (
+    'saved_search_match',
+    'watched_project_status',
+    'watched_project_funding',
+    'qa_question_asked',
+    'qa_question_answered',
+    'qa_follow_up'
+);
+ALTER TABLE notifications ALTER COLUMN type TYPE notification_type USING type::text::notification_type;
+DROP TYPE notification_type_old;
+
+DROP INDEX IF EXISTS idx_data_room_access_logs_project;
+DROP INDEX IF EXISTS idx_data_room_grants_project;
+DROP TABLE IF EXISTS data_room_access_logs;
+DROP TYPE IF EXISTS data_room_action;
+DROP TABLE IF EXISTS data_room_grants;
+ALTER TABLE project_documents DROP COLUMN IF EXISTS confidential;
+-- +goose StatementEnd
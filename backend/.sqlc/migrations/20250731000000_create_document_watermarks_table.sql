@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- PDFs with watermark set are stamped with the name and email of the investor before they are served.
+ALTER TABLE project_documents ADD COLUMN IF NOT EXISTS watermark boolean NOT NULL DEFAULT false;
+
+-- Watermarked copies of a document, one per investor and version of the document. The copy is
+-- stamped once and served from storage until the document changes, which bumps document_version.
+CREATE TABLE IF NOT EXISTS document_watermarks (
+    document_id uuid NOT NULL REFERENCES project_documents(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_version bigint NOT NULL,
+    storage_key varchar NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    PRIMARY KEY (document_id, user_id, document_version)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS document_watermarks;
+ALTER TABLE project_documents DROP COLUMN IF EXISTS watermark;
+-- +goose StatementEnd
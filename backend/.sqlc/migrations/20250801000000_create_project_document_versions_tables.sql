@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- project_documents holds the current version of every document, its id identifies the document
+-- across versions.
+ALTER TABLE project_documents ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+
+-- Every file uploaded for a document. document_id is not a foreign key so the history, and the
+-- files in storage, outlive the deletion of the document.
+CREATE TABLE IF NOT EXISTS project_document_versions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id uuid NOT NULL,
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    question_id uuid NOT NULL REFERENCES project_questions(id) ON DELETE CASCADE,
+    version int NOT NULL,
+    name varchar NOT NULL,
+    url varchar NOT NULL,
+    mime_type varchar NOT NULL,
+    size bigint NOT NULL DEFAULT 0,
+    uploaded_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    UNIQUE (document_id, version)
+);
+
+-- The document versions a snapshot was submitted with, i.e. the files reviewers evaluated.
+CREATE TABLE IF NOT EXISTS project_snapshot_documents (
+    snapshot_id uuid NOT NULL REFERENCES project_snapshots(id) ON DELETE CASCADE,
+    version_id uuid NOT NULL REFERENCES project_document_versions(id) ON DELETE CASCADE,
+    PRIMARY KEY (snapshot_id, version_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_document_versions_project ON project_document_versions(project_id);
+CREATE INDEX IF NOT EXISTS idx_project_snapshot_documents_version ON project_snapshot_documents(version_id);
+
+-- Existing documents become the first version, uploaded by the owner of the company.
+-- Which documents existing snapshots were submitted with is unknown.
+INSERT INTO project_document_versions (document_id, project_id, question_id, version, name, url, mime_type, size, uploaded_by, created_at)
+SELECT d.id, d.project_id, d.question_id, 1, d.name, d.url, d.mime_type, d.size, c.owner_id, d.created_at
+FROM project_documents d
+JOIN projects p ON p.id = d.project_id
+JOIN companies c ON c.id = p.company_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_project_snapshot_documents_version;
+DROP INDEX IF EXISTS idx_project_document_versions_project;
+DROP TABLE IF EXISTS project_snapshot_documents;
+DROP TABLE IF EXISTS project_document_versions;
+ALTER TABLE project_documents DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
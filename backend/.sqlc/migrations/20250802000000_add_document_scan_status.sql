@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE scan_status AS ENUM ('pending', 'clean', 'infected');
+
+-- Uploaded files are scanned for malware in the background and only shown to others once clean.
+-- scan_signature names the malware found in infected files.
+ALTER TABLE project_documents
+    ADD COLUMN IF NOT EXISTS scan_status scan_status NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS scan_signature varchar,
+    ADD COLUMN IF NOT EXISTS scanned_at bigint;
+
+-- Documents uploaded before scanning existed have been served for a while already, treat them as clean.
+UPDATE project_documents SET scan_status = 'clean';
+
+CREATE INDEX IF NOT EXISTS idx_project_documents_pending_scan ON project_documents(updated_at) WHERE scan_status = 'pending';
+
+-- Scans that failed, per file of a document. Pending documents are retried until they run out of attempts.
+CREATE TABLE IF NOT EXISTS document_scan_attempts (
+    document_id uuid NOT NULL REFERENCES project_documents(id) ON DELETE CASCADE,
+    storage_key varchar NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_attempt_at bigint NOT NULL DEFAULT extract(epoch from now()),
+    PRIMARY KEY (document_id, storage_key)
+);
+
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'document_infected';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE type = 'document_infected';
+ALTER TYPE notification_type RENAME TO notification_type_old;
+CREATE TYPE notification_type AS ENUM (
+    'saved_search_match',
+    'watched_project_status',
+    'watched_project_funding',
+    'qa_question_asked',
+    'qa_question_answered',
+    'qa_follow_up',
+    'data_room_access_granted'
+);
+ALTER TABLE notifications ALTER COLUMN type TYPE notification_type USING type::text::notification_type;
+DROP TYPE notification_type_old;
+
+DROP TABLE IF EXISTS document_scan_attempts;
+DROP INDEX IF EXISTS idx_project_documents_pending_scan;
+ALTER TABLE project_documents
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
+DROP TYPE IF EXISTS scan_status;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A direct upload in progress: the client PUTs the file to storage_key with a presigned URL, then
+-- completes the upload to create the document. Uploads that are never completed expire and are removed.
+CREATE TABLE IF NOT EXISTS document_uploads (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    question_id uuid NOT NULL REFERENCES project_questions(id) ON DELETE CASCADE,
+    document_id uuid REFERENCES project_documents(id) ON DELETE CASCADE,
+    uploaded_by uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key varchar NOT NULL UNIQUE,
+    name varchar NOT NULL,
+    section varchar NOT NULL,
+    sub_section varchar NOT NULL,
+    mime_type varchar NOT NULL,
+    size bigint NOT NULL,
+    expires_at bigint NOT NULL,
+    created_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_uploads_expires_at ON document_uploads(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_document_uploads_expires_at;
+DROP TABLE IF EXISTS document_uploads;
+-- +goose StatementEnd
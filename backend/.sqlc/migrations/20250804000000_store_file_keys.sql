@@ -0,0 +1,97 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Files are private, only their storage keys are stored. They are read through short-lived signed URLs
+-- generated at response time, a stored URL would give access to the file forever.
+CREATE FUNCTION pg_temp.storage_key_from_url(url text) RETURNS text AS $$
+    SELECT CASE
+        -- S3: https://<bucket>.s3.<region>.amazonaws.com/<key>
+        WHEN url ~ '^https://[^/]+\.amazonaws\.com/' THEN regexp_replace(url, '^https://[^/]+\.amazonaws\.com/', '')
+        -- Local storage: <backend url>/api/v1/storage/files/<key>
+        WHEN url ~ '/api/v1/storage/files/' THEN regexp_replace(url, '^.*?/api/v1/storage/files/', '')
+        ELSE url
+    END
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE users RENAME COLUMN profile_picture_url TO profile_picture_key;
+UPDATE users SET profile_picture_key = pg_temp.storage_key_from_url(profile_picture_key)
+WHERE profile_picture_key IS NOT NULL;
+
+ALTER TABLE team_members RENAME COLUMN resume_internal_url TO resume_internal_key;
+ALTER TABLE team_members RENAME COLUMN founders_agreement_internal_url TO founders_agreement_internal_key;
+UPDATE team_members
+SET resume_internal_key = pg_temp.storage_key_from_url(resume_internal_key),
+    founders_agreement_internal_key = pg_temp.storage_key_from_url(founders_agreement_internal_key)
+WHERE resume_internal_key IS NOT NULL OR founders_agreement_internal_key IS NOT NULL;
+
+ALTER TABLE project_documents RENAME COLUMN url TO storage_key;
+UPDATE project_documents SET storage_key = pg_temp.storage_key_from_url(storage_key);
+
+ALTER TABLE project_document_versions RENAME COLUMN url TO storage_key;
+UPDATE project_document_versions SET storage_key = pg_temp.storage_key_from_url(storage_key);
+
+-- Attachments already have their key
+ALTER TABLE message_attachments DROP COLUMN url;
+
+-- Submitted snapshots keep their documents and team members as they were, with keys instead of URLs
+UPDATE project_snapshots
+SET data = jsonb_set(data, '{documents}', (
+    SELECT coalesce(jsonb_agg(
+        (d - 'url') || jsonb_build_object('storage_key', pg_temp.storage_key_from_url(d->>'url'))
+        ORDER BY i
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(data->'documents') WITH ORDINALITY AS documents(d, i)
+))
+WHERE jsonb_typeof(data->'documents') = 'array';
+
+UPDATE project_snapshots
+SET data = jsonb_set(data, '{team_members}', (
+    SELECT coalesce(jsonb_agg(
+        (m - 'resume_internal_url' - 'founders_agreement_internal_url') || jsonb_build_object(
+            'resume_internal_key', pg_temp.storage_key_from_url(m->>'resume_internal_url'),
+            'founders_agreement_internal_key', pg_temp.storage_key_from_url(m->>'founders_agreement_internal_url')
+        )
+        ORDER BY i
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(data->'team_members') WITH ORDINALITY AS members(m, i)
+))
+WHERE jsonb_typeof(data->'team_members') = 'array';
+
+DROP FUNCTION pg_temp.storage_key_from_url(text);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The keys are kept as they are, URLs can't be rebuilt without the storage configuration
+UPDATE project_snapshots
+SET data = jsonb_set(data, '{team_members}', (
+    SELECT coalesce(jsonb_agg(
+        (m - 'resume_internal_key' - 'founders_agreement_internal_key') || jsonb_build_object(
+            'resume_internal_url', m->'resume_internal_key',
+            'founders_agreement_internal_url', m->'founders_agreement_internal_key'
+        )
+        ORDER BY i
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(data->'team_members') WITH ORDINALITY AS members(m, i)
+))
+WHERE jsonb_typeof(data->'team_members') = 'array';
+
+UPDATE project_snapshots
+SET data = jsonb_set(data, '{documents}', (
+    SELECT coalesce(jsonb_agg(
+        (d - 'storage_key') || jsonb_build_object('url', d->'storage_key')
+        ORDER BY i
+    ), '[]'::jsonb)
+    FROM jsonb_array_elements(data->'documents') WITH ORDINALITY AS documents(d, i)
+))
+WHERE jsonb_typeof(data->'documents') = 'array';
+
+ALTER TABLE message_attachments ADD COLUMN url text NOT NULL DEFAULT '';
+UPDATE message_attachments SET url = storage_key;
+ALTER TABLE message_attachments ALTER COLUMN url DROP DEFAULT;
+
+ALTER TABLE project_document_versions RENAME COLUMN storage_key TO url;
+ALTER TABLE project_documents RENAME COLUMN storage_key TO url;
+ALTER TABLE team_members RENAME COLUMN founders_agreement_internal_key TO founders_agreement_internal_url;
+ALTER TABLE team_members RENAME COLUMN resume_internal_key TO resume_internal_url;
+ALTER TABLE users RENAME COLUMN profile_picture_key TO profile_picture_url;
+-- +goose StatementEnd
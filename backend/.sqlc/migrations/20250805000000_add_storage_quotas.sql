@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The size of the team member documents counts towards the storage quota of the company.
+-- The size of documents uploaded before is unknown, they count as empty.
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS resume_internal_size bigint NOT NULL DEFAULT 0;
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS founders_agreement_internal_size bigint NOT NULL DEFAULT 0;
+
+-- The watermarked copies of the documents are stored too. They are reported apart from the quota,
+-- investors create them by downloading and they can be stamped again at any time.
+ALTER TABLE document_watermarks ADD COLUMN IF NOT EXISTS size bigint NOT NULL DEFAULT 0;
+
+-- Storage quotas set by admins, companies without one get the default quota.
+CREATE TABLE IF NOT EXISTS company_storage_quotas (
+    company_id uuid PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    quota bigint NOT NULL CHECK (quota >= 0),
+    updated_by uuid REFERENCES users(id) ON DELETE SET NULL,
+    updated_at bigint NOT NULL DEFAULT extract(epoch from now())
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS company_storage_quotas;
+ALTER TABLE document_watermarks DROP COLUMN IF EXISTS size;
+ALTER TABLE team_members DROP COLUMN IF EXISTS founders_agreement_internal_size;
+ALTER TABLE team_members DROP COLUMN IF EXISTS resume_internal_size;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A resumable upload sends the file in chunks to a multipart upload of the storage, multipart_id
+-- is its id until the upload completes. uploaded_size is the offset the client resumes from.
+ALTER TABLE document_uploads ADD COLUMN IF NOT EXISTS multipart_id varchar;
+ALTER TABLE document_uploads ADD COLUMN IF NOT EXISTS uploaded_size bigint NOT NULL DEFAULT 0;
+
+-- The chunks of a resumable upload, stored as the parts of the multipart upload.
+CREATE TABLE IF NOT EXISTS document_upload_parts (
+    upload_id uuid NOT NULL REFERENCES document_uploads(id) ON DELETE CASCADE,
+    part_number integer NOT NULL CHECK (part_number > 0),
+    etag varchar NOT NULL,
+    size bigint NOT NULL,
+    PRIMARY KEY (upload_id, part_number)
+);
+
+-- Size limits of the files answering a question, questions without one use the limit of the
+-- project documents route.
+CREATE TABLE IF NOT EXISTS project_question_file_rules (
+    question_id uuid PRIMARY KEY REFERENCES project_questions(id) ON DELETE CASCADE,
+    max_size bigint NOT NULL CHECK (max_size > 0)
+);
+
+INSERT INTO project_question_file_rules (question_id, max_size)
+SELECT id, CASE question_key WHEN 'pitch_deck_file' THEN 100 * 1024 * 1024 ELSE 50 * 1024 * 1024 END
+FROM project_questions
+WHERE question_key IN ('pitch_deck_file', 'business_plan_file', 'market_research_file',
+                       'customer_data_file', 'contracts_file', 'ip_files_upload')
+ON CONFLICT (question_id) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_question_file_rules;
+DROP TABLE IF EXISTS document_upload_parts;
+ALTER TABLE document_uploads DROP COLUMN IF EXISTS uploaded_size;
+ALTER TABLE document_uploads DROP COLUMN IF EXISTS multipart_id;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- File questions declare which files answer them. A NULL rule uses the default of the project
+-- documents: the allowed types and size bounds of the route, no limit on the number of files.
+ALTER TABLE project_question_file_rules ALTER COLUMN max_size DROP NOT NULL;
+ALTER TABLE project_question_file_rules ADD COLUMN IF NOT EXISTS allowed_types varchar[];
+ALTER TABLE project_question_file_rules ADD COLUMN IF NOT EXISTS min_size bigint CHECK (min_size >= 0);
+ALTER TABLE project_question_file_rules ADD COLUMN IF NOT EXISTS max_files integer CHECK (max_files > 0);
+
+INSERT INTO project_question_file_rules (question_id, allowed_types, max_files)
+SELECT id, ARRAY['application/pdf', 'application/vnd.ms-powerpoint',
+                 'application/vnd.openxmlformats-officedocument.presentationml.presentation'], 1
+FROM project_questions WHERE question_key = 'pitch_deck_file'
+ON CONFLICT (question_id) DO UPDATE SET allowed_types = EXCLUDED.allowed_types, max_files = EXCLUDED.max_files;
+
+INSERT INTO project_question_file_rules (question_id, allowed_types, max_files)
+SELECT id, ARRAY['image/jpeg', 'image/png'], 10
+FROM project_questions WHERE question_key = 'company_featured_images'
+ON CONFLICT (question_id) DO UPDATE SET allowed_types = EXCLUDED.allowed_types, max_files = EXCLUDED.max_files;
+
+INSERT INTO project_question_file_rules (question_id, allowed_types)
+SELECT id, ARRAY['application/pdf', 'application/msword',
+                 'application/vnd.openxmlformats-officedocument.wordprocessingml.document']
+FROM project_questions WHERE question_key = 'business_plan_file'
+ON CONFLICT (question_id) DO UPDATE SET allowed_types = EXCLUDED.allowed_types;
+
+INSERT INTO project_question_file_rules (question_id, allowed_types)
+SELECT id, ARRAY['application/pdf', 'application/vnd.ms-excel',
+                 'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet']
+FROM project_questions
+WHERE question_key IN ('cap_table_file', 'cash_flow_file', 'income_statement_file', 'balance_sheet_file')
+ON CONFLICT (question_id) DO UPDATE SET allowed_types = EXCLUDED.allowed_types;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM project_question_file_rules WHERE max_size IS NULL;
+ALTER TABLE project_question_file_rules DROP COLUMN IF EXISTS max_files;
+ALTER TABLE project_question_file_rules DROP COLUMN IF EXISTS min_size;
+ALTER TABLE project_question_file_rules DROP COLUMN IF EXISTS allowed_types;
+ALTER TABLE project_question_file_rules ALTER COLUMN max_size SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- name: CreateCommentTemplate :one
+INSERT INTO comment_templates (
+    title,
+    body,
+    created_by
+) VALUES (
+    $1, -- title
+    $2, -- body
+    $3  -- created_by
+) RETURNING *;
+
+-- name: GetCommentTemplate :one
+SELECT * FROM comment_templates
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListCommentTemplates :many
+SELECT * FROM comment_templates
+ORDER BY title;
+
+-- name: UpdateCommentTemplate :one
+UPDATE comment_templates
+SET title = $2,
+    body = $3,
+    updated_at = extract(epoch from now())
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCommentTemplate :exec
+DELETE FROM comment_templates
+WHERE id = $1;
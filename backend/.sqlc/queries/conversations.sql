@@ -0,0 +1,95 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (project_id, subject, created_by)
+VALUES (sqlc.narg(project_id), @subject, @created_by)
+RETURNING *;
+
+-- name: AddConversationParticipant :exec
+INSERT INTO conversation_participants (conversation_id, user_id)
+VALUES (@conversation_id, @user_id)
+ON CONFLICT (conversation_id, user_id) DO NOTHING;
+
+-- name: FindConversationBetween :one
+SELECT c.* FROM conversations c
+WHERE c.project_id IS NOT DISTINCT FROM sqlc.narg(project_id)::uuid
+  AND EXISTS (SELECT 1 FROM conversation_participants p WHERE p.conversation_id = c.id AND p.user_id = @first_user_id)
+  AND EXISTS (SELECT 1 FROM conversation_participants p WHERE p.conversation_id = c.id AND p.user_id = @second_user_id)
+  AND (SELECT count(*) FROM conversation_participants p WHERE p.conversation_id = c.id) = 2
+ORDER BY c.created_at
+LIMIT 1;
+
+-- name: GetConversation :one
+SELECT * FROM conversations WHERE id = @id;
+
+-- name: GetConversationForParticipant :one
+SELECT c.* FROM conversations c
+JOIN conversation_participants p ON p.conversation_id = c.id
+WHERE c.id = @id AND p.user_id = @user_id;
+
+-- name: ListConversations :many
+SELECT c.*,
+    (SELECT count(*) FROM messages m
+     WHERE m.conversation_id = c.id
+       AND m.sender_id <> @user_id
+       AND (p.last_read_at IS NULL OR m.created_at > p.last_read_at))::bigint as unread_count
+FROM conversations c
+JOIN conversation_participants p ON p.conversation_id = c.id
+WHERE p.user_id = @user_id
+ORDER BY c.last_message_at DESC, c.id DESC;
+
+-- name: ListAllConversations :many
+SELECT c.* FROM conversations c
+WHERE (sqlc.narg(project_id)::uuid IS NULL OR c.project_id = sqlc.narg(project_id)::uuid)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM conversation_participants p WHERE p.conversation_id = c.id AND p.user_id = sqlc.narg(user_id)::uuid
+  ))
+ORDER BY c.last_message_at DESC, c.id DESC
+LIMIT @page_size OFFSET @page_offset;
+
+-- name: ListConversationParticipants :many
+SELECT p.conversation_id, p.user_id, p.last_read_at, u.first_name, u.last_name, u.permissions
+FROM conversation_participants p
+JOIN users u ON u.id = p.user_id
+WHERE p.conversation_id = ANY(@conversation_ids::uuid[])
+ORDER BY p.joined_at, p.user_id;
+
+-- name: MarkConversationRead :execrows
+UPDATE conversation_participants
+SET last_read_at = extract(epoch from now())
+WHERE conversation_id = @conversation_id AND user_id = @user_id;
+
+-- name: CreateMessage :one
+INSERT INTO messages (conversation_id, sender_id, body)
+VALUES (@conversation_id, @sender_id, @body)
+RETURNING *;
+
+-- name: TouchConversation :exec
+UPDATE conversations SET last_message_at = @last_message_at WHERE id = @id;
+
+-- name: CountMessages :one
+SELECT count(*) FROM messages WHERE conversation_id = @conversation_id;
+
+-- name: ListMessages :many
+SELECT * FROM messages
+WHERE conversation_id = @conversation_id
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size OFFSET @page_offset;
+
+-- name: CreateMessageAttachment :one
+INSERT INTO message_attachments (message_id, name, storage_key, mime_type, size)
+VALUES (@message_id, @name, @storage_key, @mime_type, @size)
+RETURNING *;
+
+-- name: ListMessageAttachments :many
+SELECT * FROM message_attachments
+WHERE message_id = ANY(@message_ids::uuid[])
+ORDER BY created_at, id;
+
+-- name: CreateConversationAdminRead :exec
+INSERT INTO conversation_admin_reads (conversation_id, admin_id, reason)
+VALUES (@conversation_id, @admin_id, @reason);
+
+-- name: HasProjectRelationship :one
+SELECT (
+    EXISTS (SELECT 1 FROM watchlist_items w WHERE w.project_id = @project_id AND w.user_id = @investor_id)
+    OR EXISTS (SELECT 1 FROM transactions t WHERE t.project_id = @project_id AND t.created_by = @investor_id)
+)::boolean as related;
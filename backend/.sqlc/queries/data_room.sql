@@ -0,0 +1,68 @@
+-- name: UpdateProjectDocumentAccess :execrows
+UPDATE project_documents
+SET confidential = COALESCE(sqlc.narg(confidential), confidential),
+    watermark = COALESCE(sqlc.narg(watermark), watermark),
+    updated_at = extract(epoch from now())
+WHERE id = @id AND project_id = @project_id;
+
+-- name: ListDataRoomDocuments :many
+SELECT d.* FROM project_documents d
+WHERE d.project_id = @project_id
+  AND (@include_all::boolean OR NOT d.confidential OR EXISTS (
+    SELECT 1 FROM data_room_grants g
+    WHERE g.project_id = d.project_id
+      AND (g.document_id IS NULL OR g.document_id = d.id)
+      AND (g.expires_at IS NULL OR g.expires_at > extract(epoch from now()))
+      AND (g.investor_id = @investor_id OR (g.all_committed AND EXISTS (
+        SELECT 1 FROM transactions t WHERE t.project_id = d.project_id AND t.created_by = @investor_id
+      )))
+  ))
+  AND (@include_unscanned::boolean OR d.scan_status = 'clean')
+ORDER BY d.created_at DESC;
+
+-- name: GetDataRoomDocument :one
+SELECT d.* FROM project_documents d
+WHERE d.id = @id
+  AND d.project_id = @project_id
+  AND (@include_all::boolean OR NOT d.confidential OR EXISTS (
+    SELECT 1 FROM data_room_grants g
+    WHERE g.project_id = d.project_id
+      AND (g.document_id IS NULL OR g.document_id = d.id)
+      AND (g.expires_at IS NULL OR g.expires_at > extract(epoch from now()))
+      AND (g.investor_id = @investor_id OR (g.all_committed AND EXISTS (
+        SELECT 1 FROM transactions t WHERE t.project_id = d.project_id AND t.created_by = @investor_id
+      )))
+  ))
+  AND (@include_unscanned::boolean OR d.scan_status = 'clean');
+
+-- name: CreateDataRoomGrant :one
+INSERT INTO data_room_grants (project_id, document_id, investor_id, all_committed, expires_at, granted_by)
+VALUES (@project_id, sqlc.narg(document_id), sqlc.narg(investor_id), @all_committed, sqlc.narg(expires_at), sqlc.narg(granted_by))
+RETURNING *;
+
+-- name: ListDataRoomGrants :many
+SELECT g.*, u.email as investor_email, u.first_name as investor_first_name, u.last_name as investor_last_name,
+    d.name as document_name
+FROM data_room_grants g
+LEFT JOIN users u ON u.id = g.investor_id
+LEFT JOIN project_documents d ON d.id = g.document_id
+WHERE g.project_id = @project_id
+ORDER BY g.created_at DESC, g.id;
+
+-- name: DeleteDataRoomGrant :execrows
+DELETE FROM data_room_grants WHERE id = @id AND project_id = @project_id;
+
+-- name: CreateDataRoomAccessLog :exec
+INSERT INTO data_room_access_logs (project_id, document_id, document_name, user_id, action)
+VALUES (@project_id, @document_id, @document_name, @user_id, @action);
+
+-- name: CountDataRoomAccessLogs :one
+SELECT count(*) FROM data_room_access_logs WHERE project_id = @project_id;
+
+-- name: ListDataRoomAccessLogs :many
+SELECT l.*, u.email as user_email, u.first_name as user_first_name, u.last_name as user_last_name
+FROM data_room_access_logs l
+JOIN users u ON u.id = l.user_id
+WHERE l.project_id = @project_id
+ORDER BY l.created_at DESC, l.id DESC
+LIMIT @page_size OFFSET @page_offset;
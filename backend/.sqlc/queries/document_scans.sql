@@ -0,0 +1,30 @@
+-- name: SetDocumentScanResult :execrows
+UPDATE project_documents
+SET scan_status = @scan_status,
+    scan_signature = sqlc.narg(scan_signature),
+    scanned_at = extract(epoch from now())
+WHERE id = @id AND storage_key = @storage_key;
+
+-- name: DeleteProjectDocumentVersionByKey :exec
+DELETE FROM project_document_versions WHERE document_id = @document_id AND storage_key = @storage_key;
+
+-- name: ListPendingDocumentScans :many
+-- Lists the pending documents last changed before updated_before whose current file has failed fewer than max_attempts scans.
+SELECT d.* FROM project_documents d
+LEFT JOIN document_scan_attempts a ON a.document_id = d.id AND a.storage_key = d.storage_key
+WHERE d.scan_status = 'pending'
+  AND d.updated_at <= @updated_before
+  AND coalesce(a.attempts, 0) < @max_attempts::integer
+ORDER BY d.updated_at;
+
+-- name: RecordFailedDocumentScan :one
+INSERT INTO document_scan_attempts (document_id, storage_key, attempts)
+VALUES (@document_id, @storage_key, 1)
+ON CONFLICT (document_id, storage_key) DO UPDATE
+SET attempts = document_scan_attempts.attempts + 1,
+    last_attempt_at = extract(epoch from now())
+RETURNING attempts;
+
+-- name: CountUnscannedProjectDocuments :one
+SELECT count(*) FROM project_documents
+WHERE project_id = @project_id AND scan_status <> 'clean';
@@ -0,0 +1,57 @@
+-- name: CreateDocumentUpload :one
+INSERT INTO document_uploads (project_id, question_id, document_id, uploaded_by, storage_key, name, section, sub_section, mime_type, size, expires_at, multipart_id)
+VALUES (@project_id, @question_id, sqlc.narg(document_id), @uploaded_by, @storage_key, @name, @section, @sub_section, @mime_type, @size, @expires_at, sqlc.narg(multipart_id))
+RETURNING *;
+
+-- name: GetDocumentUpload :one
+SELECT * FROM document_uploads
+WHERE id = @id AND project_id = @project_id AND uploaded_by = @uploaded_by;
+
+-- name: DeleteDocumentUpload :execrows
+DELETE FROM document_uploads WHERE id = @id;
+
+-- name: ClaimDocumentUpload :execrows
+-- Deletes an upload being completed, 0 rows when it was already completed or has expired
+DELETE FROM document_uploads WHERE id = @id AND expires_at > extract(epoch from now());
+
+-- name: ListExpiredDocumentUploads :many
+SELECT * FROM document_uploads
+WHERE expires_at < extract(epoch from now())
+ORDER BY expires_at
+LIMIT @max_results;
+
+-- name: UpsertDocumentUploadPart :exec
+INSERT INTO document_upload_parts (upload_id, part_number, etag, size)
+VALUES (@upload_id, @part_number, @etag, @size)
+ON CONFLICT (upload_id, part_number) DO UPDATE SET etag = EXCLUDED.etag, size = EXCLUDED.size;
+
+-- name: ListDocumentUploadParts :many
+SELECT * FROM document_upload_parts
+WHERE upload_id = @upload_id
+ORDER BY part_number;
+
+-- name: AdvanceDocumentUploadOffset :execrows
+-- Only moves from the offset the chunk was sent at, a concurrent chunk for the same offset loses
+UPDATE document_uploads
+SET uploaded_size = @uploaded_size, expires_at = @expires_at
+WHERE id = @id AND uploaded_size = @offset;
+
+-- name: ClearDocumentUploadMultipart :exec
+UPDATE document_uploads SET multipart_id = NULL WHERE id = @id;
+
+-- name: GetProjectQuestionFileRule :one
+SELECT * FROM project_question_file_rules WHERE question_id = @question_id;
+
+-- name: ListProjectQuestionFileRules :many
+SELECT * FROM project_question_file_rules;
+
+-- name: CountQuestionFiles :one
+-- The documents answering a question, with the uploads in progress that will add one. Infected
+-- documents lost their file and don't count
+SELECT (
+    (SELECT count(*) FROM project_documents d
+     WHERE d.project_id = @project_id AND d.question_id = @question_id AND d.scan_status <> 'infected') +
+    (SELECT count(*) FROM document_uploads u
+     WHERE u.project_id = @project_id AND u.question_id = @question_id
+       AND u.document_id IS NULL AND u.expires_at >= extract(epoch from now()))
+)::bigint AS files;
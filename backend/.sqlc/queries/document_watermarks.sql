@@ -0,0 +1,16 @@
+-- name: GetDocumentWatermark :one
+SELECT * FROM document_watermarks
+WHERE document_id = $1 AND user_id = $2 AND document_version = $3;
+
+-- name: CreateDocumentWatermark :exec
+INSERT INTO document_watermarks (document_id, user_id, document_version, storage_key, size)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (document_id, user_id, document_version) DO NOTHING;
+
+-- name: DeleteStaleDocumentWatermarks :many
+DELETE FROM document_watermarks
+WHERE document_id = $1 AND user_id = $2 AND document_version <> $3
+RETURNING storage_key;
+
+-- name: ListDocumentWatermarkKeys :many
+SELECT storage_key FROM document_watermarks WHERE document_id = $1;
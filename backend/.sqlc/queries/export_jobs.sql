@@ -0,0 +1,89 @@
+-- name: CreateExportJob :one
+INSERT INTO export_jobs (requested_by, format, statuses)
+VALUES ($1, $2, $3)
+RETURNING id, requested_by, format, statuses, status, row_count, file_name, error, created_at, started_at, completed_at;
+
+-- name: GetExportJob :one
+SELECT id, requested_by, format, statuses, status, row_count, file_name, error, created_at, started_at, completed_at
+FROM export_jobs
+WHERE id = $1;
+
+-- name: ListExportJobs :many
+SELECT id, requested_by, format, statuses, status, row_count, file_name, error, created_at, started_at, completed_at
+FROM export_jobs
+ORDER BY created_at DESC
+LIMIT $1;
+
+-- name: GetExportJobFile :one
+SELECT file_name, format, content
+FROM export_jobs
+WHERE id = $1 AND status = 'completed';
+
+-- name: StartExportJob :exec
+UPDATE export_jobs
+SET status = 'running',
+    started_at = extract(epoch from now())
+WHERE id = $1;
+
+-- name: CompleteExportJob :exec
+UPDATE export_jobs
+SET status = 'completed',
+    row_count = $2,
+    file_name = $3,
+    content = $4,
+    completed_at = extract(epoch from now())
+WHERE id = $1;
+
+-- name: FailExportJob :exec
+UPDATE export_jobs
+SET status = 'failed',
+    error = $2,
+    completed_at = extract(epoch from now())
+WHERE id = $1;
+
+-- name: FailInterruptedExportJobs :exec
+UPDATE export_jobs
+SET status = 'failed',
+    error = 'The export was interrupted, please try again.',
+    completed_at = extract(epoch from now())
+WHERE status IN ('pending', 'running');
+
+-- name: DeleteExportJob :exec
+DELETE FROM export_jobs WHERE id = $1;
+
+-- name: ListProjectsForExport :many
+SELECT
+    p.id,
+    p.title,
+    p.description,
+    p.status,
+    p.created_at,
+    p.updated_at,
+    p.original_submission_at,
+    c.id as company_id,
+    c.name as company_name,
+    c.description as company_description,
+    c.date_founded as company_date_founded,
+    c.stages as company_stages,
+    c.website as company_website,
+    c.linkedin_url as company_linkedin_url,
+    u.email as owner_email
+FROM projects p
+JOIN companies c ON c.id = p.company_id
+JOIN users u ON u.id = c.owner_id
+WHERE cardinality(@statuses::project_status[]) = 0 OR p.status = ANY(@statuses::project_status[])
+ORDER BY p.created_at, p.id;
+
+-- name: ListExportQuestions :many
+SELECT question_key::text as question_key, input_type
+FROM project_questions
+WHERE question_key IS NOT NULL
+ORDER BY section_order, sub_section_order, question_order;
+
+-- name: ListProjectAnswersForExport :many
+SELECT pa.project_id, pq.question_key::text as question_key, pa.answer, pa.choices
+FROM project_answers pa
+JOIN project_questions pq ON pq.id = pa.question_id
+JOIN projects p ON p.id = pa.project_id
+WHERE pq.question_key IS NOT NULL
+  AND (cardinality(@statuses::project_status[]) = 0 OR p.status = ANY(@statuses::project_status[]));
@@ -0,0 +1,57 @@
+-- name: CreateInvestorQuestion :one
+INSERT INTO investor_questions (project_id, asker_id, body)
+VALUES (@project_id, @asker_id, @body)
+RETURNING *;
+
+-- name: GetInvestorQuestion :one
+SELECT * FROM investor_questions
+WHERE id = @id AND project_id = @project_id;
+
+-- name: ListInvestorQuestions :many
+SELECT q.*, u.first_name as asker_first_name, u.last_name as asker_last_name
+FROM investor_questions q
+JOIN users u ON u.id = q.asker_id
+WHERE q.project_id = @project_id
+  AND (@include_hidden::boolean OR q.hidden_at IS NULL)
+  AND (@include_all::boolean OR q.asker_id = @viewer_id OR (q.visibility = 'public' AND q.answered_at IS NOT NULL))
+ORDER BY q.created_at DESC, q.id DESC;
+
+-- name: ListInvestorQuestionReplies :many
+SELECT r.*, u.first_name as author_first_name, u.last_name as author_last_name
+FROM investor_question_replies r
+JOIN users u ON u.id = r.author_id
+WHERE r.question_id = ANY(@question_ids::uuid[])
+  AND (@include_hidden::boolean OR r.hidden_at IS NULL)
+ORDER BY r.created_at, r.id;
+
+-- name: CreateInvestorQuestionReply :one
+INSERT INTO investor_question_replies (question_id, author_id, body)
+VALUES (@question_id, @author_id, @body)
+RETURNING *;
+
+-- name: UpdateInvestorQuestionOnReply :exec
+UPDATE investor_questions
+SET answered_at = CASE WHEN @answered::boolean THEN COALESCE(answered_at, extract(epoch from now())) ELSE answered_at END,
+    visibility = COALESCE(sqlc.narg(visibility)::qa_visibility, visibility),
+    updated_at = extract(epoch from now())
+WHERE id = @id;
+
+-- name: UpdateInvestorQuestionVisibility :exec
+UPDATE investor_questions
+SET visibility = @visibility, updated_at = extract(epoch from now())
+WHERE id = @id;
+
+-- name: SetInvestorQuestionHidden :execrows
+UPDATE investor_questions
+SET hidden_at = CASE WHEN @hidden::boolean THEN extract(epoch from now()) ELSE NULL END,
+    hidden_by = CASE WHEN @hidden::boolean THEN sqlc.narg(moderator_id)::uuid ELSE NULL END
+WHERE id = @id AND project_id = @project_id;
+
+-- name: SetInvestorQuestionReplyHidden :execrows
+UPDATE investor_question_replies
+SET hidden_at = CASE WHEN @hidden::boolean THEN extract(epoch from now()) ELSE NULL END,
+    hidden_by = CASE WHEN @hidden::boolean THEN sqlc.narg(moderator_id)::uuid ELSE NULL END
+WHERE id = @id AND question_id = @question_id;
+
+-- name: DeleteInvestorQuestion :execrows
+DELETE FROM investor_questions WHERE id = @id AND project_id = @project_id;
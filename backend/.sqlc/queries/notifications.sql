@@ -0,0 +1,28 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (user_id, type, project_id, message)
+VALUES (@user_id, @type, sqlc.narg(project_id), @message)
+RETURNING *;
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE user_id = @user_id
+  AND (NOT @unread_only::boolean OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size OFFSET @page_offset;
+
+-- name: CountNotifications :one
+SELECT
+    COUNT(*) FILTER (WHERE NOT @unread_only::boolean OR read_at IS NULL) as total,
+    COUNT(*) FILTER (WHERE read_at IS NULL) as unread
+FROM notifications
+WHERE user_id = @user_id;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, extract(epoch from now()))
+WHERE id = @id AND user_id = @user_id;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = extract(epoch from now())
+WHERE user_id = @user_id AND read_at IS NULL;
@@ -0,0 +1,65 @@
+-- name: CreateProjectActivity :exec
+INSERT INTO project_activities (
+    project_id,
+    actor_id,
+    activity_type,
+    internal,
+    payload
+) VALUES (
+    $1, -- project_id
+    $2, -- actor_id
+    $3, -- activity_type
+    $4, -- internal
+    $5  -- payload
+);
+
+-- name: CreateCompanyProjectsActivity :exec
+INSERT INTO project_activities (project_id, actor_id, activity_type, internal, payload)
+SELECT p.id, sqlc.narg(actor_id)::uuid, @activity_type::project_activity_type, @internal::boolean, @payload::jsonb
+FROM projects p
+WHERE p.company_id = @company_id;
+
+-- name: ListProjectActivities :many
+-- Document activities are only listed when include_all_documents is set or the investor can open the document in the data room
+SELECT pa.*, u.first_name as actor_first_name, u.last_name as actor_last_name
+FROM project_activities pa
+LEFT JOIN users u ON u.id = pa.actor_id
+WHERE pa.project_id = @project_id
+  AND (NOT pa.internal OR @include_internal::boolean)
+  AND (@include_all_documents::boolean OR pa.activity_type NOT IN ('document_uploaded', 'document_deleted') OR EXISTS (
+    SELECT 1 FROM project_documents d
+    WHERE d.id::text = pa.payload->>'document_id'
+      AND d.project_id = pa.project_id
+      AND d.scan_status = 'clean'
+      AND (NOT d.confidential OR EXISTS (
+        SELECT 1 FROM data_room_grants g
+        WHERE g.project_id = d.project_id
+          AND (g.document_id IS NULL OR g.document_id = d.id)
+          AND (g.expires_at IS NULL OR g.expires_at > extract(epoch from now()))
+          AND (g.investor_id = @investor_id OR (g.all_committed AND EXISTS (
+            SELECT 1 FROM transactions t WHERE t.project_id = d.project_id AND t.created_by = @investor_id
+          )))
+      ))
+  ))
+ORDER BY pa.created_at DESC, pa.seq DESC
+LIMIT @page_size OFFSET @page_offset;
+
+-- name: CountProjectActivities :one
+SELECT COUNT(*) FROM project_activities pa
+WHERE pa.project_id = @project_id
+  AND (NOT pa.internal OR @include_internal::boolean)
+  AND (@include_all_documents::boolean OR pa.activity_type NOT IN ('document_uploaded', 'document_deleted') OR EXISTS (
+    SELECT 1 FROM project_documents d
+    WHERE d.id::text = pa.payload->>'document_id'
+      AND d.project_id = pa.project_id
+      AND d.scan_status = 'clean'
+      AND (NOT d.confidential OR EXISTS (
+        SELECT 1 FROM data_room_grants g
+        WHERE g.project_id = d.project_id
+          AND (g.document_id IS NULL OR g.document_id = d.id)
+          AND (g.expires_at IS NULL OR g.expires_at > extract(epoch from now()))
+          AND (g.investor_id = @investor_id OR (g.all_committed AND EXISTS (
+            SELECT 1 FROM transactions t WHERE t.project_id = d.project_id AND t.created_by = @investor_id
+          )))
+      ))
+  ));
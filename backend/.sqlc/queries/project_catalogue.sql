@@ -0,0 +1,101 @@
+-- name: ListProjectCatalogue :many
+WITH catalogue AS (
+    SELECT
+        p.id,
+        p.company_id,
+        COALESCE(
+            (SELECT pa.answer
+             FROM project_answers pa
+             JOIN project_questions pq ON pa.question_id = pq.id
+             WHERE pa.project_id = p.id AND pq.question_key = 'company_name' AND pa.answer != ''
+             LIMIT 1),
+            p.title
+        ) as title,
+        p.description,
+        p.status,
+        p.allow_edit,
+        p.created_at,
+        p.updated_at,
+        c.name as company_name
+    FROM projects p
+    LEFT JOIN companies c ON c.id = p.company_id
+    WHERE p.status = ANY(@statuses::project_status[])
+      AND (sqlc.narg(industry)::text IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          CROSS JOIN LATERAL unnest(
+              CASE WHEN cardinality(pa.choices) > 0 THEN pa.choices ELSE ARRAY[pa.answer] END
+          ) AS value
+          WHERE pa.project_id = p.id AND pq.question_key = 'company_industries'
+            AND lower(value) = lower(sqlc.narg(industry)::text)
+      ))
+      AND (sqlc.narg(stage)::text IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          CROSS JOIN LATERAL unnest(
+              CASE WHEN cardinality(pa.choices) > 0 THEN pa.choices ELSE ARRAY[pa.answer] END
+          ) AS value
+          WHERE pa.project_id = p.id AND pq.question_key = 'company_stage'
+            AND lower(value) = lower(sqlc.narg(stage)::text)
+      ))
+)
+SELECT
+    cat.id,
+    cat.company_id,
+    cat.title,
+    cat.description,
+    cat.status,
+    cat.allow_edit,
+    cat.created_at,
+    cat.updated_at,
+    cat.company_name,
+    (SELECT COUNT(*) FROM project_documents d WHERE d.project_id = cat.id AND d.scan_status = 'clean') as document_count,
+    (SELECT COUNT(*) FROM team_members t WHERE t.company_id = cat.company_id) as team_member_count
+FROM catalogue cat
+WHERE sqlc.narg(cursor_id)::uuid IS NULL
+   OR (CASE @sort::text
+        WHEN 'oldest' THEN (cat.created_at, cat.id) > (sqlc.narg(cursor_time)::bigint, sqlc.narg(cursor_id)::uuid)
+        WHEN 'updated' THEN (cat.updated_at, cat.id) < (sqlc.narg(cursor_time)::bigint, sqlc.narg(cursor_id)::uuid)
+        WHEN 'title' THEN (lower(cat.title), cat.id) > (lower(sqlc.narg(cursor_title)::text), sqlc.narg(cursor_id)::uuid)
+        ELSE (cat.created_at, cat.id) < (sqlc.narg(cursor_time)::bigint, sqlc.narg(cursor_id)::uuid)
+    END)
+ORDER BY
+    CASE WHEN @sort::text = 'oldest' THEN cat.created_at END ASC,
+    CASE WHEN @sort::text = 'updated' THEN cat.updated_at END DESC,
+    CASE WHEN @sort::text = 'title' THEN lower(cat.title) END ASC,
+    CASE WHEN @sort::text NOT IN ('oldest', 'updated', 'title') THEN cat.created_at END DESC,
+    CASE WHEN @sort::text IN ('oldest', 'title') THEN cat.id END ASC,
+    CASE WHEN @sort::text NOT IN ('oldest', 'title') THEN cat.id END DESC
+LIMIT @page_size;
+
+-- name: GetProjectCatalogueFacets :many
+WITH facet_values AS (
+    SELECT DISTINCT
+        pa.project_id,
+        (CASE pq.question_key WHEN 'company_industries' THEN 'industry' ELSE 'stage' END)::text as facet,
+        value
+    FROM project_answers pa
+    JOIN project_questions pq ON pq.id = pa.question_id
+    JOIN projects p ON p.id = pa.project_id
+    CROSS JOIN LATERAL unnest(
+        CASE WHEN cardinality(pa.choices) > 0 THEN pa.choices ELSE ARRAY[pa.answer] END
+    ) AS value
+    WHERE pq.question_key IN ('company_industries', 'company_stage')
+      AND p.status = ANY(@statuses::project_status[])
+      AND value != ''
+)
+SELECT
+    fv.facet,
+    fv.value::text as value,
+    COUNT(DISTINCT fv.project_id) as project_count
+FROM facet_values fv
+WHERE (fv.facet = 'industry' AND (sqlc.narg(stage)::text IS NULL OR EXISTS (
+        SELECT 1 FROM facet_values s
+        WHERE s.project_id = fv.project_id AND s.facet = 'stage' AND lower(s.value) = lower(sqlc.narg(stage)::text)
+    )))
+   OR (fv.facet = 'stage' AND (sqlc.narg(industry)::text IS NULL OR EXISTS (
+        SELECT 1 FROM facet_values i
+        WHERE i.project_id = fv.project_id AND i.facet = 'industry' AND lower(i.value) = lower(sqlc.narg(industry)::text)
+    )))
+GROUP BY fv.facet, fv.value
+ORDER BY fv.facet, project_count DESC, fv.value;
@@ -0,0 +1,44 @@
+-- name: CreateProjectDocumentVersion :one
+INSERT INTO project_document_versions (document_id, project_id, question_id, version, name, storage_key, mime_type, size, uploaded_by)
+VALUES (@document_id, @project_id, @question_id, @version, @name, @storage_key, @mime_type, @size, sqlc.narg(uploaded_by))
+RETURNING *;
+
+-- name: UpdateProjectDocumentFile :one
+UPDATE project_documents
+SET name = @name,
+    storage_key = @storage_key,
+    mime_type = @mime_type,
+    size = @size,
+    version = version + 1,
+    scan_status = 'pending',
+    scan_signature = NULL,
+    scanned_at = NULL,
+    updated_at = extract(epoch from now())
+WHERE id = @id AND project_id = @project_id
+RETURNING *;
+
+-- name: ListProjectDocumentVersions :many
+SELECT v.*, u.first_name as uploaded_by_first_name, u.last_name as uploaded_by_last_name
+FROM project_document_versions v
+LEFT JOIN users u ON u.id = v.uploaded_by
+WHERE v.document_id = @document_id AND v.project_id = @project_id
+  AND (@include_unscanned::boolean OR NOT EXISTS (
+    SELECT 1 FROM project_documents d
+    WHERE d.id = v.document_id AND d.version = v.version AND d.scan_status <> 'clean'
+  ))
+ORDER BY v.version DESC;
+
+-- name: ListDocumentVersionSnapshots :many
+SELECT sd.version_id, s.id as snapshot_id, s.version_number, s.created_at
+FROM project_snapshot_documents sd
+JOIN project_snapshots s ON s.id = sd.snapshot_id
+WHERE sd.version_id = ANY(@version_ids::uuid[])
+ORDER BY s.version_number;
+
+-- name: AddProjectSnapshotDocuments :exec
+INSERT INTO project_snapshot_documents (snapshot_id, version_id)
+SELECT @snapshot_id, v.id
+FROM project_documents d
+JOIN project_document_versions v ON v.document_id = d.id AND v.version = d.version
+WHERE d.project_id = @project_id
+ON CONFLICT DO NOTHING;
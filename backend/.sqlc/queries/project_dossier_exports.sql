@@ -0,0 +1,15 @@
+-- name: GetProjectDossierExport :one
+SELECT * FROM project_dossier_exports
+WHERE snapshot_id = $1 AND format = $2 AND renderer_version = $3 AND company_name = $4;
+
+-- name: UpsertProjectDossierExport :exec
+INSERT INTO project_dossier_exports (snapshot_id, format, renderer_version, company_name, content)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (snapshot_id, format, renderer_version) DO UPDATE
+SET company_name = EXCLUDED.company_name,
+    content = EXCLUDED.content,
+    created_at = extract(epoch from now());
+
+-- name: DeleteStaleProjectDossierExports :exec
+DELETE FROM project_dossier_exports
+WHERE snapshot_id = $1 AND renderer_version <> $2;
@@ -0,0 +1,58 @@
+-- name: SearchProjects :many
+-- total is the number of projects matching the search, over every page
+SELECT
+    p.id,
+    p.company_id,
+    COALESCE(
+        c.name,
+        ''
+    ) as company_name,
+    p.title,
+    p.description,
+    p.status,
+    p.allow_edit,
+    p.created_at,
+    p.updated_at,
+    ranked.rank::real as rank,
+    ranked.total,
+    (CASE
+        WHEN @query::text = '' THEN ''
+        ELSE ts_headline('english', project_search_text(p.id), websearch_to_tsquery('english', @query::text),
+            'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "')
+    END)::text as snippet
+FROM (
+    SELECT
+        sp.id,
+        sp.created_at,
+        (CASE
+            WHEN @query::text = '' THEN 0
+            ELSE ts_rank(psi.search_vector, websearch_to_tsquery('english', @query::text))
+        END) as rank,
+        count(*) OVER () as total
+    FROM projects sp
+    LEFT JOIN project_search_index psi ON psi.project_id = sp.id
+    WHERE (@query::text = '' OR psi.search_vector @@ websearch_to_tsquery('english', @query::text))
+      AND (sqlc.narg(status)::project_status IS NULL OR sp.status = sqlc.narg(status)::project_status)
+      AND (sqlc.narg(industry)::text IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          WHERE pa.project_id = sp.id AND pq.question_key = 'company_industries'
+            AND (lower(pa.answer) = lower(sqlc.narg(industry)::text)
+                OR lower(sqlc.narg(industry)::text) IN (SELECT lower(choice) FROM unnest(pa.choices) choice))
+      ))
+      AND (sqlc.narg(stage)::text IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          WHERE pa.project_id = sp.id AND pq.question_key = 'company_stage'
+            AND (lower(pa.answer) = lower(sqlc.narg(stage)::text)
+                OR lower(sqlc.narg(stage)::text) IN (SELECT lower(choice) FROM unnest(pa.choices) choice))
+      ))
+      AND (sqlc.narg(created_after)::bigint IS NULL OR sp.created_at >= sqlc.narg(created_after)::bigint)
+      AND (sqlc.narg(created_before)::bigint IS NULL OR sp.created_at <= sqlc.narg(created_before)::bigint)
+    ORDER BY rank DESC, sp.created_at DESC
+    LIMIT @page_size OFFSET @page_offset
+) ranked
+JOIN projects p ON p.id = ranked.id
+LEFT JOIN companies c ON c.id = p.company_id
+ORDER BY ranked.rank DESC, ranked.created_at DESC;
+
@@ -58,10 +58,15 @@ SELECT
             SELECT coalesce(jsonb_agg(
                 jsonb_build_object(
                     'id', pd.id::text,
+                    'version', pd.version,
+                    'version_id', (
+                        SELECT v.id::text FROM project_document_versions v
+                        WHERE v.document_id = pd.id AND v.version = pd.version
+                    ),
                     'project_id', pd.project_id::text,
                     'question_id', pd.question_id::text,
                     'name', pd.name,
-                    'url', pd.url,
+                    'storage_key', pd.storage_key,
                     'section', pd.section,
                     'sub_section', pd.sub_section,
                     'mime_type', pd.mime_type,
@@ -91,9 +96,9 @@ SELECT
                     'detailed_biography', tm.detailed_biography,
                     'previous_work', tm.previous_work,
                     'resume_external_url', tm.resume_external_url,
-                    'resume_internal_url', tm.resume_internal_url,
+                    'resume_internal_key', tm.resume_internal_key,
                     'founders_agreement_external_url', tm.founders_agreement_external_url,
-                    'founders_agreement_internal_url', tm.founders_agreement_internal_url,
+                    'founders_agreement_internal_key', tm.founders_agreement_internal_key,
                     'created_at', tm.created_at,
                     'updated_at', tm.updated_at,
                     'social_links', tm.social_links
@@ -117,3 +122,6 @@ SELECT count(id) FROM project_snapshots WHERE project_id = $1;
 
 -- name: GetLatestProjectSnapshot :one
 SELECT * FROM project_snapshots WHERE project_id = $1 ORDER BY created_at DESC LIMIT 1;
+
+-- name: GetProjectSnapshotByVersion :one
+SELECT * FROM project_snapshots WHERE project_id = $1 AND version_number = $2 LIMIT 1;
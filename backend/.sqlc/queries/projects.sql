@@ -82,7 +82,7 @@ INSERT INTO project_documents (
     project_id,
     question_id,
     name,
-    url,
+    storage_key,
     section,
     sub_section,
     mime_type,
@@ -94,7 +94,7 @@ INSERT INTO project_documents (
     $1, -- project_id
     $2, -- question_id
     $3, -- name
-    $4, -- url
+    $4, -- storage_key
     $5, -- section
     $6, -- sub_section
     $7, -- mime_type
@@ -236,17 +236,21 @@ INSERT INTO project_answers (
 ) RETURNING *;
 
 -- name: GetProjectComments :many
-SELECT pc.*, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at FROM project_comments pc
+SELECT pc.*, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at, aps.version_number as anchor_snapshot_version FROM project_comments pc
 LEFT JOIN users u ON u.id = pc.commenter_id
 LEFT JOIN project_snapshots ps ON ps.id = pc.resolved_by_snapshot_id
-WHERE pc.project_id = $1
+LEFT JOIN project_snapshots aps ON aps.id = pc.anchor_snapshot_id
+WHERE pc.project_id = @project_id
+  AND (pc.visibility = 'public' OR @include_internal::boolean)
 ORDER BY pc.created_at DESC;
 
 -- name: GetProjectComment :one
-SELECT pc.*, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at FROM project_comments pc
+SELECT pc.*, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at, aps.version_number as anchor_snapshot_version FROM project_comments pc
 LEFT JOIN users u ON u.id = pc.commenter_id
 LEFT JOIN project_snapshots ps ON ps.id = pc.resolved_by_snapshot_id
-WHERE pc.id = $1 AND pc.project_id = $2
+LEFT JOIN project_snapshots aps ON aps.id = pc.anchor_snapshot_id
+WHERE pc.id = @id AND pc.project_id = @project_id
+  AND (pc.visibility = 'public' OR @include_internal::boolean)
 LIMIT 1;
 
 -- name: CreateProjectComment :one
@@ -254,12 +258,22 @@ INSERT INTO project_comments (
     project_id,
     target_id,
     comment,
-    commenter_id
+    commenter_id,
+    visibility,
+    anchor_snapshot_id,
+    anchor_start,
+    anchor_end,
+    anchor_text
 ) VALUES (
     $1, -- project_id
     $2, -- target_id
     $3, -- comment
-    $4  -- commenter_id
+    $4, -- commenter_id
+    $5, -- visibility
+    $6, -- anchor_snapshot_id
+    $7, -- anchor_start
+    $8, -- anchor_end
+    $9  -- anchor_text
 ) RETURNING *;
 
 -- name: UpdateProjectComment :one
@@ -294,7 +308,7 @@ UPDATE project_comments
 SET
     resolved_by_snapshot_id = $1,
     updated_at = extract(epoch from now())
-WHERE projecT_id = $2 AND resolved_by_snapshot_id IS NULL;
+WHERE projecT_id = $2 AND resolved_by_snapshot_id IS NULL AND visibility = 'public';
 
 -- name: ListAllProjects :many
 SELECT
@@ -319,78 +333,6 @@ LEFT JOIN companies c ON c.id = p.company_id
 GROUP BY p.id, c.id, c.name
 ORDER BY p.created_at DESC;
 
--- name: GetNewProjectsByStatus :many
-SELECT 
-    p.id, 
-    p.company_id, 
-    COALESCE(
-        (SELECT pa.answer 
-         FROM project_answers pa
-         JOIN project_questions pq ON pa.question_id = pq.id
-         WHERE pa.project_id = p.id AND pq.question_key = 'company_name' AND pa.answer != ''
-         LIMIT 1),
-        p.title
-    ) as title,
-    p.description, 
-    p.status, 
-    p.allow_edit,
-    p.created_at, 
-    p.updated_at,
-    c.name as company_name,
-    COUNT(d.id) as document_count,
-    COUNT(t.id) as team_member_count
-FROM 
-    projects p
-LEFT JOIN 
-    project_documents d ON d.project_id = p.id
-LEFT JOIN 
-    team_members t ON t.company_id = p.company_id
-LEFT JOIN 
-    companies c ON c.id = p.company_id
-WHERE 
-    p.status = $1
-GROUP BY 
-    p.id, c.name
-ORDER BY 
-    p.created_at DESC
-LIMIT 
-    $2;
-
--- name: GetNewProjectsAnyStatus :many
-SELECT 
-    p.id, 
-    p.company_id, 
-    COALESCE(
-        (SELECT pa.answer 
-         FROM project_answers pa
-         JOIN project_questions pq ON pa.question_id = pq.id
-         WHERE pa.project_id = p.id AND pq.question_key = 'company_name' AND pa.answer != ''
-         LIMIT 1),
-        p.title
-    ) as title,
-    p.description, 
-    p.status, 
-    p.allow_edit,
-    p.created_at, 
-    p.updated_at,
-    c.name as company_name,
-    COUNT(d.id) as document_count,
-    COUNT(t.id) as team_member_count
-FROM 
-    projects p
-LEFT JOIN 
-    project_documents d ON d.project_id = p.id
-LEFT JOIN 
-    team_members t ON t.company_id = p.company_id
-LEFT JOIN 
-    companies c ON c.id = p.company_id
-GROUP BY 
-    p.id, c.name
-ORDER BY 
-    p.created_at DESC
-LIMIT 
-    $1;
-
 -- name: MatchProjectTitleToCompanyNameQuestion :exec
 UPDATE projects
 SET title = (
@@ -411,4 +353,18 @@ WHERE id = $2;
 
 -- name: CountUnresolvedProjectComments :one
 SELECT COUNT(*) FROM project_comments
-WHERE project_id = $1 AND resolved = false;
+WHERE project_id = $1 AND resolved = false AND visibility = 'public';
+
+-- name: GetProjectCommentsByIDs :many
+SELECT * FROM project_comments
+WHERE project_id = @project_id
+  AND id = ANY(@ids::uuid[])
+  AND (visibility = 'public' OR @include_internal::boolean);
+
+-- name: SetProjectCommentsResolved :many
+UPDATE project_comments
+SET
+    resolved = @resolved,
+    updated_at = extract(epoch from now())
+WHERE project_id = @project_id AND id = ANY(@ids::uuid[])
+RETURNING *;
@@ -0,0 +1,68 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (user_id, name, industry, stage, notify)
+VALUES (@user_id, @name, sqlc.narg(industry), sqlc.narg(stage), @notify)
+RETURNING *;
+
+-- name: ListSavedSearches :many
+SELECT * FROM saved_searches
+WHERE user_id = @user_id
+ORDER BY created_at DESC, id DESC;
+
+-- name: CountSavedSearches :one
+SELECT COUNT(*) FROM saved_searches WHERE user_id = @user_id;
+
+-- name: UpdateSavedSearch :one
+UPDATE saved_searches
+SET name = @name, industry = sqlc.narg(industry), stage = sqlc.narg(stage), notify = @notify,
+    updated_at = extract(epoch from now())
+WHERE id = @id AND user_id = @user_id
+RETURNING *;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches WHERE id = @id AND user_id = @user_id;
+
+-- name: MatchSavedSearches :many
+WITH matched AS (
+    INSERT INTO saved_search_matches (saved_search_id, project_id)
+    SELECT ss.id, p.id
+    FROM saved_searches ss
+    JOIN projects p ON p.status = 'verified'
+    WHERE ss.notify
+      AND EXISTS (
+          SELECT 1 FROM project_activities pa
+          WHERE pa.project_id = p.id
+            AND pa.activity_type = 'status_changed'
+            AND pa.payload->>'to' = 'verified'
+            AND pa.created_at >= ss.created_at
+      )
+      AND (ss.industry IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          CROSS JOIN LATERAL unnest(
+              CASE WHEN cardinality(pa.choices) > 0 THEN pa.choices ELSE ARRAY[pa.answer] END
+          ) AS value
+          WHERE pa.project_id = p.id AND pq.question_key = 'company_industries'
+            AND lower(value) = lower(ss.industry)
+      ))
+      AND (ss.stage IS NULL OR EXISTS (
+          SELECT 1 FROM project_answers pa
+          JOIN project_questions pq ON pq.id = pa.question_id
+          CROSS JOIN LATERAL unnest(
+              CASE WHEN cardinality(pa.choices) > 0 THEN pa.choices ELSE ARRAY[pa.answer] END
+          ) AS value
+          WHERE pa.project_id = p.id AND pq.question_key = 'company_stage'
+            AND lower(value) = lower(ss.stage)
+      ))
+    ON CONFLICT DO NOTHING
+    RETURNING saved_search_id, project_id
+)
+SELECT
+    m.saved_search_id,
+    m.project_id,
+    ss.user_id,
+    ss.name as saved_search_name,
+    p.title
+FROM matched m
+JOIN saved_searches ss ON ss.id = m.saved_search_id
+JOIN projects p ON p.id = m.project_id
+ORDER BY ss.user_id, p.title;
@@ -0,0 +1,36 @@
+-- name: ListStorageReferences :many
+-- Every file key stored in the database, with the column and record it is stored in
+SELECT 'users.profile_picture_key'::text AS source, id::text AS record_id, profile_picture_key::text AS storage_key
+FROM users WHERE profile_picture_key IS NOT NULL
+UNION ALL
+SELECT 'team_members.resume_internal_key', id::text, resume_internal_key::text
+FROM team_members WHERE resume_internal_key IS NOT NULL
+UNION ALL
+SELECT 'team_members.founders_agreement_internal_key', id::text, founders_agreement_internal_key::text
+FROM team_members WHERE founders_agreement_internal_key IS NOT NULL
+UNION ALL
+SELECT 'project_documents.storage_key', id::text, storage_key::text
+FROM project_documents
+UNION ALL
+SELECT 'project_document_versions.storage_key', id::text, storage_key::text
+FROM project_document_versions
+UNION ALL
+SELECT 'document_uploads.storage_key', id::text, storage_key::text
+FROM document_uploads
+UNION ALL
+SELECT 'document_watermarks.storage_key', document_id::text, storage_key::text
+FROM document_watermarks
+UNION ALL
+SELECT 'message_attachments.storage_key', id::text, storage_key::text
+FROM message_attachments
+UNION ALL
+SELECT 'project_snapshots.documents', s.id::text, d->>'storage_key'
+FROM project_snapshots s,
+     jsonb_array_elements(CASE WHEN jsonb_typeof(s.data->'documents') = 'array' THEN s.data->'documents' ELSE '[]'::jsonb END) d
+WHERE d->>'storage_key' IS NOT NULL
+UNION ALL
+SELECT 'project_snapshots.team_members', s.id::text, k.key
+FROM project_snapshots s,
+     jsonb_array_elements(CASE WHEN jsonb_typeof(s.data->'team_members') = 'array' THEN s.data->'team_members' ELSE '[]'::jsonb END) m,
+     LATERAL (VALUES (m->>'resume_internal_key'), (m->>'founders_agreement_internal_key')) AS k(key)
+WHERE k.key IS NOT NULL;
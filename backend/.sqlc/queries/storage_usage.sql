@@ -0,0 +1,87 @@
+-- name: ListCompanyStorageUsage :many
+SELECT company_id, company_name, quota, documents_size, images_size, versions_size, watermarks_size, team_documents_size, pending_uploads_size
+FROM (
+    SELECT
+        c.id AS company_id,
+        c.name AS company_name,
+        q.quota,
+        (
+            SELECT coalesce(sum(d.size), 0) FROM project_documents d
+            JOIN projects p ON p.id = d.project_id
+            WHERE p.company_id = c.id AND d.mime_type NOT LIKE 'image/%'
+        )::bigint AS documents_size,
+        (
+            SELECT coalesce(sum(d.size), 0) FROM project_documents d
+            JOIN projects p ON p.id = d.project_id
+            WHERE p.company_id = c.id AND d.mime_type LIKE 'image/%'
+        )::bigint AS images_size,
+        (
+            SELECT coalesce(sum(v.size), 0) FROM project_document_versions v
+            JOIN projects p ON p.id = v.project_id
+            WHERE p.company_id = c.id AND NOT EXISTS (
+                SELECT 1 FROM project_documents d WHERE d.id = v.document_id AND d.version = v.version
+            )
+        )::bigint AS versions_size,
+        (
+            SELECT coalesce(sum(w.size), 0) FROM document_watermarks w
+            JOIN project_documents d ON d.id = w.document_id
+            JOIN projects p ON p.id = d.project_id
+            WHERE p.company_id = c.id
+        )::bigint AS watermarks_size,
+        (
+            SELECT coalesce(sum(t.resume_internal_size + t.founders_agreement_internal_size), 0) FROM team_members t
+            WHERE t.company_id = c.id
+        )::bigint AS team_documents_size,
+        (
+            SELECT coalesce(sum(u.size), 0) FROM document_uploads u
+            JOIN projects p ON p.id = u.project_id
+            WHERE p.company_id = c.id AND u.expires_at > extract(epoch from now())
+        )::bigint AS pending_uploads_size
+    FROM companies c
+    LEFT JOIN company_storage_quotas q ON q.company_id = c.id
+    WHERE sqlc.narg(company_id)::uuid IS NULL OR c.id = sqlc.narg(company_id)::uuid
+) company_usage
+ORDER BY documents_size + images_size + versions_size + team_documents_size + pending_uploads_size DESC, company_name;
+
+-- name: ListProjectStorageUsage :many
+SELECT
+    p.id AS project_id,
+    p.title,
+    coalesce(sum(d.size) FILTER (WHERE d.mime_type NOT LIKE 'image/%'), 0)::bigint AS documents_size,
+    coalesce(sum(d.size) FILTER (WHERE d.mime_type LIKE 'image/%'), 0)::bigint AS images_size,
+    (
+        SELECT coalesce(sum(v.size), 0) FROM project_document_versions v
+        WHERE v.project_id = p.id AND NOT EXISTS (
+            SELECT 1 FROM project_documents cd WHERE cd.id = v.document_id AND cd.version = v.version
+        )
+    )::bigint AS versions_size,
+    (
+        SELECT coalesce(sum(w.size), 0) FROM document_watermarks w
+        JOIN project_documents wd ON wd.id = w.document_id
+        WHERE wd.project_id = p.id
+    )::bigint AS watermarks_size,
+    (
+        SELECT coalesce(sum(u.size), 0) FROM document_uploads u
+        WHERE u.project_id = p.id AND u.expires_at > extract(epoch from now())
+    )::bigint AS pending_uploads_size
+FROM projects p
+LEFT JOIN project_documents d ON d.project_id = p.id
+WHERE p.company_id = $1
+GROUP BY p.id
+ORDER BY p.created_at;
+
+-- name: LockCompanyStorage :exec
+-- Serializes the quota checks of a company until the end of the transaction
+SELECT pg_advisory_xact_lock(hashtext('storage:' || sqlc.arg(company_id)::text));
+
+-- name: UpsertCompanyStorageQuota :one
+INSERT INTO company_storage_quotas (company_id, quota, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (company_id) DO UPDATE
+SET quota = EXCLUDED.quota,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = extract(epoch from now())
+RETURNING *;
+
+-- name: DeleteCompanyStorageQuota :exec
+DELETE FROM company_storage_quotas WHERE company_id = $1;
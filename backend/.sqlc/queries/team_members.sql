@@ -4,22 +4,22 @@ INSERT INTO team_members (
     title, linkedin_url, is_account_owner,
     personal_website, commitment_type, introduction,
     industry_experience, detailed_biography, previous_work,
-    resume_external_url, resume_internal_url,
-    founders_agreement_external_url, founders_agreement_internal_url,
+    resume_external_url, founders_agreement_external_url,
     social_links
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8,
-    $9, $10, $11, $12, $13, $14, $15, $16,
-    $17
+    $9, $10, $11, $12, $13, $14, $15
 )
 RETURNING *; 
 
 -- name: UpdateTeamMemberDocuments :exec
 UPDATE team_members
 SET
-    resume_internal_url = $1,
-    founders_agreement_internal_url = $2
-WHERE id = $3 AND company_id = $4;
+    resume_internal_key = $1,
+    resume_internal_size = $2,
+    founders_agreement_internal_key = $3,
+    founders_agreement_internal_size = $4
+WHERE id = $5 AND company_id = $6;
 
 -- name: ListTeamMembers :many
 SELECT * FROM team_members 
@@ -46,9 +46,11 @@ SET
     industry_experience = COALESCE(NULLIF(@industry_experience::text, ''), industry_experience),
     previous_work = NULLIF(@previous_work::text, ''),
     resume_external_url = NULLIF(@resume_external_url::text, ''),
-    resume_internal_url = NULLIF(@resume_internal_url::text, ''),
+    resume_internal_key = CASE WHEN @clear_resume_internal::boolean THEN NULL ELSE resume_internal_key END,
+    resume_internal_size = CASE WHEN @clear_resume_internal::boolean THEN 0 ELSE resume_internal_size END,
     founders_agreement_external_url = NULLIF(@founders_agreement_external_url::text, ''),
-    founders_agreement_internal_url = NULLIF(@founders_agreement_internal_url::text, ''),
+    founders_agreement_internal_key = CASE WHEN @clear_founders_agreement_internal::boolean THEN NULL ELSE founders_agreement_internal_key END,
+    founders_agreement_internal_size = CASE WHEN @clear_founders_agreement_internal::boolean THEN 0 ELSE founders_agreement_internal_size END,
     updated_at = extract(epoch from now())
 WHERE id = @id AND company_id = @company_id
 RETURNING *;
@@ -7,7 +7,8 @@ WHERE id = $1;
 SELECT email_verified FROM users WHERE email = $1;
 
 -- name: UserExistsByEmail :one
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1);
+-- Emails are compared case-insensitively, Alice@x.com and alice@x.com are the same user
+SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1));
 
 -- name: NewUser :one
 INSERT INTO users
@@ -25,7 +26,7 @@ RETURNING
     COALESCE(title, '') as title,
     COALESCE(bio, '') as bio,
     COALESCE(linkedin, '') as linkedin,
-    profile_picture_url,
+    profile_picture_key,
     COALESCE(created_at, EXTRACT(EPOCH FROM NOW())::bigint) as created_at,
     NULLIF(updated_at, 0)::bigint as updated_at;
 
@@ -48,7 +49,7 @@ SELECT
     COALESCE(title, '') as title,
     COALESCE(bio, '') as bio,
     COALESCE(linkedin, '') as linkedin,
-    profile_picture_url,
+    profile_picture_key,
     COALESCE(created_at, EXTRACT(EPOCH FROM NOW())::bigint) as created_at,
     NULLIF(updated_at, 0)::bigint as updated_at
 FROM users
@@ -113,5 +114,10 @@ DELETE FROM user_socials WHERE id = $1;
 
 -- name: UpdateUserProfilePicture :exec
 UPDATE users
-SET profile_picture_url = $1
+SET profile_picture_key = $1
 WHERE id = $2;
+
+-- name: ListUserIDsWithPermission :many
+SELECT id FROM users
+WHERE permissions & @permission::int <> 0
+ORDER BY created_at;
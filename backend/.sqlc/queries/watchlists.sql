@@ -0,0 +1,57 @@
+-- name: AddWatchlistItem :one
+INSERT INTO watchlist_items (user_id, project_id, note, last_status, last_funding_total)
+SELECT @user_id, p.id, @note, p.status,
+    (SELECT COALESCE(SUM(t.value_amount), 0) FROM transactions t WHERE t.project_id = p.id)
+FROM projects p
+WHERE p.id = @project_id
+ON CONFLICT (user_id, project_id) DO UPDATE
+SET note = EXCLUDED.note, updated_at = extract(epoch from now())
+RETURNING id;
+
+-- name: UpdateWatchlistItemNote :execrows
+UPDATE watchlist_items
+SET note = @note, updated_at = extract(epoch from now())
+WHERE user_id = @user_id AND project_id = @project_id;
+
+-- name: DeleteWatchlistItem :execrows
+DELETE FROM watchlist_items
+WHERE user_id = @user_id AND project_id = @project_id;
+
+-- name: ListWatchlistItems :many
+SELECT
+    w.id,
+    w.project_id,
+    w.note,
+    w.created_at,
+    w.updated_at,
+    p.title,
+    p.status,
+    c.name as company_name,
+    (SELECT COALESCE(SUM(t.value_amount), 0) FROM transactions t WHERE t.project_id = p.id)::text as funding_total
+FROM watchlist_items w
+JOIN projects p ON p.id = w.project_id
+JOIN companies c ON c.id = p.company_id
+WHERE w.user_id = @user_id
+ORDER BY w.created_at DESC, w.id DESC;
+
+-- name: ListWatchlistChanges :many
+SELECT
+    w.id,
+    w.user_id,
+    w.project_id,
+    p.title,
+    w.last_status,
+    p.status as current_status,
+    w.last_funding_total::text as last_funding_total,
+    f.total::text as current_funding_total
+FROM watchlist_items w
+JOIN projects p ON p.id = w.project_id
+CROSS JOIN LATERAL (
+    SELECT COALESCE(SUM(t.value_amount), 0) as total FROM transactions t WHERE t.project_id = w.project_id
+) f
+WHERE w.last_status != p.status OR w.last_funding_total != f.total;
+
+-- name: UpdateWatchlistItemState :exec
+UPDATE watchlist_items
+SET last_status = @last_status, last_funding_total = @last_funding_total::text::decimal
+WHERE id = @id;
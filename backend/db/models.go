@@ -11,6 +11,64 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CommentVisibilityEnum string
+
+const (
+	CommentVisibilityEnumPublic   CommentVisibilityEnum = "public"
+	CommentVisibilityEnumInternal CommentVisibilityEnum = "internal"
+)
+
+func (e *CommentVisibilityEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CommentVisibilityEnum(s)
+	case string:
+		*e = CommentVisibilityEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CommentVisibilityEnum: %T", src)
+	}
+	return nil
+}
+
+type NullCommentVisibilityEnum struct {
+	CommentVisibilityEnum CommentVisibilityEnum `json:"comment_visibility_enum"`
+	Valid                 bool                  `json:"valid"` // Valid is true if CommentVisibilityEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCommentVisibilityEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.CommentVisibilityEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CommentVisibilityEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCommentVisibilityEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CommentVisibilityEnum), nil
+}
+
+func (e CommentVisibilityEnum) Valid() bool {
+	switch e {
+	case CommentVisibilityEnumPublic,
+		CommentVisibilityEnumInternal:
+		return true
+	}
+	return false
+}
+
+func AllCommentVisibilityEnumValues() []CommentVisibilityEnum {
+	return []CommentVisibilityEnum{
+		CommentVisibilityEnumPublic,
+		CommentVisibilityEnumInternal,
+	}
+}
+
 type ConditionTypeEnum string
 
 const (
@@ -75,6 +133,247 @@ func AllConditionTypeEnumValues() []ConditionTypeEnum {
 	}
 }
 
+type DataRoomAction string
+
+const (
+	DataRoomActionView     DataRoomAction = "view"
+	DataRoomActionDownload DataRoomAction = "download"
+)
+
+func (e *DataRoomAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DataRoomAction(s)
+	case string:
+		*e = DataRoomAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DataRoomAction: %T", src)
+	}
+	return nil
+}
+
+type NullDataRoomAction struct {
+	DataRoomAction DataRoomAction `json:"data_room_action"`
+	Valid          bool           `json:"valid"` // Valid is true if DataRoomAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDataRoomAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.DataRoomAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DataRoomAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDataRoomAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DataRoomAction), nil
+}
+
+func (e DataRoomAction) Valid() bool {
+	switch e {
+	case DataRoomActionView,
+		DataRoomActionDownload:
+		return true
+	}
+	return false
+}
+
+func AllDataRoomActionValues() []DataRoomAction {
+	return []DataRoomAction{
+		DataRoomActionView,
+		DataRoomActionDownload,
+	}
+}
+
+type DossierFormat string
+
+const (
+	DossierFormatHtml DossierFormat = "html"
+	DossierFormatPdf  DossierFormat = "pdf"
+)
+
+func (e *DossierFormat) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DossierFormat(s)
+	case string:
+		*e = DossierFormat(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DossierFormat: %T", src)
+	}
+	return nil
+}
+
+type NullDossierFormat struct {
+	DossierFormat DossierFormat `json:"dossier_format"`
+	Valid         bool          `json:"valid"` // Valid is true if DossierFormat is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDossierFormat) Scan(value interface{}) error {
+	if value == nil {
+		ns.DossierFormat, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DossierFormat.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDossierFormat) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DossierFormat), nil
+}
+
+func (e DossierFormat) Valid() bool {
+	switch e {
+	case DossierFormatHtml,
+		DossierFormatPdf:
+		return true
+	}
+	return false
+}
+
+func AllDossierFormatValues() []DossierFormat {
+	return []DossierFormat{
+		DossierFormatHtml,
+		DossierFormatPdf,
+	}
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatCsv  ExportFormat = "csv"
+	ExportFormatJson ExportFormat = "json"
+	ExportFormatXlsx ExportFormat = "xlsx"
+)
+
+func (e *ExportFormat) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportFormat(s)
+	case string:
+		*e = ExportFormat(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportFormat: %T", src)
+	}
+	return nil
+}
+
+type NullExportFormat struct {
+	ExportFormat ExportFormat `json:"export_format"`
+	Valid        bool         `json:"valid"` // Valid is true if ExportFormat is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportFormat) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportFormat, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportFormat.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportFormat) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportFormat), nil
+}
+
+func (e ExportFormat) Valid() bool {
+	switch e {
+	case ExportFormatCsv,
+		ExportFormatJson,
+		ExportFormatXlsx:
+		return true
+	}
+	return false
+}
+
+func AllExportFormatValues() []ExportFormat {
+	return []ExportFormat{
+		ExportFormatCsv,
+		ExportFormatJson,
+		ExportFormatXlsx,
+	}
+}
+
+type ExportJobStatus string
+
+const (
+	ExportJobStatusPending   ExportJobStatus = "pending"
+	ExportJobStatusRunning   ExportJobStatus = "running"
+	ExportJobStatusCompleted ExportJobStatus = "completed"
+	ExportJobStatusFailed    ExportJobStatus = "failed"
+)
+
+func (e *ExportJobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportJobStatus(s)
+	case string:
+		*e = ExportJobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportJobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullExportJobStatus struct {
+	ExportJobStatus ExportJobStatus `json:"export_job_status"`
+	Valid           bool            `json:"valid"` // Valid is true if ExportJobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportJobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportJobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportJobStatus), nil
+}
+
+func (e ExportJobStatus) Valid() bool {
+	switch e {
+	case ExportJobStatusPending,
+		ExportJobStatusRunning,
+		ExportJobStatusCompleted,
+		ExportJobStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllExportJobStatusValues() []ExportJobStatus {
+	return []ExportJobStatus{
+		ExportJobStatusPending,
+		ExportJobStatusRunning,
+		ExportJobStatusCompleted,
+		ExportJobStatusFailed,
+	}
+}
+
 type GroupTypeEnum string
 
 const (
@@ -276,6 +575,167 @@ func AllInvestmentStatusValues() []InvestmentStatus {
 	}
 }
 
+type NotificationType string
+
+const (
+	NotificationTypeSavedSearchMatch      NotificationType = "saved_search_match"
+	NotificationTypeWatchedProjectStatus  NotificationType = "watched_project_status"
+	NotificationTypeWatchedProjectFunding NotificationType = "watched_project_funding"
+	NotificationTypeQaQuestionAsked       NotificationType = "qa_question_asked"
+	NotificationTypeQaQuestionAnswered    NotificationType = "qa_question_answered"
+	NotificationTypeQaFollowUp            NotificationType = "qa_follow_up"
+	NotificationTypeDataRoomAccessGranted NotificationType = "data_room_access_granted"
+	NotificationTypeDocumentInfected      NotificationType = "document_infected"
+)
+
+func (e *NotificationType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationType(s)
+	case string:
+		*e = NotificationType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationType: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationType struct {
+	NotificationType NotificationType `json:"notification_type"`
+	Valid            bool             `json:"valid"` // Valid is true if NotificationType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationType) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationType), nil
+}
+
+func (e NotificationType) Valid() bool {
+	switch e {
+	case NotificationTypeSavedSearchMatch,
+		NotificationTypeWatchedProjectStatus,
+		NotificationTypeWatchedProjectFunding,
+		NotificationTypeQaQuestionAsked,
+		NotificationTypeQaQuestionAnswered,
+		NotificationTypeQaFollowUp,
+		NotificationTypeDataRoomAccessGranted,
+		NotificationTypeDocumentInfected:
+		return true
+	}
+	return false
+}
+
+func AllNotificationTypeValues() []NotificationType {
+	return []NotificationType{
+		NotificationTypeSavedSearchMatch,
+		NotificationTypeWatchedProjectStatus,
+		NotificationTypeWatchedProjectFunding,
+		NotificationTypeQaQuestionAsked,
+		NotificationTypeQaQuestionAnswered,
+		NotificationTypeQaFollowUp,
+		NotificationTypeDataRoomAccessGranted,
+		NotificationTypeDocumentInfected,
+	}
+}
+
+type ProjectActivityType string
+
+const (
+	ProjectActivityTypeStatusChanged      ProjectActivityType = "status_changed"
+	ProjectActivityTypeSnapshotCreated    ProjectActivityType = "snapshot_created"
+	ProjectActivityTypeCommentCreated     ProjectActivityType = "comment_created"
+	ProjectActivityTypeCommentResolved    ProjectActivityType = "comment_resolved"
+	ProjectActivityTypeCommentUnresolved  ProjectActivityType = "comment_unresolved"
+	ProjectActivityTypeDocumentUploaded   ProjectActivityType = "document_uploaded"
+	ProjectActivityTypeDocumentDeleted    ProjectActivityType = "document_deleted"
+	ProjectActivityTypeTeamMemberAdded    ProjectActivityType = "team_member_added"
+	ProjectActivityTypeTeamMemberUpdated  ProjectActivityType = "team_member_updated"
+	ProjectActivityTypeTeamMemberRemoved  ProjectActivityType = "team_member_removed"
+	ProjectActivityTypeTransactionCreated ProjectActivityType = "transaction_created"
+)
+
+func (e *ProjectActivityType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectActivityType(s)
+	case string:
+		*e = ProjectActivityType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectActivityType: %T", src)
+	}
+	return nil
+}
+
+type NullProjectActivityType struct {
+	ProjectActivityType ProjectActivityType `json:"project_activity_type"`
+	Valid               bool                `json:"valid"` // Valid is true if ProjectActivityType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectActivityType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectActivityType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectActivityType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectActivityType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectActivityType), nil
+}
+
+func (e ProjectActivityType) Valid() bool {
+	switch e {
+	case ProjectActivityTypeStatusChanged,
+		ProjectActivityTypeSnapshotCreated,
+		ProjectActivityTypeCommentCreated,
+		ProjectActivityTypeCommentResolved,
+		ProjectActivityTypeCommentUnresolved,
+		ProjectActivityTypeDocumentUploaded,
+		ProjectActivityTypeDocumentDeleted,
+		ProjectActivityTypeTeamMemberAdded,
+		ProjectActivityTypeTeamMemberUpdated,
+		ProjectActivityTypeTeamMemberRemoved,
+		ProjectActivityTypeTransactionCreated:
+		return true
+	}
+	return false
+}
+
+func AllProjectActivityTypeValues() []ProjectActivityType {
+	return []ProjectActivityType{
+		ProjectActivityTypeStatusChanged,
+		ProjectActivityTypeSnapshotCreated,
+		ProjectActivityTypeCommentCreated,
+		ProjectActivityTypeCommentResolved,
+		ProjectActivityTypeCommentUnresolved,
+		ProjectActivityTypeDocumentUploaded,
+		ProjectActivityTypeDocumentDeleted,
+		ProjectActivityTypeTeamMemberAdded,
+		ProjectActivityTypeTeamMemberUpdated,
+		ProjectActivityTypeTeamMemberRemoved,
+		ProjectActivityTypeTransactionCreated,
+	}
+}
+
 type ProjectStatus string
 
 const (
@@ -346,6 +806,125 @@ func AllProjectStatusValues() []ProjectStatus {
 	}
 }
 
+type QaVisibility string
+
+const (
+	QaVisibilityPrivate QaVisibility = "private"
+	QaVisibilityPublic  QaVisibility = "public"
+)
+
+func (e *QaVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QaVisibility(s)
+	case string:
+		*e = QaVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QaVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullQaVisibility struct {
+	QaVisibility QaVisibility `json:"qa_visibility"`
+	Valid        bool         `json:"valid"` // Valid is true if QaVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQaVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.QaVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QaVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQaVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QaVisibility), nil
+}
+
+func (e QaVisibility) Valid() bool {
+	switch e {
+	case QaVisibilityPrivate,
+		QaVisibilityPublic:
+		return true
+	}
+	return false
+}
+
+func AllQaVisibilityValues() []QaVisibility {
+	return []QaVisibility{
+		QaVisibilityPrivate,
+		QaVisibilityPublic,
+	}
+}
+
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+)
+
+func (e *ScanStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScanStatus(s)
+	case string:
+		*e = ScanStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScanStatus: %T", src)
+	}
+	return nil
+}
+
+type NullScanStatus struct {
+	ScanStatus ScanStatus `json:"scan_status"`
+	Valid      bool       `json:"valid"` // Valid is true if ScanStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScanStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScanStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScanStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScanStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScanStatus), nil
+}
+
+func (e ScanStatus) Valid() bool {
+	switch e {
+	case ScanStatusPending,
+		ScanStatusClean,
+		ScanStatusInfected:
+		return true
+	}
+	return false
+}
+
+func AllScanStatusValues() []ScanStatus {
+	return []ScanStatus{
+		ScanStatusPending,
+		ScanStatusClean,
+		ScanStatusInfected,
+	}
+}
+
 type SocialPlatformEnum string
 
 const (
@@ -419,6 +998,15 @@ func AllSocialPlatformEnumValues() []SocialPlatformEnum {
 	}
 }
 
+type CommentTemplate struct {
+	ID        string      `json:"id"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+	CreatedAt int64       `json:"created_at"`
+	UpdatedAt int64       `json:"updated_at"`
+}
+
 type Company struct {
 	ID            string        `json:"id"`
 	OwnerID       string        `json:"owner_id"`
@@ -434,6 +1022,114 @@ type Company struct {
 	GroupType     GroupTypeEnum `json:"group_type"`
 }
 
+type CompanyStorageQuota struct {
+	CompanyID string      `json:"company_id"`
+	Quota     int64       `json:"quota"`
+	UpdatedBy pgtype.UUID `json:"updated_by"`
+	UpdatedAt int64       `json:"updated_at"`
+}
+
+type Conversation struct {
+	ID            string      `json:"id"`
+	ProjectID     pgtype.UUID `json:"project_id"`
+	Subject       string      `json:"subject"`
+	CreatedBy     pgtype.UUID `json:"created_by"`
+	LastMessageAt int64       `json:"last_message_at"`
+	CreatedAt     int64       `json:"created_at"`
+}
+
+type ConversationAdminRead struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversation_id"`
+	AdminID        string `json:"admin_id"`
+	Reason         string `json:"reason"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+type ConversationParticipant struct {
+	ConversationID string `json:"conversation_id"`
+	UserID         string `json:"user_id"`
+	LastReadAt     *int64 `json:"last_read_at"`
+	JoinedAt       int64  `json:"joined_at"`
+}
+
+type DataRoomAccessLog struct {
+	ID           string         `json:"id"`
+	ProjectID    string         `json:"project_id"`
+	DocumentID   pgtype.UUID    `json:"document_id"`
+	DocumentName string         `json:"document_name"`
+	UserID       string         `json:"user_id"`
+	Action       DataRoomAction `json:"action"`
+	CreatedAt    int64          `json:"created_at"`
+}
+
+type DataRoomGrant struct {
+	ID           string      `json:"id"`
+	ProjectID    string      `json:"project_id"`
+	DocumentID   pgtype.UUID `json:"document_id"`
+	InvestorID   pgtype.UUID `json:"investor_id"`
+	AllCommitted bool        `json:"all_committed"`
+	ExpiresAt    *int64      `json:"expires_at"`
+	GrantedBy    pgtype.UUID `json:"granted_by"`
+	CreatedAt    int64       `json:"created_at"`
+}
+
+type DocumentScanAttempt struct {
+	DocumentID    string `json:"document_id"`
+	StorageKey    string `json:"storage_key"`
+	Attempts      int32  `json:"attempts"`
+	LastAttemptAt int64  `json:"last_attempt_at"`
+}
+
+type DocumentUpload struct {
+	ID           string      `json:"id"`
+	ProjectID    string      `json:"project_id"`
+	QuestionID   string      `json:"question_id"`
+	DocumentID   pgtype.UUID `json:"document_id"`
+	UploadedBy   string      `json:"uploaded_by"`
+	StorageKey   string      `json:"storage_key"`
+	Name         string      `json:"name"`
+	Section      string      `json:"section"`
+	SubSection   string      `json:"sub_section"`
+	MimeType     string      `json:"mime_type"`
+	Size         int64       `json:"size"`
+	ExpiresAt    int64       `json:"expires_at"`
+	CreatedAt    int64       `json:"created_at"`
+	MultipartID  *string     `json:"multipart_id"`
+	UploadedSize int64       `json:"uploaded_size"`
+}
+
+type DocumentUploadPart struct {
+	UploadID   string `json:"upload_id"`
+	PartNumber int32  `json:"part_number"`
+	Etag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+type DocumentWatermark struct {
+	DocumentID      string `json:"document_id"`
+	UserID          string `json:"user_id"`
+	DocumentVersion int64  `json:"document_version"`
+	StorageKey      string `json:"storage_key"`
+	CreatedAt       int64  `json:"created_at"`
+	Size            int64  `json:"size"`
+}
+
+type ExportJob struct {
+	ID          string          `json:"id"`
+	RequestedBy string          `json:"requested_by"`
+	Format      ExportFormat    `json:"format"`
+	Statuses    []ProjectStatus `json:"statuses"`
+	Status      ExportJobStatus `json:"status"`
+	RowCount    *int32          `json:"row_count"`
+	FileName    *string         `json:"file_name"`
+	Content     []byte          `json:"content"`
+	Error       *string         `json:"error"`
+	CreatedAt   int64           `json:"created_at"`
+	StartedAt   *int64          `json:"started_at"`
+	CompletedAt *int64          `json:"completed_at"`
+}
+
 type InvestmentIntention struct {
 	ID              string           `json:"id"`
 	ProjectID       string           `json:"project_id"`
@@ -445,6 +1141,57 @@ type InvestmentIntention struct {
 	UpdatedAt       int64            `json:"updated_at"`
 }
 
+type InvestorQuestion struct {
+	ID         string       `json:"id"`
+	ProjectID  string       `json:"project_id"`
+	AskerID    string       `json:"asker_id"`
+	Body       string       `json:"body"`
+	Visibility QaVisibility `json:"visibility"`
+	AnsweredAt *int64       `json:"answered_at"`
+	HiddenAt   *int64       `json:"hidden_at"`
+	HiddenBy   pgtype.UUID  `json:"hidden_by"`
+	CreatedAt  int64        `json:"created_at"`
+	UpdatedAt  int64        `json:"updated_at"`
+}
+
+type InvestorQuestionReply struct {
+	ID         string      `json:"id"`
+	QuestionID string      `json:"question_id"`
+	AuthorID   string      `json:"author_id"`
+	Body       string      `json:"body"`
+	HiddenAt   *int64      `json:"hidden_at"`
+	HiddenBy   pgtype.UUID `json:"hidden_by"`
+	CreatedAt  int64       `json:"created_at"`
+}
+
+type Message struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversation_id"`
+	SenderID       string `json:"sender_id"`
+	Body           string `json:"body"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+type MessageAttachment struct {
+	ID         string `json:"id"`
+	MessageID  string `json:"message_id"`
+	Name       string `json:"name"`
+	StorageKey string `json:"storage_key"`
+	MimeType   string `json:"mime_type"`
+	Size       int64  `json:"size"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type Notification struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	Type      NotificationType `json:"type"`
+	ProjectID pgtype.UUID      `json:"project_id"`
+	Message   string           `json:"message"`
+	ReadAt    *int64           `json:"read_at"`
+	CreatedAt int64            `json:"created_at"`
+}
+
 type PasswordResetToken struct {
 	ID        string `json:"id"`
 	UserID    string `json:"user_id"`
@@ -466,6 +1213,17 @@ type Project struct {
 	AllowEdit            bool          `json:"allow_edit"`
 }
 
+type ProjectActivity struct {
+	ID           string              `json:"id"`
+	ProjectID    string              `json:"project_id"`
+	ActorID      pgtype.UUID         `json:"actor_id"`
+	ActivityType ProjectActivityType `json:"activity_type"`
+	Internal     bool                `json:"internal"`
+	Payload      []byte              `json:"payload"`
+	Seq          int64               `json:"seq"`
+	CreatedAt    int64               `json:"created_at"`
+}
+
 type ProjectAnswer struct {
 	ID         string   `json:"id"`
 	ProjectID  string   `json:"project_id"`
@@ -477,29 +1235,63 @@ type ProjectAnswer struct {
 }
 
 type ProjectComment struct {
-	ID                   string      `json:"id"`
-	ProjectID            string      `json:"project_id"`
-	TargetID             string      `json:"target_id"`
-	Comment              string      `json:"comment"`
-	CommenterID          string      `json:"commenter_id"`
-	Resolved             bool        `json:"resolved"`
-	CreatedAt            int64       `json:"created_at"`
-	UpdatedAt            int64       `json:"updated_at"`
-	ResolvedBySnapshotID pgtype.UUID `json:"resolved_by_snapshot_id"`
+	ID                   string                `json:"id"`
+	ProjectID            string                `json:"project_id"`
+	TargetID             string                `json:"target_id"`
+	Comment              string                `json:"comment"`
+	CommenterID          string                `json:"commenter_id"`
+	Resolved             bool                  `json:"resolved"`
+	CreatedAt            int64                 `json:"created_at"`
+	UpdatedAt            int64                 `json:"updated_at"`
+	ResolvedBySnapshotID pgtype.UUID           `json:"resolved_by_snapshot_id"`
+	Visibility           CommentVisibilityEnum `json:"visibility"`
+	AnchorSnapshotID     pgtype.UUID           `json:"anchor_snapshot_id"`
+	AnchorStart          *int32                `json:"anchor_start"`
+	AnchorEnd            *int32                `json:"anchor_end"`
+	AnchorText           *string               `json:"anchor_text"`
 }
 
 type ProjectDocument struct {
-	ID         string `json:"id"`
-	ProjectID  string `json:"project_id"`
-	QuestionID string `json:"question_id"`
-	Name       string `json:"name"`
-	Url        string `json:"url"`
-	Section    string `json:"section"`
-	SubSection string `json:"sub_section"`
-	MimeType   string `json:"mime_type"`
-	Size       int64  `json:"size"`
-	CreatedAt  int64  `json:"created_at"`
-	UpdatedAt  int64  `json:"updated_at"`
+	ID            string     `json:"id"`
+	ProjectID     string     `json:"project_id"`
+	QuestionID    string     `json:"question_id"`
+	Name          string     `json:"name"`
+	StorageKey    string     `json:"storage_key"`
+	Section       string     `json:"section"`
+	SubSection    string     `json:"sub_section"`
+	MimeType      string     `json:"mime_type"`
+	Size          int64      `json:"size"`
+	CreatedAt     int64      `json:"created_at"`
+	UpdatedAt     int64      `json:"updated_at"`
+	Confidential  bool       `json:"confidential"`
+	Watermark     bool       `json:"watermark"`
+	Version       int32      `json:"version"`
+	ScanStatus    ScanStatus `json:"scan_status"`
+	ScanSignature *string    `json:"scan_signature"`
+	ScannedAt     *int64     `json:"scanned_at"`
+}
+
+type ProjectDocumentVersion struct {
+	ID         string      `json:"id"`
+	DocumentID string      `json:"document_id"`
+	ProjectID  string      `json:"project_id"`
+	QuestionID string      `json:"question_id"`
+	Version    int32       `json:"version"`
+	Name       string      `json:"name"`
+	StorageKey string      `json:"storage_key"`
+	MimeType   string      `json:"mime_type"`
+	Size       int64       `json:"size"`
+	UploadedBy pgtype.UUID `json:"uploaded_by"`
+	CreatedAt  int64       `json:"created_at"`
+}
+
+type ProjectDossierExport struct {
+	SnapshotID      string        `json:"snapshot_id"`
+	Format          DossierFormat `json:"format"`
+	RendererVersion int32         `json:"renderer_version"`
+	CompanyName     string        `json:"company_name"`
+	Content         []byte        `json:"content"`
+	CreatedAt       int64         `json:"created_at"`
 }
 
 type ProjectQuestion struct {
@@ -527,6 +1319,14 @@ type ProjectQuestion struct {
 	InputProps          []byte                `json:"input_props"`
 }
 
+type ProjectQuestionFileRule struct {
+	QuestionID   string   `json:"question_id"`
+	MaxSize      *int64   `json:"max_size"`
+	AllowedTypes []string `json:"allowed_types"`
+	MinSize      *int64   `json:"min_size"`
+	MaxFiles     *int32   `json:"max_files"`
+}
+
 type ProjectQuestions20250220Backup struct {
 	ID              string `json:"id"`
 	Section         string `json:"section"`
@@ -536,6 +1336,12 @@ type ProjectQuestions20250220Backup struct {
 	QuestionOrder   int32  `json:"question_order"`
 }
 
+type ProjectSearchIndex struct {
+	ProjectID    string      `json:"project_id"`
+	SearchVector interface{} `json:"search_vector"`
+	UpdatedAt    int64       `json:"updated_at"`
+}
+
 type ProjectSnapshot struct {
 	ID               string      `json:"id"`
 	ProjectID        string      `json:"project_id"`
@@ -547,27 +1353,51 @@ type ProjectSnapshot struct {
 	CreatedAt        int64       `json:"created_at"`
 }
 
+type ProjectSnapshotDocument struct {
+	SnapshotID string `json:"snapshot_id"`
+	VersionID  string `json:"version_id"`
+}
+
+type SavedSearch struct {
+	ID        string  `json:"id"`
+	UserID    string  `json:"user_id"`
+	Name      string  `json:"name"`
+	Industry  *string `json:"industry"`
+	Stage     *string `json:"stage"`
+	Notify    bool    `json:"notify"`
+	CreatedAt int64   `json:"created_at"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+type SavedSearchMatch struct {
+	SavedSearchID string `json:"saved_search_id"`
+	ProjectID     string `json:"project_id"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
 type TeamMember struct {
-	ID                           string  `json:"id"`
-	CompanyID                    string  `json:"company_id"`
-	FirstName                    string  `json:"first_name"`
-	LastName                     string  `json:"last_name"`
-	Title                        string  `json:"title"`
-	LinkedinUrl                  string  `json:"linkedin_url"`
-	IsAccountOwner               bool    `json:"is_account_owner"`
-	PersonalWebsite              *string `json:"personal_website"`
-	CommitmentType               string  `json:"commitment_type"`
-	Introduction                 string  `json:"introduction"`
-	IndustryExperience           string  `json:"industry_experience"`
-	DetailedBiography            string  `json:"detailed_biography"`
-	PreviousWork                 *string `json:"previous_work"`
-	ResumeExternalUrl            *string `json:"resume_external_url"`
-	ResumeInternalUrl            *string `json:"resume_internal_url"`
-	FoundersAgreementExternalUrl *string `json:"founders_agreement_external_url"`
-	FoundersAgreementInternalUrl *string `json:"founders_agreement_internal_url"`
-	CreatedAt                    int64   `json:"created_at"`
-	UpdatedAt                    int64   `json:"updated_at"`
-	SocialLinks                  []byte  `json:"social_links"`
+	ID                            string  `json:"id"`
+	CompanyID                     string  `json:"company_id"`
+	FirstName                     string  `json:"first_name"`
+	LastName                      string  `json:"last_name"`
+	Title                         string  `json:"title"`
+	LinkedinUrl                   string  `json:"linkedin_url"`
+	IsAccountOwner                bool    `json:"is_account_owner"`
+	PersonalWebsite               *string `json:"personal_website"`
+	CommitmentType                string  `json:"commitment_type"`
+	Introduction                  string  `json:"introduction"`
+	IndustryExperience            string  `json:"industry_experience"`
+	DetailedBiography             string  `json:"detailed_biography"`
+	PreviousWork                  *string `json:"previous_work"`
+	ResumeExternalUrl             *string `json:"resume_external_url"`
+	ResumeInternalKey             *string `json:"resume_internal_key"`
+	FoundersAgreementExternalUrl  *string `json:"founders_agreement_external_url"`
+	FoundersAgreementInternalKey  *string `json:"founders_agreement_internal_key"`
+	CreatedAt                     int64   `json:"created_at"`
+	UpdatedAt                     int64   `json:"updated_at"`
+	SocialLinks                   []byte  `json:"social_links"`
+	ResumeInternalSize            int64   `json:"resume_internal_size"`
+	FoundersAgreementInternalSize int64   `json:"founders_agreement_internal_size"`
 }
 
 type Transaction struct {
@@ -597,7 +1427,7 @@ type User struct {
 	CreatedAt         int64   `json:"created_at"`
 	UpdatedAt         int64   `json:"updated_at"`
 	TokenSalt         []byte  `json:"token_salt"`
-	ProfilePictureUrl *string `json:"profile_picture_url"`
+	ProfilePictureKey *string `json:"profile_picture_key"`
 }
 
 type UserSocial struct {
@@ -615,3 +1445,14 @@ type VerifyEmailToken struct {
 	CreatedAt int64  `json:"created_at"`
 	ExpiresAt int64  `json:"expires_at"`
 }
+
+type WatchlistItem struct {
+	ID               string         `json:"id"`
+	UserID           string         `json:"user_id"`
+	ProjectID        string         `json:"project_id"`
+	Note             string         `json:"note"`
+	LastStatus       ProjectStatus  `json:"last_status"`
+	LastFundingTotal pgtype.Numeric `json:"last_funding_total"`
+	CreatedAt        int64          `json:"created_at"`
+	UpdatedAt        int64          `json:"updated_at"`
+}
@@ -82,10 +82,15 @@ SELECT
             SELECT coalesce(jsonb_agg(
                 jsonb_build_object(
                     'id', pd.id::text,
+                    'version', pd.version,
+                    'version_id', (
+                        SELECT v.id::text FROM project_document_versions v
+                        WHERE v.document_id = pd.id AND v.version = pd.version
+                    ),
                     'project_id', pd.project_id::text,
                     'question_id', pd.question_id::text,
                     'name', pd.name,
-                    'url', pd.url,
+                    'storage_key', pd.storage_key,
                     'section', pd.section,
                     'sub_section', pd.sub_section,
                     'mime_type', pd.mime_type,
@@ -115,9 +120,9 @@ SELECT
                     'detailed_biography', tm.detailed_biography,
                     'previous_work', tm.previous_work,
                     'resume_external_url', tm.resume_external_url,
-                    'resume_internal_url', tm.resume_internal_url,
+                    'resume_internal_key', tm.resume_internal_key,
                     'founders_agreement_external_url', tm.founders_agreement_external_url,
-                    'founders_agreement_internal_url', tm.founders_agreement_internal_url,
+                    'founders_agreement_internal_key', tm.founders_agreement_internal_key,
                     'created_at', tm.created_at,
                     'updated_at', tm.updated_at,
                     'social_links', tm.social_links
@@ -167,3 +172,28 @@ func (q *Queries) GetLatestProjectSnapshot(ctx context.Context, projectID string
 	)
 	return i, err
 }
+
+const getProjectSnapshotByVersion = `-- name: GetProjectSnapshotByVersion :one
+SELECT id, project_id, data, version_number, title, description, parent_snapshot_id, created_at FROM project_snapshots WHERE project_id = $1 AND version_number = $2 LIMIT 1
+`
+
+type GetProjectSnapshotByVersionParams struct {
+	ProjectID     string `json:"project_id"`
+	VersionNumber int32  `json:"version_number"`
+}
+
+func (q *Queries) GetProjectSnapshotByVersion(ctx context.Context, arg GetProjectSnapshotByVersionParams) (ProjectSnapshot, error) {
+	row := q.db.QueryRow(ctx, getProjectSnapshotByVersion, arg.ProjectID, arg.VersionNumber)
+	var i ProjectSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Data,
+		&i.VersionNumber,
+		&i.Title,
+		&i.Description,
+		&i.ParentSnapshotID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -13,7 +13,7 @@ import (
 
 const countUnresolvedProjectComments = `-- name: CountUnresolvedProjectComments :one
 SELECT COUNT(*) FROM project_comments
-WHERE project_id = $1 AND resolved = false
+WHERE project_id = $1 AND resolved = false AND visibility = 'public'
 `
 
 func (q *Queries) CountUnresolvedProjectComments(ctx context.Context, projectID string) (int64, error) {
@@ -147,20 +147,35 @@ INSERT INTO project_comments (
     project_id,
     target_id,
     comment,
-    commenter_id
+    commenter_id,
+    visibility,
+    anchor_snapshot_id,
+    anchor_start,
+    anchor_end,
+    anchor_text
 ) VALUES (
     $1, -- project_id
     $2, -- target_id
     $3, -- comment
-    $4  -- commenter_id
-) RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id
+    $4, -- commenter_id
+    $5, -- visibility
+    $6, -- anchor_snapshot_id
+    $7, -- anchor_start
+    $8, -- anchor_end
+    $9  -- anchor_text
+) RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text
 `
 
 type CreateProjectCommentParams struct {
-	ProjectID   string `json:"project_id"`
-	TargetID    string `json:"target_id"`
-	Comment     string `json:"comment"`
-	CommenterID string `json:"commenter_id"`
+	ProjectID        string                `json:"project_id"`
+	TargetID         string                `json:"target_id"`
+	Comment          string                `json:"comment"`
+	CommenterID      string                `json:"commenter_id"`
+	Visibility       CommentVisibilityEnum `json:"visibility"`
+	AnchorSnapshotID pgtype.UUID           `json:"anchor_snapshot_id"`
+	AnchorStart      *int32                `json:"anchor_start"`
+	AnchorEnd        *int32                `json:"anchor_end"`
+	AnchorText       *string               `json:"anchor_text"`
 }
 
 func (q *Queries) CreateProjectComment(ctx context.Context, arg CreateProjectCommentParams) (ProjectComment, error) {
@@ -169,6 +184,11 @@ func (q *Queries) CreateProjectComment(ctx context.Context, arg CreateProjectCom
 		arg.TargetID,
 		arg.Comment,
 		arg.CommenterID,
+		arg.Visibility,
+		arg.AnchorSnapshotID,
+		arg.AnchorStart,
+		arg.AnchorEnd,
+		arg.AnchorText,
 	)
 	var i ProjectComment
 	err := row.Scan(
@@ -181,6 +201,11 @@ func (q *Queries) CreateProjectComment(ctx context.Context, arg CreateProjectCom
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ResolvedBySnapshotID,
+		&i.Visibility,
+		&i.AnchorSnapshotID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorText,
 	)
 	return i, err
 }
@@ -191,7 +216,7 @@ INSERT INTO project_documents (
     project_id,
     question_id,
     name,
-    url,
+    storage_key,
     section,
     sub_section,
     mime_type,
@@ -203,21 +228,21 @@ INSERT INTO project_documents (
     $1, -- project_id
     $2, -- question_id
     $3, -- name
-    $4, -- url
+    $4, -- storage_key
     $5, -- section
     $6, -- sub_section
     $7, -- mime_type
     $8, -- size in bytes
     extract(epoch from now()),
     extract(epoch from now())
-) RETURNING id, project_id, question_id, name, url, section, sub_section, mime_type, size, created_at, updated_at
+) RETURNING id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size, created_at, updated_at, confidential, watermark, version, scan_status, scan_signature, scanned_at
 `
 
 type CreateProjectDocumentParams struct {
 	ProjectID  string `json:"project_id"`
 	QuestionID string `json:"question_id"`
 	Name       string `json:"name"`
-	Url        string `json:"url"`
+	StorageKey string `json:"storage_key"`
 	Section    string `json:"section"`
 	SubSection string `json:"sub_section"`
 	MimeType   string `json:"mime_type"`
@@ -229,7 +254,7 @@ func (q *Queries) CreateProjectDocument(ctx context.Context, arg CreateProjectDo
 		arg.ProjectID,
 		arg.QuestionID,
 		arg.Name,
-		arg.Url,
+		arg.StorageKey,
 		arg.Section,
 		arg.SubSection,
 		arg.MimeType,
@@ -241,13 +266,19 @@ func (q *Queries) CreateProjectDocument(ctx context.Context, arg CreateProjectDo
 		&i.ProjectID,
 		&i.QuestionID,
 		&i.Name,
-		&i.Url,
+		&i.StorageKey,
 		&i.Section,
 		&i.SubSection,
 		&i.MimeType,
 		&i.Size,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Confidential,
+		&i.Watermark,
+		&i.Version,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
 	)
 	return i, err
 }
@@ -313,177 +344,6 @@ func (q *Queries) GetCompanyByUserID(ctx context.Context, ownerID string) (Compa
 	return i, err
 }
 
-const getNewProjectsAnyStatus = `-- name: GetNewProjectsAnyStatus :many
-SELECT 
-    p.id, 
-    p.company_id, 
-    COALESCE(
-        (SELECT pa.answer 
-         FROM project_answers pa
-         JOIN project_questions pq ON pa.question_id = pq.id
-         WHERE pa.project_id = p.id AND pq.question_key = 'company_name' AND pa.answer != ''
-         LIMIT 1),
-        p.title
-    ) as title,
-    p.description, 
-    p.status, 
-    p.allow_edit,
-    p.created_at, 
-    p.updated_at,
-    c.name as company_name,
-    COUNT(d.id) as document_count,
-    COUNT(t.id) as team_member_count
-FROM 
-    projects p
-LEFT JOIN 
-    project_documents d ON d.project_id = p.id
-LEFT JOIN 
-    team_members t ON t.company_id = p.company_id
-LEFT JOIN 
-    companies c ON c.id = p.company_id
-GROUP BY 
-    p.id, c.name
-ORDER BY 
-    p.created_at DESC
-LIMIT 
-    $1
-`
-
-type GetNewProjectsAnyStatusRow struct {
-	ID              string        `json:"id"`
-	CompanyID       string        `json:"company_id"`
-	Title           string        `json:"title"`
-	Description     *string       `json:"description"`
-	Status          ProjectStatus `json:"status"`
-	AllowEdit       bool          `json:"allow_edit"`
-	CreatedAt       int64         `json:"created_at"`
-	UpdatedAt       int64         `json:"updated_at"`
-	CompanyName     *string       `json:"company_name"`
-	DocumentCount   int64         `json:"document_count"`
-	TeamMemberCount int64         `json:"team_member_count"`
-}
-
-func (q *Queries) GetNewProjectsAnyStatus(ctx context.Context, limit int32) ([]GetNewProjectsAnyStatusRow, error) {
-	rows, err := q.db.Query(ctx, getNewProjectsAnyStatus, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetNewProjectsAnyStatusRow
-	for rows.Next() {
-		var i GetNewProjectsAnyStatusRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.CompanyID,
-			&i.Title,
-			&i.Description,
-			&i.Status,
-			&i.AllowEdit,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.CompanyName,
-			&i.DocumentCount,
-			&i.TeamMemberCount,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const getNewProjectsByStatus = `-- name: GetNewProjectsByStatus :many
-SELECT 
-    p.id, 
-    p.company_id, 
-    COALESCE(
-        (SELECT pa.answer 
-         FROM project_answers pa
-         JOIN project_questions pq ON pa.question_id = pq.id
-         WHERE pa.project_id = p.id AND pq.question_key = 'company_name' AND pa.answer != ''
-         LIMIT 1),
-        p.title
-    ) as title,
-    p.description, 
-    p.status, 
-    p.allow_edit,
-    p.created_at, 
-    p.updated_at,
-    c.name as company_name,
-    COUNT(d.id) as document_count,
-    COUNT(t.id) as team_member_count
-FROM 
-    projects p
-LEFT JOIN 
-    project_documents d ON d.project_id = p.id
-LEFT JOIN 
-    team_members t ON t.company_id = p.company_id
-LEFT JOIN 
-    companies c ON c.id = p.company_id
-WHERE 
-    p.status = $1
-GROUP BY 
-    p.id, c.name
-ORDER BY 
-    p.created_at DESC
-LIMIT 
-    $2
-`
-
-type GetNewProjectsByStatusParams struct {
-	Status ProjectStatus `json:"status"`
-	Limit  int32         `json:"limit"`
-}
-
-type GetNewProjectsByStatusRow struct {
-	ID              string        `json:"id"`
-	CompanyID       string        `json:"company_id"`
-	Title           string        `json:"title"`
-	Description     *string       `json:"description"`
-	Status          ProjectStatus `json:"status"`
-	AllowEdit       bool          `json:"allow_edit"`
-	CreatedAt       int64         `json:"created_at"`
-	UpdatedAt       int64         `json:"updated_at"`
-	CompanyName     *string       `json:"company_name"`
-	DocumentCount   int64         `json:"document_count"`
-	TeamMemberCount int64         `json:"team_member_count"`
-}
-
-func (q *Queries) GetNewProjectsByStatus(ctx context.Context, arg GetNewProjectsByStatusParams) ([]GetNewProjectsByStatusRow, error) {
-	rows, err := q.db.Query(ctx, getNewProjectsByStatus, arg.Status, arg.Limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetNewProjectsByStatusRow
-	for rows.Next() {
-		var i GetNewProjectsByStatusRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.CompanyID,
-			&i.Title,
-			&i.Description,
-			&i.Status,
-			&i.AllowEdit,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.CompanyName,
-			&i.DocumentCount,
-			&i.TeamMemberCount,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const getProjectAnswers = `-- name: GetProjectAnswers :many
 SELECT 
     pa.id as answer_id,
@@ -587,35 +447,44 @@ func (q *Queries) GetProjectByIDAsAdmin(ctx context.Context, id string) (Project
 }
 
 const getProjectComment = `-- name: GetProjectComment :one
-SELECT pc.id, pc.project_id, pc.target_id, pc.comment, pc.commenter_id, pc.resolved, pc.created_at, pc.updated_at, pc.resolved_by_snapshot_id, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at FROM project_comments pc
+SELECT pc.id, pc.project_id, pc.target_id, pc.comment, pc.commenter_id, pc.resolved, pc.created_at, pc.updated_at, pc.resolved_by_snapshot_id, pc.visibility, pc.anchor_snapshot_id, pc.anchor_start, pc.anchor_end, pc.anchor_text, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at, aps.version_number as anchor_snapshot_version FROM project_comments pc
 LEFT JOIN users u ON u.id = pc.commenter_id
 LEFT JOIN project_snapshots ps ON ps.id = pc.resolved_by_snapshot_id
+LEFT JOIN project_snapshots aps ON aps.id = pc.anchor_snapshot_id
 WHERE pc.id = $1 AND pc.project_id = $2
+  AND (pc.visibility = 'public' OR $3::boolean)
 LIMIT 1
 `
 
 type GetProjectCommentParams struct {
-	ID        string `json:"id"`
-	ProjectID string `json:"project_id"`
+	ID              string `json:"id"`
+	ProjectID       string `json:"project_id"`
+	IncludeInternal bool   `json:"include_internal"`
 }
 
 type GetProjectCommentRow struct {
-	ID                   string      `json:"id"`
-	ProjectID            string      `json:"project_id"`
-	TargetID             string      `json:"target_id"`
-	Comment              string      `json:"comment"`
-	CommenterID          string      `json:"commenter_id"`
-	Resolved             bool        `json:"resolved"`
-	CreatedAt            int64       `json:"created_at"`
-	UpdatedAt            int64       `json:"updated_at"`
-	ResolvedBySnapshotID pgtype.UUID `json:"resolved_by_snapshot_id"`
-	CommenterFirstName   *string     `json:"commenter_first_name"`
-	CommenterLastName    *string     `json:"commenter_last_name"`
-	ResolvedBySnapshotAt *int64      `json:"resolved_by_snapshot_at"`
+	ID                    string                `json:"id"`
+	ProjectID             string                `json:"project_id"`
+	TargetID              string                `json:"target_id"`
+	Comment               string                `json:"comment"`
+	CommenterID           string                `json:"commenter_id"`
+	Resolved              bool                  `json:"resolved"`
+	CreatedAt             int64                 `json:"created_at"`
+	UpdatedAt             int64                 `json:"updated_at"`
+	ResolvedBySnapshotID  pgtype.UUID           `json:"resolved_by_snapshot_id"`
+	Visibility            CommentVisibilityEnum `json:"visibility"`
+	AnchorSnapshotID      pgtype.UUID           `json:"anchor_snapshot_id"`
+	AnchorStart           *int32                `json:"anchor_start"`
+	AnchorEnd             *int32                `json:"anchor_end"`
+	AnchorText            *string               `json:"anchor_text"`
+	CommenterFirstName    *string               `json:"commenter_first_name"`
+	CommenterLastName     *string               `json:"commenter_last_name"`
+	ResolvedBySnapshotAt  *int64                `json:"resolved_by_snapshot_at"`
+	AnchorSnapshotVersion *int32                `json:"anchor_snapshot_version"`
 }
 
 func (q *Queries) GetProjectComment(ctx context.Context, arg GetProjectCommentParams) (GetProjectCommentRow, error) {
-	row := q.db.QueryRow(ctx, getProjectComment, arg.ID, arg.ProjectID)
+	row := q.db.QueryRow(ctx, getProjectComment, arg.ID, arg.ProjectID, arg.IncludeInternal)
 	var i GetProjectCommentRow
 	err := row.Scan(
 		&i.ID,
@@ -627,38 +496,57 @@ func (q *Queries) GetProjectComment(ctx context.Context, arg GetProjectCommentPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ResolvedBySnapshotID,
+		&i.Visibility,
+		&i.AnchorSnapshotID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorText,
 		&i.CommenterFirstName,
 		&i.CommenterLastName,
 		&i.ResolvedBySnapshotAt,
+		&i.AnchorSnapshotVersion,
 	)
 	return i, err
 }
 
 const getProjectComments = `-- name: GetProjectComments :many
-SELECT pc.id, pc.project_id, pc.target_id, pc.comment, pc.commenter_id, pc.resolved, pc.created_at, pc.updated_at, pc.resolved_by_snapshot_id, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at FROM project_comments pc
+SELECT pc.id, pc.project_id, pc.target_id, pc.comment, pc.commenter_id, pc.resolved, pc.created_at, pc.updated_at, pc.resolved_by_snapshot_id, pc.visibility, pc.anchor_snapshot_id, pc.anchor_start, pc.anchor_end, pc.anchor_text, u.first_name as commenter_first_name, u.last_name as commenter_last_name, ps.created_at as resolved_by_snapshot_at, aps.version_number as anchor_snapshot_version FROM project_comments pc
 LEFT JOIN users u ON u.id = pc.commenter_id
 LEFT JOIN project_snapshots ps ON ps.id = pc.resolved_by_snapshot_id
+LEFT JOIN project_snapshots aps ON aps.id = pc.anchor_snapshot_id
 WHERE pc.project_id = $1
+  AND (pc.visibility = 'public' OR $2::boolean)
 ORDER BY pc.created_at DESC
 `
 
+type GetProjectCommentsParams struct {
+	ProjectID       string `json:"project_id"`
+	IncludeInternal bool   `json:"include_internal"`
+}
+
 type GetProjectCommentsRow struct {
-	ID                   string      `json:"id"`
-	ProjectID            string      `json:"project_id"`
-	TargetID             string      `json:"target_id"`
-	Comment              string      `json:"comment"`
-	CommenterID          string      `json:"commenter_id"`
-	Resolved             bool        `json:"resolved"`
-	CreatedAt            int64       `json:"created_at"`
-	UpdatedAt            int64       `json:"updated_at"`
-	ResolvedBySnapshotID pgtype.UUID `json:"resolved_by_snapshot_id"`
-	CommenterFirstName   *string     `json:"commenter_first_name"`
-	CommenterLastName    *string     `json:"commenter_last_name"`
-	ResolvedBySnapshotAt *int64      `json:"resolved_by_snapshot_at"`
+	ID                    string                `json:"id"`
+	ProjectID             string                `json:"project_id"`
+	TargetID              string                `json:"target_id"`
+	Comment               string                `json:"comment"`
+	CommenterID           string                `json:"commenter_id"`
+	Resolved              bool                  `json:"resolved"`
+	CreatedAt             int64                 `json:"created_at"`
+	UpdatedAt             int64                 `json:"updated_at"`
+	ResolvedBySnapshotID  pgtype.UUID           `json:"resolved_by_snapshot_id"`
+	Visibility            CommentVisibilityEnum `json:"visibility"`
+	AnchorSnapshotID      pgtype.UUID           `json:"anchor_snapshot_id"`
+	AnchorStart           *int32                `json:"anchor_start"`
+	AnchorEnd             *int32                `json:"anchor_end"`
+	AnchorText            *string               `json:"anchor_text"`
+	CommenterFirstName    *string               `json:"commenter_first_name"`
+	CommenterLastName     *string               `json:"commenter_last_name"`
+	ResolvedBySnapshotAt  *int64                `json:"resolved_by_snapshot_at"`
+	AnchorSnapshotVersion *int32                `json:"anchor_snapshot_version"`
 }
 
-func (q *Queries) GetProjectComments(ctx context.Context, projectID string) ([]GetProjectCommentsRow, error) {
-	rows, err := q.db.Query(ctx, getProjectComments, projectID)
+func (q *Queries) GetProjectComments(ctx context.Context, arg GetProjectCommentsParams) ([]GetProjectCommentsRow, error) {
+	rows, err := q.db.Query(ctx, getProjectComments, arg.ProjectID, arg.IncludeInternal)
 	if err != nil {
 		return nil, err
 	}
@@ -676,9 +564,63 @@ func (q *Queries) GetProjectComments(ctx context.Context, projectID string) ([]G
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.ResolvedBySnapshotID,
+			&i.Visibility,
+			&i.AnchorSnapshotID,
+			&i.AnchorStart,
+			&i.AnchorEnd,
+			&i.AnchorText,
 			&i.CommenterFirstName,
 			&i.CommenterLastName,
 			&i.ResolvedBySnapshotAt,
+			&i.AnchorSnapshotVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProjectCommentsByIDs = `-- name: GetProjectCommentsByIDs :many
+SELECT id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text FROM project_comments
+WHERE project_id = $1
+  AND id = ANY($2::uuid[])
+  AND (visibility = 'public' OR $3::boolean)
+`
+
+type GetProjectCommentsByIDsParams struct {
+	ProjectID       string   `json:"project_id"`
+	Ids             []string `json:"ids"`
+	IncludeInternal bool     `json:"include_internal"`
+}
+
+func (q *Queries) GetProjectCommentsByIDs(ctx context.Context, arg GetProjectCommentsByIDsParams) ([]ProjectComment, error) {
+	rows, err := q.db.Query(ctx, getProjectCommentsByIDs, arg.ProjectID, arg.Ids, arg.IncludeInternal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectComment
+	for rows.Next() {
+		var i ProjectComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.TargetID,
+			&i.Comment,
+			&i.CommenterID,
+			&i.Resolved,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ResolvedBySnapshotID,
+			&i.Visibility,
+			&i.AnchorSnapshotID,
+			&i.AnchorStart,
+			&i.AnchorEnd,
+			&i.AnchorText,
 		); err != nil {
 			return nil, err
 		}
@@ -702,7 +644,7 @@ func (q *Queries) GetProjectCountOwnedByCompany(ctx context.Context, companyID s
 }
 
 const getProjectDocument = `-- name: GetProjectDocument :one
-SELECT project_documents.id, project_documents.project_id, project_documents.question_id, project_documents.name, project_documents.url, project_documents.section, project_documents.sub_section, project_documents.mime_type, project_documents.size, project_documents.created_at, project_documents.updated_at FROM project_documents
+SELECT project_documents.id, project_documents.project_id, project_documents.question_id, project_documents.name, project_documents.storage_key, project_documents.section, project_documents.sub_section, project_documents.mime_type, project_documents.size, project_documents.created_at, project_documents.updated_at, project_documents.confidential, project_documents.watermark, project_documents.version, project_documents.scan_status, project_documents.scan_signature, project_documents.scanned_at FROM project_documents
 JOIN projects ON project_documents.project_id = projects.id
 WHERE project_documents.id = $1 
 AND project_documents.project_id = $2
@@ -723,19 +665,25 @@ func (q *Queries) GetProjectDocument(ctx context.Context, arg GetProjectDocument
 		&i.ProjectID,
 		&i.QuestionID,
 		&i.Name,
-		&i.Url,
+		&i.StorageKey,
 		&i.Section,
 		&i.SubSection,
 		&i.MimeType,
 		&i.Size,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Confidential,
+		&i.Watermark,
+		&i.Version,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
 	)
 	return i, err
 }
 
 const getProjectDocuments = `-- name: GetProjectDocuments :many
-SELECT id, project_id, question_id, name, url, section, sub_section, mime_type, size, created_at, updated_at FROM project_documents
+SELECT id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size, created_at, updated_at, confidential, watermark, version, scan_status, scan_signature, scanned_at FROM project_documents
 WHERE project_id = $1
 ORDER BY created_at DESC
 `
@@ -754,13 +702,19 @@ func (q *Queries) GetProjectDocuments(ctx context.Context, projectID string) ([]
 			&i.ProjectID,
 			&i.QuestionID,
 			&i.Name,
-			&i.Url,
+			&i.StorageKey,
 			&i.Section,
 			&i.SubSection,
 			&i.MimeType,
 			&i.Size,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Confidential,
+			&i.Watermark,
+			&i.Version,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1326,7 +1280,7 @@ SET
     resolved = true,
     updated_at = extract(epoch from now())
 WHERE id = $1 AND project_id = $2
-RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id
+RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text
 `
 
 type ResolveProjectCommentParams struct {
@@ -1347,6 +1301,11 @@ func (q *Queries) ResolveProjectComment(ctx context.Context, arg ResolveProjectC
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ResolvedBySnapshotID,
+		&i.Visibility,
+		&i.AnchorSnapshotID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorText,
 	)
 	return i, err
 }
@@ -1367,12 +1326,62 @@ func (q *Queries) SetProjectAllowEdit(ctx context.Context, arg SetProjectAllowEd
 	return err
 }
 
+const setProjectCommentsResolved = `-- name: SetProjectCommentsResolved :many
+UPDATE project_comments
+SET
+    resolved = $1,
+    updated_at = extract(epoch from now())
+WHERE project_id = $2 AND id = ANY($3::uuid[])
+RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text
+`
+
+type SetProjectCommentsResolvedParams struct {
+	Resolved  bool     `json:"resolved"`
+	ProjectID string   `json:"project_id"`
+	Ids       []string `json:"ids"`
+}
+
+func (q *Queries) SetProjectCommentsResolved(ctx context.Context, arg SetProjectCommentsResolvedParams) ([]ProjectComment, error) {
+	rows, err := q.db.Query(ctx, setProjectCommentsResolved, arg.Resolved, arg.ProjectID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectComment
+	for rows.Next() {
+		var i ProjectComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.TargetID,
+			&i.Comment,
+			&i.CommenterID,
+			&i.Resolved,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ResolvedBySnapshotID,
+			&i.Visibility,
+			&i.AnchorSnapshotID,
+			&i.AnchorStart,
+			&i.AnchorEnd,
+			&i.AnchorText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setSnapshotIDToProjectComments = `-- name: SetSnapshotIDToProjectComments :exec
 UPDATE project_comments
 SET
     resolved_by_snapshot_id = $1,
     updated_at = extract(epoch from now())
-WHERE projecT_id = $2 AND resolved_by_snapshot_id IS NULL
+WHERE projecT_id = $2 AND resolved_by_snapshot_id IS NULL AND visibility = 'public'
 `
 
 type SetSnapshotIDToProjectCommentsParams struct {
@@ -1391,7 +1400,7 @@ SET
     resolved = false,
     updated_at = extract(epoch from now())
 WHERE id = $1 AND project_id = $2
-RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id
+RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text
 `
 
 type UnresolveProjectCommentParams struct {
@@ -1412,6 +1421,11 @@ func (q *Queries) UnresolveProjectComment(ctx context.Context, arg UnresolveProj
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ResolvedBySnapshotID,
+		&i.Visibility,
+		&i.AnchorSnapshotID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorText,
 	)
 	return i, err
 }
@@ -1453,7 +1467,7 @@ UPDATE project_comments
 SET comment = $2,
     updated_at = extract(epoch from now())
 WHERE id = $1
-RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id
+RETURNING id, project_id, target_id, comment, commenter_id, resolved, created_at, updated_at, resolved_by_snapshot_id, visibility, anchor_snapshot_id, anchor_start, anchor_end, anchor_text
 `
 
 type UpdateProjectCommentParams struct {
@@ -1474,6 +1488,11 @@ func (q *Queries) UpdateProjectComment(ctx context.Context, arg UpdateProjectCom
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ResolvedBySnapshotID,
+		&i.Visibility,
+		&i.AnchorSnapshotID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorText,
 	)
 	return i, err
 }
@@ -15,15 +15,13 @@ INSERT INTO team_members (
     title, linkedin_url, is_account_owner,
     personal_website, commitment_type, introduction,
     industry_experience, detailed_biography, previous_work,
-    resume_external_url, resume_internal_url,
-    founders_agreement_external_url, founders_agreement_internal_url,
+    resume_external_url, founders_agreement_external_url,
     social_links
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8,
-    $9, $10, $11, $12, $13, $14, $15, $16,
-    $17
+    $9, $10, $11, $12, $13, $14, $15
 )
-RETURNING id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_url, founders_agreement_external_url, founders_agreement_internal_url, created_at, updated_at, social_links
+RETURNING id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_key, founders_agreement_external_url, founders_agreement_internal_key, created_at, updated_at, social_links, resume_internal_size, founders_agreement_internal_size
 `
 
 type CreateTeamMemberParams struct {
@@ -40,9 +38,7 @@ type CreateTeamMemberParams struct {
 	DetailedBiography            string  `json:"detailed_biography"`
 	PreviousWork                 *string `json:"previous_work"`
 	ResumeExternalUrl            *string `json:"resume_external_url"`
-	ResumeInternalUrl            *string `json:"resume_internal_url"`
 	FoundersAgreementExternalUrl *string `json:"founders_agreement_external_url"`
-	FoundersAgreementInternalUrl *string `json:"founders_agreement_internal_url"`
 	SocialLinks                  []byte  `json:"social_links"`
 }
 
@@ -61,9 +57,7 @@ func (q *Queries) CreateTeamMember(ctx context.Context, arg CreateTeamMemberPara
 		arg.DetailedBiography,
 		arg.PreviousWork,
 		arg.ResumeExternalUrl,
-		arg.ResumeInternalUrl,
 		arg.FoundersAgreementExternalUrl,
-		arg.FoundersAgreementInternalUrl,
 		arg.SocialLinks,
 	)
 	var i TeamMember
@@ -82,12 +76,14 @@ func (q *Queries) CreateTeamMember(ctx context.Context, arg CreateTeamMemberPara
 		&i.DetailedBiography,
 		&i.PreviousWork,
 		&i.ResumeExternalUrl,
-		&i.ResumeInternalUrl,
+		&i.ResumeInternalKey,
 		&i.FoundersAgreementExternalUrl,
-		&i.FoundersAgreementInternalUrl,
+		&i.FoundersAgreementInternalKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SocialLinks,
+		&i.ResumeInternalSize,
+		&i.FoundersAgreementInternalSize,
 	)
 	return i, err
 }
@@ -108,7 +104,7 @@ func (q *Queries) DeleteTeamMember(ctx context.Context, arg DeleteTeamMemberPara
 }
 
 const getTeamMember = `-- name: GetTeamMember :one
-SELECT id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_url, founders_agreement_external_url, founders_agreement_internal_url, created_at, updated_at, social_links FROM team_members 
+SELECT id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_key, founders_agreement_external_url, founders_agreement_internal_key, created_at, updated_at, social_links, resume_internal_size, founders_agreement_internal_size FROM team_members 
 WHERE id = $1 AND company_id = $2 
 LIMIT 1
 `
@@ -136,18 +132,20 @@ func (q *Queries) GetTeamMember(ctx context.Context, arg GetTeamMemberParams) (T
 		&i.DetailedBiography,
 		&i.PreviousWork,
 		&i.ResumeExternalUrl,
-		&i.ResumeInternalUrl,
+		&i.ResumeInternalKey,
 		&i.FoundersAgreementExternalUrl,
-		&i.FoundersAgreementInternalUrl,
+		&i.FoundersAgreementInternalKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SocialLinks,
+		&i.ResumeInternalSize,
+		&i.FoundersAgreementInternalSize,
 	)
 	return i, err
 }
 
 const listTeamMembers = `-- name: ListTeamMembers :many
-SELECT id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_url, founders_agreement_external_url, founders_agreement_internal_url, created_at, updated_at, social_links FROM team_members 
+SELECT id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_key, founders_agreement_external_url, founders_agreement_internal_key, created_at, updated_at, social_links, resume_internal_size, founders_agreement_internal_size FROM team_members 
 WHERE company_id = $1 
 ORDER BY created_at DESC
 `
@@ -176,12 +174,14 @@ func (q *Queries) ListTeamMembers(ctx context.Context, companyID string) ([]Team
 			&i.DetailedBiography,
 			&i.PreviousWork,
 			&i.ResumeExternalUrl,
-			&i.ResumeInternalUrl,
+			&i.ResumeInternalKey,
 			&i.FoundersAgreementExternalUrl,
-			&i.FoundersAgreementInternalUrl,
+			&i.FoundersAgreementInternalKey,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.SocialLinks,
+			&i.ResumeInternalSize,
+			&i.FoundersAgreementInternalSize,
 		); err != nil {
 			return nil, err
 		}
@@ -208,32 +208,34 @@ SET
     industry_experience = COALESCE(NULLIF($10::text, ''), industry_experience),
     previous_work = NULLIF($11::text, ''),
     resume_external_url = NULLIF($12::text, ''),
-    resume_internal_url = NULLIF($13::text, ''),
+    resume_internal_key = CASE WHEN $13::boolean THEN NULL ELSE resume_internal_key END,
+    resume_internal_size = CASE WHEN $13::boolean THEN 0 ELSE resume_internal_size END,
     founders_agreement_external_url = NULLIF($14::text, ''),
-    founders_agreement_internal_url = NULLIF($15::text, ''),
+    founders_agreement_internal_key = CASE WHEN $15::boolean THEN NULL ELSE founders_agreement_internal_key END,
+    founders_agreement_internal_size = CASE WHEN $15::boolean THEN 0 ELSE founders_agreement_internal_size END,
     updated_at = extract(epoch from now())
 WHERE id = $16 AND company_id = $17
-RETURNING id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_url, founders_agreement_external_url, founders_agreement_internal_url, created_at, updated_at, social_links
+RETURNING id, company_id, first_name, last_name, title, linkedin_url, is_account_owner, personal_website, commitment_type, introduction, industry_experience, detailed_biography, previous_work, resume_external_url, resume_internal_key, founders_agreement_external_url, founders_agreement_internal_key, created_at, updated_at, social_links, resume_internal_size, founders_agreement_internal_size
 `
 
 type UpdateTeamMemberParams struct {
-	FirstName                    string `json:"first_name"`
-	LastName                     string `json:"last_name"`
-	Title                        string `json:"title"`
-	DetailedBiography            string `json:"detailed_biography"`
-	LinkedinUrl                  string `json:"linkedin_url"`
-	SocialLinks                  []byte `json:"social_links"`
-	PersonalWebsite              string `json:"personal_website"`
-	CommitmentType               string `json:"commitment_type"`
-	Introduction                 string `json:"introduction"`
-	IndustryExperience           string `json:"industry_experience"`
-	PreviousWork                 string `json:"previous_work"`
-	ResumeExternalUrl            string `json:"resume_external_url"`
-	ResumeInternalUrl            string `json:"resume_internal_url"`
-	FoundersAgreementExternalUrl string `json:"founders_agreement_external_url"`
-	FoundersAgreementInternalUrl string `json:"founders_agreement_internal_url"`
-	ID                           string `json:"id"`
-	CompanyID                    string `json:"company_id"`
+	FirstName                      string `json:"first_name"`
+	LastName                       string `json:"last_name"`
+	Title                          string `json:"title"`
+	DetailedBiography              string `json:"detailed_biography"`
+	LinkedinUrl                    string `json:"linkedin_url"`
+	SocialLinks                    []byte `json:"social_links"`
+	PersonalWebsite                string `json:"personal_website"`
+	CommitmentType                 string `json:"commitment_type"`
+	Introduction                   string `json:"introduction"`
+	IndustryExperience             string `json:"industry_experience"`
+	PreviousWork                   string `json:"previous_work"`
+	ResumeExternalUrl              string `json:"resume_external_url"`
+	ClearResumeInternal            bool   `json:"clear_resume_internal"`
+	FoundersAgreementExternalUrl   string `json:"founders_agreement_external_url"`
+	ClearFoundersAgreementInternal bool   `json:"clear_founders_agreement_internal"`
+	ID                             string `json:"id"`
+	CompanyID                      string `json:"company_id"`
 }
 
 func (q *Queries) UpdateTeamMember(ctx context.Context, arg UpdateTeamMemberParams) (TeamMember, error) {
@@ -250,9 +252,9 @@ func (q *Queries) UpdateTeamMember(ctx context.Context, arg UpdateTeamMemberPara
 		arg.IndustryExperience,
 		arg.PreviousWork,
 		arg.ResumeExternalUrl,
-		arg.ResumeInternalUrl,
+		arg.ClearResumeInternal,
 		arg.FoundersAgreementExternalUrl,
-		arg.FoundersAgreementInternalUrl,
+		arg.ClearFoundersAgreementInternal,
 		arg.ID,
 		arg.CompanyID,
 	)
@@ -272,12 +274,14 @@ func (q *Queries) UpdateTeamMember(ctx context.Context, arg UpdateTeamMemberPara
 		&i.DetailedBiography,
 		&i.PreviousWork,
 		&i.ResumeExternalUrl,
-		&i.ResumeInternalUrl,
+		&i.ResumeInternalKey,
 		&i.FoundersAgreementExternalUrl,
-		&i.FoundersAgreementInternalUrl,
+		&i.FoundersAgreementInternalKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.SocialLinks,
+		&i.ResumeInternalSize,
+		&i.FoundersAgreementInternalSize,
 	)
 	return i, err
 }
@@ -285,22 +289,28 @@ func (q *Queries) UpdateTeamMember(ctx context.Context, arg UpdateTeamMemberPara
 const updateTeamMemberDocuments = `-- name: UpdateTeamMemberDocuments :exec
 UPDATE team_members
 SET
-    resume_internal_url = $1,
-    founders_agreement_internal_url = $2
-WHERE id = $3 AND company_id = $4
+    resume_internal_key = $1,
+    resume_internal_size = $2,
+    founders_agreement_internal_key = $3,
+    founders_agreement_internal_size = $4
+WHERE id = $5 AND company_id = $6
 `
 
 type UpdateTeamMemberDocumentsParams struct {
-	ResumeInternalUrl            *string `json:"resume_internal_url"`
-	FoundersAgreementInternalUrl *string `json:"founders_agreement_internal_url"`
-	ID                           string  `json:"id"`
-	CompanyID                    string  `json:"company_id"`
+	ResumeInternalKey             *string `json:"resume_internal_key"`
+	ResumeInternalSize            int64   `json:"resume_internal_size"`
+	FoundersAgreementInternalKey  *string `json:"founders_agreement_internal_key"`
+	FoundersAgreementInternalSize int64   `json:"founders_agreement_internal_size"`
+	ID                            string  `json:"id"`
+	CompanyID                     string  `json:"company_id"`
 }
 
 func (q *Queries) UpdateTeamMemberDocuments(ctx context.Context, arg UpdateTeamMemberDocumentsParams) error {
 	_, err := q.db.Exec(ctx, updateTeamMemberDocuments,
-		arg.ResumeInternalUrl,
-		arg.FoundersAgreementInternalUrl,
+		arg.ResumeInternalKey,
+		arg.ResumeInternalSize,
+		arg.FoundersAgreementInternalKey,
+		arg.FoundersAgreementInternalSize,
 		arg.ID,
 		arg.CompanyID,
 	)
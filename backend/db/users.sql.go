@@ -72,7 +72,7 @@ func (q *Queries) DeleteUserSocial(ctx context.Context, id string) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, first_name, last_name, bio, title, linkedin, email, password, permissions, email_verified, created_at, updated_at, token_salt, profile_picture_url FROM users WHERE email = $1 LIMIT 1
+SELECT id, first_name, last_name, bio, title, linkedin, email, password, permissions, email_verified, created_at, updated_at, token_salt, profile_picture_key FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -92,13 +92,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TokenSalt,
-		&i.ProfilePictureUrl,
+		&i.ProfilePictureKey,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, first_name, last_name, bio, title, linkedin, email, password, permissions, email_verified, created_at, updated_at, token_salt, profile_picture_url
+SELECT id, first_name, last_name, bio, title, linkedin, email, password, permissions, email_verified, created_at, updated_at, token_salt, profile_picture_key
 FROM users 
 WHERE id = $1
 `
@@ -120,7 +120,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TokenSalt,
-		&i.ProfilePictureUrl,
+		&i.ProfilePictureKey,
 	)
 	return i, err
 }
@@ -133,7 +133,7 @@ SELECT
     COALESCE(title, '') as title,
     COALESCE(bio, '') as bio,
     COALESCE(linkedin, '') as linkedin,
-    profile_picture_url,
+    profile_picture_key,
     COALESCE(created_at, EXTRACT(EPOCH FROM NOW())::bigint) as created_at,
     NULLIF(updated_at, 0)::bigint as updated_at
 FROM users
@@ -147,7 +147,7 @@ type GetUserDetailsRow struct {
 	Title             string  `json:"title"`
 	Bio               string  `json:"bio"`
 	Linkedin          string  `json:"linkedin"`
-	ProfilePictureUrl *string `json:"profile_picture_url"`
+	ProfilePictureKey *string `json:"profile_picture_key"`
 	CreatedAt         int64   `json:"created_at"`
 	UpdatedAt         int64   `json:"updated_at"`
 }
@@ -162,7 +162,7 @@ func (q *Queries) GetUserDetails(ctx context.Context, id string) (GetUserDetails
 		&i.Title,
 		&i.Bio,
 		&i.Linkedin,
-		&i.ProfilePictureUrl,
+		&i.ProfilePictureKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -211,6 +211,32 @@ func (q *Queries) GetUserSocialsByUserID(ctx context.Context, userID string) ([]
 	return items, nil
 }
 
+const listUserIDsWithPermission = `-- name: ListUserIDsWithPermission :many
+SELECT id FROM users
+WHERE permissions & $1::int <> 0
+ORDER BY created_at
+`
+
+func (q *Queries) ListUserIDsWithPermission(ctx context.Context, permission int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listUserIDsWithPermission, permission)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT 
     id,
@@ -304,7 +330,7 @@ RETURNING
     COALESCE(title, '') as title,
     COALESCE(bio, '') as bio,
     COALESCE(linkedin, '') as linkedin,
-    profile_picture_url,
+    profile_picture_key,
     COALESCE(created_at, EXTRACT(EPOCH FROM NOW())::bigint) as created_at,
     NULLIF(updated_at, 0)::bigint as updated_at
 `
@@ -326,7 +352,7 @@ type NewUserRow struct {
 	Title             string  `json:"title"`
 	Bio               string  `json:"bio"`
 	Linkedin          string  `json:"linkedin"`
-	ProfilePictureUrl *string `json:"profile_picture_url"`
+	ProfilePictureKey *string `json:"profile_picture_key"`
 	CreatedAt         int64   `json:"created_at"`
 	UpdatedAt         int64   `json:"updated_at"`
 }
@@ -345,7 +371,7 @@ func (q *Queries) NewUser(ctx context.Context, arg NewUserParams) (NewUserRow, e
 		&i.Title,
 		&i.Bio,
 		&i.Linkedin,
-		&i.ProfilePictureUrl,
+		&i.ProfilePictureKey,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -395,17 +421,17 @@ func (q *Queries) UpdateUserEmailVerifiedStatus(ctx context.Context, arg UpdateU
 
 const updateUserProfilePicture = `-- name: UpdateUserProfilePicture :exec
 UPDATE users
-SET profile_picture_url = $1
+SET profile_picture_key = $1
 WHERE id = $2
 `
 
 type UpdateUserProfilePictureParams struct {
-	ProfilePictureUrl *string `json:"profile_picture_url"`
+	ProfilePictureKey *string `json:"profile_picture_key"`
 	ID                string  `json:"id"`
 }
 
 func (q *Queries) UpdateUserProfilePicture(ctx context.Context, arg UpdateUserProfilePictureParams) error {
-	_, err := q.db.Exec(ctx, updateUserProfilePicture, arg.ProfilePictureUrl, arg.ID)
+	_, err := q.db.Exec(ctx, updateUserProfilePicture, arg.ProfilePictureKey, arg.ID)
 	return err
 }
 
@@ -442,9 +468,10 @@ func (q *Queries) UpdateUsersRole(ctx context.Context, arg UpdateUsersRoleParams
 }
 
 const userExistsByEmail = `-- name: UserExistsByEmail :one
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)
+SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1))
 `
 
+// Emails are compared case-insensitively, Alice@x.com and alice@x.com are the same user
 func (q *Queries) UserExistsByEmail(ctx context.Context, email string) (bool, error) {
 	row := q.db.QueryRow(ctx, userExistsByEmail, email)
 	var exists bool
@@ -6,6 +6,7 @@ import (
 
 	"KonferCA/SPUR/db"
 	"KonferCA/SPUR/internal/spur_wallet"
+	"KonferCA/SPUR/scanner"
 	"KonferCA/SPUR/storage"
 )
 
@@ -24,6 +25,7 @@ Example:
 	func SetupRoutes(e *echo.Group, s CoreServer) {
 	    // Use s.GetQueries() for database access
 	    // Use s.GetStorage() for file operations
+	    // Use s.GetScanner() to scan uploaded files for malware
 	    // Use s.GetSpurWallet() for SPUR wallet operations
 	    // etc.
 	}
@@ -31,7 +33,8 @@ Example:
 type CoreServer interface {
 	GetDB() *pgxpool.Pool
 	GetQueries() *db.Queries
-	GetStorage() *storage.Storage
+	GetStorage() storage.Storage
+	GetScanner() scanner.Scanner
 	GetEcho() *echo.Echo
 	GetSpurWallet() *spur_wallet.SpurWalletConfig
 }
@@ -46,8 +46,12 @@ func getCORSConfigByEnv() em.CORSConfig {
 		"Content-Type",
 		"X-CSRF-Token",
 		"X-Requested-With",
+		"Upload-Offset",
 	}
 
+	// Resumable uploads return the offset to resume from in a header
+	exposeHeaders := []string{"Upload-Offset"}
+
 	switch appEnv {
 	case common.DEVELOPMENT_ENV, common.TEST_ENV:
 		return em.CORSConfig{
@@ -62,6 +66,7 @@ func getCORSConfigByEnv() em.CORSConfig {
 			},
 			AllowMethods:     allowMethods,
 			AllowHeaders:     allowHeaders,
+			ExposeHeaders:    exposeHeaders,
 			AllowCredentials: true,
 			MaxAge:           300,
 		}
@@ -76,6 +81,7 @@ func getCORSConfigByEnv() em.CORSConfig {
 			},
 			AllowMethods:     allowMethods,
 			AllowHeaders:     allowHeaders,
+			ExposeHeaders:    exposeHeaders,
 			AllowCredentials: true,
 			MaxAge:           300,
 		}
@@ -90,6 +96,7 @@ func getCORSConfigByEnv() em.CORSConfig {
 			},
 			AllowMethods:     allowMethods,
 			AllowHeaders:     allowHeaders,
+			ExposeHeaders:    exposeHeaders,
 			AllowCredentials: true,
 			MaxAge:           300,
 		}
@@ -108,6 +115,7 @@ func getCORSConfigByEnv() em.CORSConfig {
 		}),
 		AllowMethods:     allowMethods,
 		AllowHeaders:     []string{"*"},
+		ExposeHeaders:    exposeHeaders,
 		AllowCredentials: true,
 		MaxAge:           300,
 	}
@@ -35,7 +35,7 @@ func TestGetCORSConfigByEnv(t *testing.T) {
 				http.MethodDelete,
 				http.MethodOptions,
 			},
-			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
+			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Upload-Offset"},
 			expectedUnsafeWildcard: false,
 		},
 		{
@@ -52,7 +52,7 @@ func TestGetCORSConfigByEnv(t *testing.T) {
 				http.MethodDelete,
 				http.MethodOptions,
 			},
-			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
+			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Upload-Offset"},
 			expectedUnsafeWildcard: false,
 		},
 		{
@@ -69,7 +69,7 @@ func TestGetCORSConfigByEnv(t *testing.T) {
 				http.MethodDelete,
 				http.MethodOptions,
 			},
-			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
+			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Upload-Offset"},
 			expectedUnsafeWildcard: false,
 		},
 		{
@@ -86,7 +86,7 @@ func TestGetCORSConfigByEnv(t *testing.T) {
 				http.MethodDelete,
 				http.MethodOptions,
 			},
-			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
+			expectedAllowHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With", "Upload-Offset"},
 			expectedUnsafeWildcard: false,
 		},
 		{
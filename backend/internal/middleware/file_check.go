@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"strings"
@@ -25,12 +27,17 @@ type FileConfig struct {
 	MaxSize          int64
 	AllowedTypes     []string // ex. ["image/jpeg", "image/png", "application/pdf"]
 	StrictValidation bool     // If true, always verify content type matches header
+	// If true, look inside documents for macros, scripts and decompression bombs, see InspectFileContent
+	DeepInspection      bool
+	MaxUncompressedSize int64 // Bound on the decompressed size of a document, defaults to 100MB
+	MaxArchiveEntries   int   // Bound on the number of parts of an OOXML document, defaults to 2000
 }
 
 /*
 FileCheck middleware ensures uploaded files meet specified criteria:
 - Size limits (via Content-Length header and actual file size)
 - MIME type validation
+- Content inspection of documents when DeepInspection is set, issues are returned as the data of the error
 
 Usage:
 
@@ -42,6 +49,7 @@ Usage:
 	        "image/png",
 	        "application/pdf",
 	    },
+	    DeepInspection: true,
 	}))
 */
 func FileCheck(config FileConfig) echo.MiddlewareFunc {
@@ -76,6 +84,15 @@ func FileCheck(config FileConfig) echo.MiddlewareFunc {
 						if err := validateFile(file, config); err != nil {
 							return err
 						}
+						if config.DeepInspection {
+							if err := inspectFile(file, config); err != nil {
+								var inspectionErr *ContentInspectionError
+								if errors.As(err, &inspectionErr) {
+									return v1_common.FailWithData(c, http.StatusBadRequest, inspectionErr.Error(), inspectionErr, err)
+								}
+								return err
+							}
+						}
 					}
 				}
 			}
@@ -87,15 +104,8 @@ func FileCheck(config FileConfig) echo.MiddlewareFunc {
 
 // validateFile checks both size and MIME type of a single file
 func validateFile(file *multipart.FileHeader, config FileConfig) error {
-	// Check file size
-	size := file.Size
-	if size > config.MaxSize {
-		return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusRequestEntityTooLarge,
-			fmt.Sprintf("file %s size %d exceeds maximum allowed size of %d", file.Filename, size, config.MaxSize), "")
-	}
-	if size < config.MinSize {
-		return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest,
-			fmt.Sprintf("file %s size %d below minimum required size of %d", file.Filename, size, config.MinSize), "")
+	if err := checkFileSize(file.Filename, file.Size, config); err != nil {
+		return err
 	}
 
 	// Check MIME type if restrictions are specified
@@ -104,6 +114,7 @@ func validateFile(file *multipart.FileHeader, config FileConfig) error {
 		declaredType = strings.Split(declaredType, ";")[0] // Remove parameters
 
 		// If no Content-Type header or strict validation is enabled, check actual content
+		var actualType string
 		if declaredType == "" || config.StrictValidation {
 			f, err := file.Open()
 			if err != nil {
@@ -115,36 +126,103 @@ func validateFile(file *multipart.FileHeader, config FileConfig) error {
 			if err != nil {
 				return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest, "could not detect file type", "")
 			}
+			actualType = mime.String()
+		}
 
-			actualType := mime.String()
+		if err := checkFileType(file.Filename, declaredType, actualType, config); err != nil {
+			return err
+		}
+	}
 
-			// If we have both types, verify they match (when strict validation is enabled)
-			if declaredType != "" && config.StrictValidation && !strings.EqualFold(declaredType, actualType) {
-				return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest,
-					fmt.Sprintf("declared Content-Type (%s) doesn't match actual content type (%s)",
-						declaredType, actualType), "")
-			}
+	return nil
+}
 
-			// Use actual type if no declared type, otherwise use declared type
-			if declaredType == "" {
-				declaredType = actualType
-			}
+/*
+ValidateFileContent applies the checks of FileCheck to a file that didn't go through the
+middleware, e.g. a file uploaded straight to the storage or assembled from chunks. Returns an *APIError
+for the size and type checks, and a *ContentInspectionError when DeepInspection finds issues.
+*/
+func ValidateFileContent(name string, declaredType string, data []byte, config FileConfig) error {
+	if err := checkFileSize(name, int64(len(data)), config); err != nil {
+		return err
+	}
+
+	if len(config.AllowedTypes) > 0 {
+		declaredType = strings.TrimSpace(strings.Split(declaredType, ";")[0])
+		if err := checkFileType(name, declaredType, mimetype.Detect(data).String(), config); err != nil {
+			return err
 		}
+	}
 
-		isAllowed := false
-		for _, allowed := range config.AllowedTypes {
-			if strings.EqualFold(declaredType, allowed) {
-				isAllowed = true
-				break
-			}
+	if config.DeepInspection {
+		if issues := InspectFileContent(data, config); len(issues) > 0 {
+			return &ContentInspectionError{File: name, Issues: issues}
 		}
+	}
+
+	return nil
+}
 
-		if !isAllowed {
+// checkFileSize checks the size of a file against the bounds of the config
+func checkFileSize(name string, size int64, config FileConfig) error {
+	if size > config.MaxSize {
+		return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file %s size %d exceeds maximum allowed size of %d", name, size, config.MaxSize), "")
+	}
+	if size < config.MinSize {
+		return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest,
+			fmt.Sprintf("file %s size %d below minimum required size of %d", name, size, config.MinSize), "")
+	}
+	return nil
+}
+
+/*
+checkFileType checks that the type of a file is allowed. actualType is the type detected from
+the content, it is empty when it wasn't read. With strict validation the declared type must
+match it, without a declared type the detected one is used.
+*/
+func checkFileType(name string, declaredType string, actualType string, config FileConfig) error {
+	if actualType != "" {
+		// If we have both types, verify they match (when strict validation is enabled)
+		if declaredType != "" && config.StrictValidation && !strings.EqualFold(declaredType, actualType) {
 			return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest,
-				fmt.Sprintf("file type %s not allowed for %s. Allowed types: %v",
-					declaredType, file.Filename, config.AllowedTypes), "")
+				fmt.Sprintf("declared Content-Type (%s) doesn't match actual content type (%s)",
+					declaredType, actualType), "")
+		}
+
+		// Use actual type if no declared type, otherwise use declared type
+		if declaredType == "" {
+			declaredType = actualType
+		}
+	}
+
+	for _, allowed := range config.AllowedTypes {
+		if strings.EqualFold(declaredType, allowed) {
+			return nil
 		}
 	}
 
+	return v1_common.NewError(v1_common.ErrorTypeValidation, http.StatusBadRequest,
+		fmt.Sprintf("file type %s not allowed for %s. Allowed types: %v",
+			declaredType, name, config.AllowedTypes), "")
+}
+
+// inspectFile reads a file and returns a *ContentInspectionError when InspectFileContent finds issues
+func inspectFile(file *multipart.FileHeader, config FileConfig) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if issues := InspectFileContent(data, config); len(issues) > 0 {
+		return &ContentInspectionError{File: file.Filename, Issues: issues}
+	}
+
 	return nil
 }
@@ -215,3 +215,48 @@ func TestFileCheck(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 }
+
+func TestValidateFileContent(t *testing.T) {
+	config := FileConfig{
+		MinSize:          10,
+		MaxSize:          1024,
+		AllowedTypes:     []string{"application/pdf"},
+		StrictValidation: true,
+		DeepInspection:   true,
+	}
+	pdf := buildPDF("1 0 obj\n<< /Type /Catalog >>\nendobj")
+
+	assert.NoError(t, ValidateFileContent("plan.pdf", "application/pdf; charset=binary", pdf, config))
+
+	tests := []struct {
+		name         string
+		declaredType string
+		content      []byte
+		code         int
+	}{
+		{name: "too large", declaredType: "application/pdf", content: append(pdf, bytes.Repeat([]byte(" "), 1024)...), code: http.StatusRequestEntityTooLarge},
+		{name: "too small", declaredType: "application/pdf", content: []byte("%PDF-1.4"), code: http.StatusBadRequest},
+		{name: "declared type doesn't match", declaredType: "image/png", content: pdf, code: http.StatusBadRequest},
+		{name: "type not allowed", declaredType: "", content: bytes.Repeat([]byte("plain text "), 5), code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFileContent("plan.pdf", tt.declaredType, tt.content, config)
+			apiErr, ok := err.(*v1_common.APIError)
+			if assert.True(t, ok, err) {
+				assert.Equal(t, tt.code, apiErr.Code)
+			}
+		})
+	}
+
+	t.Run("content inspection", func(t *testing.T) {
+		script := buildPDF("1 0 obj\n<< /Type /Catalog /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >>\nendobj")
+		err := ValidateFileContent("plan.pdf", "application/pdf", script, config)
+		inspectionErr, ok := err.(*ContentInspectionError)
+		if assert.True(t, ok, err) {
+			assert.Equal(t, "plan.pdf", inspectionErr.File)
+			assert.NotEmpty(t, inspectionErr.Issues)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package middleware
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Limits used by the content inspection when FileConfig doesn't set them
+const (
+	defaultMaxUncompressedSize = 100 * 1024 * 1024
+	defaultMaxArchiveEntries   = 2000
+)
+
+// Codes of the issues found by the content inspection
+const (
+	FileIssueInvalidDocument      = "invalid_document"
+	FileIssueTooManyEntries       = "too_many_entries"
+	FileIssueUncompressedTooLarge = "uncompressed_too_large"
+	FileIssueMacros               = "macros_not_allowed"
+	FileIssuePDFJavaScript        = "pdf_javascript"
+	FileIssuePDFLaunchAction      = "pdf_launch_action"
+)
+
+const (
+	oleMagic                       = "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"
+	zipMagic                       = "PK\x03\x04"
+	pdfMagic                       = "%PDF-"
+	contentTypesPart               = "[content_types].xml"
+	vbaProjectPart                 = "vbaproject.bin"
+	macroEnabledContentTypeKeyword = "macroenabled"
+	vbaProjectContentType          = "application/vnd.ms-office.vbaproject"
+)
+
+var (
+	// pdfNamePattern matches PDF name objects, e.g. /JavaScript
+	pdfNamePattern = regexp.MustCompile(`/[^\s/<>\[\]()%{}]*`)
+	// pdfStreamPattern matches the start of the data of a PDF stream
+	pdfStreamPattern = regexp.MustCompile(`stream\r?\n`)
+	// oleVBAStorage is the name of the storage holding the macros of .doc and .xls files, names are UTF-16LE
+	oleVBAStorage = utf16LE("_VBA_PROJECT")
+)
+
+// FileIssue is one reason an uploaded file was rejected by the content inspection
+type FileIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+/*
+ContentInspectionError lists the issues found in an uploaded file. Handlers return it with
+v1_common.FailWithData, so the file and its issues are the data of the response.
+*/
+type ContentInspectionError struct {
+	File   string      `json:"file"`
+	Issues []FileIssue `json:"issues"`
+}
+
+func (e *ContentInspectionError) Error() string {
+	return fmt.Sprintf("file %s failed content inspection", e.File)
+}
+
+/*
+InspectFileContent looks inside documents for content that the MIME type check can't see.
+The kind of document is taken from the content itself, not from the declared type:
+  - OOXML (.docx, .xlsx): bounded decompressed size and entry count, no VBA macros
+  - PDF: no JavaScript or Launch actions, also inside compressed streams
+  - OLE (.doc, .xls): no VBA macros
+
+Other files, e.g. images, have no issues. Returns nil when the file is fine.
+*/
+func InspectFileContent(data []byte, config FileConfig) []FileIssue {
+	maxSize := config.MaxUncompressedSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxUncompressedSize
+	}
+	maxEntries := config.MaxArchiveEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxArchiveEntries
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte(zipMagic)):
+		return inspectOOXML(data, maxSize, maxEntries)
+	case bytes.Contains(data[:min(len(data), 1024)], []byte(pdfMagic)):
+		return inspectPDF(data, maxSize)
+	case bytes.HasPrefix(data, []byte(oleMagic)):
+		if bytes.Contains(data, oleVBAStorage) {
+			return []FileIssue{{Code: FileIssueMacros, Message: "documents with macros are not allowed"}}
+		}
+	}
+
+	return nil
+}
+
+// inspectOOXML checks the parts of an OOXML archive, every part is decompressed to count its real size
+func inspectOOXML(data []byte, maxSize int64, maxEntries int) []FileIssue {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return []FileIssue{{Code: FileIssueInvalidDocument, Message: "the file is not a valid Office document"}}
+	}
+
+	if len(archive.File) > maxEntries {
+		return []FileIssue{{Code: FileIssueTooManyEntries,
+			Message: fmt.Sprintf("the document has %d parts, at most %d are allowed", len(archive.File), maxEntries)}}
+	}
+
+	var issues []FileIssue
+	hasContentTypes := false
+	remaining := maxSize
+	for _, entry := range archive.File {
+		name := strings.ToLower(entry.Name)
+		if path.Base(name) == vbaProjectPart {
+			issues = addIssue(issues, FileIssueMacros, "documents with macros are not allowed")
+		}
+
+		part, err := entry.Open()
+		if err != nil {
+			return addIssue(issues, FileIssueInvalidDocument, "the file is not a valid Office document")
+		}
+
+		// Only the content types are kept, the other parts are counted and dropped
+		var dst io.Writer = io.Discard
+		var contentTypes bytes.Buffer
+		if name == contentTypesPart {
+			hasContentTypes = true
+			dst = &contentTypes
+		}
+
+		n, err := io.Copy(dst, io.LimitReader(part, remaining+1))
+		part.Close()
+		if err != nil {
+			return addIssue(issues, FileIssueInvalidDocument, "the file is not a valid Office document")
+		}
+		remaining -= n
+		if remaining < 0 {
+			return addIssue(issues, FileIssueUncompressedTooLarge,
+				fmt.Sprintf("the document is larger than %d bytes once decompressed", maxSize))
+		}
+
+		// A macro-enabled document renamed to .docx or .xlsx still declares its macro-enabled content type,
+		// and a VBA project stored under another part name still declares the content type of VBA projects
+		if name == contentTypesPart {
+			declared := strings.ToLower(contentTypes.String())
+			if strings.Contains(declared, macroEnabledContentTypeKeyword) || strings.Contains(declared, vbaProjectContentType) {
+				issues = addIssue(issues, FileIssueMacros, "documents with macros are not allowed")
+			}
+		}
+	}
+
+	if !hasContentTypes {
+		issues = addIssue(issues, FileIssueInvalidDocument, "the file is not a valid Office document")
+	}
+
+	return issues
+}
+
+// inspectPDF looks for actions that run code in the objects of the PDF and in its Flate compressed streams
+func inspectPDF(data []byte, maxSize int64) []FileIssue {
+	issues := inspectPDFNames(nil, data)
+
+	remaining := maxSize
+	for _, loc := range pdfStreamPattern.FindAllIndex(data, -1) {
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+
+		// Streams that aren't Flate compressed fail here and were already covered by the raw scan
+		reader, err := zlib.NewReader(bytes.NewReader(data[start : start+end]))
+		if err != nil {
+			continue
+		}
+		var decoded bytes.Buffer
+		n, _ := io.Copy(&decoded, io.LimitReader(reader, remaining+1))
+		reader.Close()
+		remaining -= n
+		if remaining < 0 {
+			return addIssue(issues, FileIssueUncompressedTooLarge,
+				fmt.Sprintf("the document is larger than %d bytes once decompressed", maxSize))
+		}
+
+		issues = inspectPDFNames(issues, decoded.Bytes())
+	}
+
+	return issues
+}
+
+// inspectPDFNames adds an issue for every JavaScript or Launch name found in data
+func inspectPDFNames(issues []FileIssue, data []byte) []FileIssue {
+	for _, raw := range pdfNamePattern.FindAll(data, -1) {
+		switch decodePDFName(raw[1:]) {
+		case "JavaScript", "JS":
+			issues = addIssue(issues, FileIssuePDFJavaScript, "PDFs with JavaScript are not allowed")
+		case "Launch":
+			issues = addIssue(issues, FileIssuePDFLaunchAction, "PDFs that launch other programs are not allowed")
+		}
+	}
+	return issues
+}
+
+// decodePDFName resolves the #xx escapes of a PDF name, e.g. J#61vaScript is JavaScript
+func decodePDFName(name []byte) string {
+	if !bytes.Contains(name, []byte("#")) {
+		return string(name)
+	}
+
+	var decoded strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if b, err := hex.DecodeString(string(name[i+1 : i+3])); err == nil {
+				decoded.WriteByte(b[0])
+				i += 2
+				continue
+			}
+		}
+		decoded.WriteByte(name[i])
+	}
+	return decoded.String()
+}
+
+// addIssue adds an issue unless one with the same code was already found
+func addIssue(issues []FileIssue, code string, message string) []FileIssue {
+	for _, issue := range issues {
+		if issue.Code == code {
+			return issues
+		}
+	}
+	return append(issues, FileIssue{Code: code, Message: message})
+}
+
+func utf16LE(s string) []byte {
+	out := make([]byte, 0, len(s)*2)
+	for _, r := range s {
+		out = append(out, byte(r), 0)
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+// buildZip creates an archive with the given parts, in order
+func buildZip(t *testing.T, parts ...[2]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, part := range parts {
+		f, err := w.Create(part[0])
+		require.NoError(t, err)
+		_, err = f.Write([]byte(part[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func buildDocx(t *testing.T, extra ...[2]string) []byte {
+	parts := append([][2]string{
+		{"[Content_Types].xml", docxContentTypes},
+		{"word/document.xml", "<w:document><w:body><w:p>Pitch</w:p></w:body></w:document>"},
+	}, extra...)
+	return buildZip(t, parts...)
+}
+
+func flate(t *testing.T, data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func buildPDF(objects ...string) []byte {
+	return []byte("%PDF-1.7\n" + strings.Join(objects, "\n") + "\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+}
+
+func issueCodes(issues []FileIssue) []string {
+	codes := make([]string, len(issues))
+	for i, issue := range issues {
+		codes[i] = issue.Code
+	}
+	return codes
+}
+
+func TestInspectFileContent(t *testing.T) {
+	manyParts := [][2]string{{"[Content_Types].xml", docxContentTypes}}
+	for i := 0; i < 20; i++ {
+		manyParts = append(manyParts, [2]string{fmt.Sprintf("word/media/image%d.png", i), "png"})
+	}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		config   FileConfig
+		expected []string
+	}{
+		{
+			name:    "clean docx",
+			content: buildDocx(t),
+		},
+		{
+			name:     "docx with a vba project",
+			content:  buildDocx(t, [2]string{"word/vbaProject.bin", "macro"}),
+			expected: []string{FileIssueMacros},
+		},
+		{
+			name: "macro-enabled document renamed to docx",
+			content: buildZip(t,
+				[2]string{"[Content_Types].xml", strings.Replace(docxContentTypes,
+					"application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml",
+					"application/vnd.ms-word.document.macroEnabled.main+xml", 1)},
+				[2]string{"word/document.xml", "<w:document/>"}),
+			expected: []string{FileIssueMacros},
+		},
+		{
+			name: "vba project stored under another name",
+			content: buildZip(t,
+				[2]string{"[Content_Types].xml", strings.Replace(docxContentTypes, "</Types>",
+					`<Default Extension="dat" ContentType="application/vnd.ms-office.vbaProject"/>`+"\n</Types>", 1)},
+				[2]string{"word/document.xml", "<w:document/>"},
+				[2]string{"word/data.dat", "macro"}),
+			expected: []string{FileIssueMacros},
+		},
+		{
+			name:     "zip bomb",
+			content:  buildDocx(t, [2]string{"word/media/bomb.bin", strings.Repeat("0", 64*1024)}),
+			config:   FileConfig{MaxUncompressedSize: 16 * 1024},
+			expected: []string{FileIssueUncompressedTooLarge},
+		},
+		{
+			name:     "too many parts",
+			content:  buildZip(t, manyParts...),
+			config:   FileConfig{MaxArchiveEntries: 10},
+			expected: []string{FileIssueTooManyEntries},
+		},
+		{
+			name:     "zip that isn't an office document",
+			content:  buildZip(t, [2]string{"notes.txt", "hello"}),
+			expected: []string{FileIssueInvalidDocument},
+		},
+		{
+			name:     "truncated archive",
+			content:  buildDocx(t)[:40],
+			expected: []string{FileIssueInvalidDocument},
+		},
+		{
+			name:    "clean pdf",
+			content: buildPDF("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj"),
+		},
+		{
+			name:     "pdf with an open action running javascript",
+			content:  buildPDF("1 0 obj\n<< /Type /Catalog /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >>\nendobj"),
+			expected: []string{FileIssuePDFJavaScript},
+		},
+		{
+			name:     "pdf with an escaped javascript name",
+			content:  buildPDF("1 0 obj\n<< /Type /Catalog /OpenAction << /S /J#61vaScript >> >>\nendobj"),
+			expected: []string{FileIssuePDFJavaScript},
+		},
+		{
+			name:     "pdf with a launch action",
+			content:  buildPDF("1 0 obj\n<< /Type /Catalog /OpenAction << /S /Launch /F (cmd.exe) >> >>\nendobj"),
+			expected: []string{FileIssuePDFLaunchAction},
+		},
+		{
+			name: "pdf with javascript inside a compressed object stream",
+			content: buildPDF("1 0 obj\n<< /Type /ObjStm /Filter /FlateDecode >>\nstream\n" +
+				flate(t, "<< /S /JavaScript /JS (app.alert(1)) >>") + "\nendstream\nendobj"),
+			expected: []string{FileIssuePDFJavaScript},
+		},
+		{
+			name: "pdf with a stream bomb",
+			content: buildPDF("1 0 obj\n<< /Filter /FlateDecode >>\nstream\n" +
+				flate(t, strings.Repeat("0", 64*1024)) + "\nendstream\nendobj"),
+			config:   FileConfig{MaxUncompressedSize: 16 * 1024},
+			expected: []string{FileIssueUncompressedTooLarge},
+		},
+		{
+			name:    "doc without macros",
+			content: append([]byte(oleMagic), utf16LE("WordDocument")...),
+		},
+		{
+			name:     "doc with macros",
+			content:  append([]byte(oleMagic), utf16LE("_VBA_PROJECT_CUR")...),
+			expected: []string{FileIssueMacros},
+		},
+		{
+			name:    "png is not inspected",
+			content: []byte("\x89PNG\r\n\x1a\n/JavaScript"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := InspectFileContent(tt.content, tt.config)
+			if tt.expected == nil {
+				assert.Empty(t, issues)
+				return
+			}
+			assert.ElementsMatch(t, tt.expected, issueCodes(issues))
+		})
+	}
+}
+
+func TestFileCheckDeepInspection(t *testing.T) {
+	e := echo.New()
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "success")
+	}
+
+	upload := func(content []byte) error {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="file"; filename="deck.pdf"`)
+		h.Set("Content-Type", "application/pdf")
+		part, err := writer.CreatePart(h)
+		require.NoError(t, err)
+		part.Write(content)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.ContentLength = int64(body.Len())
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		return FileCheck(FileConfig{
+			MinSize:          1,
+			MaxSize:          1024 * 1024,
+			AllowedTypes:     []string{"application/pdf"},
+			StrictValidation: true,
+			DeepInspection:   true,
+		})(handler)(c)
+	}
+
+	t.Run("clean document passes", func(t *testing.T) {
+		assert.NoError(t, upload(buildPDF("1 0 obj\n<< /Type /Catalog >>\nendobj")))
+	})
+
+	t.Run("issues come back as structured details", func(t *testing.T) {
+		err := upload(buildPDF("1 0 obj\n<< /OpenAction << /S /Launch /F (cmd.exe) >> /AA << /O << /S /JavaScript >> >> >>\nendobj"))
+		apiErr, ok := err.(*v1_common.APIError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+		assert.Contains(t, apiErr.Message, "deck.pdf")
+
+		details, ok := apiErr.Data.(*ContentInspectionError)
+		require.True(t, ok)
+		assert.Equal(t, "deck.pdf", details.File)
+		assert.ElementsMatch(t, []string{FileIssuePDFJavaScript, FileIssuePDFLaunchAction}, issueCodes(details.Issues))
+	})
+}
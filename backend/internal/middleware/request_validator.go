@@ -5,8 +5,8 @@ import (
 	"KonferCA/SPUR/internal/permissions"
 	"KonferCA/SPUR/internal/spur_wallet"
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
 	"fmt"
-	"os"
 	"reflect"
 	"regexp"
 	"strings"
@@ -45,7 +45,7 @@ type CustomValidator struct {
 /*
 NewRequestValidator creates and initializes a new CustomValidator with registered custom validation rules. The following custom validations are registered:
   - valid_permissions: Validates user permissions
-  - s3_url: Validates S3 bucket URLs
+  - s3_url: Validates URLs of files in the configured storage
   - wallet_address: Validates cryptocurrency wallet addresses
   - transaction_hash: Validates transaction hashes (64 hex characters)
   - linkedin_url: Validates LinkedIn profile URLs
@@ -111,25 +111,23 @@ func validatePermissions(fl validator.FieldLevel) bool {
 }
 
 /*
-validateS3URL verifies that a field contains a valid S3 URL for the configured bucket.
-The URL must start with the expected S3 bucket prefix from AWS_S3_BUCKET environment variable.
+validateS3URL verifies that a field contains the URL of a file of the configured storage backend,
+see storage.BaseURLFromEnv.
 
-Returns true if the URL is valid for the configured bucket, false otherwise.
-Logs a warning if AWS_S3_BUCKET environment variable is not set.
+Returns true if the URL points to the configured storage, false otherwise.
+Logs a warning if the storage backend is not configured.
 */
 func validateS3URL(fl validator.FieldLevel) bool {
 	url := fl.Field().String()
-	bucket := os.Getenv("AWS_S3_BUCKET")
+	expectedPrefix := storage.BaseURLFromEnv()
 
-	if bucket == "" {
-		log.Warn().Msg("AWS_S3_BUCKET env variable not set")
+	if expectedPrefix == "" {
+		log.Warn().Msg("Storage backend not configured, AWS_S3_BUCKET env variable not set")
 
 		return false
 	}
 
-	expectedPrefix := fmt.Sprintf("https://%s.s3.us-east-1.amazonaws.com/", bucket)
-
-	return strings.HasPrefix(url, expectedPrefix)
+	return len(url) > len(expectedPrefix) && strings.HasPrefix(url, expectedPrefix)
 }
 
 /*
@@ -285,7 +283,7 @@ func formatErrorMessage(field, tag, param string) string {
 	case "valid_permissions":
 		return fmt.Sprintf("%s contains invalid permissions", field)
 	case "s3_url":
-		return fmt.Sprintf("%s must be a valid S3 URL", field)
+		return fmt.Sprintf("%s must be a valid file URL", field)
 	case "wallet_address":
 		return fmt.Sprintf("%s must be a valid Ethereum wallet address", field)
 	case "transaction_hash":
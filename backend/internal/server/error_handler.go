@@ -35,6 +35,7 @@ func errorHandler(err error, c echo.Context) {
 		message = "internal server error"
 		errType = v1_common.ErrorTypeInternal
 		details string
+		data    interface{}
 	)
 
 	internalErr, _ := c.Get("internal_error").(error)
@@ -76,6 +77,7 @@ func errorHandler(err error, c echo.Context) {
 		errType = e.Type
 		message = e.Message
 		details = e.Details
+		data = e.Data
 	default:
 		if internalErr != nil {
 			log.Error().Err(internalErr).Msg("internal error occurred")
@@ -109,6 +111,7 @@ func errorHandler(err error, c echo.Context) {
 		Type:      errType,
 		Message:   message,
 		Details:   details,
+		Data:      data,
 		RequestID: requestID,
 		Code:      code,
 	}
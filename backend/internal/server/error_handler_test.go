@@ -139,6 +139,7 @@ func TestErrorHandler(t *testing.T) {
 		expectedType     v1_common.ErrorType
 		expectedMsg      string
 		expectedDetails  interface{}
+		expectedData     interface{}
 		checkLoggedError bool
 	}{
 		{
@@ -207,6 +208,17 @@ func TestErrorHandler(t *testing.T) {
 			expectedDetails:  "",
 			checkLoggedError: false,
 		},
+		{
+			name: "api error with data",
+			err: &v1_common.APIError{Type: v1_common.ErrorTypeBadRequest, Message: "file deck.pdf failed content inspection",
+				Data: map[string]interface{}{"file": "deck.pdf"}, Code: http.StatusBadRequest},
+			expectedStatus:   http.StatusBadRequest,
+			expectedType:     v1_common.ErrorTypeBadRequest,
+			expectedMsg:      "file deck.pdf failed content inspection",
+			expectedDetails:  "",
+			expectedData:     map[string]interface{}{"file": "deck.pdf"},
+			checkLoggedError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,6 +259,7 @@ func TestErrorHandler(t *testing.T) {
 			} else {
 				assert.Equal(t, tt.expectedDetails, response.Details, "Details mismatch")
 			}
+			assert.Equal(t, tt.expectedData, response.Data, "Data mismatch")
 
 			// verify log entry
 			logLines := strings.Split(strings.TrimSpace(buf.String()), "\n")
@@ -2,13 +2,17 @@ package server
 
 import (
 	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/spur_wallet"
+	"KonferCA/SPUR/scanner"
 	"KonferCA/SPUR/storage"
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
 /*
@@ -19,6 +23,8 @@ DB_HOST DB_PORT DB_USER DB_PASSWORD DB_NAME DB_SSLMODE
 
 Additionally, the following env variables are required for SPUR wallet operations:
 SPUR_WALLET_ADDRESS
+
+Uploaded files are scanned by the clamd daemon at CLAMAV_ADDRESS.
 */
 func New() (*Server, error) {
 	connStr := fmt.Sprintf(
@@ -40,6 +46,11 @@ func New() (*Server, error) {
 		return nil, err
 	}
 
+	fileScanner, err := scanner.NewScanner()
+	if err != nil {
+		return nil, err
+	}
+
 	spurWallet, err := spur_wallet.NewSpurWalletConfig()
 	if err != nil {
 		return nil, err
@@ -54,6 +65,7 @@ func New() (*Server, error) {
 		DBPool:     pool,
 		Echo:       e,
 		Storage:    store,
+		Scanner:    fileScanner,
 		SpurWallet: spurWallet,
 	}
 
@@ -83,10 +95,18 @@ func (s *Server) GetQueries() *db.Queries {
 Implement the CoreServer interface GetStorage method that simply
 returns an storage instance.
 */
-func (s *Server) GetStorage() *storage.Storage {
+func (s *Server) GetStorage() storage.Storage {
 	return s.Storage
 }
 
+/*
+Implement the CoreServer interface GetScanner method that simply
+returns the malware scanner instance.
+*/
+func (s *Server) GetScanner() scanner.Scanner {
+	return s.Scanner
+}
+
 /*
 Implement the CoreServer interface GetEcho method that simply
 returns the root echo instance.
@@ -105,7 +125,27 @@ func (s *Server) GetSpurWallet() *spur_wallet.SpurWalletConfig {
 
 /*
 Start the server and binds it to the given port.
+Export jobs that were still running when the server last stopped can't finish anymore, so they are marked as failed first.
+Documents whose scan was interrupted are scanned again in the background, and failed scans are retried periodically.
+Direct uploads that were never completed are removed in the background.
+Files no row references anymore are removed in the background, see service.ReconcileStorage.
+The investor alert matcher runs in the background.
+The background jobs stop when the server stops.
 */
 func (s *Server) Start(port string) error {
+	if err := s.GetQueries().FailInterruptedExportJobs(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to mark interrupted export jobs as failed.")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go service.RescanPendingDocuments(s.GetQueries(), s.Storage, s.Scanner, 0)
+	go service.StartScanRetrier(ctx, s.GetQueries(), s.Storage, s.Scanner)
+	go service.StartUploadCleaner(ctx, s.GetQueries(), s.Storage)
+	go service.StartStorageReconciler(ctx, s.GetQueries(), s.Storage, service.StorageReconcileOptionsFromEnv())
+
+	go service.StartAlertMatcher(ctx, s.DBPool)
+
 	return s.Echo.Start(fmt.Sprintf(":%s", port))
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"KonferCA/SPUR/internal/spur_wallet"
+	"KonferCA/SPUR/scanner"
 	"KonferCA/SPUR/storage"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,7 +12,8 @@ import (
 type Server struct {
 	DBPool     *pgxpool.Pool
 	Echo       *echo.Echo
-	Storage    *storage.Storage
+	Storage    storage.Storage
+	Scanner    scanner.Scanner
 	SpurWallet *spur_wallet.SpurWalletConfig
 }
 
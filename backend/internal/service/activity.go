@@ -0,0 +1,103 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ActivityPayload holds the details of an activity, it is stored as JSON with the activity.
+type ActivityPayload map[string]interface{}
+
+func actorUUID(actorID string) pgtype.UUID {
+	var actor pgtype.UUID
+	if parsed, err := uuid.Parse(actorID); err == nil {
+		actor.Bytes = parsed
+		actor.Valid = true
+	}
+	return actor
+}
+
+// RecordProjectActivity appends an entry to the activity timeline of a project.
+// The actor id can be empty for activities that are not caused by a user.
+//
+// Internal activities are only shown to users that can view all projects, they must be used
+// for anything derived from a resource the project owner can't see.
+func RecordProjectActivity(queries *db.Queries, ctx context.Context, projectID string, actorID string, activityType db.ProjectActivityType, internal bool, payload ActivityPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return queries.CreateProjectActivity(ctx, db.CreateProjectActivityParams{
+		ProjectID:    projectID,
+		ActorID:      actorUUID(actorID),
+		ActivityType: activityType,
+		Internal:     internal,
+		Payload:      data,
+	})
+}
+
+// RecordCompanyActivity appends an entry to the activity timeline of every project of a company.
+// This is used for company level changes, such as team members, that are part of each application.
+func RecordCompanyActivity(queries *db.Queries, ctx context.Context, companyID string, actorID string, activityType db.ProjectActivityType, payload ActivityPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return queries.CreateCompanyProjectsActivity(ctx, db.CreateCompanyProjectsActivityParams{
+		ActorID:      actorUUID(actorID),
+		ActivityType: activityType,
+		Internal:     false,
+		Payload:      data,
+		CompanyID:    companyID,
+	})
+}
+
+// RecordActivityInTx records an activity within the transaction of the change it describes, so the
+// entry is only kept when the change is committed. The activity is written behind a savepoint: when
+// recording fails the transaction is still usable, the caller logs the error and commits the change.
+func RecordActivityInTx(ctx context.Context, tx pgx.Tx, record func(queries *db.Queries) error) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := record(db.New(savepoint)); err != nil {
+		savepoint.Rollback(ctx)
+		return err
+	}
+
+	return savepoint.Commit(ctx)
+}
+
+// UpdateProjectStatus sets the status of a project and records the transition in the project's activity timeline.
+// Nothing is recorded if the project already has the given status.
+func UpdateProjectStatus(queries *db.Queries, ctx context.Context, projectID string, actorID string, status db.ProjectStatus) error {
+	project, err := queries.GetProjectByIDAsAdmin(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	err = queries.UpdateProjectStatus(ctx, db.UpdateProjectStatusParams{
+		ID:     projectID,
+		Status: status,
+	})
+	if err != nil {
+		return err
+	}
+
+	if project.Status == status {
+		return nil
+	}
+
+	return RecordProjectActivity(queries, ctx, projectID, actorID, db.ProjectActivityTypeStatusChanged, false, ActivityPayload{
+		"from": project.Status,
+		"to":   status,
+	})
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/views"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// alertMatchInterval is how often saved searches and watchlists are checked for changes.
+const alertMatchInterval = 5 * time.Minute
+
+// pendingNotification is a notification that still has to be stored.
+type pendingNotification struct {
+	UserID    string
+	Type      db.NotificationType
+	ProjectID string
+	Message   string
+}
+
+/*
+StartAlertMatcher runs MatchAlerts every alertMatchInterval until ctx is done.
+It is meant to run in a goroutine.
+*/
+func StartAlertMatcher(ctx context.Context, pool *pgxpool.Pool) {
+	ticker := time.NewTicker(alertMatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			matchCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			if err := MatchAlerts(matchCtx, pool); err != nil {
+				log.Error().Err(err).Msg("Failed to match investor alerts.")
+			}
+			cancel()
+		}
+	}
+}
+
+/*
+MatchAlerts notifies investors about projects that were verified since they saved a matching search,
+and about status or funding changes of the projects on their watchlist. Matches and watchlist states
+are recorded with the notifications in one transaction, so nothing is notified twice. Emails are sent
+once the notifications are stored.
+*/
+func MatchAlerts(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := db.New(pool).WithTx(tx)
+
+	var pending []pendingNotification
+
+	matches, err := queries.MatchSavedSearches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		pending = append(pending, pendingNotification{
+			UserID:    match.UserID,
+			Type:      db.NotificationTypeSavedSearchMatch,
+			ProjectID: match.ProjectID,
+			Message:   fmt.Sprintf("%s was just verified and matches your saved search \"%s\".", match.Title, match.SavedSearchName),
+		})
+	}
+
+	changes, err := queries.ListWatchlistChanges(ctx)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		pending = append(pending, watchlistChangeNotifications(change)...)
+
+		err = queries.UpdateWatchlistItemState(ctx, db.UpdateWatchlistItemStateParams{
+			LastStatus:       change.CurrentStatus,
+			LastFundingTotal: change.CurrentFundingTotal,
+			ID:               change.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	notifications := make([]db.Notification, 0, len(pending))
+	for _, n := range pending {
+		notification, err := CreateNotification(queries, ctx, n.UserID, n.Type, n.ProjectID, n.Message)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	sendNotificationEmails(ctx, db.New(pool), notifications)
+
+	return nil
+}
+
+// watchlistChangeNotifications describes what changed on a watched project since the investor was last notified.
+func watchlistChangeNotifications(change db.ListWatchlistChangesRow) []pendingNotification {
+	var notifications []pendingNotification
+
+	if change.LastStatus != change.CurrentStatus {
+		notifications = append(notifications, pendingNotification{
+			UserID:    change.UserID,
+			Type:      db.NotificationTypeWatchedProjectStatus,
+			ProjectID: change.ProjectID,
+			Message:   fmt.Sprintf("%s changed status from %s to %s.", change.Title, change.LastStatus, change.CurrentStatus),
+		})
+	}
+
+	last, current := FormatFundingTotal(change.LastFundingTotal), FormatFundingTotal(change.CurrentFundingTotal)
+	if last != current {
+		notifications = append(notifications, pendingNotification{
+			UserID:    change.UserID,
+			Type:      db.NotificationTypeWatchedProjectFunding,
+			ProjectID: change.ProjectID,
+			Message:   fmt.Sprintf("%s has raised %s so far, up from %s.", change.Title, current, last),
+		})
+	}
+
+	return notifications
+}
+
+// FormatFundingTotal drops the trailing zeros of a decimal amount, e.g. 1500.500000 becomes 1500.5
+func FormatFundingTotal(amount string) string {
+	if strings.Contains(amount, ".") {
+		amount = strings.TrimRight(strings.TrimRight(amount, "0"), ".")
+	}
+	if amount == "" {
+		return "0"
+	}
+	return amount
+}
+
+// CreateNotification stores an in-app notification for a user, optionally about a project.
+func CreateNotification(queries *db.Queries, ctx context.Context, userID string, notificationType db.NotificationType, projectID string, message string) (db.Notification, error) {
+	var projectUUID pgtype.UUID
+	if projectID != "" {
+		parsed, err := uuid.Parse(projectID)
+		if err != nil {
+			return db.Notification{}, err
+		}
+		projectUUID.Valid = true
+		projectUUID.Bytes = parsed
+	}
+
+	return queries.CreateNotification(ctx, db.CreateNotificationParams{
+		UserID:    userID,
+		Type:      notificationType,
+		ProjectID: projectUUID,
+		Message:   message,
+	})
+}
+
+/*
+SendNotificationEmail emails a notification to its user, with a link to the project it is about.
+The function requires the FRONTEND_URL env to work.
+It is important to call this function in a go routine to not block.
+*/
+func SendNotificationEmail(ctx context.Context, to string, notification db.Notification) error {
+	url := fmt.Sprintf("%s/dashboard", os.Getenv("FRONTEND_URL"))
+	if notification.ProjectID.Valid {
+		url = fmt.Sprintf("%s/projects/%s/overview", os.Getenv("FRONTEND_URL"), uuid.UUID(notification.ProjectID.Bytes))
+	}
+
+	buf := bytes.Buffer{}
+	err := views.NotificationEmail(notification.Message, url).Render(ctx, &buf)
+	if err != nil {
+		return err
+	}
+
+	return SendEmail(ctx, "Project Update", os.Getenv("NOREPLY_EMAIL"), []string{to}, buf.String())
+}
+
+func sendNotificationEmails(ctx context.Context, queries *db.Queries, notifications []db.Notification) {
+	for _, notification := range notifications {
+		user, err := queries.GetUserByID(ctx, notification.UserID)
+		if err != nil {
+			log.Error().Err(err).Str("user_id", notification.UserID).Msg("Failed to get user for notification email.")
+			continue
+		}
+		if err := SendNotificationEmail(ctx, user.Email, notification); err != nil {
+			log.Error().Err(err).Str("notification_id", notification.ID).Msg("Failed to send notification email.")
+		}
+	}
+}
+
+/*
+NotifyUser stores a notification for a user and emails it in the background, so
+the caller does not wait for the mail server.
+*/
+func NotifyUser(queries *db.Queries, ctx context.Context, userID string, notificationType db.NotificationType, projectID string, message string) error {
+	notification, err := CreateNotification(queries, ctx, userID, notificationType, projectID, message)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		sendNotificationEmails(ctx, queries, []db.Notification{notification})
+	}()
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFundingTotal(t *testing.T) {
+	assert.Equal(t, "0", FormatFundingTotal("0"))
+	assert.Equal(t, "0", FormatFundingTotal("0.000000000000000000"))
+	assert.Equal(t, "1500", FormatFundingTotal("1500.000000000000000000"))
+	assert.Equal(t, "1500.5", FormatFundingTotal("1500.500000000000000000"))
+	assert.Equal(t, "100", FormatFundingTotal("100"))
+}
+
+func TestWatchlistChangeNotifications(t *testing.T) {
+	change := db.ListWatchlistChangesRow{
+		UserID:              "user",
+		ProjectID:           "project",
+		Title:               "Sunbeam",
+		LastStatus:          db.ProjectStatusPending,
+		CurrentStatus:       db.ProjectStatusVerified,
+		LastFundingTotal:    "0.000000000000000000",
+		CurrentFundingTotal: "2500.000000000000000000",
+	}
+
+	notifications := watchlistChangeNotifications(change)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, db.NotificationTypeWatchedProjectStatus, notifications[0].Type)
+	assert.Equal(t, "Sunbeam changed status from pending to verified.", notifications[0].Message)
+	assert.Equal(t, db.NotificationTypeWatchedProjectFunding, notifications[1].Type)
+	assert.Equal(t, "Sunbeam has raised 2500 so far, up from 0.", notifications[1].Message)
+	assert.Equal(t, "user", notifications[1].UserID)
+
+	change.CurrentStatus = change.LastStatus
+	change.CurrentFundingTotal = "0"
+	assert.Empty(t, watchlistChangeNotifications(change))
+}
+
+func TestStartAlertMatcherStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		StartAlertMatcher(ctx, nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the alert matcher kept running after its context was done")
+	}
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/views"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// DossierRendererVersion is stored with every cached export. Bump it whenever the layout of
+// the dossier changes so that exports of existing snapshots are rendered again.
+const DossierRendererVersion = 1
+
+// dossierSnapshot is the subset of the project snapshot data needed to build a dossier.
+type dossierSnapshot struct {
+	Questions []struct {
+		Question        string   `json:"question"`
+		Section         string   `json:"section"`
+		SubSection      string   `json:"sub_section"`
+		SectionOrder    int      `json:"section_order"`
+		SubSectionOrder int      `json:"sub_section_order"`
+		QuestionOrder   int      `json:"question_order"`
+		InputType       string   `json:"input_type"`
+		Answer          string   `json:"answer"`
+		Choices         []string `json:"choices"`
+	} `json:"questions"`
+	Documents []struct {
+		Name       string `json:"name"`
+		Section    string `json:"section"`
+		SubSection string `json:"sub_section"`
+		MimeType   string `json:"mime_type"`
+		Size       int64  `json:"size"`
+	} `json:"documents"`
+	TeamMembers []struct {
+		FirstName          string  `json:"first_name"`
+		LastName           string  `json:"last_name"`
+		Title              string  `json:"title"`
+		CommitmentType     string  `json:"commitment_type"`
+		Introduction       string  `json:"introduction"`
+		IndustryExperience string  `json:"industry_experience"`
+		DetailedBiography  string  `json:"detailed_biography"`
+		PreviousWork       *string `json:"previous_work"`
+		LinkedinURL        string  `json:"linkedin_url"`
+	} `json:"team_members"`
+}
+
+/*
+BuildProjectDossier lays out a project snapshot as a dossier.
+Questions are grouped by section and sub-section, in section_order, sub_section_order and question_order.
+Unanswered questions are left out, and file, team and funding structure questions get their own sections.
+*/
+func BuildProjectDossier(snapshot db.ProjectSnapshot, companyName string) (views.ProjectDossier, error) {
+	var data dossierSnapshot
+	if err := json.Unmarshal(snapshot.Data, &data); err != nil {
+		return views.ProjectDossier{}, err
+	}
+
+	dossier := views.ProjectDossier{
+		Title:       snapshot.Title,
+		CompanyName: companyName,
+		Version:     snapshot.VersionNumber,
+		SubmittedAt: time.Unix(snapshot.CreatedAt, 0),
+	}
+	if snapshot.Description != nil {
+		dossier.Description = *snapshot.Description
+	}
+
+	questions := data.Questions
+	sort.SliceStable(questions, func(i, j int) bool {
+		a, b := questions[i], questions[j]
+		if a.SectionOrder != b.SectionOrder {
+			return a.SectionOrder < b.SectionOrder
+		}
+		if a.SubSectionOrder != b.SubSectionOrder {
+			return a.SubSectionOrder < b.SubSectionOrder
+		}
+		return a.QuestionOrder < b.QuestionOrder
+	})
+
+	for _, question := range questions {
+		switch question.InputType {
+		case "fundingstructure":
+			var funding db.FundingStructureModel
+			if question.Answer != "" && json.Unmarshal([]byte(question.Answer), &funding) == nil && funding.Type != "" {
+				dossier.Funding = &funding
+			}
+			continue
+		case "file", "team":
+			continue
+		}
+
+		if strings.TrimSpace(question.Answer) == "" && len(question.Choices) == 0 {
+			continue
+		}
+
+		if len(dossier.Sections) == 0 || dossier.Sections[len(dossier.Sections)-1].Name != question.Section {
+			dossier.Sections = append(dossier.Sections, views.DossierSection{Name: question.Section})
+		}
+		section := &dossier.Sections[len(dossier.Sections)-1]
+
+		if len(section.SubSections) == 0 || section.SubSections[len(section.SubSections)-1].Name != question.SubSection {
+			section.SubSections = append(section.SubSections, views.DossierSubSection{Name: question.SubSection})
+		}
+		subSection := &section.SubSections[len(section.SubSections)-1]
+
+		subSection.Questions = append(subSection.Questions, views.DossierQuestion{
+			Question: question.Question,
+			Answer:   question.Answer,
+			Choices:  question.Choices,
+		})
+	}
+
+	for _, member := range data.TeamMembers {
+		previousWork := ""
+		if member.PreviousWork != nil {
+			previousWork = *member.PreviousWork
+		}
+		dossier.TeamMembers = append(dossier.TeamMembers, views.DossierTeamMember{
+			Name:               strings.TrimSpace(member.FirstName + " " + member.LastName),
+			Title:              member.Title,
+			CommitmentType:     member.CommitmentType,
+			Introduction:       member.Introduction,
+			IndustryExperience: member.IndustryExperience,
+			Biography:          member.DetailedBiography,
+			PreviousWork:       previousWork,
+			LinkedinURL:        member.LinkedinURL,
+		})
+	}
+
+	for _, document := range data.Documents {
+		dossier.Documents = append(dossier.Documents, views.DossierDocument{
+			Name:       document.Name,
+			Section:    document.Section,
+			SubSection: document.SubSection,
+			MimeType:   document.MimeType,
+			Size:       document.Size,
+		})
+	}
+
+	return dossier, nil
+}
+
+// RenderProjectDossierHTML renders a dossier as a standalone HTML document.
+func RenderProjectDossierHTML(ctx context.Context, dossier views.ProjectDossier) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := views.ProjectDossierPage(dossier).Render(ctx, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+RenderProjectDossierPDF renders a dossier as an A4 PDF document.
+It follows the layout of the HTML dossier. The built-in fonts only cover Latin-1, so any
+other characters are dropped.
+*/
+func RenderProjectDossierPDF(dossier views.ProjectDossier) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(dossier.Title, true)
+	pdf.SetCreator("SPUR", true)
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.CellFormat(0, 10, tr(fmt.Sprintf("%s - version %d - page %d", dossier.Title, dossier.Version, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	heading := func(text string) {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "B", 15)
+		pdf.SetTextColor(17, 24, 39)
+		pdf.MultiCell(0, 8, tr(text), "B", "L", false)
+		pdf.Ln(3)
+	}
+	subHeading := func(text string) {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(55, 65, 81)
+		pdf.MultiCell(0, 6, tr(text), "", "L", false)
+		pdf.Ln(1)
+	}
+	label := func(text string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(17, 24, 39)
+		pdf.MultiCell(0, 5, tr(text), "", "L", false)
+	}
+	body := func(text string) {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(17, 24, 39)
+		pdf.MultiCell(0, 5, tr(text), "", "L", false)
+		pdf.Ln(3)
+	}
+	meta := func(text string) {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.MultiCell(0, 5, tr(text), "", "L", false)
+	}
+	row := func(widths []float64, values []string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		pdf.SetTextColor(17, 24, 39)
+		for i, value := range values {
+			pdf.CellFormat(widths[i], 7, tr(value), "B", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(0, 10, tr(dossier.Title), "", "L", false)
+	if dossier.CompanyName != "" && dossier.CompanyName != dossier.Title {
+		meta(dossier.CompanyName)
+	}
+	meta(fmt.Sprintf("Application version %d, submitted %s", dossier.Version, dossier.SubmittedAt.UTC().Format("January 2, 2006")))
+	if dossier.Description != "" {
+		pdf.Ln(2)
+		body(dossier.Description)
+	}
+
+	for _, section := range dossier.Sections {
+		heading(section.Name)
+		for _, subSection := range section.SubSections {
+			if subSection.Name != "" && subSection.Name != section.Name {
+				subHeading(subSection.Name)
+			}
+			for _, question := range subSection.Questions {
+				label(question.Question)
+				if len(question.Choices) > 0 {
+					body("- " + strings.Join(question.Choices, "\n- "))
+				} else {
+					body(question.Answer)
+				}
+			}
+		}
+	}
+
+	if funding := dossier.Funding; funding != nil {
+		heading("Funding Structure")
+		widths := []float64{60, 110}
+		row(widths, []string{"Structure", views.FundingTypeLabel(funding.Type)}, false)
+		switch funding.Type {
+		case "minimum":
+			row(widths, []string{"Minimum amount", "$" + stringValue(funding.MinAmount)}, false)
+			row(widths, []string{"Maximum amount", "$" + stringValue(funding.MaxAmount)}, false)
+		case "tiered":
+		default:
+			row(widths, []string{"Amount", "$" + funding.Amount}, false)
+		}
+		if funding.Type != "tiered" {
+			row(widths, []string{"Equity offered", funding.EquityPercentage + "%"}, false)
+		}
+		if funding.LimitInvestors && funding.MaxInvestors != nil {
+			row(widths, []string{"Maximum investors", fmt.Sprint(*funding.MaxInvestors)}, false)
+		}
+		if funding.Type == "tiered" && len(funding.Tiers) > 0 {
+			subHeading("Tiers")
+			row(widths, []string{"Amount", "Equity"}, true)
+			for _, tier := range funding.Tiers {
+				row(widths, []string{"$" + tier.Amount, tier.EquityPercentage + "%"}, false)
+			}
+		}
+	}
+
+	if len(dossier.TeamMembers) > 0 {
+		heading("Team")
+		for _, member := range dossier.TeamMembers {
+			subHeading(member.Name)
+			role := member.Title
+			if member.CommitmentType != "" {
+				role += ", " + member.CommitmentType
+			}
+			meta(role)
+			if member.LinkedinURL != "" {
+				meta(member.LinkedinURL)
+			}
+			pdf.Ln(1)
+			if member.Introduction != "" {
+				body(member.Introduction)
+			}
+			if member.IndustryExperience != "" {
+				label("Industry experience")
+				body(member.IndustryExperience)
+			}
+			if member.Biography != "" {
+				label("Biography")
+				body(member.Biography)
+			}
+			if member.PreviousWork != "" {
+				label("Previous work")
+				body(member.PreviousWork)
+			}
+		}
+	}
+
+	if len(dossier.Documents) > 0 {
+		heading("Documents")
+		widths := []float64{70, 45, 35, 20}
+		row(widths, []string{"Name", "Section", "Type", "Size"}, true)
+		for _, document := range dossier.Documents {
+			row(widths, []string{
+				truncate(document.Name, 45),
+				truncate(document.Section, 28),
+				truncate(document.MimeType, 22),
+				views.FormatDossierSize(document.Size),
+			}, false)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+GetProjectDossier returns the dossier of a snapshot in the given format.
+Snapshots never change, so the rendered dossier is cached per snapshot and only rendered
+again when DossierRendererVersion changes or the company is renamed, the name isn't part of the snapshot.
+*/
+func GetProjectDossier(queries *db.Queries, ctx context.Context, snapshot db.ProjectSnapshot, companyName string, format db.DossierFormat) ([]byte, error) {
+	cached, err := queries.GetProjectDossierExport(ctx, db.GetProjectDossierExportParams{
+		SnapshotID:      snapshot.ID,
+		Format:          format,
+		RendererVersion: DossierRendererVersion,
+		CompanyName:     companyName,
+	})
+	if err == nil {
+		return cached.Content, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	dossier, err := BuildProjectDossier(snapshot, companyName)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	switch format {
+	case db.DossierFormatPdf:
+		content, err = RenderProjectDossierPDF(dossier)
+	default:
+		content, err = RenderProjectDossierHTML(ctx, dossier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A failure to cache the export shouldn't fail the export itself, it will be rendered again next time
+	err = queries.UpsertProjectDossierExport(ctx, db.UpsertProjectDossierExportParams{
+		SnapshotID:      snapshot.ID,
+		Format:          format,
+		RendererVersion: DossierRendererVersion,
+		CompanyName:     companyName,
+		Content:         content,
+	})
+	if err == nil {
+		err = queries.DeleteStaleProjectDossierExports(ctx, db.DeleteStaleProjectDossierExportsParams{
+			SnapshotID:      snapshot.ID,
+			RendererVersion: DossierRendererVersion,
+		})
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("snapshot_id", snapshot.ID).Msg("Failed to cache project dossier.")
+	}
+
+	return content, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// truncate shortens text to at most max characters so it fits in a table cell
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDossierSnapshot(t *testing.T) db.ProjectSnapshot {
+	funding, err := json.Marshal(db.FundingStructureModel{
+		Type:             "target",
+		Amount:           "250000",
+		EquityPercentage: "10",
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"questions": []map[string]interface{}{
+			{"question": "What is your mission?", "section": "The Basics", "sub_section": "Company", "section_order": 0, "sub_section_order": 1, "question_order": 1, "input_type": "textarea", "answer": "Clean <energy> for all"},
+			{"question": "Company name", "section": "The Basics", "sub_section": "Company", "section_order": 0, "sub_section_order": 1, "question_order": 0, "input_type": "textinput", "answer": "Sunbeam"},
+			{"question": "Who are your competitors?", "section": "Market", "sub_section": "Competition", "section_order": 1, "sub_section_order": 0, "question_order": 0, "input_type": "textarea", "answer": ""},
+			{"question": "Industries", "section": "Market", "sub_section": "Overview", "section_order": 1, "sub_section_order": 0, "question_order": 1, "input_type": "multiselect", "answer": "", "choices": []string{"Energy", "Hardware"}},
+			{"question": "Funding Structure", "section": "The Basics", "sub_section": "Funding Structure", "section_order": 0, "sub_section_order": 4, "question_order": 0, "input_type": "fundingstructure", "answer": string(funding)},
+			{"question": "Pitch deck", "section": "The Basics", "sub_section": "Company", "section_order": 0, "sub_section_order": 1, "question_order": 2, "input_type": "file", "answer": ""},
+		},
+		"documents": []map[string]interface{}{
+			{"name": "deck.pdf", "section": "The Basics", "sub_section": "Company", "mime_type": "application/pdf", "size": 2048},
+		},
+		"team_members": []map[string]interface{}{
+			{"first_name": "Ada", "last_name": "Lovelace", "title": "CEO", "commitment_type": "Full-time", "detailed_biography": "Wrote the first program."},
+		},
+	})
+	require.NoError(t, err)
+
+	return db.ProjectSnapshot{
+		ID:            "7b7a3a8e-54a5-4f4f-9f0b-3f1e6f0f2b11",
+		Title:         "Sunbeam",
+		Data:          data,
+		VersionNumber: 2,
+		CreatedAt:     1700000000,
+	}
+}
+
+func TestBuildProjectDossier(t *testing.T) {
+	dossier, err := BuildProjectDossier(testDossierSnapshot(t), "Sunbeam Inc.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Sunbeam", dossier.Title)
+	assert.Equal(t, "Sunbeam Inc.", dossier.CompanyName)
+	assert.Equal(t, int32(2), dossier.Version)
+
+	// unanswered, file and funding questions are not part of the sections
+	require.Len(t, dossier.Sections, 2)
+	assert.Equal(t, "The Basics", dossier.Sections[0].Name)
+	require.Len(t, dossier.Sections[0].SubSections, 1)
+	questions := dossier.Sections[0].SubSections[0].Questions
+	require.Len(t, questions, 2)
+	assert.Equal(t, "Company name", questions[0].Question)
+	assert.Equal(t, "What is your mission?", questions[1].Question)
+
+	assert.Equal(t, "Market", dossier.Sections[1].Name)
+	require.Len(t, dossier.Sections[1].SubSections, 1)
+	assert.Equal(t, []string{"Energy", "Hardware"}, dossier.Sections[1].SubSections[0].Questions[0].Choices)
+
+	require.NotNil(t, dossier.Funding)
+	assert.Equal(t, "target", dossier.Funding.Type)
+	assert.Equal(t, "250000", dossier.Funding.Amount)
+
+	require.Len(t, dossier.TeamMembers, 1)
+	assert.Equal(t, "Ada Lovelace", dossier.TeamMembers[0].Name)
+	assert.Equal(t, "Wrote the first program.", dossier.TeamMembers[0].Biography)
+
+	require.Len(t, dossier.Documents, 1)
+	assert.Equal(t, "deck.pdf", dossier.Documents[0].Name)
+}
+
+func TestRenderProjectDossier(t *testing.T) {
+	dossier, err := BuildProjectDossier(testDossierSnapshot(t), "Sunbeam Inc.")
+	require.NoError(t, err)
+
+	t.Run("html", func(t *testing.T) {
+		content, err := RenderProjectDossierHTML(context.Background(), dossier)
+		require.NoError(t, err)
+
+		html := string(content)
+		assert.Contains(t, html, "Clean &lt;energy&gt; for all")
+		assert.Contains(t, html, "Funding Structure")
+		assert.Contains(t, html, "$250000")
+		assert.Contains(t, html, "Ada Lovelace")
+		assert.Contains(t, html, "2.0 KB")
+		assert.Less(t, strings.Index(html, "Company name"), strings.Index(html, "What is your mission?"))
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		content, err := RenderProjectDossierPDF(dossier)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "%PDF-"))
+	})
+}
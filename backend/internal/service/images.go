@@ -0,0 +1,303 @@
+package service
+
+import (
+	"KonferCA/SPUR/storage"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"path"
+
+	"github.com/rs/zerolog/log"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxImageSide and maxImagePixels bound the images that are decoded, a small file can declare
+	// dimensions that need gigabytes of memory once decoded. 50 megapixels admit the originals of
+	// phones and cameras up to 48MP, they take at most 200MB decoded.
+	maxImageSide   = 12000
+	maxImagePixels = 50_000_000
+	// maxConcurrentImages is how many images are decoded at once, the others wait their turn
+	maxConcurrentImages = 2
+	// imageJPEGQuality is the quality of the generated images
+	imageJPEGQuality = 85
+	// fullImageVariant is the variant whose key is stored in the database
+	fullImageVariant = "full"
+)
+
+var (
+	// ErrInvalidImage is returned for files that aren't a JPEG, PNG or WebP image
+	ErrInvalidImage = errors.New("file is not a valid JPEG, PNG or WebP image")
+	// ErrImageTooLarge is returned for images whose dimensions are over maxImageSide or maxImagePixels
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+// ImageVariant is a size generated for every uploaded image, it fits in a MaxSide square
+type ImageVariant struct {
+	Name    string
+	MaxSide int
+}
+
+// ImageVariants are the sizes generated by ProcessImage, the full size replaces the uploaded file
+var ImageVariants = []ImageVariant{
+	{Name: "thumbnail", MaxSide: 256},
+	{Name: "medium", MaxSide: 1024},
+	{Name: fullImageVariant, MaxSide: 2560},
+}
+
+// imageFormats are the formats accepted by ProcessImage, other registered decoders are ignored
+var imageFormats = map[string]bool{"jpeg": true, "png": true, "webp": true}
+
+// imageSlots bounds the memory taken by concurrent uploads, a slot is held while an image is decoded and resized
+var imageSlots = make(chan struct{}, maxConcurrentImages)
+
+/*
+ProcessImage decodes an uploaded image and generates every size of ImageVariants as a JPEG. The
+images are turned upright according to their EXIF orientation, and no metadata is carried over,
+so GPS positions and camera details of the original are dropped. Transparent areas become white.
+
+The dimensions are checked before decoding, images over maxImageSide or maxImagePixels are
+rejected with ErrImageTooLarge. At most maxConcurrentImages images are decoded at once.
+*/
+func ProcessImage(data []byte) (map[string][]byte, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !imageFormats[format] {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if config.Width > maxImageSide || config.Height > maxImageSide || config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	imageSlots <- struct{}{}
+	defer func() { <-imageSlots }()
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	variants := make(map[string][]byte, len(ImageVariants))
+	for _, variant := range ImageVariants {
+		// Sizes fit in a square, so the image can be turned after it was scaled down
+		resized := orientImage(resizeImage(img, variant.MaxSide), orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: imageJPEGQuality}); err != nil {
+			return nil, fmt.Errorf("couldn't encode image: %v", err)
+		}
+		variants[variant.Name] = buf.Bytes()
+	}
+
+	return variants, nil
+}
+
+/*
+StoreImage processes an uploaded image with ProcessImage and stores every size under dir, e.g.
+dir/thumbnail.jpg. Returns the key of the full size, which is what gets stored in the database,
+and its content. Nothing is left in the storage when it fails.
+*/
+func StoreImage(ctx context.Context, store storage.Storage, dir string, data []byte) (string, []byte, error) {
+	variants, err := ProcessImage(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var uploaded []string
+	for _, variant := range ImageVariants {
+		key := path.Join(dir, variant.Name+".jpg")
+		if _, err := store.UploadFile(ctx, key, "image/jpeg", variants[variant.Name]); err != nil {
+			for _, key := range uploaded {
+				if err := store.DeleteFile(ctx, key); err != nil {
+					log.Error().Err(err).Str("key", key).Msg("Failed to delete image variant.")
+				}
+			}
+			return "", nil, err
+		}
+		uploaded = append(uploaded, key)
+	}
+
+	return path.Join(dir, fullImageVariant+".jpg"), variants[fullImageVariant], nil
+}
+
+// DeleteStoredFile deletes a file, with every size when it is an image stored with StoreImage
+func DeleteStoredFile(ctx context.Context, store storage.Storage, key string) error {
+	if !IsStoredImage(key) {
+		return store.DeleteFile(ctx, key)
+	}
+
+	for _, variant := range ImageVariants {
+		if err := store.DeleteFile(ctx, ImageVariantKey(key, variant.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsStoredImage reports if key is the full size of an image stored with StoreImage
+func IsStoredImage(key string) bool {
+	return path.Base(key) == fullImageVariant+".jpg"
+}
+
+/*
+ImageVariantKey returns the key of a size of the image stored under key by StoreImage. Images
+uploaded before they were processed only have one size, their key is returned for every size.
+*/
+func ImageVariantKey(key string, variant string) string {
+	if !IsStoredImage(key) {
+		return key
+	}
+	return path.Join(path.Dir(key), variant+".jpg")
+}
+
+// imageVariantOwner returns the full size key of the image a variant belongs to, "" when key isn't a variant
+func imageVariantOwner(key string) string {
+	for _, variant := range ImageVariants {
+		if path.Base(key) == variant.Name+".jpg" {
+			return path.Join(path.Dir(key), fullImageVariant+".jpg")
+		}
+	}
+	return ""
+}
+
+// resizeImage scales an image down to fit in a maxSide square, onto a white background
+func resizeImage(img image.Image, maxSide int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// orientImage turns an image upright according to its EXIF orientation, 1 to 8
+func orientImage(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = width-1-x, y
+			case 3: // upside down
+				sx, sy = width-1-x, height-1-y
+			case 4: // upside down and mirrored
+				sx, sy = x, height-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // turned left, needs a quarter turn clockwise
+				sx, sy = y, height-1-x
+			case 7: // transversed
+				sx, sy = width-1-y, height-1-x
+			case 8: // turned right, needs a quarter turn counterclockwise
+				sx, sy = width-1-y, x
+			}
+			dst.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation of a JPEG, 1 (upright) when there is none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// The metadata segments are all before the image data
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// exifOrientation reads the orientation tag of the first IFD of EXIF data
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < entries; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
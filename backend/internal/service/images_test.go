@@ -0,0 +1,187 @@
+package service
+
+import (
+	"KonferCA/SPUR/storage"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	// The top left corner is red, to check the orientation
+	for y := 0; y < height/4; y++ {
+		for x := 0; x < width/4; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+// withOrientation adds an EXIF segment with the orientation tag after the start of a JPEG
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+// pngWithSize returns a PNG whose header declares the given dimensions
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+
+	// The IHDR chunk follows the 8 bytes signature: length, type, width, height, ..., crc
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestProcessImage(t *testing.T) {
+	t.Run("generates every size", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, testImage(3000, 1000)))
+
+		variants, err := ProcessImage(buf.Bytes())
+		require.NoError(t, err)
+
+		expected := map[string]image.Point{
+			"thumbnail": {256, 85},
+			"medium":    {1024, 341},
+			"full":      {2560, 853},
+		}
+		for name, size := range expected {
+			img := decodeJPEG(t, variants[name])
+			assert.Equal(t, size, img.Bounds().Size(), name)
+		}
+	})
+
+	t.Run("small images are not enlarged", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, testImage(100, 50)))
+
+		variants, err := ProcessImage(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, image.Point{100, 50}, decodeJPEG(t, variants["full"]).Bounds().Size())
+	})
+
+	t.Run("turns images upright and strips metadata", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, testImage(80, 40), &jpeg.Options{Quality: 95}))
+		original := withOrientation(buf.Bytes(), 6)
+		require.Equal(t, 6, jpegOrientation(original))
+
+		variants, err := ProcessImage(original)
+		require.NoError(t, err)
+
+		full := variants["full"]
+		assert.NotContains(t, string(full), "Exif")
+		assert.Equal(t, 1, jpegOrientation(full))
+
+		// A quarter turn clockwise moves the red top left corner to the top right
+		img := decodeJPEG(t, full)
+		require.Equal(t, image.Point{40, 80}, img.Bounds().Size())
+		r, g, b, _ := img.At(37, 2).RGBA()
+		assert.Greater(t, r>>8, uint32(200))
+		assert.Less(t, g>>8, uint32(60))
+		assert.Less(t, b>>8, uint32(60))
+	})
+
+	t.Run("rejects decompression bombs", func(t *testing.T) {
+		_, err := ProcessImage(pngWithSize(t, 50000, 50000))
+		assert.Equal(t, ErrImageTooLarge, err)
+		_, err = ProcessImage(pngWithSize(t, 8000, 8000))
+		assert.Equal(t, ErrImageTooLarge, err)
+		_, err = ProcessImage(pngWithSize(t, 9000, 6000))
+		assert.Equal(t, ErrImageTooLarge, err)
+	})
+
+	t.Run("processes camera originals", func(t *testing.T) {
+		// 24 megapixels, the size of common camera and phone photos
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 6000, 4000)), nil))
+
+		variants, err := ProcessImage(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, image.Point{2560, 1706}, decodeJPEG(t, variants["full"]).Bounds().Size())
+	})
+
+	t.Run("rejects other files", func(t *testing.T) {
+		_, err := ProcessImage([]byte("%PDF-1.4 not an image"))
+		assert.Equal(t, ErrInvalidImage, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, gif.Encode(&buf, testImage(10, 10), nil))
+		_, err = ProcessImage(buf.Bytes())
+		assert.Equal(t, ErrInvalidImage, err)
+	})
+}
+
+func TestStoreImage(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage("/files/", "secret")
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(600, 300)))
+
+	key, full, err := StoreImage(ctx, store, "users/1/profile-picture/abc", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "users/1/profile-picture/abc/full.jpg", key)
+	assert.True(t, IsStoredImage(key))
+
+	stored, err := store.DownloadFile(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, full, stored)
+
+	for _, variant := range ImageVariants {
+		variantKey := ImageVariantKey(key, variant.Name)
+		assert.Equal(t, "users/1/profile-picture/abc/"+variant.Name+".jpg", variantKey)
+		assert.Equal(t, key, imageVariantOwner(variantKey))
+		_, err := store.HeadFile(ctx, variantKey)
+		assert.NoError(t, err, variant.Name)
+	}
+
+	// Pictures uploaded before processing have a single size
+	assert.Equal(t, "users/1/profile-picture/old.png", ImageVariantKey("users/1/profile-picture/old.png", "thumbnail"))
+
+	require.NoError(t, DeleteStoredFile(ctx, store, key))
+	objects, err := store.ListFiles(ctx, "users/1/")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
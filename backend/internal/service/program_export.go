@@ -0,0 +1,391 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportJobTimeout bounds how long a single program export may run in the background.
+const exportJobTimeout = 10 * time.Minute
+
+// ProgramExportCompany holds the company fields of an exported project.
+type ProgramExportCompany struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	DateFounded int64    `json:"date_founded"`
+	Stages      []string `json:"stages"`
+	Website     string   `json:"website"`
+	LinkedinURL string   `json:"linkedin_url"`
+	OwnerEmail  string   `json:"owner_email"`
+}
+
+// ProgramExportRecord is one exported project with its company, funding structure and answers keyed by question key.
+type ProgramExportRecord struct {
+	ProjectID   string                    `json:"project_id"`
+	Title       string                    `json:"title"`
+	Description string                    `json:"description"`
+	Status      db.ProjectStatus          `json:"status"`
+	CreatedAt   int64                     `json:"created_at"`
+	UpdatedAt   int64                     `json:"updated_at"`
+	SubmittedAt *int64                    `json:"submitted_at"`
+	Company     ProgramExportCompany      `json:"company"`
+	Funding     *db.FundingStructureModel `json:"funding_structure"`
+	Answers     map[string]string         `json:"answers"`
+}
+
+// ProgramExport is the data of every exported project. QuestionKeys holds the answer columns in form order.
+type ProgramExport struct {
+	QuestionKeys []string
+	Records      []ProgramExportRecord
+}
+
+// programExportColumns are the fixed columns of the tabular formats, the answers follow them.
+var programExportColumns = []string{
+	"project_id",
+	"title",
+	"description",
+	"status",
+	"created_at",
+	"updated_at",
+	"submitted_at",
+	"company_id",
+	"company_name",
+	"company_description",
+	"company_date_founded",
+	"company_stages",
+	"company_website",
+	"company_linkedin_url",
+	"owner_email",
+	"funding_type",
+	"funding_amount",
+	"funding_equity_percentage",
+	"funding_min_amount",
+	"funding_max_amount",
+	"funding_tiers",
+	"funding_max_investors",
+}
+
+// answerColumnPrefix marks the answer columns of the tabular formats, question keys such as
+// company_name would otherwise clash with the fixed columns.
+const answerColumnPrefix = "answer:"
+
+/*
+BuildProgramExport collects every project with the given statuses, or all projects when no status is given.
+Only questions with a question key are exported. File and team questions have no answer worth exporting,
+and the funding structure is split into its own columns.
+*/
+func BuildProgramExport(queries *db.Queries, ctx context.Context, statuses []db.ProjectStatus) (ProgramExport, error) {
+	if statuses == nil {
+		statuses = []db.ProjectStatus{}
+	}
+
+	questions, err := queries.ListExportQuestions(ctx)
+	if err != nil {
+		return ProgramExport{}, err
+	}
+
+	export := ProgramExport{}
+	fundingKeys := map[string]bool{}
+	for _, question := range questions {
+		switch question.InputType {
+		case db.InputTypeEnumFundingstructure:
+			fundingKeys[question.QuestionKey] = true
+		case db.InputTypeEnumFile, db.InputTypeEnumTeam:
+		default:
+			export.QuestionKeys = append(export.QuestionKeys, question.QuestionKey)
+		}
+	}
+
+	projects, err := queries.ListProjectsForExport(ctx, statuses)
+	if err != nil {
+		return ProgramExport{}, err
+	}
+
+	answers, err := queries.ListProjectAnswersForExport(ctx, statuses)
+	if err != nil {
+		return ProgramExport{}, err
+	}
+
+	byProject := make(map[string]int, len(projects))
+	export.Records = make([]ProgramExportRecord, len(projects))
+	for i, project := range projects {
+		byProject[project.ID] = i
+		export.Records[i] = ProgramExportRecord{
+			ProjectID:   project.ID,
+			Title:       project.Title,
+			Description: stringValue(project.Description),
+			Status:      project.Status,
+			CreatedAt:   project.CreatedAt,
+			UpdatedAt:   project.UpdatedAt,
+			SubmittedAt: project.OriginalSubmissionAt,
+			Company: ProgramExportCompany{
+				ID:          project.CompanyID,
+				Name:        project.CompanyName,
+				Description: stringValue(project.CompanyDescription),
+				DateFounded: project.CompanyDateFounded,
+				Stages:      project.CompanyStages,
+				Website:     stringValue(project.CompanyWebsite),
+				LinkedinURL: project.CompanyLinkedinUrl,
+				OwnerEmail:  project.OwnerEmail,
+			},
+			Answers: map[string]string{},
+		}
+	}
+
+	for _, answer := range answers {
+		i, ok := byProject[answer.ProjectID]
+		if !ok {
+			continue
+		}
+		record := &export.Records[i]
+
+		if fundingKeys[answer.QuestionKey] {
+			var funding db.FundingStructureModel
+			if answer.Answer != "" && json.Unmarshal([]byte(answer.Answer), &funding) == nil && funding.Type != "" {
+				record.Funding = &funding
+			}
+			continue
+		}
+
+		if len(answer.Choices) > 0 {
+			record.Answers[answer.QuestionKey] = joinExportList(answer.Choices)
+		} else {
+			record.Answers[answer.QuestionKey] = answer.Answer
+		}
+	}
+
+	return export, nil
+}
+
+// Table flattens the export into a header row and one row per project.
+func (export ProgramExport) Table() ([]string, [][]string) {
+	header := append([]string{}, programExportColumns...)
+	for _, key := range export.QuestionKeys {
+		header = append(header, answerColumnPrefix+key)
+	}
+
+	rows := make([][]string, len(export.Records))
+	for i, record := range export.Records {
+		submittedAt := ""
+		if record.SubmittedAt != nil {
+			submittedAt = formatExportTime(*record.SubmittedAt)
+		}
+
+		row := []string{
+			record.ProjectID,
+			record.Title,
+			record.Description,
+			string(record.Status),
+			formatExportTime(record.CreatedAt),
+			formatExportTime(record.UpdatedAt),
+			submittedAt,
+			record.Company.ID,
+			record.Company.Name,
+			record.Company.Description,
+			formatExportTime(record.Company.DateFounded),
+			joinExportList(record.Company.Stages),
+			record.Company.Website,
+			record.Company.LinkedinURL,
+			record.Company.OwnerEmail,
+		}
+		row = append(row, fundingColumns(record.Funding)...)
+		for _, key := range export.QuestionKeys {
+			row = append(row, record.Answers[key])
+		}
+		rows[i] = row
+	}
+
+	return header, rows
+}
+
+func fundingColumns(funding *db.FundingStructureModel) []string {
+	if funding == nil {
+		return make([]string, 7)
+	}
+
+	tiers := make([]string, len(funding.Tiers))
+	for i, tier := range funding.Tiers {
+		tiers[i] = fmt.Sprintf("%s for %s%%", tier.Amount, tier.EquityPercentage)
+	}
+
+	maxInvestors := ""
+	if funding.LimitInvestors && funding.MaxInvestors != nil {
+		maxInvestors = fmt.Sprint(*funding.MaxInvestors)
+	}
+
+	return []string{
+		funding.Type,
+		funding.Amount,
+		funding.EquityPercentage,
+		stringValue(funding.MinAmount),
+		stringValue(funding.MaxAmount),
+		joinExportList(tiers),
+		maxInvestors,
+	}
+}
+
+// joinExportList writes a list into a single cell as a JSON array, so the items may contain any character.
+func joinExportList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(items); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func formatExportTime(timestamp int64) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+}
+
+/*
+escapeSpreadsheetFormula prevents spreadsheet applications from evaluating user provided
+text as a formula when a CSV export is opened.
+*/
+func escapeSpreadsheetFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// EncodeProgramExport encodes the export in the given format.
+func EncodeProgramExport(export ProgramExport, format db.ExportFormat) ([]byte, error) {
+	switch format {
+	case db.ExportFormatJson:
+		records := export.Records
+		if records == nil {
+			records = []ProgramExportRecord{}
+		}
+		return json.MarshalIndent(records, "", "  ")
+
+	case db.ExportFormatCsv:
+		header, rows := export.Table()
+		buf := bytes.Buffer{}
+		w := csv.NewWriter(&buf)
+		if err := w.Write(header); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			for i := range row {
+				row[i] = escapeSpreadsheetFormula(row[i])
+			}
+			if err := w.Write(row); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+
+	case db.ExportFormatXlsx:
+		header, rows := export.Table()
+		file := excelize.NewFile()
+		defer file.Close()
+
+		sheet := "Projects"
+		if err := file.SetSheetName("Sheet1", sheet); err != nil {
+			return nil, err
+		}
+		stream, err := file.NewStreamWriter(sheet)
+		if err != nil {
+			return nil, err
+		}
+		// cells are written as strings, so spreadsheet formulas are never evaluated
+		for i, values := range append([][]string{header}, rows...) {
+			row := make([]interface{}, len(values))
+			for j, value := range values {
+				row[j] = value
+			}
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := stream.SetRow(cell, row); err != nil {
+				return nil, err
+			}
+		}
+		if err := stream.Flush(); err != nil {
+			return nil, err
+		}
+
+		buf, err := file.WriteToBuffer()
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+/*
+RunExportJob builds and stores the file of an export job. It is meant to run in a goroutine,
+the outcome is recorded on the job: completed with the file, or failed with the error.
+*/
+func RunExportJob(queries *db.Queries, job db.CreateExportJobRow) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportJobTimeout)
+	defer cancel()
+
+	logger := log.With().Str("export_job_id", job.ID).Logger()
+
+	fail := func(err error) {
+		logger.Error().Err(err).Msg("Failed to export program data.")
+		message := "The export failed, please try again."
+		if err := queries.FailExportJob(context.Background(), db.FailExportJobParams{ID: job.ID, Error: &message}); err != nil {
+			logger.Error().Err(err).Msg("Failed to mark export job as failed.")
+		}
+	}
+
+	if err := queries.StartExportJob(ctx, job.ID); err != nil {
+		fail(err)
+		return
+	}
+
+	export, err := BuildProgramExport(queries, ctx, job.Statuses)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	content, err := EncodeProgramExport(export, job.Format)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	rowCount := int32(len(export.Records))
+	fileName := fmt.Sprintf("spur-projects-%s.%s", time.Unix(job.CreatedAt, 0).UTC().Format("20060102-150405"), job.Format)
+	err = queries.CompleteExportJob(ctx, db.CompleteExportJobParams{
+		ID:       job.ID,
+		RowCount: &rowCount,
+		FileName: &fileName,
+		Content:  content,
+	})
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	logger.Info().Int32("rows", rowCount).Msg("Program export completed.")
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func testProgramExport() ProgramExport {
+	submittedAt := int64(1700000000)
+	maxInvestors := int32(5)
+	return ProgramExport{
+		QuestionKeys: []string{"company_name", "company_industries"},
+		Records: []ProgramExportRecord{
+			{
+				ProjectID:   "7b7a3a8e-54a5-4f4f-9f0b-3f1e6f0f2b11",
+				Title:       "Sunbeam",
+				Status:      db.ProjectStatusPending,
+				CreatedAt:   1690000000,
+				SubmittedAt: &submittedAt,
+				Company: ProgramExportCompany{
+					Name:   "=HYPERLINK(\"http://evil\")",
+					Stages: []string{"seed", "growth"},
+				},
+				Funding: &db.FundingStructureModel{
+					Type:             "tiered",
+					Tiers:            []db.FundingTier{{Amount: "1000", EquityPercentage: "1"}, {Amount: "5000", EquityPercentage: "4"}},
+					LimitInvestors:   true,
+					MaxInvestors:     &maxInvestors,
+					EquityPercentage: "",
+				},
+				Answers: map[string]string{"company_name": "Sunbeam", "company_industries": `["Energy","Food; Farming"]`},
+			},
+			{
+				ProjectID: "0b4c5b53-3c0e-4d3f-8f3e-1f9a7f3a2c22",
+				Title:     "Harvest",
+				Status:    db.ProjectStatusDraft,
+				Answers:   map[string]string{},
+			},
+		},
+	}
+}
+
+func TestProgramExportTable(t *testing.T) {
+	header, rows := testProgramExport().Table()
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, append(append([]string{}, programExportColumns...), "answer:company_name", "answer:company_industries"), header)
+	for _, row := range rows {
+		assert.Len(t, row, len(header))
+	}
+
+	column := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("missing column %s", name)
+		return -1
+	}
+
+	assert.Equal(t, "2023-11-14T22:13:20Z", rows[0][column("submitted_at")])
+	assert.Equal(t, `["seed","growth"]`, rows[0][column("company_stages")])
+	assert.Equal(t, "tiered", rows[0][column("funding_type")])
+	assert.Equal(t, `["1000 for 1%","5000 for 4%"]`, rows[0][column("funding_tiers")])
+	assert.Equal(t, "5", rows[0][column("funding_max_investors")])
+	assert.Equal(t, `["Energy","Food; Farming"]`, rows[0][column("answer:company_industries")])
+
+	assert.Equal(t, "", rows[1][column("submitted_at")])
+	assert.Equal(t, "", rows[1][column("funding_type")])
+	assert.Equal(t, "", rows[1][column("company_name")])
+	assert.Equal(t, "", rows[1][column("answer:company_name")])
+}
+
+func TestEncodeProgramExport(t *testing.T) {
+	export := testProgramExport()
+
+	t.Run("csv", func(t *testing.T) {
+		content, err := EncodeProgramExport(export, db.ExportFormatCsv)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		// formulas are escaped so they are shown as text
+		assert.Contains(t, records[1], "'=HYPERLINK(\"http://evil\")")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		content, err := EncodeProgramExport(export, db.ExportFormatJson)
+		require.NoError(t, err)
+
+		var records []map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &records))
+		require.Len(t, records, 2)
+		assert.Equal(t, "Sunbeam", records[0]["answers"].(map[string]interface{})["company_name"])
+		assert.Equal(t, "tiered", records[0]["funding_structure"].(map[string]interface{})["type"])
+		assert.Nil(t, records[1]["funding_structure"])
+	})
+
+	t.Run("empty json", func(t *testing.T) {
+		content, err := EncodeProgramExport(ProgramExport{}, db.ExportFormatJson)
+		require.NoError(t, err)
+		assert.Equal(t, "[]", string(content))
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		content, err := EncodeProgramExport(export, db.ExportFormatXlsx)
+		require.NoError(t, err)
+
+		file, err := excelize.OpenReader(bytes.NewReader(content))
+		require.NoError(t, err)
+		defer file.Close()
+
+		rows, err := file.GetRows("Projects")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "project_id", rows[0][0])
+		assert.Equal(t, "Sunbeam", rows[1][1])
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := EncodeProgramExport(export, db.ExportFormat("pdf"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// importIgnoredColumns are export columns that are generated on import, so they are accepted but not read.
+var importIgnoredColumns = map[string]bool{
+	"project_id":   true,
+	"status":       true,
+	"created_at":   true,
+	"updated_at":   true,
+	"submitted_at": true,
+	"company_id":   true,
+}
+
+/*
+ParseProgramImport reads applications from a file laid out like a program export, so an export
+can be edited and imported again. Answer columns are named after the question key, with or without
+the answer: prefix of the export.
+*/
+func ParseProgramImport(content []byte, format db.ExportFormat) ([]ProgramExportRecord, error) {
+	switch format {
+	case db.ExportFormatJson:
+		var records []ProgramExportRecord
+		if err := json.Unmarshal(content, &records); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		for i := range records {
+			if records[i].Answers == nil {
+				records[i].Answers = map[string]string{}
+			}
+		}
+		return records, nil
+
+	case db.ExportFormatCsv:
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		return parseImportTable(rows, true)
+
+	case db.ExportFormatXlsx:
+		file, err := excelize.OpenReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid spreadsheet: %w", err)
+		}
+		defer file.Close()
+
+		rows, err := file.GetRows(file.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("invalid spreadsheet: %w", err)
+		}
+		return parseImportTable(rows, false)
+	}
+
+	return nil, fmt.Errorf("unsupported import format: %s", format)
+}
+
+// parseImportTable is the reverse of ProgramExport.Table, the first row holds the column names.
+func parseImportTable(rows [][]string, escaped bool) ([]ProgramExportRecord, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file has no header row")
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, column := range rows[0] {
+		header[i] = strings.TrimSpace(column)
+	}
+
+	records := make([]ProgramExportRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		values := map[string]string{}
+		empty := true
+		for j, column := range header {
+			if column == "" || j >= len(row) {
+				continue
+			}
+			// the escape is the first character of the cell, it must be removed before trimming
+			value := row[j]
+			if escaped {
+				value = unescapeSpreadsheetFormula(value)
+			}
+			value = strings.TrimSpace(value)
+			if value != "" {
+				empty = false
+			}
+			values[column] = value
+		}
+		// spreadsheets often keep trailing blank rows
+		if empty {
+			continue
+		}
+
+		record, err := importRecordFromColumns(values)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func importRecordFromColumns(values map[string]string) (ProgramExportRecord, error) {
+	record := ProgramExportRecord{
+		Title:       values["title"],
+		Description: values["description"],
+		Company: ProgramExportCompany{
+			Name:        values["company_name"],
+			Description: values["company_description"],
+			Stages:      SplitImportList(values["company_stages"]),
+			Website:     values["company_website"],
+			LinkedinURL: values["company_linkedin_url"],
+			OwnerEmail:  values["owner_email"],
+		},
+		Answers: map[string]string{},
+	}
+
+	if founded := values["company_date_founded"]; founded != "" {
+		t, err := time.Parse(time.RFC3339, founded)
+		if err != nil {
+			return record, fmt.Errorf("company_date_founded must be an RFC 3339 date")
+		}
+		record.Company.DateFounded = t.Unix()
+	}
+
+	funding, err := importFundingFromColumns(values)
+	if err != nil {
+		return record, err
+	}
+	record.Funding = funding
+
+	fixed := map[string]bool{}
+	for _, column := range programExportColumns {
+		fixed[column] = true
+	}
+	for column, value := range values {
+		if value == "" {
+			continue
+		}
+		if key, ok := strings.CutPrefix(column, answerColumnPrefix); ok {
+			record.Answers[key] = value
+			continue
+		}
+		// hand written files may use bare question keys for answers
+		if fixed[column] || importIgnoredColumns[column] {
+			continue
+		}
+		if _, ok := record.Answers[column]; !ok {
+			record.Answers[column] = value
+		}
+	}
+
+	return record, nil
+}
+
+// importFundingFromColumns is the reverse of fundingColumns.
+func importFundingFromColumns(values map[string]string) (*db.FundingStructureModel, error) {
+	if values["funding_type"] == "" {
+		return nil, nil
+	}
+
+	funding := db.FundingStructureModel{
+		Type:             values["funding_type"],
+		Amount:           values["funding_amount"],
+		EquityPercentage: values["funding_equity_percentage"],
+	}
+	if value := values["funding_min_amount"]; value != "" {
+		funding.MinAmount = &value
+	}
+	if value := values["funding_max_amount"]; value != "" {
+		funding.MaxAmount = &value
+	}
+	for _, tier := range SplitImportList(values["funding_tiers"]) {
+		amount, equity, ok := strings.Cut(tier, " for ")
+		if !ok {
+			return nil, fmt.Errorf("funding tier %q must look like \"1000 for 5%%\"", tier)
+		}
+		funding.Tiers = append(funding.Tiers, db.FundingTier{
+			Amount:           strings.TrimSpace(amount),
+			EquityPercentage: strings.TrimSuffix(strings.TrimSpace(equity), "%"),
+		})
+	}
+	if value := values["funding_max_investors"]; value != "" {
+		maxInvestors, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("funding_max_investors must be a number")
+		}
+		n := int32(maxInvestors)
+		funding.LimitInvestors = true
+		funding.MaxInvestors = &n
+	}
+
+	return &funding, nil
+}
+
+/*
+SplitImportList is the reverse of joinExportList, it reads a JSON array of strings. Any other value is
+a list of one item, as typed by hand in a spreadsheet.
+*/
+func SplitImportList(value string) []string {
+	var items []string
+	if value = strings.TrimSpace(value); !strings.HasPrefix(value, "[") || json.Unmarshal([]byte(value), &items) != nil {
+		items = []string{value}
+	}
+
+	list := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// unescapeSpreadsheetFormula is the reverse of escapeSpreadsheetFormula.
+func unescapeSpreadsheetFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
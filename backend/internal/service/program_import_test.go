@@ -0,0 +1,97 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgramImportRoundTrip(t *testing.T) {
+	export := testProgramExport()
+	export.Records[0].Company.OwnerEmail = "founder@sunbeam.dev"
+	export.Records[0].Company.DateFounded = 1600000000
+
+	for _, format := range []db.ExportFormat{db.ExportFormatCsv, db.ExportFormatJson, db.ExportFormatXlsx} {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := EncodeProgramExport(export, format)
+			require.NoError(t, err)
+
+			records, err := ParseProgramImport(content, format)
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+
+			record := records[0]
+			assert.Equal(t, "Sunbeam", record.Title)
+			assert.Equal(t, "founder@sunbeam.dev", record.Company.OwnerEmail)
+			assert.Equal(t, "=HYPERLINK(\"http://evil\")", record.Company.Name)
+			assert.Equal(t, []string{"seed", "growth"}, record.Company.Stages)
+			assert.Equal(t, int64(1600000000), record.Company.DateFounded)
+			assert.Equal(t, []string{"Energy", "Food; Farming"}, SplitImportList(record.Answers["company_industries"]))
+
+			require.NotNil(t, record.Funding)
+			assert.Equal(t, "tiered", record.Funding.Type)
+			require.Len(t, record.Funding.Tiers, 2)
+			assert.Equal(t, "5000", record.Funding.Tiers[1].Amount)
+			assert.Equal(t, "4", record.Funding.Tiers[1].EquityPercentage)
+			require.NotNil(t, record.Funding.MaxInvestors)
+			assert.Equal(t, int32(5), *record.Funding.MaxInvestors)
+
+			assert.Nil(t, records[1].Funding)
+			assert.Empty(t, records[1].Answers)
+		})
+	}
+}
+
+func TestParseProgramImportCSV(t *testing.T) {
+	t.Run("answers columns and blank rows", func(t *testing.T) {
+		content := "owner_email,company_name,status,company_industries\n" +
+			"a@b.dev,Acme,pending,Energy\n" +
+			",,,\n"
+		records, err := ParseProgramImport([]byte(content), db.ExportFormatCsv)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "a@b.dev", records[0].Company.OwnerEmail)
+		// status is generated on import
+		assert.NotContains(t, records[0].Answers, "status")
+		assert.Equal(t, "Energy", records[0].Answers["company_industries"])
+	})
+
+	t.Run("escaped cells are trimmed once unescaped", func(t *testing.T) {
+		content := "owner_email,company_name\na@b.dev,\"'\t Acme \"\n"
+		records, err := ParseProgramImport([]byte(content), db.ExportFormatCsv)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "Acme", records[0].Company.Name)
+	})
+
+	t.Run("lists", func(t *testing.T) {
+		content := "owner_email,company_stages,funding_type,funding_tiers\n" +
+			"a@b.dev,seed,tiered,\"[\"\"1000 for 1%\"\", \"\"5000 for 4%\"\"]\"\n"
+		records, err := ParseProgramImport([]byte(content), db.ExportFormatCsv)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		// a single item may be written as is
+		assert.Equal(t, []string{"seed"}, records[0].Company.Stages)
+		require.NotNil(t, records[0].Funding)
+		assert.Len(t, records[0].Funding.Tiers, 2)
+	})
+
+	t.Run("invalid tier", func(t *testing.T) {
+		content := "owner_email,funding_type,funding_tiers\na@b.dev,tiered,lots\n"
+		_, err := ParseProgramImport([]byte(content), db.ExportFormatCsv)
+		assert.ErrorContains(t, err, "row 1")
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		content := "owner_email,company_date_founded\na@b.dev,yesterday\n"
+		_, err := ParseProgramImport([]byte(content), db.ExportFormatCsv)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		_, err := ParseProgramImport([]byte(""), db.ExportFormatCsv)
+		assert.Error(t, err)
+	})
+}
@@ -30,7 +30,7 @@ func CreateProjectSnapshot(queries *db.Queries, ctx context.Context, projectID s
 		pgUUID.Valid = true
 	}
 
-	return queries.CreateProjectSnapshot(
+	err = queries.CreateProjectSnapshot(
 		ctx,
 		db.CreateProjectSnapshotParams{
 			ID:               projectID,
@@ -38,16 +38,28 @@ func CreateProjectSnapshot(queries *db.Queries, ctx context.Context, projectID s
 			ParentSnapshotID: pgUUID,
 		},
 	)
+	if err != nil {
+		return err
+	}
+
+	// Record the document versions the snapshot was submitted with
+	snapshot, err := queries.GetLatestProjectSnapshot(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	return queries.AddProjectSnapshotDocuments(ctx, db.AddProjectSnapshotDocumentsParams{
+		SnapshotID: snapshot.ID,
+		ProjectID:  projectID,
+	})
 }
 
 // SubmitProject sets the status of a project as "pending", updates the project's title according to company_name question and
-// creates a snapshot for the project.
-func SubmitProject(queries *db.Queries, ctx context.Context, projectID string) error {
+// creates a snapshot for the project. The status change and the new snapshot are recorded in the activity timeline
+// with the submitting user as actor.
+func SubmitProject(queries *db.Queries, ctx context.Context, projectID string, actorID string) error {
 	// Update project status to pending
-	err := queries.UpdateProjectStatus(ctx, db.UpdateProjectStatusParams{
-		ID:     projectID,
-		Status: db.ProjectStatusPending,
-	})
+	err := UpdateProjectStatus(queries, ctx, projectID, actorID, db.ProjectStatusPending)
 	if err != nil {
 		return err
 	}
@@ -70,6 +82,14 @@ func SubmitProject(queries *db.Queries, ctx context.Context, projectID string) e
 		return err
 	}
 
+	err = RecordProjectActivity(queries, ctx, projectID, actorID, db.ProjectActivityTypeSnapshotCreated, false, ActivityPayload{
+		"snapshot_id":    snapshot.ID,
+		"version_number": snapshot.VersionNumber,
+	})
+	if err != nil {
+		return err
+	}
+
 	// Reset allow edit to false
 	err = queries.SetProjectAllowEdit(ctx, db.SetProjectAllowEditParams{ID: projectID, AllowEdit: false})
 	if err != nil {
@@ -104,13 +124,33 @@ func GetLatestProjectSnapshot(queries *db.Queries, ctx context.Context, projectI
 
 // CreateProjectComment creates a new project comment, sets the 'allow_edit' flag to 'true', and update project status to 'needs review'.
 // The affect project row is one matching the project id in the comment parameters.
+//
+// Internal comments are reviewer-only notes, so they never unlock the project for editing nor change its status.
+// The comment is recorded in the activity timeline, internal comments as internal activities.
 func CreateProjectComment(queries *db.Queries, ctx context.Context, commentParams db.CreateProjectCommentParams) (db.ProjectComment, error) {
+	if commentParams.Visibility == "" {
+		commentParams.Visibility = db.CommentVisibilityEnumPublic
+	}
+
 	// Create new comment
 	comment, err := queries.CreateProjectComment(ctx, commentParams)
 	if err != nil {
 		return db.ProjectComment{}, err
 	}
 
+	err = RecordProjectActivity(queries, ctx, comment.ProjectID, comment.CommenterID, db.ProjectActivityTypeCommentCreated,
+		comment.Visibility == db.CommentVisibilityEnumInternal, ActivityPayload{
+			"comment_id": comment.ID,
+			"target_id":  comment.TargetID,
+		})
+	if err != nil {
+		return db.ProjectComment{}, err
+	}
+
+	if comment.Visibility == db.CommentVisibilityEnumInternal {
+		return comment, nil
+	}
+
 	// Set 'allow_flag' to true
 	err = queries.SetProjectAllowEdit(ctx, db.SetProjectAllowEditParams{
 		AllowEdit: true,
@@ -121,10 +161,7 @@ func CreateProjectComment(queries *db.Queries, ctx context.Context, commentParam
 	}
 
 	// Set project status to 'needs review'
-	err = queries.UpdateProjectStatus(ctx, db.UpdateProjectStatusParams{
-		ID:     commentParams.ProjectID,
-		Status: db.ProjectStatusNeedsreview,
-	})
+	err = UpdateProjectStatus(queries, ctx, commentParams.ProjectID, commentParams.CommenterID, db.ProjectStatusNeedsreview)
 	if err != nil {
 		return db.ProjectComment{}, err
 	}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/scanner"
+	"KonferCA/SPUR/storage"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// scanTimeout bounds the scan of one document, including reading it back from storage on a rescan
+	scanTimeout = 5 * time.Minute
+	// scanRetryInterval is how often the documents whose scan failed are scanned again
+	scanRetryInterval = 10 * time.Minute
+	// maxScanAttempts bounds the failed scans of one file, the document then stays pending for an admin to look at
+	maxScanAttempts = 5
+)
+
+/*
+ScanProjectDocument scans the current file of a document and records the outcome. It is meant to
+run in a goroutine after the upload is saved, the document stays pending and hidden from everyone
+but the founder until the file is found clean.
+
+Infected files are deleted from storage together with their version, and every admin is notified.
+When the scan itself fails the document stays pending, it is scanned again by StartScanRetrier.
+The outcome is ignored when the document was moved to another file in the meantime, that file has
+its own scan.
+*/
+func ScanProjectDocument(queries *db.Queries, store storage.Storage, fileScanner scanner.Scanner, doc db.ProjectDocument, content []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+	defer cancel()
+
+	logger := log.With().Str("document_id", doc.ID).Str("project_id", doc.ProjectID).Logger()
+
+	result, err := fileScanner.Scan(ctx, content)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to scan document.")
+		recordFailedScan(queries, doc)
+		return
+	}
+
+	if !result.Infected {
+		if _, err := queries.SetDocumentScanResult(ctx, db.SetDocumentScanResultParams{
+			ScanStatus: db.ScanStatusClean,
+			ID:         doc.ID,
+			StorageKey: doc.StorageKey,
+		}); err != nil {
+			logger.Error().Err(err).Msg("Failed to mark document as clean.")
+		}
+		return
+	}
+
+	logger.Warn().Str("signature", result.Signature).Msg("Malware found in uploaded document.")
+
+	updated, err := queries.SetDocumentScanResult(ctx, db.SetDocumentScanResultParams{
+		ScanStatus:    db.ScanStatusInfected,
+		ScanSignature: &result.Signature,
+		ID:            doc.ID,
+		StorageKey:    doc.StorageKey,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to mark document as infected.")
+		return
+	}
+
+	if err := store.DeleteFile(ctx, doc.StorageKey); err != nil {
+		logger.Error().Err(err).Msg("Failed to delete infected document.")
+	}
+
+	// The version is removed so the history never points to the deleted file
+	if err := queries.DeleteProjectDocumentVersionByKey(ctx, db.DeleteProjectDocumentVersionByKeyParams{
+		DocumentID: doc.ID,
+		StorageKey: doc.StorageKey,
+	}); err != nil {
+		logger.Error().Err(err).Msg("Failed to delete infected document version.")
+	}
+
+	if updated == 0 {
+		return
+	}
+
+	admins, err := queries.ListUserIDsWithPermission(ctx, int32(permissions.PermIsAdmin))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get admins to notify.")
+		return
+	}
+
+	message := fmt.Sprintf("Malware (%s) was found in the document \"%s\", the file was deleted.", result.Signature, doc.Name)
+	for _, adminID := range admins {
+		if err := NotifyUser(queries, ctx, adminID, db.NotificationTypeDocumentInfected, doc.ProjectID, message); err != nil {
+			logger.Error().Err(err).Str("user_id", adminID).Msg("Failed to notify admin of infected document.")
+		}
+	}
+}
+
+/*
+ScanStoredProjectDocument reads the current file of a document back from storage and scans it,
+for files that never went through the server, e.g. direct uploads. See ScanProjectDocument.
+*/
+func ScanStoredProjectDocument(queries *db.Queries, store storage.Storage, fileScanner scanner.Scanner, doc db.ProjectDocument) {
+	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+	content, err := store.DownloadFile(ctx, doc.StorageKey)
+	cancel()
+	if err != nil {
+		log.Error().Err(err).Str("document_id", doc.ID).Msg("Failed to download document to scan.")
+		recordFailedScan(queries, doc)
+		return
+	}
+
+	ScanProjectDocument(queries, store, fileScanner, doc, content)
+}
+
+// recordFailedScan counts a failed scan of the current file of a document, see maxScanAttempts.
+func recordFailedScan(queries *db.Queries, doc db.ProjectDocument) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attempts, err := queries.RecordFailedDocumentScan(ctx, db.RecordFailedDocumentScanParams{
+		DocumentID: doc.ID,
+		StorageKey: doc.StorageKey,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("document_id", doc.ID).Msg("Failed to record failed document scan.")
+		return
+	}
+	if attempts >= maxScanAttempts {
+		log.Error().Str("document_id", doc.ID).Str("project_id", doc.ProjectID).Int32("attempts", attempts).
+			Msg("Giving up scanning document, it stays pending.")
+	}
+}
+
+/*
+StartScanRetrier runs RescanPendingDocuments every scanRetryInterval until ctx is done.
+Documents changed within scanTimeout are left out, their first scan may still be running.
+It is meant to run in a goroutine.
+*/
+func StartScanRetrier(ctx context.Context, queries *db.Queries, store storage.Storage, fileScanner scanner.Scanner) {
+	ticker := time.NewTicker(scanRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RescanPendingDocuments(queries, store, fileScanner, scanTimeout)
+		}
+	}
+}
+
+/*
+RescanPendingDocuments scans the documents that have been pending for at least pendingFor, e.g. because
+the server stopped before the scanner answered or the scan failed. The files are read back from storage.
+Files that failed maxScanAttempts scans are skipped.
+*/
+func RescanPendingDocuments(queries *db.Queries, store storage.Storage, fileScanner scanner.Scanner, pendingFor time.Duration) {
+	docs, err := queries.ListPendingDocumentScans(context.Background(), db.ListPendingDocumentScansParams{
+		UpdatedBefore: time.Now().Add(-pendingFor).Unix(),
+		MaxAttempts:   maxScanAttempts,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get documents waiting for a scan.")
+		return
+	}
+
+	for _, doc := range docs {
+		ScanStoredProjectDocument(queries, store, fileScanner, doc)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/storage"
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// storageReconcileInterval is how often the storage is compared with the database
+	storageReconcileInterval = 24 * time.Hour
+	// defaultOrphanGracePeriod is how old an unreferenced file must be before it is deleted. A file
+	// is uploaded before the row referencing it is committed, younger files may still get one.
+	defaultOrphanGracePeriod = 24 * time.Hour
+)
+
+// StoragePrefixes are the key prefixes of the files owned by the database
+var StoragePrefixes = []string{
+	"projects/",
+	"member/",
+	"users/",
+	"conversations/",
+}
+
+// StorageReconcileOptions configures ReconcileStorage
+type StorageReconcileOptions struct {
+	// DryRun only reports, nothing is deleted
+	DryRun bool
+	// GracePeriod is how old an orphan must be to be deleted
+	GracePeriod time.Duration
+}
+
+// StorageReconcileReport is the result of ReconcileStorage
+type StorageReconcileReport struct {
+	// Files is the number of files found under StoragePrefixes
+	Files int
+	// Orphans are the files no row references
+	Orphans []storage.ObjectInfo
+	// Dangling are the rows referencing a file that doesn't exist
+	Dangling []db.ListStorageReferencesRow
+	// Deleted is the number of orphans deleted
+	Deleted int
+}
+
+/*
+StorageReconcileOptionsFromEnv reads the options of the scheduled reconciliation:
+
+STORAGE_GC_DRY_RUN STORAGE_GC_GRACE_PERIOD
+
+The orphans are deleted unless STORAGE_GC_DRY_RUN is "true". The grace period is a duration
+like "72h", defaultOrphanGracePeriod when not set or invalid.
+*/
+func StorageReconcileOptionsFromEnv() StorageReconcileOptions {
+	options := StorageReconcileOptions{
+		DryRun:      os.Getenv("STORAGE_GC_DRY_RUN") == "true",
+		GracePeriod: defaultOrphanGracePeriod,
+	}
+
+	if value := os.Getenv("STORAGE_GC_GRACE_PERIOD"); value != "" {
+		gracePeriod, err := time.ParseDuration(value)
+		if err != nil || gracePeriod < 0 {
+			log.Error().Err(err).Str("value", value).Msg("Invalid STORAGE_GC_GRACE_PERIOD, using the default.")
+		} else {
+			options.GracePeriod = gracePeriod
+		}
+	}
+
+	return options
+}
+
+/*
+StartStorageReconciler runs ReconcileStorage every storageReconcileInterval until ctx is done.
+It is meant to run in a goroutine.
+*/
+func StartStorageReconciler(ctx context.Context, queries *db.Queries, store storage.Storage, options StorageReconcileOptions) {
+	ticker := time.NewTicker(storageReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reconcileCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+			if _, err := ReconcileStorage(reconcileCtx, queries, store, options); err != nil {
+				log.Error().Err(err).Msg("Failed to reconcile storage.")
+			}
+			cancel()
+		}
+	}
+}
+
+/*
+ReconcileStorage compares the files under StoragePrefixes with every key stored in the database.
+Files no row references are orphans, left behind by failed writes and deleted rows, and are
+deleted once older than the grace period. Rows referencing a missing file are only reported,
+they need a person to decide what to do.
+
+The references are read before the files are listed, so a file uploaded in between is at worst
+seen as a young orphan, which the grace period keeps.
+*/
+func ReconcileStorage(ctx context.Context, queries *db.Queries, store storage.Storage, options StorageReconcileOptions) (StorageReconcileReport, error) {
+	references, err := queries.ListStorageReferences(ctx)
+	if err != nil {
+		return StorageReconcileReport{}, err
+	}
+
+	var files []storage.ObjectInfo
+	for _, prefix := range StoragePrefixes {
+		objects, err := store.ListFiles(ctx, prefix)
+		if err != nil {
+			return StorageReconcileReport{}, err
+		}
+		files = append(files, objects...)
+	}
+
+	report := compareStorage(files, references)
+
+	for _, reference := range report.Dangling {
+		log.Warn().
+			Str("source", reference.Source).
+			Str("record_id", reference.RecordID).
+			Str("key", reference.StorageKey).
+			Msg("Database references a missing file.")
+	}
+
+	deleteBefore := time.Now().Add(-options.GracePeriod)
+	for _, orphan := range report.Orphans {
+		logger := log.With().Str("key", orphan.Key).Int64("size", orphan.Size).Time("last_modified", orphan.LastModified).Logger()
+		if options.DryRun || orphan.LastModified.After(deleteBefore) {
+			logger.Info().Msg("Found orphaned file.")
+			continue
+		}
+		if err := store.DeleteFile(ctx, orphan.Key); err != nil {
+			logger.Error().Err(err).Msg("Failed to delete orphaned file.")
+			continue
+		}
+		logger.Info().Msg("Deleted orphaned file.")
+		report.Deleted++
+	}
+
+	log.Info().
+		Bool("dry_run", options.DryRun).
+		Int("files", report.Files).
+		Int("orphans", len(report.Orphans)).
+		Int("dangling", len(report.Dangling)).
+		Int("deleted", report.Deleted).
+		Msg("Reconciled storage.")
+
+	return report, nil
+}
+
+// compareStorage finds the orphans and dangling references, both sorted by key
+func compareStorage(files []storage.ObjectInfo, references []db.ListStorageReferencesRow) StorageReconcileReport {
+	report := StorageReconcileReport{Files: len(files)}
+
+	existing := make(map[string]bool, len(files))
+	for _, file := range files {
+		existing[file.Key] = true
+	}
+	referenced := make(map[string]bool, len(references))
+	for _, reference := range references {
+		referenced[reference.StorageKey] = true
+		if !existing[reference.StorageKey] {
+			report.Dangling = append(report.Dangling, reference)
+		}
+	}
+
+	for _, file := range files {
+		// The sizes of an image are referenced through the key of its full size
+		if referenced[file.Key] || referenced[imageVariantOwner(file.Key)] {
+			continue
+		}
+		report.Orphans = append(report.Orphans, file)
+	}
+
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		return report.Orphans[i].Key < report.Orphans[j].Key
+	})
+	sort.Slice(report.Dangling, func(i, j int) bool {
+		if report.Dangling[i].StorageKey != report.Dangling[j].StorageKey {
+			return report.Dangling[i].StorageKey < report.Dangling[j].StorageKey
+		}
+		return report.Dangling[i].Source < report.Dangling[j].Source
+	})
+
+	return report
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareStorage(t *testing.T) {
+	now := time.Now()
+	files := []storage.ObjectInfo{
+		{Key: "projects/1/documents/deck.pdf", LastModified: now},
+		{Key: "users/1/profile-picture/old.png", LastModified: now},
+		{Key: "member/1/documents/resume/cv.pdf", LastModified: now},
+		{Key: "users/1/profile-picture/2/full.jpg", LastModified: now},
+		{Key: "users/1/profile-picture/2/thumbnail.jpg", LastModified: now},
+	}
+	references := []db.ListStorageReferencesRow{
+		{Source: "project_documents.storage_key", RecordID: "doc", StorageKey: "projects/1/documents/deck.pdf"},
+		{Source: "project_document_versions.storage_key", RecordID: "v1", StorageKey: "projects/1/documents/deck.pdf"},
+		{Source: "users.profile_picture_key", RecordID: "1", StorageKey: "users/1/profile-picture/new.png"},
+		{Source: "users.profile_picture_key", RecordID: "2", StorageKey: "users/1/profile-picture/2/full.jpg"},
+		{Source: "message_attachments.storage_key", RecordID: "a", StorageKey: "conversations/1/attachments/lost.pdf"},
+	}
+
+	report := compareStorage(files, references)
+
+	assert.Equal(t, 5, report.Files)
+	if assert.Len(t, report.Orphans, 2) {
+		assert.Equal(t, "member/1/documents/resume/cv.pdf", report.Orphans[0].Key)
+		assert.Equal(t, "users/1/profile-picture/old.png", report.Orphans[1].Key)
+	}
+	if assert.Len(t, report.Dangling, 2) {
+		assert.Equal(t, "conversations/1/attachments/lost.pdf", report.Dangling[0].StorageKey)
+		assert.Equal(t, "users/1/profile-picture/new.png", report.Dangling[1].StorageKey)
+	}
+}
+
+func TestStorageReconcileOptionsFromEnv(t *testing.T) {
+	t.Setenv("STORAGE_GC_DRY_RUN", "true")
+	t.Setenv("STORAGE_GC_GRACE_PERIOD", "72h")
+	options := StorageReconcileOptionsFromEnv()
+	assert.True(t, options.DryRun)
+	assert.Equal(t, 72*time.Hour, options.GracePeriod)
+
+	t.Setenv("STORAGE_GC_DRY_RUN", "")
+	t.Setenv("STORAGE_GC_GRACE_PERIOD", "soon")
+	options = StorageReconcileOptionsFromEnv()
+	assert.False(t, options.DryRun)
+	assert.Equal(t, defaultOrphanGracePeriod, options.GracePeriod)
+}
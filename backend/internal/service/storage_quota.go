@@ -0,0 +1,284 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultCompanyStorageQuota is the storage quota of companies without one set by an admin
+	defaultCompanyStorageQuota = 500 << 20
+	// defaultProjectStorageQuota is the storage quota of every project, 0 means projects are only
+	// limited by the quota of their company
+	defaultProjectStorageQuota = 0
+)
+
+// ErrStorageQuotaExceeded is returned by CheckStorageQuota when a file doesn't fit in the quota
+var ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// StorageQuotas are the default quotas in bytes, 0 means unlimited
+type StorageQuotas struct {
+	Company int64
+	Project int64
+}
+
+// StorageUsage is the storage used by a company or a project, in bytes
+type StorageUsage struct {
+	// Documents are the current files of the project documents, without images
+	Documents int64
+	// Images are the featured images and other image documents
+	Images int64
+	// Versions are the files of previous versions and of deleted documents, the history is never removed
+	Versions int64
+	// Watermarks are the copies of the documents stamped for the investors who downloaded them.
+	// They don't count towards the quota: investors create them, and they are stamped again when deleted
+	Watermarks int64
+	// TeamDocuments are the resumes and founders agreements of the team members, always 0 for a project
+	TeamDocuments int64
+	// PendingUploads are the direct uploads in progress, their size is reserved until they complete or expire
+	PendingUploads int64
+	// Quota is 0 when there is no limit
+	Quota int64
+}
+
+// Used is the total storage charged to the quota, including the pending uploads
+func (u StorageUsage) Used() int64 {
+	return u.Documents + u.Images + u.Versions + u.TeamDocuments + u.PendingUploads
+}
+
+// Fits reports if size more bytes fit in the quota
+func (u StorageUsage) Fits(size int64) bool {
+	return u.Quota == 0 || u.Used()+size <= u.Quota
+}
+
+// ProjectStorageUsage is the storage used by one project of a company
+type ProjectStorageUsage struct {
+	ProjectID string
+	Title     string
+	StorageUsage
+}
+
+// CompanyStorageUsage is the storage used by a company, with the usage of each of its projects
+type CompanyStorageUsage struct {
+	CompanyID   string
+	CompanyName string
+	StorageUsage
+	// CustomQuota is set when an admin changed the quota of the company
+	CustomQuota bool
+	Projects    []ProjectStorageUsage
+}
+
+// StorageQuotaError describes the quota a file didn't fit in, it wraps ErrStorageQuotaExceeded
+type StorageQuotaError struct {
+	// Scope is "company" or "project"
+	Scope string
+	Usage StorageUsage
+	Size  int64
+}
+
+func (e *StorageQuotaError) Error() string {
+	return fmt.Sprintf("the %s storage quota of %s is exceeded: %s used, the file needs %s",
+		e.Scope, FormatByteSize(e.Usage.Quota), FormatByteSize(e.Usage.Used()), FormatByteSize(e.Size))
+}
+
+func (e *StorageQuotaError) Unwrap() error {
+	return ErrStorageQuotaExceeded
+}
+
+/*
+StorageQuotasFromEnv reads the default quotas:
+
+STORAGE_QUOTA_COMPANY STORAGE_QUOTA_PROJECT
+
+The quotas are sizes like "500MB" or "2GB", or a number of bytes, "0" disables the quota.
+Invalid or missing values fall back to defaultCompanyStorageQuota and defaultProjectStorageQuota.
+*/
+func StorageQuotasFromEnv() StorageQuotas {
+	return StorageQuotas{
+		Company: byteSizeFromEnv("STORAGE_QUOTA_COMPANY", defaultCompanyStorageQuota),
+		Project: byteSizeFromEnv("STORAGE_QUOTA_PROJECT", defaultProjectStorageQuota),
+	}
+}
+
+func byteSizeFromEnv(name string, fallback int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	size, err := ParseByteSize(value)
+	if err != nil {
+		log.Error().Err(err).Str("value", value).Msgf("Invalid %s, using the default.", name)
+		return fallback
+	}
+	return size
+}
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size like "500MB", "1.5GB" or "1024", units are powers of 1024
+func ParseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// FormatByteSize formats a size in the largest unit it has at least one of, e.g. "1.5 MB"
+func FormatByteSize(size int64) string {
+	for _, unit := range byteSizeUnits {
+		if size >= unit.size && unit.size > 1 {
+			value := strconv.FormatFloat(float64(size)/float64(unit.size), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + " " + unit.suffix
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// companyStorageUsage converts a row of ListCompanyStorageUsage, the default quota applies without a custom one
+func companyStorageUsage(row db.ListCompanyStorageUsageRow, quotas StorageQuotas) CompanyStorageUsage {
+	usage := CompanyStorageUsage{
+		CompanyID:   row.CompanyID,
+		CompanyName: row.CompanyName,
+		StorageUsage: StorageUsage{
+			Documents:      row.DocumentsSize,
+			Images:         row.ImagesSize,
+			Versions:       row.VersionsSize,
+			Watermarks:     row.WatermarksSize,
+			TeamDocuments:  row.TeamDocumentsSize,
+			PendingUploads: row.PendingUploadsSize,
+			Quota:          quotas.Company,
+		},
+		CustomQuota: row.Quota != nil,
+	}
+	if row.Quota != nil {
+		usage.Quota = *row.Quota
+	}
+	return usage
+}
+
+/*
+GetCompanyStorageUsage returns the storage used by a company and each of its projects. Every
+file the founders store counts, also the versions reviewers rely on, which are kept after the
+document is deleted. Only an admin can give more space once the quota is reached. The watermarked
+copies are reported but not charged, they are a cache of the downloads of the investors.
+*/
+func GetCompanyStorageUsage(ctx context.Context, queries *db.Queries, companyID string, quotas StorageQuotas) (CompanyStorageUsage, error) {
+	var id pgtype.UUID
+	if err := id.Scan(companyID); err != nil {
+		return CompanyStorageUsage{}, err
+	}
+
+	rows, err := queries.ListCompanyStorageUsage(ctx, id)
+	if err != nil {
+		return CompanyStorageUsage{}, err
+	}
+	if len(rows) == 0 {
+		return CompanyStorageUsage{}, pgx.ErrNoRows
+	}
+	usage := companyStorageUsage(rows[0], quotas)
+
+	projects, err := queries.ListProjectStorageUsage(ctx, companyID)
+	if err != nil {
+		return CompanyStorageUsage{}, err
+	}
+	usage.Projects = make([]ProjectStorageUsage, 0, len(projects))
+	for _, project := range projects {
+		usage.Projects = append(usage.Projects, ProjectStorageUsage{
+			ProjectID: project.ProjectID,
+			Title:     project.Title,
+			StorageUsage: StorageUsage{
+				Documents:      project.DocumentsSize,
+				Images:         project.ImagesSize,
+				Versions:       project.VersionsSize,
+				Watermarks:     project.WatermarksSize,
+				PendingUploads: project.PendingUploadsSize,
+				Quota:          quotas.Project,
+			},
+		})
+	}
+
+	return usage, nil
+}
+
+// ListCompanyStorageUsage returns the storage used by every company, the largest first, without their projects
+func ListCompanyStorageUsage(ctx context.Context, queries *db.Queries, quotas StorageQuotas) ([]CompanyStorageUsage, error) {
+	rows, err := queries.ListCompanyStorageUsage(ctx, pgtype.UUID{})
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]CompanyStorageUsage, 0, len(rows))
+	for _, row := range rows {
+		usages = append(usages, companyStorageUsage(row, quotas))
+	}
+	return usages, nil
+}
+
+/*
+CheckStorageQuota checks that a file of size bytes fits in the quota of the company and, when
+projectID is set, of the project. replaced is the size of a file that is removed from storage
+once the new one is saved, e.g. the current resume of a team member. A new version of a project
+document replaces nothing, the previous versions stay in storage.
+
+queries must be bound to the transaction that records the file: the storage of the company stays
+locked until the transaction ends, so concurrent uploads are checked one after the other and
+can't both take the space left for one.
+
+Returns a *StorageQuotaError wrapping ErrStorageQuotaExceeded when the file doesn't fit.
+*/
+func CheckStorageQuota(ctx context.Context, queries *db.Queries, companyID, projectID string, size, replaced int64) error {
+	// A smaller file is always accepted, even when the quota was lowered below the usage
+	if size <= replaced {
+		return nil
+	}
+
+	if err := queries.LockCompanyStorage(ctx, companyID); err != nil {
+		return err
+	}
+
+	usage, err := GetCompanyStorageUsage(ctx, queries, companyID, StorageQuotasFromEnv())
+	if err != nil {
+		return err
+	}
+
+	if !usage.Fits(size - replaced) {
+		return &StorageQuotaError{Scope: "company", Usage: usage.StorageUsage, Size: size}
+	}
+
+	for _, project := range usage.Projects {
+		if project.ProjectID == projectID && !project.Fits(size-replaced) {
+			return &StorageQuotaError{Scope: "project", Usage: project.StorageUsage, Size: size}
+		}
+	}
+
+	return nil
+}
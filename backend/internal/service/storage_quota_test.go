@@ -0,0 +1,83 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	valid := map[string]int64{
+		"1024":   1024,
+		"0":      0,
+		"512KB":  512 << 10,
+		"500MB":  500 << 20,
+		"500 mb": 500 << 20,
+		"1.5GB":  3 << 29,
+		"10B":    10,
+	}
+	for value, expected := range valid {
+		size, err := ParseByteSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	for _, value := range []string{"", "lots", "-1MB", "MB"} {
+		_, err := ParseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	assert.Equal(t, "512 B", FormatByteSize(512))
+	assert.Equal(t, "1.5 KB", FormatByteSize(1536))
+	assert.Equal(t, "500 MB", FormatByteSize(500<<20))
+	assert.Equal(t, "2 GB", FormatByteSize(2<<30))
+}
+
+func TestStorageQuotasFromEnv(t *testing.T) {
+	t.Setenv("STORAGE_QUOTA_COMPANY", "2GB")
+	t.Setenv("STORAGE_QUOTA_PROJECT", "250MB")
+	assert.Equal(t, StorageQuotas{Company: 2 << 30, Project: 250 << 20}, StorageQuotasFromEnv())
+
+	t.Setenv("STORAGE_QUOTA_COMPANY", "plenty")
+	t.Setenv("STORAGE_QUOTA_PROJECT", "")
+	assert.Equal(t, StorageQuotas{Company: defaultCompanyStorageQuota, Project: defaultProjectStorageQuota}, StorageQuotasFromEnv())
+}
+
+func TestStorageUsage(t *testing.T) {
+	quota := int64(100)
+	row := db.ListCompanyStorageUsageRow{
+		CompanyID:          "company",
+		DocumentsSize:      40,
+		ImagesSize:         20,
+		VersionsSize:       8,
+		WatermarksSize:     30,
+		TeamDocumentsSize:  10,
+		PendingUploadsSize: 5,
+	}
+
+	// The watermarked copies are not charged
+	usage := companyStorageUsage(row, StorageQuotas{Company: 500})
+	assert.Equal(t, int64(83), usage.Used())
+	assert.Equal(t, int64(500), usage.Quota)
+	assert.False(t, usage.CustomQuota)
+
+	row.Quota = &quota
+	usage = companyStorageUsage(row, StorageQuotas{Company: 500})
+	assert.Equal(t, int64(100), usage.Quota)
+	assert.True(t, usage.CustomQuota)
+	assert.True(t, usage.Fits(17))
+	assert.False(t, usage.Fits(18))
+
+	// A quota of 0 has no limit
+	usage.Quota = 0
+	assert.True(t, usage.Fits(1<<40))
+
+	err := error(&StorageQuotaError{Scope: "company", Usage: StorageUsage{Documents: 450 << 20, Quota: 500 << 20}, Size: 60 << 20})
+	assert.True(t, errors.Is(err, ErrStorageQuotaExceeded))
+	assert.Equal(t, "the company storage quota of 500 MB is exceeded: 450 MB used, the file needs 60 MB", err.Error())
+}
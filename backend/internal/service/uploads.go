@@ -0,0 +1,76 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/storage"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// uploadCleanupInterval is how often expired direct uploads are removed
+	uploadCleanupInterval = 15 * time.Minute
+	// uploadCleanupBatchSize bounds the uploads removed in one run, the rest waits for the next run
+	uploadCleanupBatchSize = 500
+)
+
+/*
+StartUploadCleaner runs CleanupExpiredUploads every uploadCleanupInterval until ctx is done.
+It is meant to run in a goroutine.
+*/
+func StartUploadCleaner(ctx context.Context, queries *db.Queries, store storage.Storage) {
+	ticker := time.NewTicker(uploadCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cleanupCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			CleanupExpiredUploads(cleanupCtx, queries, store)
+			cancel()
+		}
+	}
+}
+
+/*
+CleanupExpiredUploads removes the direct and resumable uploads that were never completed, together with
+the file or the chunks the client uploaded. The record is kept when they can't be deleted, so it is tried again.
+Returns the number of uploads removed.
+*/
+func CleanupExpiredUploads(ctx context.Context, queries *db.Queries, store storage.Storage) int {
+	uploads, err := queries.ListExpiredDocumentUploads(ctx, uploadCleanupBatchSize)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get expired uploads.")
+		return 0
+	}
+
+	removed := 0
+	for _, upload := range uploads {
+		// The chunks of a resumable upload that was never assembled
+		if upload.MultipartID != nil {
+			if err := store.AbortMultipartUpload(ctx, upload.StorageKey, *upload.MultipartID); err != nil {
+				log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to abort multipart upload of expired upload.")
+				continue
+			}
+		}
+		// Deleting a key that was never uploaded succeeds as well
+		if err := store.DeleteFile(ctx, upload.StorageKey); err != nil {
+			log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete file of expired upload.")
+			continue
+		}
+		if _, err := queries.DeleteDocumentUpload(ctx, upload.ID); err != nil {
+			log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete expired upload.")
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		log.Info().Int("uploads", removed).Msg("Removed expired uploads.")
+	}
+	return removed
+}
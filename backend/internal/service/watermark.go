@@ -0,0 +1,51 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// watermarkDescription places the text diagonally across the middle of every page, light
+// enough to keep the page readable.
+const watermarkDescription = "fontname:Helvetica, points:24, rotation:45, opacity:0.2, fillcolor:#808080, scalefactor:0.8 rel"
+
+func init() {
+	// pdfcpu writes its configuration to the home directory unless told otherwise and exits
+	// the process when it can't. The default configuration and core fonts are all we need.
+	api.DisableConfigDir()
+}
+
+/*
+WatermarkText returns the text stamped on the documents a user downloads.
+*/
+func WatermarkText(user *db.User, at time.Time) string {
+	name := strings.TrimSpace(stringValue(user.FirstName) + " " + stringValue(user.LastName))
+	if name == "" {
+		name = user.Email
+	}
+	return fmt.Sprintf("Confidential - %s\n%s\n%s", name, user.Email, at.UTC().Format("2006-01-02 15:04 UTC"))
+}
+
+/*
+WatermarkPDF stamps text on top of every page of a PDF and returns the stamped copy.
+The original content is left untouched.
+*/
+func WatermarkPDF(content []byte, text string) ([]byte, error) {
+	wm, err := api.TextWatermark(text, watermarkDescription, true, false, types.POINTS)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := api.AddWatermarks(bytes.NewReader(content), &out, nil, wm, nil); err != nil {
+		return nil, fmt.Errorf("couldn't watermark PDF: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"KonferCA/SPUR/db"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPDF(t *testing.T, pages int) []byte {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	for i := 0; i < pages; i++ {
+		pdf.AddPage()
+		pdf.Cell(40, 10, "Pitch deck")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	return buf.Bytes()
+}
+
+func TestWatermarkText(t *testing.T) {
+	at := time.Date(2025, 7, 31, 14, 5, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	first, last := "Ada", "Lovelace"
+
+	assert.Equal(t, "Confidential - Ada Lovelace\nada@example.com\n2025-07-31 18:05 UTC",
+		WatermarkText(&db.User{FirstName: &first, LastName: &last, Email: "ada@example.com"}, at))
+	assert.Equal(t, "Confidential - ada@example.com\nada@example.com\n2025-07-31 18:05 UTC",
+		WatermarkText(&db.User{Email: "ada@example.com"}, at), "falls back to the email without a name")
+}
+
+func TestWatermarkPDF(t *testing.T) {
+	original := testPDF(t, 3)
+
+	hasWatermarks, err := api.HasWatermarks(bytes.NewReader(original), nil)
+	require.NoError(t, err)
+	require.False(t, hasWatermarks)
+
+	stamped, err := WatermarkPDF(original, WatermarkText(&db.User{Email: "ada@example.com"}, time.Now()))
+	require.NoError(t, err)
+
+	hasWatermarks, err = api.HasWatermarks(bytes.NewReader(stamped), nil)
+	require.NoError(t, err)
+	assert.True(t, hasWatermarks)
+
+	pages, err := api.PageCount(bytes.NewReader(stamped), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pages)
+
+	_, err = WatermarkPDF([]byte("not a pdf"), "text")
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectActivity(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	otherID, otherEmail, otherPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, otherEmail, s)
+
+	adminID, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	_, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	// Comment handlers look up the caller's company, so the admin needs one as well
+	adminCompanyID, err := createTestCompany(ctx, s, adminID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, adminCompanyID, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	otherCompanyID, err := createTestCompany(ctx, s, otherID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, otherCompanyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Test Project", "Test Description", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	otherToken := loginAndGetToken(t, s, otherEmail, otherPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+
+	doRequest := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			jsonBody, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(jsonBody)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	commentsPath := fmt.Sprintf("/api/v1/project/%s/comments", projectID)
+	activityPath := fmt.Sprintf("/api/v1/project/%s/activity", projectID)
+
+	// Internal note first, then a public comment that moves the project to 'needs review'
+	rec := doRequest(http.MethodPost, commentsPath, adminToken, map[string]interface{}{
+		"comment":    "Double check the market size",
+		"target_id":  uuid.New().String(),
+		"visibility": "internal",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = doRequest(http.MethodPost, commentsPath, adminToken, map[string]interface{}{
+		"comment":   "Please add a source",
+		"target_id": uuid.New().String(),
+	})
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	type activityList struct {
+		Activities []struct {
+			Type     string                 `json:"type"`
+			Internal bool                   `json:"internal"`
+			Actor    *struct{ ID string }   `json:"actor"`
+			Payload  map[string]interface{} `json:"payload"`
+		} `json:"activities"`
+		Total int64 `json:"total"`
+	}
+
+	t.Run("Founder sees public activity only", func(t *testing.T) {
+		rec := doRequest(http.MethodGet, activityPath, founderToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response activityList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(2), response.Total)
+		require.Len(t, response.Activities, 2)
+
+		// Newest first
+		assert.Equal(t, "status_changed", response.Activities[0].Type)
+		assert.Equal(t, "pending", response.Activities[0].Payload["from"])
+		assert.Equal(t, "needs review", response.Activities[0].Payload["to"])
+		assert.Equal(t, "comment_created", response.Activities[1].Type)
+		assert.False(t, response.Activities[1].Internal)
+		require.NotNil(t, response.Activities[1].Actor)
+		assert.Equal(t, adminID, response.Activities[1].Actor.ID)
+	})
+
+	t.Run("Admin sees internal activity", func(t *testing.T) {
+		rec := doRequest(http.MethodGet, activityPath, adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response activityList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(3), response.Total)
+		require.Len(t, response.Activities, 3)
+		assert.True(t, response.Activities[2].Internal)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		rec := doRequest(http.MethodGet, activityPath+"?page=2&limit=2", adminToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response activityList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(3), response.Total)
+		require.Len(t, response.Activities, 1)
+		assert.Equal(t, "comment_created", response.Activities[0].Type)
+
+		rec = doRequest(http.MethodGet, activityPath+"?limit=500", adminToken, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Other founders can't see the timeline", func(t *testing.T) {
+		rec := doRequest(http.MethodGet, activityPath, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Investors only see the activity of shared documents", func(t *testing.T) {
+		var questionID string
+		err := s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+		require.NoError(t, err)
+
+		_, err = s.GetDB().Exec(ctx, `
+			WITH documents AS (
+				INSERT INTO project_documents (project_id, question_id, name, storage_key, section, sub_section, mime_type, confidential, scan_status)
+				VALUES ($1, $2, 'deck.pdf', 'projects/deck.pdf', 'overview', 'pitch', 'application/pdf', false, 'clean'),
+				       ($1, $2, 'cap-table.pdf', 'projects/cap-table.pdf', 'overview', 'pitch', 'application/pdf', true, 'clean')
+				RETURNING id, name
+			)
+			INSERT INTO project_activities (project_id, activity_type, payload)
+			SELECT $1, 'document_uploaded', jsonb_build_object('document_id', id::text, 'name', name) FROM documents
+		`, projectID, questionID)
+		require.NoError(t, err)
+
+		rec := doRequest(http.MethodGet, activityPath, investorToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response activityList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(3), response.Total)
+		require.Len(t, response.Activities, 3)
+		for _, activity := range response.Activities {
+			assert.False(t, activity.Internal)
+			assert.NotEqual(t, "cap-table.pdf", activity.Payload["name"])
+		}
+
+		// The founder sees the activity of every document
+		rec = doRequest(http.MethodGet, activityPath, founderToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(4), response.Total)
+	})
+
+	_, err = s.GetDB().Exec(ctx, `DELETE FROM project_comments WHERE project_id = $1`, projectID)
+	require.NoError(t, err)
+}
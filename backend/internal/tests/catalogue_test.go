@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectCatalogue(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, _, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	var industryQuestionID, stageQuestionID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions WHERE question_key = 'company_industries'`).Scan(&industryQuestionID)
+	require.NoError(t, err)
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions WHERE question_key = 'company_stage'`).Scan(&stageQuestionID)
+	require.NoError(t, err)
+
+	// A unique industry keeps other projects in the database out of the results
+	industry := "Industry " + uuid.NewString()
+
+	type testProject struct {
+		id        uuid.UUID
+		title     string
+		status    string
+		createdAt int64
+		stage     string
+	}
+	projects := []testProject{
+		{uuid.New(), "Charlie", "pending", 1000, "Seed"},
+		{uuid.New(), "alpha", "verified", 3000, "Seed"},
+		{uuid.New(), "Bravo", "pending", 2000, "Growth"},
+		{uuid.New(), "Delta", "draft", 4000, "Seed"},
+	}
+	for _, project := range projects {
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO projects (id, company_id, title, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, project.id, companyID, project.title, project.status, project.createdAt)
+		require.NoError(t, err)
+		defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, project.id)
+
+		_, err = s.GetDB().Exec(ctx, `
+			INSERT INTO project_answers (project_id, question_id, answer, choices)
+			VALUES ($1, $2, '', ARRAY[$3::text]), ($1, $4, $5, '{}')
+		`, project.id, industryQuestionID, industry, stageQuestionID, project.stage)
+		require.NoError(t, err)
+	}
+
+	type catalogueResponse struct {
+		Projects []struct {
+			ID     string `json:"id"`
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"projects"`
+		NextCursor string `json:"next_cursor"`
+		HasMore    bool   `json:"has_more"`
+		Facets     *struct {
+			Industries []struct {
+				Value string `json:"value"`
+				Count int64  `json:"count"`
+			} `json:"industries"`
+			Stages []struct {
+				Value string `json:"value"`
+				Count int64  `json:"count"`
+			} `json:"stages"`
+		} `json:"facets"`
+	}
+
+	get := func(params url.Values) (int, catalogueResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/project/catalogue?"+params.Encode(), nil)
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+
+		var response catalogueResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec.Code, response
+	}
+
+	// walk returns the titles of every page for the given sort, one project per page
+	walk := func(sort string) []string {
+		titles := []string{}
+		params := url.Values{"industry": {industry}, "sort": {sort}, "limit": {"1"}}
+		for i := 0; i < 10; i++ {
+			code, response := get(params)
+			require.Equal(t, http.StatusOK, code)
+			for _, project := range response.Projects {
+				titles = append(titles, project.Title)
+			}
+			if !response.HasMore {
+				assert.Empty(t, response.NextCursor)
+				break
+			}
+			params.Set("cursor", response.NextCursor)
+		}
+		return titles
+	}
+
+	t.Run("Pages through projects newest first", func(t *testing.T) {
+		// drafts are never part of the catalogue
+		assert.Equal(t, []string{"alpha", "Bravo", "Charlie"}, walk("newest"))
+	})
+
+	t.Run("Supports other sort orders", func(t *testing.T) {
+		assert.Equal(t, []string{"Charlie", "Bravo", "alpha"}, walk("oldest"))
+		assert.Equal(t, []string{"alpha", "Bravo", "Charlie"}, walk("title"))
+	})
+
+	t.Run("Filters by status and stage", func(t *testing.T) {
+		code, response := get(url.Values{"industry": {industry}, "statuses": {"pending"}})
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, response.Projects, 2)
+		for _, project := range response.Projects {
+			assert.Equal(t, "pending", project.Status)
+		}
+
+		code, response = get(url.Values{"industry": {industry}, "stage": {"growth"}})
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, response.Projects, 1)
+		assert.Equal(t, "Bravo", response.Projects[0].Title)
+	})
+
+	t.Run("Returns facet counts on the first page", func(t *testing.T) {
+		code, response := get(url.Values{"industry": {industry}, "limit": {"1"}})
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, response.Facets)
+
+		stages := map[string]int64{}
+		for _, stage := range response.Facets.Stages {
+			stages[stage.Value] = stage.Count
+		}
+		// stage counts are narrowed by the industry filter, Delta is a draft
+		assert.Equal(t, map[string]int64{"Seed": 2, "Growth": 1}, stages)
+
+		found := false
+		for _, facet := range response.Facets.Industries {
+			if facet.Value == industry {
+				found = true
+				assert.Equal(t, int64(3), facet.Count)
+			}
+		}
+		assert.True(t, found)
+
+		params := url.Values{"industry": {industry}, "limit": {"1"}, "cursor": {response.NextCursor}}
+		code, response = get(params)
+		require.Equal(t, http.StatusOK, code)
+		assert.Nil(t, response.Facets)
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		code, _ := get(url.Values{"statuses": {"draft"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = get(url.Values{"cursor": {"garbage"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		_, first := get(url.Values{"industry": {industry}, "limit": {"1"}})
+		code, _ = get(url.Values{"industry": {industry}, "sort": {"title"}, "cursor": {first.NextCursor}})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
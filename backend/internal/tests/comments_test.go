@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -530,3 +531,443 @@ func TestCommentEndpoints(t *testing.T) {
 		require.NoError(t, err)
 	}()
 }
+
+func TestInternalComments(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	// Founder only has startup owner permissions, so internal notes must stay hidden
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	adminID, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	// Comment handlers look up the caller's company, so the admin needs one as well
+	adminCompanyID, err := createTestCompany(ctx, s, adminID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, adminCompanyID, s)
+
+	// Investors can view all projects, but reviewer notes are not meant for them
+	investorID, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	investorCompanyID, err := createTestCompany(ctx, s, investorID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, investorCompanyID, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Test Project", "Test Description", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+
+	// Admin creates an internal note
+	body, err := json.Marshal(map[string]interface{}{
+		"comment":    "Market size looks inflated, double check before approving",
+		"target_id":  uuid.New().String(),
+		"visibility": "internal",
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments", projectID), bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", adminToken))
+	rec := httptest.NewRecorder()
+	s.GetEcho().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "internal", created["visibility"])
+	internalCommentID := created["id"].(string)
+
+	t.Run("Internal comment does not unlock project", func(t *testing.T) {
+		var status string
+		var allowEdit bool
+		err := s.GetDB().QueryRow(ctx, `SELECT status, allow_edit FROM projects WHERE id = $1`, projectID).Scan(&status, &allowEdit)
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status)
+		assert.False(t, allowEdit)
+
+		var count int64
+		err = s.GetDB().QueryRow(ctx, `SELECT COUNT(*) FROM project_comments WHERE project_id = $1 AND resolved = false AND visibility = 'public'`, projectID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Founder can't see internal comment", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/project/%s/comments", projectID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", founderToken))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Comments []CommentResponse `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Len(t, response.Comments, 0)
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/project/%s/comments/%s", projectID, internalCommentID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", founderToken))
+		rec = httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments/%s/resolve", projectID, internalCommentID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", founderToken))
+		rec = httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Investor can't see internal comment", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/project/%s/comments", projectID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", investorToken))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Comments []CommentResponse `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Len(t, response.Comments, 0)
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/project/%s/comments/%s", projectID, internalCommentID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", investorToken))
+		rec = httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Admin can see internal comment", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/project/%s/comments", projectID), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", adminToken))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Comments []map[string]interface{} `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Comments, 1)
+		assert.Equal(t, internalCommentID, response.Comments[0]["id"])
+		assert.Equal(t, "internal", response.Comments[0]["visibility"])
+	})
+
+	_, err = s.GetDB().Exec(ctx, `DELETE FROM project_comments WHERE project_id = $1`, projectID)
+	require.NoError(t, err)
+}
+
+func TestBulkComments(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	adminID, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Test Project", "Test Description", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	var questionIDs []string
+	rows, err := s.GetDB().Query(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 2`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		questionIDs = append(questionIDs, id)
+	}
+	rows.Close()
+	require.Len(t, questionIDs, 2)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	doRequest := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			jsonBody, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(jsonBody)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Founders can't manage templates
+	rec := doRequest(http.MethodGet, "/api/v1/comment-templates", founderToken, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = doRequest(http.MethodPost, "/api/v1/comment-templates", adminToken, map[string]string{
+		"title": "Market size source",
+		"body":  "{{company_name}}, please add a source for \"{{question}}\".",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &template))
+	templateID := template["id"].(string)
+	defer s.GetDB().Exec(ctx, `DELETE FROM comment_templates WHERE id = $1`, templateID)
+	assert.Equal(t, []interface{}{"company_name", "question"}, template["placeholders"])
+	assert.Equal(t, adminID, template["created_by"])
+
+	var commentIDs []string
+	t.Run("Bulk create from template", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments/bulk", projectID), adminToken, map[string]interface{}{
+			"comments": []map[string]interface{}{
+				{"target_id": questionIDs[0], "template_id": templateID},
+				{"target_id": questionIDs[1], "comment": "Resume missing"},
+			},
+		})
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var response struct {
+			Comments []CommentResponse `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Comments, 2)
+		assert.Contains(t, response.Comments[0].Comment, "Test Company, please add a source for")
+		for _, comment := range response.Comments {
+			commentIDs = append(commentIDs, comment.ID)
+		}
+
+		var status string
+		var allowEdit bool
+		err := s.GetDB().QueryRow(ctx, `SELECT status, allow_edit FROM projects WHERE id = $1`, projectID).Scan(&status, &allowEdit)
+		require.NoError(t, err)
+		assert.Equal(t, "needs review", status)
+		assert.True(t, allowEdit)
+	})
+
+	t.Run("Bulk create is atomic", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments/bulk", projectID), adminToken, map[string]interface{}{
+			"comments": []map[string]interface{}{
+				{"target_id": questionIDs[0], "comment": "This one is fine"},
+				{"target_id": questionIDs[1], "template_id": uuid.New().String()},
+			},
+		})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		var count int64
+		err := s.GetDB().QueryRow(ctx, `SELECT COUNT(*) FROM project_comments WHERE project_id = $1`, projectID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Founder bulk resolves and unresolves", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments/bulk/resolve", projectID), founderToken, map[string]interface{}{
+			"comment_ids": commentIDs,
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var count int64
+		err := s.GetDB().QueryRow(ctx, `SELECT COUNT(*) FROM project_comments WHERE project_id = $1 AND resolved = false`, projectID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+
+		rec = doRequest(http.MethodPost, fmt.Sprintf("/api/v1/project/%s/comments/bulk/unresolve", projectID), founderToken, map[string]interface{}{
+			"comment_ids": append(commentIDs, uuid.New().String()),
+		})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		err = s.GetDB().QueryRow(ctx, `SELECT COUNT(*) FROM project_comments WHERE project_id = $1 AND resolved = false`, projectID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	_, err = s.GetDB().Exec(ctx, `DELETE FROM project_comments WHERE project_id = $1`, projectID)
+	require.NoError(t, err)
+}
+
+func TestCommentAnchors(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, _, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	adminID, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	adminCompanyID, err := createTestCompany(ctx, s, adminID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, adminCompanyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Test Project", "Test Description", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+	defer s.GetDB().Exec(ctx, `DELETE FROM project_snapshots WHERE project_id = $1`, projectID)
+
+	var questionID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+	require.NoError(t, err)
+
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_answers (project_id, question_id, answer)
+		VALUES ($1, $2, $3)
+	`, projectID, questionID, "Our market is worth 2B in Canada")
+	require.NoError(t, err)
+
+	// Submitted version that the reviewer comments on
+	require.NoError(t, service.CreateProjectSnapshot(s.GetQueries(), ctx, projectID.String()))
+
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	doRequest := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			jsonBody, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(jsonBody)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", adminToken))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	type anchorResponse struct {
+		SnapshotID      *string `json:"snapshot_id"`
+		SnapshotVersion *int32  `json:"snapshot_version"`
+		Anchor          *struct {
+			Start        int32  `json:"start"`
+			End          int32  `json:"end"`
+			Text         string `json:"text"`
+			TextExists   bool   `json:"text_exists"`
+			Moved        bool   `json:"moved"`
+			CurrentStart *int   `json:"current_start"`
+		} `json:"anchor"`
+	}
+
+	commentsPath := fmt.Sprintf("/api/v1/project/%s/comments", projectID)
+
+	t.Run("Range outside of the answer", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, commentsPath, map[string]interface{}{
+			"target_id":    questionID,
+			"comment":      "Source?",
+			"anchor_start": 20,
+			"anchor_end":   500,
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Range requires both ends", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, commentsPath, map[string]interface{}{
+			"target_id":    questionID,
+			"comment":      "Source?",
+			"anchor_start": 20,
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Anchored comment follows the draft", func(t *testing.T) {
+		rec := doRequest(http.MethodPost, commentsPath, map[string]interface{}{
+			"target_id":    questionID,
+			"comment":      "Source?",
+			"anchor_start": 20,
+			"anchor_end":   22,
+		})
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var created anchorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		require.NotNil(t, created.SnapshotID)
+		require.NotNil(t, created.SnapshotVersion)
+		assert.Equal(t, int32(1), *created.SnapshotVersion)
+		require.NotNil(t, created.Anchor)
+		assert.Equal(t, "2B", created.Anchor.Text)
+		assert.True(t, created.Anchor.TextExists)
+		assert.False(t, created.Anchor.Moved)
+
+		// Founder edits the answer but keeps the figure
+		_, err := s.GetDB().Exec(ctx, `UPDATE project_answers SET answer = $1 WHERE project_id = $2 AND question_id = $3`,
+			"Per Statistics Canada, our market is worth 2B", projectID, questionID)
+		require.NoError(t, err)
+
+		rec = doRequest(http.MethodGet, commentsPath, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var list struct {
+			Comments []anchorResponse `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list.Comments, 1)
+		require.NotNil(t, list.Comments[0].Anchor)
+		assert.True(t, list.Comments[0].Anchor.TextExists)
+		assert.True(t, list.Comments[0].Anchor.Moved)
+		require.NotNil(t, list.Comments[0].Anchor.CurrentStart)
+		assert.Equal(t, 43, *list.Comments[0].Anchor.CurrentStart)
+
+		// Founder removes the figure
+		_, err = s.GetDB().Exec(ctx, `UPDATE project_answers SET answer = $1 WHERE project_id = $2 AND question_id = $3`,
+			"Per Statistics Canada, our market is large", projectID, questionID)
+		require.NoError(t, err)
+
+		rec = doRequest(http.MethodGet, commentsPath, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list.Comments, 1)
+		require.NotNil(t, list.Comments[0].Anchor)
+		assert.False(t, list.Comments[0].Anchor.TextExists)
+		assert.Nil(t, list.Comments[0].Anchor.CurrentStart)
+	})
+
+	_, err = s.GetDB().Exec(ctx, `DELETE FROM project_comments WHERE project_id = $1`, projectID)
+	require.NoError(t, err)
+}
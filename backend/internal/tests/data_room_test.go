@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataRoom(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	investorID, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	_, otherEmail, otherPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, otherEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, questionID, err := insertTestProject(ctx, s, companyID, "pending")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	pitchID := uuid.New()
+	capTableID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_documents (id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size, confidential, scan_status)
+		VALUES ($1, $3, $4, 'pitch.pdf', $5, 'overview', 'pitch', 'application/pdf', 2048, false, 'clean'),
+		       ($2, $3, $4, 'cap-table.xlsx', $6, 'finance', 'cap table', 'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet', 4096, true, 'clean')
+	`, pitchID, capTableID, projectID, questionID, "projects/pitch.pdf", "projects/cap-table.xlsx")
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+	otherToken := loginAndGetToken(t, s, otherEmail, otherPassword)
+
+	base := fmt.Sprintf("/api/v1/project/%s/data-room", projectID)
+	documents := func(token string) []string {
+		rec := doTestRequest(s, http.MethodGet, base, token, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response v1_projects.DataRoomResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		names := make([]string, len(response.Documents))
+		for i, doc := range response.Documents {
+			names[i] = doc.Name
+		}
+		return names
+	}
+	capTableURL := fmt.Sprintf("%s/documents/%s/url", base, capTableID)
+
+	t.Run("Confidential documents are hidden without a grant", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"pitch.pdf"}, documents(investorToken))
+		assert.ElementsMatch(t, []string{"pitch.pdf", "cap-table.xlsx"}, documents(founderToken))
+
+		rec := doTestRequest(s, http.MethodGet, capTableURL, investorToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("New uploads are confidential until the founder shares them", func(t *testing.T) {
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO project_documents (project_id, question_id, name, storage_key, section, sub_section, mime_type, size, scan_status)
+			VALUES ($1, $2, 'forecast.pdf', 'projects/forecast.pdf', 'finance', 'forecast', 'application/pdf', 2048, 'clean')
+		`, projectID, questionID)
+		require.NoError(t, err)
+		defer s.GetDB().Exec(ctx, `DELETE FROM project_documents WHERE project_id = $1 AND name = 'forecast.pdf'`, projectID)
+
+		assert.NotContains(t, documents(investorToken), "forecast.pdf")
+		assert.Contains(t, documents(founderToken), "forecast.pdf")
+	})
+
+	t.Run("Investors can't grant access", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base+"/grants", investorToken, fmt.Sprintf(`{"investor_id": "%s"}`, investorID))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("A grant needs exactly one recipient", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base+"/grants", founderToken, fmt.Sprintf(`{"investor_id": "%s", "all_committed": true}`, investorID))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPost, base+"/grants", founderToken, `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	var grantID string
+
+	t.Run("Founder grants access to a document", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base+"/grants", founderToken,
+			fmt.Sprintf(`{"investor_id": "%s", "document_id": "%s"}`, investorID, capTableID))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var grant v1_projects.DataRoomGrantResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &grant))
+		require.NotNil(t, grant.InvestorID)
+		assert.Equal(t, investorID, *grant.InvestorID)
+		assert.False(t, grant.Expired)
+		grantID = grant.ID
+
+		var notifications int
+		err := s.GetDB().QueryRow(ctx, `
+			SELECT count(*) FROM notifications WHERE user_id = $1 AND type = 'data_room_access_granted'
+		`, investorID).Scan(&notifications)
+		require.NoError(t, err)
+		assert.Equal(t, 1, notifications)
+	})
+
+	t.Run("Granted investor downloads through a signed URL", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"pitch.pdf", "cap-table.xlsx"}, documents(investorToken))
+
+		rec := doTestRequest(s, http.MethodGet, capTableURL+"?disposition=inline", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response v1_projects.DataRoomURLResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Contains(t, response.URL, "projects/cap-table.xlsx")
+		assert.Contains(t, response.URL, "signature=")
+		assert.NotZero(t, response.ExpiresAt)
+
+		// The signed URL is served by the storage route, without a session
+		content := []byte("cap table")
+		_, err := s.GetStorage().UploadFile(ctx, "projects/cap-table.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+		require.NoError(t, err)
+
+		rec = doTestRequest(s, http.MethodGet, response.URL, "", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, content, rec.Body.Bytes())
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "inline")
+
+		rec = doTestRequest(s, http.MethodGet, strings.Replace(response.URL, "disposition=inline", "disposition=attachment", 1), "", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Other investors still can't access the document", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"pitch.pdf"}, documents(otherToken))
+
+		rec := doTestRequest(s, http.MethodGet, capTableURL, otherToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Founder sees the access log", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, base+"/access-log", investorToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, base+"/access-log", founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response v1_projects.DataRoomAccessLogResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Entries, 1)
+		assert.Equal(t, investorID, response.Entries[0].UserID)
+		assert.Equal(t, "cap-table.xlsx", response.Entries[0].DocumentName)
+		assert.Equal(t, "view", string(response.Entries[0].Action))
+	})
+
+	t.Run("Founder turns on watermarking", func(t *testing.T) {
+		docURL := fmt.Sprintf("/api/v1/project/%s/documents/%s", projectID, capTableID)
+
+		rec := doTestRequest(s, http.MethodPatch, docURL, founderToken, `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPatch, docURL, investorToken, `{"watermark": true}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPatch, fmt.Sprintf("/api/v1/project/%s/documents/%s", projectID, pitchID), founderToken, `{"watermark": true}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = doTestRequest(s, http.MethodGet, base, founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response v1_projects.DataRoomResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		for _, doc := range response.Documents {
+			assert.Equal(t, doc.ID == pitchID.String(), doc.Watermark, doc.Name)
+			assert.Equal(t, doc.ID == capTableID.String(), doc.Confidential, doc.Name)
+		}
+	})
+
+	t.Run("Investors get their watermarked copy", func(t *testing.T) {
+		// A copy stamped for the investor on an earlier download
+		key := fmt.Sprintf("projects/%s/watermarks/%s/1/%s.pdf", projectID, pitchID, investorID)
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO document_watermarks (document_id, user_id, document_version, storage_key)
+			VALUES ($1, $2, 1, $3)
+		`, pitchID, investorID, key)
+		require.NoError(t, err)
+
+		rec := doTestRequest(s, http.MethodGet, fmt.Sprintf("%s/documents/%s/url", base, pitchID), investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var investorURL v1_projects.DataRoomURLResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &investorURL))
+		assert.Contains(t, investorURL.URL, key)
+
+		// The founder always gets the original
+		rec = doTestRequest(s, http.MethodGet, fmt.Sprintf("%s/documents/%s/url", base, pitchID), founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var founderURL v1_projects.DataRoomURLResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &founderURL))
+		assert.Contains(t, founderURL.URL, "projects/pitch.pdf")
+	})
+
+	t.Run("Revoking the grant hides the document again", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodDelete, fmt.Sprintf("%s/grants/%s", base, grantID), founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		assert.ElementsMatch(t, []string{"pitch.pdf"}, documents(investorToken))
+	})
+}
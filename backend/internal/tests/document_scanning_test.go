@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+	"KonferCA/SPUR/scanner"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentScanning(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status, allow_edit)
+		VALUES ($1, $2, $3, $4, $5, false)
+	`, projectID, companyID, "Tidal Batteries", "Grid storage", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	var questionID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+	require.NoError(t, err)
+
+	// A freshly uploaded document shared with the investors, its scan hasn't finished yet
+	deckID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_documents (id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size, confidential)
+		VALUES ($1, $2, $3, 'deck.pdf', $4, 'overview', 'pitch', 'application/pdf', 2048, false)
+	`, deckID, projectID, questionID, "projects/deck.pdf")
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	request := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+	dataRoom := func(token string) []v1_projects.DataRoomDocumentResponse {
+		rec := request(fmt.Sprintf("/api/v1/project/%s/data-room", projectID), token)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response v1_projects.DataRoomResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Documents
+	}
+	documents := func(token string) []v1_projects.DocumentResponse {
+		rec := request(fmt.Sprintf("/api/v1/project/%s/documents", projectID), token)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response struct {
+			Documents []v1_projects.DocumentResponse `json:"documents"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Documents
+	}
+	deckURL := fmt.Sprintf("/api/v1/project/%s/data-room/documents/%s/url", projectID, deckID)
+
+	t.Run("Pending documents are only shown to the founder", func(t *testing.T) {
+		docs := dataRoom(founderToken)
+		require.Len(t, docs, 1)
+		assert.Equal(t, db.ScanStatusPending, docs[0].ScanStatus)
+
+		founderDocs := documents(founderToken)
+		require.Len(t, founderDocs, 1)
+		assert.Equal(t, db.ScanStatusPending, founderDocs[0].ScanStatus)
+
+		assert.Empty(t, dataRoom(investorToken))
+		assert.Empty(t, documents(adminToken))
+
+		rec := request(deckURL, investorToken)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Pending documents block submission", func(t *testing.T) {
+		count, err := s.GetQueries().CountUnscannedProjectDocuments(ctx, projectID.String())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("A clean scan makes the document visible", func(t *testing.T) {
+		docs, err := s.GetQueries().GetProjectDocuments(ctx, projectID.String())
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		service.ScanProjectDocument(s.GetQueries(), s.GetStorage(), s.GetScanner(), docs[0], []byte("%PDF-1.4 quarterly numbers"))
+
+		var status string
+		var scannedAt *int64
+		err = s.GetDB().QueryRow(ctx, `SELECT scan_status, scanned_at FROM project_documents WHERE id = $1`, deckID).Scan(&status, &scannedAt)
+		require.NoError(t, err)
+		assert.Equal(t, "clean", status)
+		assert.NotNil(t, scannedAt)
+
+		investorDocs := dataRoom(investorToken)
+		require.Len(t, investorDocs, 1)
+		assert.Equal(t, db.ScanStatusClean, investorDocs[0].ScanStatus)
+		assert.Len(t, documents(adminToken), 1)
+
+		rec := request(deckURL, investorToken)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		count, err := s.GetQueries().CountUnscannedProjectDocuments(ctx, projectID.String())
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("The result of a replaced file is ignored", func(t *testing.T) {
+		_, err := s.GetDB().Exec(ctx, `UPDATE project_documents SET scan_status = 'pending' WHERE id = $1`, deckID)
+		require.NoError(t, err)
+
+		stale := db.ProjectDocument{ID: deckID.String(), ProjectID: projectID.String(), StorageKey: "projects/old-deck.pdf"}
+		service.ScanProjectDocument(s.GetQueries(), s.GetStorage(), s.GetScanner(), stale, []byte("%PDF-1.4 old numbers"))
+
+		var status string
+		err = s.GetDB().QueryRow(ctx, `SELECT scan_status FROM project_documents WHERE id = $1`, deckID).Scan(&status)
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status)
+	})
+
+	t.Run("Failed scans are retried a bounded number of times", func(t *testing.T) {
+		_, err := s.GetStorage().UploadFile(ctx, "projects/deck.pdf", "application/pdf", []byte("%PDF-1.4 quarterly numbers"))
+		require.NoError(t, err)
+
+		attempts := func() int {
+			var attempts int
+			err := s.GetDB().QueryRow(ctx, `
+				SELECT coalesce(max(attempts), 0) FROM document_scan_attempts WHERE document_id = $1 AND storage_key = 'projects/deck.pdf'
+			`, deckID).Scan(&attempts)
+			require.NoError(t, err)
+			return attempts
+		}
+
+		service.RescanPendingDocuments(s.GetQueries(), s.GetStorage(), failingScanner{}, 0)
+		assert.Equal(t, 1, attempts())
+
+		for i := 0; i < 10; i++ {
+			service.RescanPendingDocuments(s.GetQueries(), s.GetStorage(), failingScanner{}, 0)
+		}
+		limit := attempts()
+		assert.Less(t, limit, 11)
+
+		// Once out of attempts the document is left pending
+		service.RescanPendingDocuments(s.GetQueries(), s.GetStorage(), s.GetScanner(), 0)
+		assert.Equal(t, limit, attempts())
+		var status string
+		err = s.GetDB().QueryRow(ctx, `SELECT scan_status FROM project_documents WHERE id = $1`, deckID).Scan(&status)
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status)
+
+		// Documents changed recently are left to their first scan
+		_, err = s.GetDB().Exec(ctx, `DELETE FROM document_scan_attempts WHERE document_id = $1`, deckID)
+		require.NoError(t, err)
+		_, err = s.GetDB().Exec(ctx, `UPDATE project_documents SET updated_at = extract(epoch from now()) WHERE id = $1`, deckID)
+		require.NoError(t, err)
+		service.RescanPendingDocuments(s.GetQueries(), s.GetStorage(), failingScanner{}, time.Hour)
+		assert.Equal(t, 0, attempts())
+
+		service.RescanPendingDocuments(s.GetQueries(), s.GetStorage(), s.GetScanner(), -time.Minute)
+		err = s.GetDB().QueryRow(ctx, `SELECT scan_status FROM project_documents WHERE id = $1`, deckID).Scan(&status)
+		require.NoError(t, err)
+		assert.Equal(t, "clean", status)
+	})
+}
+
+// failingScanner stands in for a scanner that can't be reached
+type failingScanner struct{}
+
+func (failingScanner) Scan(ctx context.Context, data []byte) (scanner.Result, error) {
+	return scanner.Result{}, errors.New("scanner unavailable")
+}
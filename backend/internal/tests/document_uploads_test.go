@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectDocumentUploads(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	otherID, otherEmail, otherPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, otherEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	otherCompanyID, err := createTestCompany(ctx, s, otherID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, otherCompanyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Tidal Batteries", "Grid storage", "draft")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	var questionID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	otherToken := loginAndGetToken(t, s, otherEmail, otherPassword)
+
+	uploadsURL := fmt.Sprintf("/api/v1/project/%s/documents/uploads", projectID)
+	request := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+	uploadBody := func(mimeType string, size int) string {
+		return fmt.Sprintf(`{"question_id": "%s", "name": "deck.pdf", "section": "overview", "sub_section": "pitch", "mime_type": "%s", "size": %d}`,
+			questionID, mimeType, size)
+	}
+
+	var upload v1_projects.DocumentUploadResponse
+
+	t.Run("Founder gets a presigned upload URL", func(t *testing.T) {
+		rec := request(uploadsURL, founderToken, uploadBody("application/pdf", 2048))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &upload))
+
+		assert.Equal(t, http.MethodPut, upload.Method)
+		assert.Contains(t, upload.UploadURL, fmt.Sprintf("projects/%s/documents/", projectID))
+		assert.Contains(t, upload.UploadURL, "signature=")
+		assert.Equal(t, "application/pdf", upload.Headers[echo.HeaderContentType])
+		assert.Equal(t, "2048", upload.Headers[echo.HeaderContentLength])
+
+		var key string
+		var size int64
+		err := s.GetDB().QueryRow(ctx, `SELECT storage_key, size FROM document_uploads WHERE id = $1`, upload.ID).Scan(&key, &size)
+		require.NoError(t, err)
+		assert.Contains(t, upload.UploadURL, key)
+		assert.Equal(t, int64(2048), size)
+	})
+
+	t.Run("Declared files follow the document rules", func(t *testing.T) {
+		rec := request(uploadsURL, founderToken, uploadBody("application/zip", 2048))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = request(uploadsURL, founderToken, uploadBody("application/pdf", 20*1024*1024))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("Only the owner can upload to a project", func(t *testing.T) {
+		rec := request(uploadsURL, otherToken, uploadBody("application/pdf", 2048))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Only the uploader can complete an upload", func(t *testing.T) {
+		rec := request(fmt.Sprintf("%s/%s/complete", uploadsURL, upload.ID), otherToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = request(fmt.Sprintf("%s/%s/complete", uploadsURL, uuid.New()), founderToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("The file is uploaded with the signed URL then completed", func(t *testing.T) {
+		rec := request(uploadsURL, founderToken, uploadBody("application/pdf", 2048))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var direct v1_projects.DocumentUploadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &direct))
+
+		content := append([]byte("%PDF-1.4\n"), make([]byte, 2048-9)...)
+		put := func(contentType string, body []byte) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(direct.Method, direct.UploadURL, bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, contentType)
+			rec := httptest.NewRecorder()
+			s.GetEcho().ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec = put("image/png", content)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = put(direct.Headers[echo.HeaderContentType], content[:1024])
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = request(fmt.Sprintf("%s/%s/complete", uploadsURL, direct.ID), founderToken, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = put(direct.Headers[echo.HeaderContentType], content)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = request(fmt.Sprintf("%s/%s/complete", uploadsURL, direct.ID), founderToken, "")
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var doc v1_projects.DocumentResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+		assert.Equal(t, "deck.pdf", doc.Name)
+
+		assert.Contains(t, doc.URL, "signature=")
+
+		// Only the key is stored, the URL in the response is signed for the request
+		var key string
+		err = s.GetDB().QueryRow(ctx, `SELECT storage_key FROM project_documents WHERE id = $1`, doc.ID).Scan(&key)
+		require.NoError(t, err)
+		assert.NotContains(t, key, "?")
+		stored, err := s.GetStorage().DownloadFile(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, content, stored)
+
+		var uploads int
+		err = s.GetDB().QueryRow(ctx, `SELECT count(*) FROM document_uploads WHERE id = $1`, direct.ID).Scan(&uploads)
+		require.NoError(t, err)
+		assert.Zero(t, uploads)
+	})
+
+	t.Run("The content of the file must match its declared type", func(t *testing.T) {
+		rec := request(uploadsURL, founderToken, uploadBody("application/pdf", 2048))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var direct v1_projects.DocumentUploadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &direct))
+
+		// The storage keeps the signed type, the content is only checked when the upload is completed
+		req := httptest.NewRequest(direct.Method, direct.UploadURL, bytes.NewReader(make([]byte, 2048)))
+		req.Header.Set(echo.HeaderContentType, direct.Headers[echo.HeaderContentType])
+		rec = httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = request(fmt.Sprintf("%s/%s/complete", uploadsURL, direct.ID), founderToken, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("An upload is completed once", func(t *testing.T) {
+		rec := request(uploadsURL, founderToken, uploadBody("application/pdf", 2048))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var direct v1_projects.DocumentUploadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &direct))
+
+		req := httptest.NewRequest(direct.Method, direct.UploadURL, bytes.NewReader(append([]byte("%PDF-1.4\n"), make([]byte, 2048-9)...)))
+		req.Header.Set(echo.HeaderContentType, direct.Headers[echo.HeaderContentType])
+		rec = httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var key string
+		err := s.GetDB().QueryRow(ctx, `SELECT storage_key FROM document_uploads WHERE id = $1`, direct.ID).Scan(&key)
+		require.NoError(t, err)
+
+		codes := make([]int, 3)
+		var wg sync.WaitGroup
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i] = request(fmt.Sprintf("%s/%s/complete", uploadsURL, direct.ID), founderToken, "").Code
+			}(i)
+		}
+		wg.Wait()
+
+		created := 0
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				created++
+			} else {
+				assert.Contains(t, []int{http.StatusNotFound, http.StatusConflict}, code)
+			}
+		}
+		assert.Equal(t, 1, created)
+
+		var documents int
+		err = s.GetDB().QueryRow(ctx, `SELECT count(*) FROM project_documents WHERE storage_key = $1`, key).Scan(&documents)
+		require.NoError(t, err)
+		assert.Equal(t, 1, documents)
+	})
+
+	t.Run("Expired uploads can't be completed", func(t *testing.T) {
+		_, err := s.GetDB().Exec(ctx, `UPDATE document_uploads SET expires_at = extract(epoch from now()) - 60 WHERE id = $1`, upload.ID)
+		require.NoError(t, err)
+
+		rec := request(fmt.Sprintf("%s/%s/complete", uploadsURL, upload.ID), founderToken, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		expired, err := s.GetQueries().ListExpiredDocumentUploads(ctx, 100)
+		require.NoError(t, err)
+		ids := make([]string, len(expired))
+		for i, expiredUpload := range expired {
+			ids[i] = expiredUpload.ID
+		}
+		assert.Contains(t, ids, upload.ID)
+	})
+}
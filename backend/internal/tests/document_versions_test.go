@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentVersions(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, questionID, err := insertTestProject(ctx, s, companyID, "draft")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	// uploadVersion stores a file as the next version of the deck, like handleUploadProjectDocument
+	// does after the file is in storage
+	deckID := uuid.New()
+	uploadVersion := func(version int, name string) {
+		key := fmt.Sprintf("projects/%s/documents/%s", projectID, name)
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO project_documents (id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size, version, scan_status)
+			VALUES ($1, $2, $3, $4, $5, 'overview', 'pitch', 'application/pdf', 2048, $6, 'clean')
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, storage_key = EXCLUDED.storage_key, version = EXCLUDED.version
+		`, deckID, projectID, questionID, name, key, version)
+		require.NoError(t, err)
+		_, err = s.GetDB().Exec(ctx, `
+			INSERT INTO project_document_versions (document_id, project_id, question_id, version, name, storage_key, mime_type, size, uploaded_by)
+			VALUES ($1, $2, $3, $4, $5, $6, 'application/pdf', 2048, $7)
+		`, deckID, projectID, questionID, version, name, key, founderID)
+		require.NoError(t, err)
+	}
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	versionsURL := fmt.Sprintf("/api/v1/project/%s/documents/%s/versions", projectID, deckID)
+	versions := func(token string) []v1_projects.DocumentVersionResponse {
+		rec := doTestRequest(s, http.MethodGet, versionsURL, token, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response v1_projects.DocumentVersionsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Versions
+	}
+
+	// Submitted with the first deck, then the deck is replaced and the project submitted again
+	uploadVersion(1, "deck-v1.pdf")
+	require.NoError(t, service.CreateProjectSnapshot(s.GetQueries(), ctx, projectID))
+	uploadVersion(2, "deck-v2.pdf")
+	require.NoError(t, service.CreateProjectSnapshot(s.GetQueries(), ctx, projectID))
+	uploadVersion(3, "deck-v3.pdf")
+
+	t.Run("Versions list the snapshots they were submitted with", func(t *testing.T) {
+		list := versions(adminToken)
+		require.Len(t, list, 3)
+
+		assert.Equal(t, int32(3), list[0].Version)
+		assert.Empty(t, list[0].Snapshots, "not submitted yet")
+
+		assert.Equal(t, "deck-v2.pdf", list[1].Name)
+		require.Len(t, list[1].Snapshots, 1)
+		assert.Equal(t, int32(2), list[1].Snapshots[0].VersionNumber)
+
+		assert.Equal(t, "deck-v1.pdf", list[2].Name)
+		require.Len(t, list[2].Snapshots, 1)
+		assert.Equal(t, int32(1), list[2].Snapshots[0].VersionNumber)
+		require.NotNil(t, list[2].UploadedBy)
+		assert.Equal(t, founderID, *list[2].UploadedBy)
+	})
+
+	t.Run("Investors can't see the history", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, versionsURL, investorToken, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("History is kept after the document is deleted", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodDelete, fmt.Sprintf("/api/v1/project/%s/documents/%s", projectID, deckID), founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		assert.Len(t, versions(founderToken), 3)
+	})
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportProject(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	otherID, otherEmail, otherPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, otherEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	_, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	otherCompanyID, err := createTestCompany(ctx, s, otherID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, otherCompanyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, projectID, companyID, "Sunbeam Solar", "Rooftop panels", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	otherToken := loginAndGetToken(t, s, otherEmail, otherPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+
+	export := func(token string, query string) *httptest.ResponseRecorder {
+		path := fmt.Sprintf("/api/v1/project/%s/export%s", projectID, query)
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Requires a submitted version", func(t *testing.T) {
+		rec := export(founderToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	require.NoError(t, service.CreateProjectSnapshot(s.GetQueries(), ctx, projectID.String()))
+
+	t.Run("Founder exports html", func(t *testing.T) {
+		rec := export(founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "text/html"))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "sunbeam-solar-v1.html")
+		assert.Contains(t, rec.Body.String(), "Sunbeam Solar")
+	})
+
+	t.Run("Admin exports pdf and it is cached", func(t *testing.T) {
+		rec := export(adminToken, "?format=pdf&version=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-"))
+
+		var count int
+		err := s.GetDB().QueryRow(ctx, `
+			SELECT count(*) FROM project_dossier_exports e
+			JOIN project_snapshots ps ON ps.id = e.snapshot_id
+			WHERE ps.project_id = $1
+		`, projectID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		cached := export(adminToken, "?format=pdf&version=1")
+		require.Equal(t, http.StatusOK, cached.Code)
+		assert.Equal(t, rec.Body.Bytes(), cached.Body.Bytes())
+	})
+
+	t.Run("A renamed company is exported with its new name", func(t *testing.T) {
+		_, err := s.GetDB().Exec(ctx, `UPDATE companies SET name = 'Sunbeam Energy Inc' WHERE id = $1`, companyID)
+		require.NoError(t, err)
+
+		rec := export(founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Sunbeam Energy Inc")
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		rec := export(adminToken, "?version=5")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Other founders can't export", func(t *testing.T) {
+		rec := export(otherToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Investors can't export", func(t *testing.T) {
+		rec := export(investorToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		rec := export(founderToken, "?format=docx")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
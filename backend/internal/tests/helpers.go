@@ -15,6 +15,7 @@ import (
 	"KonferCA/SPUR/internal/server"
 
 	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
@@ -126,6 +127,36 @@ func removeTestCompany(ctx context.Context, companyID string, s *server.Server)
 	return err
 }
 
+/*
+Inserts a test project with the given status for a company, without going through the API. Remember to remove the test project with removeTestProject().
+
+The function returns projectID, the id of the first question of the form to attach answers and documents to, error
+*/
+func insertTestProject(ctx context.Context, s *server.Server, companyID string, status string) (string, string, error) {
+	projectID := uuid.New().String()
+
+	_, err := s.DBPool.Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)`,
+		projectID, companyID, "Test Project", "Test Description", status)
+	if err != nil {
+		return "", "", err
+	}
+
+	var questionID string
+	err = s.DBPool.QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+
+	return projectID, questionID, err
+}
+
+/*
+Removes a test project from the database.
+*/
+func removeTestProject(ctx context.Context, projectID string, s *server.Server) error {
+	_, err := s.DBPool.Exec(ctx, "DELETE FROM projects WHERE id = $1", projectID)
+	return err
+}
+
 func createTestAdmin(ctx context.Context, s *server.Server) (string, string, string, error) {
 	// Create admin user with all permissions
 	perms := permissions.PermAdmin | permissions.PermManageUsers | permissions.PermViewAllProjects |
@@ -181,3 +212,27 @@ func generateTestToken(t *testing.T, userID string, perms uint32, salt []byte) s
 	require.NoError(t, err)
 	return token
 }
+
+/*
+Sends a JSON request to the test server as the user of token, no token is sent when it is empty.
+*/
+func doTestRequest(s *server.Server, method, path, token, body string) *httptest.ResponseRecorder {
+	return doTestRequestWithHeaders(s, method, path, token, nil, body)
+}
+
+/*
+Same as doTestRequest, the given headers are set on top of the JSON content type.
+*/
+func doTestRequestWithHeaders(s *server.Server, method, path, token string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	s.GetEcho().ServeHTTP(rec, req)
+	return rec
+}
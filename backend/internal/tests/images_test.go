@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+	"KonferCA/SPUR/internal/v1/v1_users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG returns a noisy PNG, large enough for the minimum upload size
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x * y), A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestImageUploads(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status, allow_edit)
+		VALUES ($1, $2, $3, $4, $5, true)
+	`, projectID, companyID, "Solar Sails", "Space propulsion", "draft")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+
+	upload := func(url, fileName string, content []byte, fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		for name, value := range fields {
+			require.NoError(t, writer.WriteField(name, value))
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, url, body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", founderToken))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	profilePictureURL := fmt.Sprintf("/api/v1/users/%s/profile-picture", founderID)
+
+	t.Run("profile pictures are stored as standard sizes", func(t *testing.T) {
+		rec := upload(profilePictureURL, "me.png", testPNG(t, 3000, 2000), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response v1_users.UploadProfilePictureResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Contains(t, response.URL, "signature=")
+		for _, size := range []string{"thumbnail", "medium", "full"} {
+			assert.Contains(t, response.URLs[size], "/"+size+".jpg?", size)
+		}
+
+		var key string
+		err := s.GetDB().QueryRow(ctx, `SELECT profile_picture_key FROM users WHERE id = $1`, founderID).Scan(&key)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(key, "/full.jpg"), key)
+
+		stored, err := s.GetStorage().DownloadFile(ctx, key)
+		require.NoError(t, err)
+		img, err := jpeg.Decode(bytes.NewReader(stored))
+		require.NoError(t, err)
+		assert.Equal(t, image.Point{2560, 1706}, img.Bounds().Size())
+	})
+
+	t.Run("decompression bombs are rejected", func(t *testing.T) {
+		bomb := testPNG(t, 64, 64)
+		// The header claims 60000x60000 pixels, the IHDR chunk follows the 8 bytes signature
+		binary.BigEndian.PutUint32(bomb[16:], 60000)
+		binary.BigEndian.PutUint32(bomb[20:], 60000)
+		binary.BigEndian.PutUint32(bomb[29:], crc32.ChecksumIEEE(bomb[12:29]))
+
+		rec := upload(profilePictureURL, "bomb.png", bomb, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "too large")
+	})
+
+	t.Run("featured images are stored as standard sizes", func(t *testing.T) {
+		var questionID string
+		err := s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions WHERE question_key = 'company_featured_images'`).Scan(&questionID)
+		require.NoError(t, err)
+
+		rec := upload(fmt.Sprintf("/api/v1/project/%s/documents", projectID), "office.png", testPNG(t, 800, 600), map[string]string{
+			"question_id": questionID,
+			"name":        "office.png",
+			"section":     "overview",
+			"sub_section": "introduction",
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var doc v1_projects.DocumentResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+		assert.Equal(t, "office.jpg", doc.Name)
+		assert.Len(t, doc.URLs, 3)
+		assert.Contains(t, doc.URLs["thumbnail"], "/thumbnail.jpg?")
+
+		var mimeType string
+		err = s.GetDB().QueryRow(ctx, `SELECT mime_type FROM project_documents WHERE id = $1`, doc.ID).Scan(&mimeType)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", mimeType)
+	})
+}
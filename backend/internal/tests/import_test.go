@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportApplications(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	_, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	upload := func(token, fileName, content string, dryRun bool) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteField("dry_run", fmt.Sprint(dryRun)))
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/project/import", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	newEmail := "imported-" + adminEmail
+	defer removeTestUser(ctx, newEmail, s)
+
+	csvContent := "owner_email,company_name,answer:company_mascot\n" +
+		fmt.Sprintf("%s,Acme,Otter\n", founderEmail) +
+		fmt.Sprintf("%s,Globex,\n", newEmail) +
+		fmt.Sprintf("%s,Globex Again,\n", newEmail) +
+		fmt.Sprintf("%s,Initech,\n", strings.ToUpper(founderEmail))
+
+	t.Run("Startup owners cannot import", func(t *testing.T) {
+		rec := upload(founderToken, "cohort.csv", csvContent, true)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Rejects unknown file types", func(t *testing.T) {
+		rec := upload(adminToken, "cohort.txt", csvContent, true)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Rejects malformed files", func(t *testing.T) {
+		rec := upload(adminToken, "cohort.json", "{not json", true)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Dry run reports every row", func(t *testing.T) {
+		rec := upload(adminToken, "cohort.csv", csvContent, true)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response v1_projects.ImportApplicationsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.True(t, response.DryRun)
+		assert.Equal(t, 4, response.Total)
+		require.Len(t, response.Rows, 4)
+		assert.Zero(t, response.Created)
+		assert.Equal(t, response.Total-response.Failed, response.Valid)
+
+		// existing owner and unknown question key
+		assert.Equal(t, "failed", response.Rows[0].Status)
+		messages := []string{}
+		for _, err := range response.Rows[0].Errors {
+			messages = append(messages, err.Message)
+		}
+		assert.Contains(t, messages, "A user with this email already exists")
+		assert.Contains(t, messages, "Unknown question key")
+
+		// the same owner can't be imported twice
+		assert.Equal(t, "failed", response.Rows[2].Status)
+		assert.Nil(t, response.Rows[2].ProjectID)
+
+		// emails are compared case-insensitively with the existing users
+		assert.Equal(t, "failed", response.Rows[3].Status)
+		require.NotEmpty(t, response.Rows[3].Errors)
+		assert.Equal(t, "A user with this email already exists", response.Rows[3].Errors[0].Message)
+
+		// nothing is created in a dry run
+		var exists bool
+		err := s.GetDB().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, newEmail).Scan(&exists)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_messages"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectMessages(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	investorID, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	_, strangerEmail, strangerPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, strangerEmail, s)
+
+	adminID, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "verified")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+	defer s.GetDB().Exec(ctx, `DELETE FROM conversations WHERE created_by = ANY($1::uuid[])`, []string{founderID, investorID, adminID})
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+	strangerToken := loginAndGetToken(t, s, strangerEmail, strangerPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	start := func(token, recipientID string) *httptest.ResponseRecorder {
+		return doTestRequest(s, http.MethodPost, "/api/v1/conversations", token,
+			fmt.Sprintf(`{"recipient_id":"%s","project_id":"%s","subject":"Intro","body":"Hello there"}`, recipientID, projectID))
+	}
+
+	var conversationID string
+
+	t.Run("Investors without a relationship can't start a conversation", func(t *testing.T) {
+		rec := start(investorToken, founderID)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPost, "/api/v1/conversations", founderToken,
+			fmt.Sprintf(`{"recipient_id":"%s","body":"Hi"}`, investorID))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Watching investors can message the founder", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/watchlist", investorToken, fmt.Sprintf(`{"project_id":"%s"}`, projectID))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = start(investorToken, founderID)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var conversation v1_messages.ConversationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &conversation))
+		assert.Len(t, conversation.Participants, 2)
+		conversationID = conversation.ID
+
+		// A second conversation about the same project continues the first one
+		rec = start(founderToken, investorID)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &conversation))
+		assert.Equal(t, conversationID, conversation.ID)
+	})
+
+	t.Run("Unread counts and read receipts", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/conversations/"+conversationID+"/messages", founderToken, `{"body":"Happy to chat"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/conversations", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var list v1_messages.ConversationListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list.Conversations, 1)
+		assert.Equal(t, int64(2), list.Conversations[0].UnreadCount)
+
+		rec = doTestRequest(s, http.MethodPost, "/api/v1/conversations/"+conversationID+"/read", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/conversations/"+conversationID+"/messages", founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var messages v1_messages.MessageListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &messages))
+		assert.Equal(t, int64(3), messages.Total)
+		require.NotEmpty(t, messages.Messages)
+		assert.Equal(t, "Happy to chat", messages.Messages[0].Body)
+		assert.Contains(t, messages.Messages[0].ReadBy, investorID)
+	})
+
+	t.Run("Outsiders can't read the conversation", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/conversations/"+conversationID+"/messages", strangerToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPost, "/api/v1/conversations/"+conversationID+"/messages", strangerToken, `{"body":"Hi"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/admin/conversations", founderToken, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Admins read conversations with a reason", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/admin/conversations/"+conversationID+"/messages", adminToken, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/admin/conversations/"+conversationID+"/messages?reason="+url.QueryEscape("Dispute about terms"), adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var reads int
+		require.NoError(t, s.GetDB().QueryRow(ctx,
+			`SELECT count(*) FROM conversation_admin_reads WHERE conversation_id = $1 AND admin_id = $2`, conversationID, adminID).Scan(&reads))
+		assert.Equal(t, 1, reads)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/admin/conversations?user_id="+investorID, adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var list v1_messages.ConversationListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		assert.Len(t, list.Conversations, 1)
+	})
+
+	t.Run("Anyone can message an admin", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/conversations", strangerToken, fmt.Sprintf(`{"recipient_id":"%s","body":"Help"}`, adminID))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+}
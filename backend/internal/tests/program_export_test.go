@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_exports"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramExport(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "pending")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	waitForExport := func(t *testing.T, id string) v1_exports.ExportJobResponse {
+		var job v1_exports.ExportJobResponse
+		require.Eventually(t, func() bool {
+			rec := doTestRequest(s, http.MethodGet, "/api/v1/exports/"+id, adminToken, "")
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+			return job.Status == db.ExportJobStatusCompleted || job.Status == db.ExportJobStatusFailed
+		}, 10*time.Second, 100*time.Millisecond)
+		return job
+	}
+
+	t.Run("Startup owners cannot export", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/exports", founderToken, `{"format":"csv"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Rejects unknown formats", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/exports", adminToken, `{"format":"pdf"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Admin exports csv", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/exports", adminToken, `{"format":"csv","statuses":["pending"]}`)
+		require.Equal(t, http.StatusAccepted, rec.Code)
+
+		var created v1_exports.ExportJobResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		defer s.GetDB().Exec(ctx, `DELETE FROM export_jobs WHERE id = $1`, created.ID)
+
+		job := waitForExport(t, created.ID)
+		require.Equal(t, db.ExportJobStatusCompleted, job.Status)
+		require.NotNil(t, job.RowCount)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/exports/"+created.ID+"/download", adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "text/csv"))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), ".csv")
+
+		records, err := csv.NewReader(bytes.NewReader(rec.Body.Bytes())).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, int(*job.RowCount)+1, len(records))
+		assert.Contains(t, rec.Body.String(), projectID)
+	})
+
+	t.Run("Admin exports json without drafts", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/exports", adminToken, `{"format":"json","statuses":["draft"]}`)
+		require.Equal(t, http.StatusAccepted, rec.Code)
+
+		var created v1_exports.ExportJobResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		defer s.GetDB().Exec(ctx, `DELETE FROM export_jobs WHERE id = $1`, created.ID)
+
+		job := waitForExport(t, created.ID)
+		require.Equal(t, db.ExportJobStatusCompleted, job.Status)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/exports/"+created.ID+"/download", adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), projectID)
+	})
+
+	t.Run("Download before completion", func(t *testing.T) {
+		id := uuid.New().String()
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO export_jobs (id, requested_by, format)
+			SELECT $1, id, 'csv' FROM users WHERE email = $2
+		`, id, adminEmail)
+		require.NoError(t, err)
+		defer s.GetDB().Exec(ctx, `DELETE FROM export_jobs WHERE id = $1`, id)
+
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/exports/"+id+"/download", adminToken, "")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Unknown export", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/exports/"+uuid.New().String(), adminToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = doTestRequest(s, http.MethodDelete, "/api/v1/exports/"+uuid.New().String(), adminToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
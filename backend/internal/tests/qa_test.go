@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvestorQA(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	askerID, askerEmail, askerPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, askerEmail, s)
+
+	_, otherEmail, otherPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, otherEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "pending")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	askerToken := loginAndGetToken(t, s, askerEmail, askerPassword)
+	otherToken := loginAndGetToken(t, s, otherEmail, otherPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	base := fmt.Sprintf("/api/v1/project/%s/qa", projectID)
+	threads := func(token string) []v1_projects.QAThreadResponse {
+		rec := doTestRequest(s, http.MethodGet, base, token, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response v1_projects.QAThreadsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Threads
+	}
+
+	var questionID string
+
+	t.Run("Founders can't ask questions", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base, founderToken, `{"body":"Anyone there?"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Investor asks a private question", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base, askerToken, `{"body":"What is your churn?"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var thread v1_projects.QAThreadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &thread))
+		assert.Equal(t, "private", string(thread.Visibility))
+		questionID = thread.ID
+
+		assert.Empty(t, threads(otherToken))
+		require.Len(t, threads(founderToken), 1)
+
+		var count int
+		require.NoError(t, s.GetDB().QueryRow(ctx,
+			`SELECT count(*) FROM notifications WHERE user_id = $1 AND type = 'qa_question_asked'`, founderID).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Other investors can't reply", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base+"/"+questionID+"/replies", otherToken, `{"body":"Me too"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Founder answers publicly", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, base+"/"+questionID+"/replies", founderToken, `{"body":"Under 2% monthly","visibility":"public"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		visible := threads(otherToken)
+		require.Len(t, visible, 1)
+		assert.Nil(t, visible[0].Asker, "the asker stays anonymous")
+		assert.NotNil(t, visible[0].AnsweredAt)
+		require.Len(t, visible[0].Replies, 1)
+		assert.Equal(t, "founder", visible[0].Replies[0].AuthorRole)
+
+		mine := threads(askerToken)
+		require.Len(t, mine, 1)
+		assert.True(t, mine[0].Mine)
+		require.NotNil(t, mine[0].Asker)
+		assert.Equal(t, askerID, mine[0].Asker.ID)
+
+		var count int
+		require.NoError(t, s.GetDB().QueryRow(ctx,
+			`SELECT count(*) FROM notifications WHERE user_id = $1 AND type = 'qa_question_answered'`, askerID).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Q&A does not affect the review", func(t *testing.T) {
+		var allowEdit bool
+		var status string
+		require.NoError(t, s.GetDB().QueryRow(ctx, `SELECT allow_edit, status FROM projects WHERE id = $1`, projectID).Scan(&allowEdit, &status))
+		assert.False(t, allowEdit)
+		assert.Equal(t, "pending", status)
+
+		var comments int
+		require.NoError(t, s.GetDB().QueryRow(ctx, `SELECT count(*) FROM project_comments WHERE project_id = $1`, projectID).Scan(&comments))
+		assert.Zero(t, comments)
+	})
+
+	t.Run("Only admins moderate", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPatch, base+"/"+questionID+"/moderation", founderToken, `{"hidden":true}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPatch, base+"/"+questionID+"/moderation", adminToken, `{"hidden":true}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		assert.Empty(t, threads(otherToken))
+		assert.Empty(t, threads(founderToken))
+		hidden := threads(adminToken)
+		require.Len(t, hidden, 1)
+		assert.NotNil(t, hidden[0].HiddenAt)
+
+		rec = doTestRequest(s, http.MethodPost, base+"/"+questionID+"/replies", founderToken, `{"body":"Still here"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = doTestRequest(s, http.MethodDelete, base+"/"+questionID, adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, threads(adminToken))
+	})
+}
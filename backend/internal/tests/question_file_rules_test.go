@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionFileRules(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "draft")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	// The pitch deck is a single presentation
+	var pitchDeckID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions WHERE question_key = 'pitch_deck_file'`).Scan(&pitchDeckID)
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+
+	createUpload := func(name, mimeType string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"question_id": "%s", "name": "%s", "section": "overview", "sub_section": "pitch", "mime_type": "%s", "size": 2048}`,
+			pitchDeckID, name, mimeType)
+		return doTestRequest(s, http.MethodPost, fmt.Sprintf("/api/v1/project/%s/documents/uploads", projectID), founderToken, body)
+	}
+
+	t.Run("Questions describe the files they accept", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/project/questions", founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response struct {
+			FileRules        []v1_projects.QuestionFileRulesResponse `json:"file_rules"`
+			DefaultFileRules v1_projects.QuestionFileRulesResponse   `json:"default_file_rules"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Zero(t, response.DefaultFileRules.MaxFiles)
+		assert.Contains(t, response.DefaultFileRules.AllowedTypes, "application/pdf")
+
+		var pitchDeck *v1_projects.QuestionFileRulesResponse
+		for i := range response.FileRules {
+			if response.FileRules[i].QuestionID == pitchDeckID {
+				pitchDeck = &response.FileRules[i]
+			}
+		}
+		require.NotNil(t, pitchDeck)
+		assert.Equal(t, int64(1), pitchDeck.MaxFiles)
+		assert.Equal(t, int64(100*1024*1024), pitchDeck.MaxSize)
+		assert.NotContains(t, pitchDeck.AllowedTypes, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	})
+
+	t.Run("Files follow the types of the question", func(t *testing.T) {
+		rec := createUpload("deck.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Files follow the count of the question", func(t *testing.T) {
+		rec := createUpload("deck.pdf", "application/pdf")
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		// The upload in progress already answers the question
+		rec = createUpload("other.pdf", "application/pdf")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 1")
+	})
+
+	t.Run("Infected files don't answer the question", func(t *testing.T) {
+		_, err := s.GetDB().Exec(ctx, `DELETE FROM document_uploads WHERE project_id = $1`, projectID)
+		require.NoError(t, err)
+		_, err = s.GetDB().Exec(ctx, `
+			INSERT INTO project_documents (project_id, question_id, name, storage_key, section, sub_section, mime_type, size, scan_status)
+			VALUES ($1, $2, 'deck.pdf', 'projects/deck.pdf', 'overview', 'pitch', 'application/pdf', 2048, 'infected')
+		`, projectID, pitchDeckID)
+		require.NoError(t, err)
+
+		// The founder replaces the deck the scan deleted
+		rec := createUpload("clean.pdf", "application/pdf")
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+}
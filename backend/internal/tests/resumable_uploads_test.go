@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_projects"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumableDocumentUploads(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+	const mb = 1 << 20
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "draft")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	// The business plan allows files larger than the project documents route, and any number of them
+	var businessPlanID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions WHERE question_key = 'business_plan_file'`).Scan(&businessPlanID)
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+
+	resumableURL := fmt.Sprintf("/api/v1/project/%s/documents/resumable", projectID)
+	create := func(mimeType string, size int) (*httptest.ResponseRecorder, v1_projects.ResumableUploadResponse) {
+		body := fmt.Sprintf(`{"question_id": "%s", "name": "plan.pdf", "section": "overview", "sub_section": "business", "mime_type": "%s", "size": %d}`,
+			businessPlanID, mimeType, size)
+		rec := doTestRequest(s, http.MethodPost, resumableURL, founderToken, body)
+		var upload v1_projects.ResumableUploadResponse
+		if rec.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &upload))
+		}
+		return rec, upload
+	}
+	sendChunk := func(uploadID string, offset int, chunk []byte) *httptest.ResponseRecorder {
+		headers := map[string]string{"Upload-Offset": strconv.Itoa(offset), echo.HeaderContentType: "application/offset+octet-stream"}
+		return doTestRequestWithHeaders(s, http.MethodPatch, fmt.Sprintf("%s/%s", resumableURL, uploadID), founderToken, headers, string(chunk))
+	}
+	complete := func(uploadID string) *httptest.ResponseRecorder {
+		return doTestRequest(s, http.MethodPost, fmt.Sprintf("/api/v1/project/%s/documents/uploads/%s/complete", projectID, uploadID), founderToken, "")
+	}
+
+	t.Run("Files follow the limit of the question", func(t *testing.T) {
+		rec, _ := create("application/pdf", 200*mb)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+		rec, _ = create("application/zip", 2*mb)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("An interrupted upload resumes from its offset", func(t *testing.T) {
+		content := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte(" "), 20*mb)...)
+
+		rec, upload := create("application/pdf", len(content))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Equal(t, int64(0), upload.Offset)
+		assert.Equal(t, int64(len(content)), upload.Size)
+		chunkSize := int(upload.ChunkSize)
+		require.Less(t, chunkSize, len(content))
+
+		rec = sendChunk(upload.ID, 1024, content[1024:1024+chunkSize])
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("Upload-Offset"))
+
+		rec = sendChunk(upload.ID, 0, content[:chunkSize-1])
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = sendChunk(upload.ID, 0, content[:chunkSize])
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, strconv.Itoa(chunkSize), rec.Header().Get("Upload-Offset"))
+
+		// A retry of a chunk that was stored gets the offset to resume from
+		rec = sendChunk(upload.ID, 0, content[:chunkSize])
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, strconv.Itoa(chunkSize), rec.Header().Get("Upload-Offset"))
+
+		rec = complete(upload.ID)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, fmt.Sprintf("%s/%s", resumableURL, upload.ID), founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var status v1_projects.ResumableUploadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		assert.Equal(t, int64(chunkSize), status.Offset)
+
+		for offset := int(status.Offset); offset < len(content); offset += chunkSize {
+			end := min(offset+chunkSize, len(content))
+			rec = sendChunk(upload.ID, offset, content[offset:end])
+			require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		}
+
+		rec = complete(upload.ID)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var doc v1_projects.DocumentResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+		var key string
+		err := s.GetDB().QueryRow(ctx, `SELECT storage_key FROM project_documents WHERE id = $1`, doc.ID).Scan(&key)
+		require.NoError(t, err)
+		stored, err := s.GetStorage().DownloadFile(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(content, stored))
+
+		var uploads int
+		err = s.GetDB().QueryRow(ctx, `SELECT count(*) FROM document_uploads WHERE id = $1`, upload.ID).Scan(&uploads)
+		require.NoError(t, err)
+		assert.Zero(t, uploads)
+	})
+
+	t.Run("Assembled files are validated like uploads through the server", func(t *testing.T) {
+		content := []byte("<html><body>" + strings.Repeat("not a deck ", 200) + "</body></html>")
+
+		rec, upload := create("application/pdf", len(content))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		rec = sendChunk(upload.ID, 0, content)
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		rec = complete(upload.ID)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var uploads int
+		err := s.GetDB().QueryRow(ctx, `SELECT count(*) FROM document_uploads WHERE id = $1`, upload.ID).Scan(&uploads)
+		require.NoError(t, err)
+		assert.Zero(t, uploads)
+	})
+
+	t.Run("A cancelled upload is discarded", func(t *testing.T) {
+		rec, upload := create("application/pdf", 2*mb)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		rec = doTestRequest(s, http.MethodDelete, fmt.Sprintf("%s/%s", resumableURL, upload.ID), founderToken, "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, fmt.Sprintf("%s/%s", resumableURL, upload.ID), founderToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProjects(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	// The search index is kept up to date by triggers, so plain inserts are searchable right away
+	solarID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, solarID, companyID, "Sunbeam Solar", "Affordable <b>rooftop</b> panels", "pending")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, solarID)
+
+	farmID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`, farmID, companyID, "Harvest Hub", "Marketplace for local farmers", "draft")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, farmID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	search := func(token string, params url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/project/search?"+params.Encode(), nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		s.GetEcho().ServeHTTP(rec, req)
+		return rec
+	}
+
+	type searchResponse struct {
+		Projects []struct {
+			ID      string `json:"id"`
+			Status  string `json:"status"`
+			Snippet string `json:"snippet"`
+		} `json:"projects"`
+		Total int64 `json:"total"`
+	}
+
+	t.Run("Matches and highlights terms", func(t *testing.T) {
+		rec := search(adminToken, url.Values{"q": {"rooftop"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response searchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Projects, 1)
+		assert.Equal(t, int64(1), response.Total)
+		assert.Equal(t, solarID.String(), response.Projects[0].ID)
+		assert.Contains(t, response.Projects[0].Snippet, "<mark>rooftop</mark>")
+		assert.NotContains(t, response.Projects[0].Snippet, "<b>")
+	})
+
+	t.Run("Pages past the last one keep the total", func(t *testing.T) {
+		rec := search(adminToken, url.Values{"q": {"rooftop"}, "page": {"3"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response searchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Empty(t, response.Projects)
+		assert.Equal(t, int64(1), response.Total)
+	})
+
+	t.Run("Matches answers after they change", func(t *testing.T) {
+		var questionID string
+		err := s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions LIMIT 1`).Scan(&questionID)
+		require.NoError(t, err)
+
+		_, err = s.GetDB().Exec(ctx, `
+			INSERT INTO project_answers (project_id, question_id, answer)
+			VALUES ($1, $2, 'Drones that monitor irrigation')
+		`, farmID, questionID)
+		require.NoError(t, err)
+
+		rec := search(adminToken, url.Values{"q": {"irrigation"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response searchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Projects, 1)
+		assert.Equal(t, farmID.String(), response.Projects[0].ID)
+	})
+
+	t.Run("Filters by status", func(t *testing.T) {
+		rec := search(adminToken, url.Values{"status": {"draft"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response searchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		for _, project := range response.Projects {
+			assert.Equal(t, "draft", project.Status)
+		}
+		assert.NotContains(t, fmt.Sprint(response.Projects), solarID.String())
+	})
+
+	t.Run("Rejects inverted date range", func(t *testing.T) {
+		rec := search(adminToken, url.Values{"created_after": {"200"}, "created_before": {"100"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Founders can't search", func(t *testing.T) {
+		rec := search(founderToken, url.Values{"q": {"solar"}})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
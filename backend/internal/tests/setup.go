@@ -11,8 +11,8 @@ properly boot up and run.
 */
 func setupEnv() {
 	os.Setenv("APP_ENV", common.TEST_ENV)
-	// this is not a real bucket and is just here to allow
-	// the stogare.NewStorage to proceed
+	// tests use the in-memory storage backend, this is not a real bucket and is
+	// just here for the code that still reads it
 	os.Setenv("AWS_S3_BUCKET", "test-bucket")
 	os.Setenv("DB_HOST", "localhost")
 	os.Setenv("DB_PORT", "5433")
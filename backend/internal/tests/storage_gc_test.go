@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageReconciliation(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+	store := s.GetStorage()
+
+	founderID, founderEmail, _, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO projects (id, company_id, title, description, status, allow_edit)
+		VALUES ($1, $2, $3, $4, $5, true)
+	`, projectID, companyID, "Kelp Farms", "Ocean farming", "draft")
+	require.NoError(t, err)
+	defer s.GetDB().Exec(ctx, `DELETE FROM projects WHERE id = $1`, projectID)
+
+	var questionID string
+	err = s.GetDB().QueryRow(ctx, `SELECT id FROM project_questions ORDER BY section_order, question_order LIMIT 1`).Scan(&questionID)
+	require.NoError(t, err)
+
+	// The deck is stored and referenced, the old deck lost its row, the profile picture lost its file
+	deckKey := fmt.Sprintf("projects/%s/documents/deck.pdf", projectID)
+	orphanKey := fmt.Sprintf("projects/%s/documents/old-deck.pdf", projectID)
+	pictureKey := fmt.Sprintf("users/%s/profile-picture/missing.png", founderID)
+
+	_, err = store.UploadFile(ctx, deckKey, "application/pdf", []byte("%PDF-1.4 deck"))
+	require.NoError(t, err)
+	_, err = store.UploadFile(ctx, orphanKey, "application/pdf", []byte("%PDF-1.4 old deck"))
+	require.NoError(t, err)
+
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_documents (id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size)
+		VALUES ($1, $2, $3, 'deck.pdf', $4, 'overview', 'pitch', 'application/pdf', 13)
+	`, uuid.New(), projectID, questionID, deckKey)
+	require.NoError(t, err)
+	_, err = s.GetDB().Exec(ctx, `UPDATE users SET profile_picture_key = $1 WHERE id = $2`, pictureKey, founderID)
+	require.NoError(t, err)
+
+	orphanKeys := func(report service.StorageReconcileReport) []string {
+		keys := make([]string, len(report.Orphans))
+		for i, orphan := range report.Orphans {
+			keys[i] = orphan.Key
+		}
+		return keys
+	}
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		report, err := service.ReconcileStorage(ctx, s.GetQueries(), store, service.StorageReconcileOptions{DryRun: true})
+		require.NoError(t, err)
+
+		assert.Contains(t, orphanKeys(report), orphanKey)
+		assert.NotContains(t, orphanKeys(report), deckKey)
+		assert.Zero(t, report.Deleted)
+
+		var dangling []string
+		for _, reference := range report.Dangling {
+			if reference.RecordID == founderID {
+				dangling = append(dangling, reference.Source+" "+reference.StorageKey)
+			}
+		}
+		assert.Equal(t, []string{"users.profile_picture_key " + pictureKey}, dangling)
+
+		_, err = store.HeadFile(ctx, orphanKey)
+		assert.NoError(t, err)
+	})
+
+	t.Run("young orphans are kept", func(t *testing.T) {
+		report, err := service.ReconcileStorage(ctx, s.GetQueries(), store, service.StorageReconcileOptions{GracePeriod: time.Hour})
+		require.NoError(t, err)
+		assert.Contains(t, orphanKeys(report), orphanKey)
+
+		_, err = store.HeadFile(ctx, orphanKey)
+		assert.NoError(t, err)
+	})
+
+	t.Run("old orphans are deleted", func(t *testing.T) {
+		report, err := service.ReconcileStorage(ctx, s.GetQueries(), store, service.StorageReconcileOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, orphanKeys(report), orphanKey)
+		assert.GreaterOrEqual(t, report.Deleted, 1)
+
+		_, err = store.HeadFile(ctx, orphanKey)
+		assert.Equal(t, storage.ErrFileNotFound, err)
+		_, err = store.HeadFile(ctx, deckKey)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_companies"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageQuotas(t *testing.T) {
+	setupEnv()
+	t.Setenv("STORAGE_QUOTA_COMPANY", "10MB")
+	t.Setenv("STORAGE_QUOTA_PROJECT", "0")
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+	const mb = 1 << 20
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, adminEmail, adminPassword, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, questionID, err := insertTestProject(ctx, s, companyID, "draft")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+
+	documentID := uuid.New()
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_documents (id, project_id, question_id, name, storage_key, section, sub_section, mime_type, size)
+		VALUES ($1, $2, $3, 'deck.pdf', $4, 'overview', 'pitch', 'application/pdf', $5),
+		       (gen_random_uuid(), $2, $3, 'office.jpg', $6, 'overview', 'pitch', 'image/jpeg', $7)
+	`, documentID, projectID, questionID, fmt.Sprintf("projects/%s/documents/deck.pdf", projectID), 4*mb,
+		fmt.Sprintf("projects/%s/documents/office/full.jpg", projectID), 1*mb)
+	require.NoError(t, err)
+
+	// The history of a deleted document and a copy stamped for an investor stay in storage
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO project_document_versions (document_id, project_id, question_id, version, name, url, mime_type, size)
+		VALUES (gen_random_uuid(), $1, $2, 1, 'old.pdf', $3, 'application/pdf', $4)
+	`, projectID, questionID, fmt.Sprintf("projects/%s/documents/old.pdf", projectID), 1*mb)
+	require.NoError(t, err)
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO document_watermarks (document_id, user_id, document_version, storage_key, size)
+		VALUES ($1, $2, 1, $3, $4)
+	`, documentID, founderID, fmt.Sprintf("projects/%s/watermarks/deck.pdf", projectID), 1*mb)
+	require.NoError(t, err)
+
+	_, err = s.GetDB().Exec(ctx, `
+		INSERT INTO team_members (company_id, first_name, last_name, title, linkedin_url, commitment_type, introduction, industry_experience, detailed_biography, resume_internal_key, resume_internal_size)
+		VALUES ($1, 'Ada', 'Lovelace', 'CTO', 'https://linkedin.com/in/ada', 'Full-time', 'Intro', 'Tech', 'Bio', 'member/1/documents/resume/cv.pdf', $2)
+	`, companyID, 2*mb)
+	require.NoError(t, err)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	adminToken := loginAndGetToken(t, s, adminEmail, adminPassword)
+
+	uploadsURL := fmt.Sprintf("/api/v1/project/%s/documents/uploads", projectID)
+	uploadBody := func(size int, documentID string) string {
+		return fmt.Sprintf(`{"question_id": "%s", "document_id": "%s", "name": "plan.pdf", "section": "overview", "sub_section": "pitch", "mime_type": "application/pdf", "size": %d}`,
+			questionID, documentID, size)
+	}
+
+	t.Run("founders see the usage of their company", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/company/storage", founderToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var usage v1_companies.CompanyStorageUsageResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usage))
+		assert.Equal(t, companyID, usage.CompanyID)
+		assert.Equal(t, int64(4*mb), usage.Documents)
+		assert.Equal(t, int64(1*mb), usage.Images)
+		assert.Equal(t, int64(1*mb), usage.Versions)
+		assert.Equal(t, int64(1*mb), usage.Watermarks)
+		assert.Equal(t, int64(2*mb), usage.TeamDocuments)
+		// The watermarked copy isn't charged to the company
+		assert.Equal(t, int64(8*mb), usage.Used)
+		assert.Equal(t, int64(10*mb), usage.Quota)
+		assert.False(t, usage.CustomQuota)
+		if assert.Len(t, usage.Projects, 1) {
+			assert.Equal(t, projectID, usage.Projects[0].ProjectID)
+			assert.Equal(t, int64(6*mb), usage.Projects[0].Used)
+		}
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/company/admin/storage", founderToken, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("uploads over the quota are rejected", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, uploadsURL, founderToken, uploadBody(4*mb, ""))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "storage quota")
+
+		// The previous versions stay in storage, a new version doesn't free them
+		rec = doTestRequest(s, http.MethodPost, uploadsURL, founderToken, uploadBody(3*mb, documentID.String()))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPost, uploadsURL, founderToken, uploadBody(2*mb, documentID.String()))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		// The pending upload reserves its size
+		rec = doTestRequest(s, http.MethodPost, uploadsURL, founderToken, uploadBody(1*mb, ""))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("admins change the quota of a company", func(t *testing.T) {
+		quotaURL := fmt.Sprintf("/api/v1/company/admin/%s/storage-quota", companyID)
+		rec := doTestRequest(s, http.MethodPut, quotaURL, adminToken, fmt.Sprintf(`{"quota": %d}`, 20*mb))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var usage v1_companies.CompanyStorageUsageResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usage))
+		assert.Equal(t, int64(20*mb), usage.Quota)
+		assert.True(t, usage.CustomQuota)
+
+		rec = doTestRequest(s, http.MethodPost, uploadsURL, founderToken, uploadBody(2*mb, ""))
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/company/admin/storage", adminToken, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var report v1_companies.StorageReportResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, int64(10*mb), report.DefaultQuota)
+		var found bool
+		for _, company := range report.Companies {
+			if company.CompanyID == companyID {
+				found = true
+				assert.Equal(t, int64(20*mb), company.Quota)
+				assert.Equal(t, int64(4*mb), company.PendingUploads)
+				assert.Empty(t, company.Projects)
+			}
+		}
+		assert.True(t, found)
+
+		rec = doTestRequest(s, http.MethodPut, quotaURL, adminToken, `{"quota": null}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usage))
+		assert.Equal(t, int64(10*mb), usage.Quota)
+		assert.False(t, usage.CustomQuota)
+
+		rec = doTestRequest(s, http.MethodPut, quotaURL, adminToken, `{"quota": -1}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_notifications"
+	"KonferCA/SPUR/internal/v1/v1_watchlists"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchlistsAndAlerts(t *testing.T) {
+	setupEnv()
+	s := setupTestServer(t)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+
+	founderID, founderEmail, founderPassword, err := createTestUser(ctx, s, permissions.PermStartupOwner)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, founderEmail, s)
+
+	_, investorEmail, investorPassword, err := createTestUser(ctx, s, permissions.PermInvestor)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, investorEmail, s)
+
+	adminID, adminEmail, _, err := createTestAdmin(ctx, s)
+	require.NoError(t, err)
+	defer removeTestUser(ctx, adminEmail, s)
+
+	companyID, err := createTestCompany(ctx, s, founderID)
+	require.NoError(t, err)
+	defer removeTestCompany(ctx, companyID, s)
+
+	projectID, _, err := insertTestProject(ctx, s, companyID, "pending")
+	require.NoError(t, err)
+	defer removeTestProject(ctx, projectID, s)
+	defer s.GetDB().Exec(ctx, `DELETE FROM transactions WHERE project_id = $1`, projectID)
+
+	founderToken := loginAndGetToken(t, s, founderEmail, founderPassword)
+	investorToken := loginAndGetToken(t, s, investorEmail, investorPassword)
+
+	notifications := func() v1_notifications.NotificationListResponse {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/notifications?unread_only=true", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response v1_notifications.NotificationListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("Startup owners have no watchlist", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodGet, "/api/v1/watchlist", founderToken, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Watch a project", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/watchlist", investorToken,
+			fmt.Sprintf(`{"project_id":"%s","note":"Strong team"}`, projectID))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = doTestRequest(s, http.MethodPatch, "/api/v1/watchlist/"+projectID, investorToken, `{"note":"Follow up in March"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = doTestRequest(s, http.MethodGet, "/api/v1/watchlist", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var watchlist v1_watchlists.WatchlistResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &watchlist))
+		require.Len(t, watchlist.Items, 1)
+		assert.Equal(t, "Follow up in March", watchlist.Items[0].Note)
+		assert.Equal(t, "0", watchlist.Items[0].FundingTotal)
+	})
+
+	t.Run("Unknown projects can't be watched", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/watchlist", investorToken, fmt.Sprintf(`{"project_id":"%s"}`, uuid.New()))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Save a search", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/saved-searches", investorToken, `{"name":"Everything"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var search v1_watchlists.SavedSearchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &search))
+		assert.True(t, search.Notify)
+		assert.Nil(t, search.Industry)
+
+		rec = doTestRequest(s, http.MethodPut, "/api/v1/saved-searches/"+uuid.New().String(), investorToken, `{"name":"Nothing"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Verified projects and watched changes are notified", func(t *testing.T) {
+		require.NoError(t, service.UpdateProjectStatus(s.GetQueries(), ctx, projectID, adminID, db.ProjectStatusVerified))
+		_, err := s.GetDB().Exec(ctx, `
+			INSERT INTO transactions (project_id, company_id, tx_hash, from_address, to_address, value_amount, created_by)
+			VALUES ($1, $2, 'hash', 'from', 'to', 1500.5, $3)
+		`, projectID, companyID, adminID)
+		require.NoError(t, err)
+
+		require.NoError(t, service.MatchAlerts(ctx, s.GetDB()))
+
+		response := notifications()
+		types := []string{}
+		for _, notification := range response.Notifications {
+			if notification.ProjectID != nil && *notification.ProjectID == projectID {
+				types = append(types, string(notification.Type))
+			}
+		}
+		assert.ElementsMatch(t, []string{"saved_search_match", "watched_project_status", "watched_project_funding"}, types)
+
+		// nothing new is notified on the next run
+		require.NoError(t, service.MatchAlerts(ctx, s.GetDB()))
+		assert.Equal(t, response.Unread, notifications().Unread)
+
+		rec := doTestRequest(s, http.MethodPost, "/api/v1/notifications/read", investorToken, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, int64(0), notifications().Unread)
+	})
+
+	t.Run("Remove from watchlist", func(t *testing.T) {
+		rec := doTestRequest(s, http.MethodDelete, "/api/v1/watchlist/"+projectID, investorToken, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = doTestRequest(s, http.MethodDelete, "/api/v1/watchlist/"+projectID, investorToken, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -4,11 +4,16 @@ import (
 	"KonferCA/SPUR/internal/interfaces"
 	"KonferCA/SPUR/internal/v1/v1_auth"
 	"KonferCA/SPUR/internal/v1/v1_companies"
+	"KonferCA/SPUR/internal/v1/v1_exports"
 	"KonferCA/SPUR/internal/v1/v1_health"
+	"KonferCA/SPUR/internal/v1/v1_messages"
+	"KonferCA/SPUR/internal/v1/v1_notifications"
 	"KonferCA/SPUR/internal/v1/v1_projects"
+	"KonferCA/SPUR/internal/v1/v1_storage"
 	"KonferCA/SPUR/internal/v1/v1_teams"
 	"KonferCA/SPUR/internal/v1/v1_transactions"
 	"KonferCA/SPUR/internal/v1/v1_users"
+	"KonferCA/SPUR/internal/v1/v1_watchlists"
 )
 
 func SetupRoutes(s interfaces.CoreServer) {
@@ -16,10 +21,15 @@ func SetupRoutes(s interfaces.CoreServer) {
 	g := e.Group("/api/v1")
 
 	v1_health.SetupHealthcheckRoutes(g, s)
+	v1_messages.SetupMessageRoutes(g, s)
+	v1_notifications.SetupNotificationRoutes(g, s)
 	v1_auth.SetupAuthRoutes(g, s)
 	v1_companies.SetupCompanyRoutes(g, s)
+	v1_exports.SetupExportRoutes(g, s)
 	v1_projects.SetupRoutes(g, s)
+	v1_storage.SetupStorageRoutes(g, s)
 	v1_teams.SetupRoutes(g, s)
 	v1_transactions.SetupTransactionRoutes(g, s)
 	v1_users.SetupUserRoutes(g, s)
+	v1_watchlists.SetupWatchlistRoutes(g, s)
 }
@@ -186,11 +186,12 @@ func (h *Handler) handleRegister(c echo.Context) error {
 		AccessToken: accessToken,
 		CompanyId:   &company.ID,
 		User: UserResponse{
-			ID:                newUser.ID,
-			Email:             newUser.Email,
-			EmailVerified:     newUser.EmailVerified,
-			Permissions:       uint32(newUser.Permissions),
-			ProfilePictureUrl: newUser.ProfilePictureUrl,
+			ID:                 newUser.ID,
+			Email:              newUser.Email,
+			EmailVerified:      newUser.EmailVerified,
+			Permissions:        uint32(newUser.Permissions),
+			ProfilePictureUrl:  v1_common.OptionalSignedFileURL(c.Request().Context(), h.server.GetStorage(), newUser.ProfilePictureKey),
+			ProfilePictureUrls: v1_common.OptionalSignedImageURLs(c.Request().Context(), h.server.GetStorage(), newUser.ProfilePictureKey),
 		},
 	})
 }
@@ -247,13 +248,14 @@ func (h *Handler) handleLogin(c echo.Context) error {
 		AccessToken: accessToken,
 		CompanyId:   companyId,
 		User: UserResponse{
-			ID:                user.ID,
-			FirstName:         user.FirstName,
-			LastName:          user.LastName,
-			Email:             user.Email,
-			EmailVerified:     user.EmailVerified,
-			Permissions:       uint32(user.Permissions),
-			ProfilePictureUrl: user.ProfilePictureUrl,
+			ID:                 user.ID,
+			FirstName:          user.FirstName,
+			LastName:           user.LastName,
+			Email:              user.Email,
+			EmailVerified:      user.EmailVerified,
+			Permissions:        uint32(user.Permissions),
+			ProfilePictureUrl:  v1_common.OptionalSignedFileURL(c.Request().Context(), h.server.GetStorage(), user.ProfilePictureKey),
+			ProfilePictureUrls: v1_common.OptionalSignedImageURLs(c.Request().Context(), h.server.GetStorage(), user.ProfilePictureKey),
 		},
 	})
 }
@@ -472,13 +474,14 @@ func (h *Handler) handleVerifyCookie(c echo.Context) error {
 		AccessToken: accessToken,
 		CompanyId:   companyId,
 		User: UserResponse{
-			ID:                user.ID,
-			FirstName:         user.FirstName,
-			LastName:          user.LastName,
-			Email:             user.Email,
-			EmailVerified:     user.EmailVerified,
-			Permissions:       uint32(user.Permissions),
-			ProfilePictureUrl: user.ProfilePictureUrl,
+			ID:                 user.ID,
+			FirstName:          user.FirstName,
+			LastName:           user.LastName,
+			Email:              user.Email,
+			EmailVerified:      user.EmailVerified,
+			Permissions:        uint32(user.Permissions),
+			ProfilePictureUrl:  v1_common.OptionalSignedFileURL(c.Request().Context(), h.server.GetStorage(), user.ProfilePictureKey),
+			ProfilePictureUrls: v1_common.OptionalSignedImageURLs(c.Request().Context(), h.server.GetStorage(), user.ProfilePictureKey),
 		},
 	})
 }
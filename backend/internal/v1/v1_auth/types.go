@@ -39,6 +39,8 @@ type UserResponse struct {
 	EmailVerified     bool    `json:"email_verified"`
 	Permissions       uint32  `json:"permissions"`
 	ProfilePictureUrl *string `json:"profile_picture_url"`
+	// ProfilePictureUrls has a URL per size of the picture, see service.ImageVariants
+	ProfilePictureUrls map[string]string `json:"profile_picture_urls,omitempty"`
 }
 
 type ForgotPasswordRequest struct {
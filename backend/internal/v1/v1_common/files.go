@@ -0,0 +1,74 @@
+package v1_common
+
+import (
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/storage"
+	"context"
+	"path"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileURLExpiry is how long the signed file URLs in responses stay valid
+const FileURLExpiry = 15 * time.Minute
+
+/*
+SignedFileURL resolves the storage key of a file to a short-lived download URL for a response,
+the file is saved as filename, or under its key when filename is empty. Handlers only call it
+once the caller is allowed to read the resource the file belongs to.
+
+Returns "" when there is no file. When the URL can't be signed the error is logged and ""
+is returned, one file never fails a whole response.
+*/
+func SignedFileURL(ctx context.Context, store storage.Storage, key string, filename string) string {
+	if key == "" {
+		return ""
+	}
+	if filename == "" {
+		filename = path.Base(key)
+	}
+
+	url, err := store.GetSignedDownloadURL(ctx, key, filename, false, FileURLExpiry)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("Failed to sign file URL.")
+		return ""
+	}
+	return url
+}
+
+// OptionalSignedFileURL is SignedFileURL for optional files, nil when there is no file
+func OptionalSignedFileURL(ctx context.Context, store storage.Storage, key *string) *string {
+	if key == nil || *key == "" {
+		return nil
+	}
+	if url := SignedFileURL(ctx, store, *key, ""); url != "" {
+		return &url
+	}
+	return nil
+}
+
+/*
+SignedImageURLs resolves the key of an image stored with service.StoreImage to a signed URL per
+size, see service.ImageVariants. Images stored before they were processed have the same URL for
+every size.
+*/
+func SignedImageURLs(ctx context.Context, store storage.Storage, key string) map[string]string {
+	if key == "" {
+		return nil
+	}
+
+	urls := make(map[string]string, len(service.ImageVariants))
+	for _, variant := range service.ImageVariants {
+		urls[variant.Name] = SignedFileURL(ctx, store, service.ImageVariantKey(key, variant.Name), "")
+	}
+	return urls
+}
+
+// OptionalSignedImageURLs is SignedImageURLs for optional images, nil when there is no image
+func OptionalSignedImageURLs(ctx context.Context, store storage.Storage, key *string) map[string]string {
+	if key == nil {
+		return nil
+	}
+	return SignedImageURLs(ctx, store, *key)
+}
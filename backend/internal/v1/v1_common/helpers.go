@@ -80,6 +80,25 @@ func Fail(c echo.Context, code int, publicErrMsg string, internalErr error) erro
 	}
 }
 
+/*
+FailWithData is Fail with structured details, data is returned as JSON in the data field of
+the response.
+*/
+func FailWithData(c echo.Context, code int, publicErrMsg string, data interface{}, internalErr error) error {
+	c.Set("internal_error", internalErr)
+	if publicErrMsg == "" {
+		publicErrMsg = http.StatusText(code)
+	}
+
+	return &APIError{
+		Type:    DetermineErrorType(code),
+		Message: publicErrMsg,
+		Details: GetErrorDetails(internalErr),
+		Data:    data,
+		Code:    code,
+	}
+}
+
 /*
 Helper function to determine the error type based on the http status code.
 
@@ -20,12 +20,14 @@ type APISuccess struct {
 }
 
 /*
-Use this for any API response that needs a message and a request_id.
+Use this for any API response that needs a message and a request_id. Data holds structured
+details that clients can act on, e.g. the issues found in an uploaded file.
 */
 type APIError struct {
-	Type      ErrorType `json:"type"`
-	Message   string    `json:"message"`
-	Details   string    `json:"details,omitempty"`
-	RequestID string    `json:"request_id,omitempty"`
-	Code      int       `json:"code"`
+	Type      ErrorType   `json:"type"`
+	Message   string      `json:"message"`
+	Details   string      `json:"details,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Code      int         `json:"code"`
 }
@@ -37,6 +37,19 @@ func SetupCompanyRoutes(e *echo.Group, s interfaces.CoreServer) {
 		middleware.Auth(s.GetDB(), permissions.PermAdmin),
 	)
 
+	// Storage used by the own company, per project
+	// Auth: Startup owners only
+	companies.GET("/company/storage", h.handleGetStorageUsage,
+		middleware.Auth(s.GetDB(), permissions.PermSubmitProject),
+	)
+
+	// Admin routes for the storage used by every company and their quotas
+	// Auth: Admins only
+	adminStorage := companies.Group("/company/admin", middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+	adminStorage.GET("/storage", h.handleGetStorageReport)
+	adminStorage.GET("/:id/storage", h.handleGetCompanyStorageUsage)
+	adminStorage.PUT("/:id/storage-quota", h.handleUpdateStorageQuota)
+
 	// Setup all the routes for getting a single company
 	// Auth: Admins only
 	companies.GET("/project/:id/company", h.handleGetCompanyByProject,
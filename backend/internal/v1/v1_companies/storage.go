@@ -0,0 +1,169 @@
+package v1_companies
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+func storageUsageResponse(usage service.StorageUsage) StorageUsageResponse {
+	return StorageUsageResponse{
+		Documents:      usage.Documents,
+		Images:         usage.Images,
+		Versions:       usage.Versions,
+		Watermarks:     usage.Watermarks,
+		TeamDocuments:  usage.TeamDocuments,
+		PendingUploads: usage.PendingUploads,
+		Used:           usage.Used(),
+		Quota:          usage.Quota,
+	}
+}
+
+func companyStorageUsageResponse(usage service.CompanyStorageUsage) CompanyStorageUsageResponse {
+	response := CompanyStorageUsageResponse{
+		CompanyID:            usage.CompanyID,
+		CompanyName:          usage.CompanyName,
+		StorageUsageResponse: storageUsageResponse(usage.StorageUsage),
+		CustomQuota:          usage.CustomQuota,
+	}
+	for _, project := range usage.Projects {
+		response.Projects = append(response.Projects, ProjectStorageUsageResponse{
+			ProjectID:            project.ProjectID,
+			Title:                project.Title,
+			StorageUsageResponse: storageUsageResponse(project.StorageUsage),
+		})
+	}
+	return response
+}
+
+/*
+ * handleGetStorageUsage returns the storage used by the company of the user, per project.
+ * Endpoint: GET /company/storage
+ * Response: CompanyStorageUsageResponse
+ */
+func (h *Handler) handleGetStorageUsage(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	company, err := h.server.GetQueries().GetCompanyByOwnerID(ctx, user.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+	}
+
+	usage, err := service.GetCompanyStorageUsage(ctx, h.server.GetQueries(), company.ID, service.StorageQuotasFromEnv())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get storage usage", err)
+	}
+
+	return c.JSON(http.StatusOK, companyStorageUsageResponse(usage))
+}
+
+/*
+ * handleGetStorageReport returns the storage used by every company, the largest first.
+ * Endpoint: GET /company/admin/storage
+ * Response: StorageReportResponse
+ */
+func (h *Handler) handleGetStorageReport(c echo.Context) error {
+	quotas := service.StorageQuotasFromEnv()
+	usages, err := service.ListCompanyStorageUsage(c.Request().Context(), h.server.GetQueries(), quotas)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get storage usage", err)
+	}
+
+	response := StorageReportResponse{
+		DefaultQuota: quotas.Company,
+		Companies:    make([]CompanyStorageUsageResponse, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		response.Companies = append(response.Companies, companyStorageUsageResponse(usage))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleGetCompanyStorageUsage returns the storage used by any company, per project.
+ * Endpoint: GET /company/admin/:id/storage
+ * Response: CompanyStorageUsageResponse
+ */
+func (h *Handler) handleGetCompanyStorageUsage(c echo.Context) error {
+	companyID := c.Param("id")
+	if _, err := uuid.Parse(companyID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid company id", err)
+	}
+
+	usage, err := service.GetCompanyStorageUsage(c.Request().Context(), h.server.GetQueries(), companyID, service.StorageQuotasFromEnv())
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get storage usage", err)
+	}
+
+	return c.JSON(http.StatusOK, companyStorageUsageResponse(usage))
+}
+
+/*
+ * handleUpdateStorageQuota sets the storage quota of a company, a null quota resets it to the
+ * default. Files already stored are kept when the quota is lowered below the usage, only new
+ * uploads are rejected.
+ * Endpoint: PUT /company/admin/:id/storage-quota
+ * Request body: UpdateStorageQuotaRequest
+ * Response: CompanyStorageUsageResponse
+ */
+func (h *Handler) handleUpdateStorageQuota(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	companyID := c.Param("id")
+	if _, err := uuid.Parse(companyID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid company id", err)
+	}
+
+	var req UpdateStorageQuotaRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	if _, err := queries.GetCompanyByID(ctx, companyID); err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get company", err)
+	}
+
+	if req.Quota == nil {
+		err = queries.DeleteCompanyStorageQuota(ctx, companyID)
+	} else {
+		_, err = queries.UpsertCompanyStorageQuota(ctx, db.UpsertCompanyStorageQuotaParams{
+			CompanyID: companyID,
+			Quota:     *req.Quota,
+			UpdatedBy: pgtype.UUID{Bytes: uuid.MustParse(user.ID), Valid: true},
+		})
+	}
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update storage quota", err)
+	}
+
+	usage, err := service.GetCompanyStorageUsage(ctx, queries, companyID, service.StorageQuotasFromEnv())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get storage usage", err)
+	}
+
+	return c.JSON(http.StatusOK, companyStorageUsageResponse(usage))
+}
@@ -58,3 +58,57 @@ CompaniesResponse represents the response body for a list of companies.
 type CompaniesResponse struct {
 	Companies []CompanyResponse `json:"companies"`
 }
+
+/*
+StorageUsageResponse is the storage used by a company or a project, in bytes. Versions are the
+files of previous versions and deleted documents. Watermarks are the copies stamped for investors,
+they are not part of used. Pending uploads are direct uploads in progress, their size is reserved.
+A quota of 0 means there is no limit.
+*/
+type StorageUsageResponse struct {
+	Documents      int64 `json:"documents"`
+	Images         int64 `json:"images"`
+	Versions       int64 `json:"versions"`
+	Watermarks     int64 `json:"watermarks"`
+	TeamDocuments  int64 `json:"team_documents"`
+	PendingUploads int64 `json:"pending_uploads"`
+	Used           int64 `json:"used"`
+	Quota          int64 `json:"quota"`
+}
+
+/*
+ProjectStorageUsageResponse is the storage used by one project of a company.
+*/
+type ProjectStorageUsageResponse struct {
+	ProjectID string `json:"project_id"`
+	Title     string `json:"title"`
+	StorageUsageResponse
+}
+
+/*
+CompanyStorageUsageResponse is the storage used by a company. CustomQuota is set when an admin
+changed the quota, the default applies otherwise. Projects are only listed for a single company.
+*/
+type CompanyStorageUsageResponse struct {
+	CompanyID   string `json:"company_id"`
+	CompanyName string `json:"company_name"`
+	StorageUsageResponse
+	CustomQuota bool                          `json:"custom_quota"`
+	Projects    []ProjectStorageUsageResponse `json:"projects,omitempty"`
+}
+
+/*
+StorageReportResponse is the storage used by every company, the largest first.
+*/
+type StorageReportResponse struct {
+	DefaultQuota int64                         `json:"default_quota"`
+	Companies    []CompanyStorageUsageResponse `json:"companies"`
+}
+
+/*
+UpdateStorageQuotaRequest sets the storage quota of a company in bytes, 0 removes the limit.
+A null quota resets the company to the default quota.
+*/
+type UpdateStorageQuotaRequest struct {
+	Quota *int64 `json:"quota" validate:"omitempty,min=0"`
+}
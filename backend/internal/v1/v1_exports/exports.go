@@ -0,0 +1,204 @@
+package v1_exports
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// maxListedExports is the number of most recent export jobs returned by the list endpoint
+const maxListedExports = 50
+
+var exportContentTypes = map[db.ExportFormat]string{
+	db.ExportFormatCsv:  "text/csv; charset=utf-8",
+	db.ExportFormatJson: echo.MIMEApplicationJSONCharsetUTF8,
+	db.ExportFormatXlsx: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func exportJobToResponse(job db.GetExportJobRow) ExportJobResponse {
+	statuses := job.Statuses
+	if statuses == nil {
+		statuses = []db.ProjectStatus{}
+	}
+
+	return ExportJobResponse{
+		ID:          job.ID,
+		RequestedBy: job.RequestedBy,
+		Format:      job.Format,
+		Statuses:    statuses,
+		Status:      job.Status,
+		RowCount:    job.RowCount,
+		FileName:    job.FileName,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+}
+
+/*
+ * getExportJobID reads and validates the export job id path parameter
+ */
+func getExportJobID(c echo.Context) (string, error) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", v1_common.NewValidationError("Invalid export id")
+	}
+	return id, nil
+}
+
+/*
+ * handleCreateExport starts a program wide export of projects, their companies and answers.
+ * The export runs in the background, poll the returned job until it is completed and then
+ * download the file.
+ *
+ * body:
+ * - format: csv, json or xlsx
+ * - statuses: optional list of project statuses to export, all projects when empty
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleCreateExport(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req CreateExportRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
+	}
+	if req.Statuses == nil {
+		req.Statuses = []db.ProjectStatus{}
+	}
+
+	queries := h.server.GetQueries()
+	job, err := queries.CreateExportJob(c.Request().Context(), db.CreateExportJobParams{
+		RequestedBy: user.ID,
+		Format:      req.Format,
+		Statuses:    req.Statuses,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create export", err)
+	}
+
+	// the export can take a while for large programs, so it doesn't block the request
+	go service.RunExportJob(queries, job)
+
+	return c.JSON(http.StatusAccepted, exportJobToResponse(db.GetExportJobRow(job)))
+}
+
+/*
+ * handleListExports lists the most recent export jobs, newest first.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleListExports(c echo.Context) error {
+	jobs, err := h.server.GetQueries().ListExportJobs(c.Request().Context(), maxListedExports)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to list exports", err)
+	}
+
+	response := ExportJobListResponse{Exports: make([]ExportJobResponse, len(jobs))}
+	for i, job := range jobs {
+		response.Exports[i] = exportJobToResponse(db.GetExportJobRow(job))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleGetExport returns the status of an export job.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleGetExport(c echo.Context) error {
+	id, err := getExportJobID(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.server.GetQueries().GetExportJob(c.Request().Context(), id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.NewNotFoundError("Export")
+		}
+		return v1_common.NewInternalError(err)
+	}
+
+	return c.JSON(http.StatusOK, exportJobToResponse(job))
+}
+
+/*
+ * handleDownloadExport downloads the file of a completed export job.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleDownloadExport(c echo.Context) error {
+	id, err := getExportJobID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	file, err := queries.GetExportJobFile(ctx, id)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			return v1_common.NewInternalError(err)
+		}
+		if _, err := queries.GetExportJob(ctx, id); err == nil {
+			return v1_common.Fail(c, http.StatusConflict, "Export is not completed", nil)
+		}
+		return v1_common.NewNotFoundError("Export")
+	}
+
+	fileName := fmt.Sprintf("export.%s", file.Format)
+	if file.FileName != nil {
+		fileName = *file.FileName
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	return c.Blob(http.StatusOK, exportContentTypes[file.Format], file.Content)
+}
+
+/*
+ * handleDeleteExport deletes an export job and its file.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleDeleteExport(c echo.Context) error {
+	id, err := getExportJobID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	if _, err := queries.GetExportJob(ctx, id); err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.NewNotFoundError("Export")
+		}
+		return v1_common.NewInternalError(err)
+	}
+
+	if err := queries.DeleteExportJob(ctx, id); err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Export deleted")
+}
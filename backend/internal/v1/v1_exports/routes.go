@@ -0,0 +1,21 @@
+package v1_exports
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupExportRoutes(g *echo.Group, s interfaces.CoreServer) {
+	h := &Handler{server: s}
+
+	// Program data exports - admin only
+	exports := g.Group("/exports", middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+	exports.POST("", h.handleCreateExport)
+	exports.GET("", h.handleListExports)
+	exports.GET("/:id", h.handleGetExport)
+	exports.GET("/:id/download", h.handleDownloadExport)
+	exports.DELETE("/:id", h.handleDeleteExport)
+}
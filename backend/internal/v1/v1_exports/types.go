@@ -0,0 +1,33 @@
+package v1_exports
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/interfaces"
+)
+
+type Handler struct {
+	server interfaces.CoreServer
+}
+
+type CreateExportRequest struct {
+	Format   db.ExportFormat    `json:"format" validate:"required,oneof=csv json xlsx"`
+	Statuses []db.ProjectStatus `json:"statuses" validate:"omitempty,dive,oneof=draft pending verified declined withdrawn 'needs review'"`
+}
+
+type ExportJobResponse struct {
+	ID          string             `json:"id"`
+	RequestedBy string             `json:"requested_by"`
+	Format      db.ExportFormat    `json:"format"`
+	Statuses    []db.ProjectStatus `json:"statuses"`
+	Status      db.ExportJobStatus `json:"status"`
+	RowCount    *int32             `json:"row_count"`
+	FileName    *string            `json:"file_name"`
+	Error       *string            `json:"error"`
+	CreatedAt   int64              `json:"created_at"`
+	StartedAt   *int64             `json:"started_at"`
+	CompletedAt *int64             `json:"completed_at"`
+}
+
+type ExportJobListResponse struct {
+	Exports []ExportJobResponse `json:"exports"`
+}
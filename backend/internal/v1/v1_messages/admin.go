@@ -0,0 +1,121 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * handleAdminListConversations lists every conversation, most recently active first.
+ * Only metadata and participants are returned, reading messages is audited separately.
+ *
+ * parameters (all optional):
+ * - project_id: only conversations about the project
+ * - user_id: only conversations the user is part of
+ * - page, limit: pagination (default: page 1, 20 per page)
+ *
+ * Security:
+ * - Requires PermIsAdmin
+ */
+func (h *Handler) handleAdminListConversations(c echo.Context) error {
+	var req ListAllConversationsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	conversations, err := queries.ListAllConversations(ctx, db.ListAllConversationsParams{
+		ProjectID:  toUUID(req.ProjectID),
+		UserID:     toUUID(req.UserID),
+		PageSize:   int32(req.Limit),
+		PageOffset: int32((req.Page - 1) * req.Limit),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get conversations", err)
+	}
+
+	var participants []db.ListConversationParticipantsRow
+	if len(conversations) > 0 {
+		conversationIDs := make([]string, len(conversations))
+		for i, conversation := range conversations {
+			conversationIDs[i] = conversation.ID
+		}
+		participants, err = queries.ListConversationParticipants(ctx, conversationIDs)
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get participants", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, ConversationListResponse{
+		Conversations: buildConversationResponses(conversations, nil, participants),
+	})
+}
+
+/*
+ * handleAdminReadConversation lets an admin read a conversation they are not part of,
+ * e.g. to settle a dispute. Every read is recorded with the admin and the given reason.
+ *
+ * parameters:
+ * - reason: why the conversation is read (required)
+ * - page, limit: pagination (default: page 1, 50 per page)
+ *
+ * Security:
+ * - Requires PermIsAdmin
+ */
+func (h *Handler) handleAdminReadConversation(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req AdminReadConversationRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "A reason is required to read a conversation", err)
+	}
+
+	conversationID := c.Param("id")
+	if _, err := uuid.Parse(conversationID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid conversation id", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	conversation, err := queries.GetConversation(ctx, conversationID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Conversation not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get conversation", err)
+	}
+
+	err = queries.CreateConversationAdminRead(ctx, db.CreateConversationAdminReadParams{
+		ConversationID: conversation.ID,
+		AdminID:        user.ID,
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to record read", err)
+	}
+
+	response, err := messagePage(ctx, queries, h.server.GetStorage(), conversation, req.Page, req.Limit)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get messages", err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
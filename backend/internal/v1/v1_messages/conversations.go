@@ -0,0 +1,352 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+func isAdmin(user *db.User) bool {
+	return permissions.HasPermission(uint32(user.Permissions), permissions.PermIsAdmin)
+}
+
+func optionalUUID(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	s := uuid.UUID(id.Bytes).String()
+	return &s
+}
+
+func toUUID(id string) pgtype.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
+
+/*
+ * relatedInvestor returns the id of the investor when one of the users owns the project
+ * and the other one is an investor. The caller still has to check that the investor
+ * watches or funded the project.
+ */
+func relatedInvestor(sender *db.User, recipient *db.User, ownerID string) (string, bool) {
+	canViewProjects := func(user *db.User) bool {
+		return permissions.HasPermission(uint32(user.Permissions), permissions.PermViewAllProjects)
+	}
+
+	switch {
+	case sender.ID == ownerID && recipient.ID != ownerID && canViewProjects(recipient):
+		return recipient.ID, true
+	case recipient.ID == ownerID && sender.ID != ownerID && canViewProjects(sender):
+		return sender.ID, true
+	}
+	return "", false
+}
+
+/*
+ * canStartConversation checks that two users have a legitimate relationship.
+ * Admins can talk to anyone. Otherwise the conversation must be about a project,
+ * one side must own it and the other must be an investor watching or funding it.
+ */
+func canStartConversation(queries *db.Queries, ctx context.Context, sender *db.User, recipient *db.User, projectID string) (bool, error) {
+	if isAdmin(sender) || isAdmin(recipient) {
+		return true, nil
+	}
+	if projectID == "" {
+		return false, nil
+	}
+
+	company, err := queries.GetCompanyByProjectID(ctx, projectID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	investorID, ok := relatedInvestor(sender, recipient, company.OwnerID)
+	if !ok {
+		return false, nil
+	}
+
+	return queries.HasProjectRelationship(ctx, db.HasProjectRelationshipParams{
+		ProjectID:  projectID,
+		InvestorID: investorID,
+	})
+}
+
+/*
+ * buildConversationResponses assembles conversations with their participants.
+ * unread holds the unread message count of the viewer per conversation.
+ */
+func buildConversationResponses(conversations []db.Conversation, unread map[string]int64, participants []db.ListConversationParticipantsRow) []ConversationResponse {
+	byConversation := make(map[string][]ParticipantResponse, len(conversations))
+	for _, participant := range participants {
+		byConversation[participant.ConversationID] = append(byConversation[participant.ConversationID], ParticipantResponse{
+			UserID:     participant.UserID,
+			FirstName:  participant.FirstName,
+			LastName:   participant.LastName,
+			IsAdmin:    permissions.HasPermission(uint32(participant.Permissions), permissions.PermIsAdmin),
+			LastReadAt: participant.LastReadAt,
+		})
+	}
+
+	response := make([]ConversationResponse, len(conversations))
+	for i, conversation := range conversations {
+		response[i] = ConversationResponse{
+			ID:            conversation.ID,
+			ProjectID:     optionalUUID(conversation.ProjectID),
+			Subject:       conversation.Subject,
+			CreatedBy:     optionalUUID(conversation.CreatedBy),
+			Participants:  byConversation[conversation.ID],
+			UnreadCount:   unread[conversation.ID],
+			LastMessageAt: conversation.LastMessageAt,
+			CreatedAt:     conversation.CreatedAt,
+		}
+		if response[i].Participants == nil {
+			response[i].Participants = []ParticipantResponse{}
+		}
+	}
+
+	return response
+}
+
+func (h *Handler) conversationResponse(ctx context.Context, queries *db.Queries, conversation db.Conversation, unread int64) (ConversationResponse, error) {
+	participants, err := queries.ListConversationParticipants(ctx, []string{conversation.ID})
+	if err != nil {
+		return ConversationResponse{}, err
+	}
+
+	return buildConversationResponses([]db.Conversation{conversation}, map[string]int64{conversation.ID: unread}, participants)[0], nil
+}
+
+/*
+ * sendMessage stores a message and moves the read receipt of the sender past it.
+ * It must run in the transaction of the caller.
+ */
+func sendMessage(queries *db.Queries, ctx context.Context, conversationID string, senderID string, body string) (db.Message, error) {
+	message, err := queries.CreateMessage(ctx, db.CreateMessageParams{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Body:           body,
+	})
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	err = queries.TouchConversation(ctx, db.TouchConversationParams{
+		LastMessageAt: message.CreatedAt,
+		ID:            conversationID,
+	})
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	_, err = queries.MarkConversationRead(ctx, db.MarkConversationReadParams{
+		ConversationID: conversationID,
+		UserID:         senderID,
+	})
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	return message, nil
+}
+
+/*
+ * handleStartConversation starts a conversation with another user and sends its first message.
+ * If the two users already have a conversation about the same project, the message is
+ * added to it instead.
+ *
+ * Security:
+ * - Admins can message anyone and anyone can message an admin
+ * - Otherwise the conversation must be about a project, between its owner and an
+ *   investor that watches it or has a transaction on it
+ */
+func (h *Handler) handleStartConversation(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req StartConversationRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Message can not be empty", nil)
+	}
+	if req.RecipientID == user.ID {
+		return v1_common.Fail(c, http.StatusBadRequest, "You can not message yourself", nil)
+	}
+
+	ctx := c.Request().Context()
+
+	recipient, err := h.server.GetQueries().GetUserByID(ctx, req.RecipientID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Recipient not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get recipient", err)
+	}
+
+	allowed, err := canStartConversation(h.server.GetQueries(), ctx, user, &recipient, req.ProjectID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to check relationship", err)
+	}
+	if !allowed {
+		return v1_common.Fail(c, http.StatusForbidden, "You can only message users you have a relationship with", nil)
+	}
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to begin transaction", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+	projectID := toUUID(req.ProjectID)
+	status := http.StatusOK
+
+	conversation, err := queries.FindConversationBetween(ctx, db.FindConversationBetweenParams{
+		ProjectID:    projectID,
+		FirstUserID:  user.ID,
+		SecondUserID: recipient.ID,
+	})
+	if err == pgx.ErrNoRows {
+		status = http.StatusCreated
+		conversation, err = queries.CreateConversation(ctx, db.CreateConversationParams{
+			ProjectID: projectID,
+			Subject:   strings.TrimSpace(req.Subject),
+			CreatedBy: toUUID(user.ID),
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create conversation", err)
+		}
+
+		for _, participantID := range []string{user.ID, recipient.ID} {
+			err = queries.AddConversationParticipant(ctx, db.AddConversationParticipantParams{
+				ConversationID: conversation.ID,
+				UserID:         participantID,
+			})
+			if err != nil {
+				return v1_common.Fail(c, http.StatusInternalServerError, "Failed to add participant", err)
+			}
+		}
+	} else if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to find conversation", err)
+	}
+
+	message, err := sendMessage(queries, ctx, conversation.ID, user.ID, body)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to send message", err)
+	}
+	conversation.LastMessageAt = message.CreatedAt
+
+	response, err := h.conversationResponse(ctx, queries, conversation, 0)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get conversation", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to commit transaction", err)
+	}
+
+	return c.JSON(status, response)
+}
+
+/*
+ * handleListConversations lists the conversations of the user, most recently active first,
+ * with the number of messages the user hasn't read yet.
+ *
+ * Security:
+ * - Users only see the conversations they are part of
+ */
+func (h *Handler) handleListConversations(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	rows, err := queries.ListConversations(ctx, user.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get conversations", err)
+	}
+
+	conversations := make([]db.Conversation, len(rows))
+	conversationIDs := make([]string, len(rows))
+	unread := make(map[string]int64, len(rows))
+	for i, row := range rows {
+		conversations[i] = db.Conversation{
+			ID:            row.ID,
+			ProjectID:     row.ProjectID,
+			Subject:       row.Subject,
+			CreatedBy:     row.CreatedBy,
+			LastMessageAt: row.LastMessageAt,
+			CreatedAt:     row.CreatedAt,
+		}
+		conversationIDs[i] = row.ID
+		unread[row.ID] = row.UnreadCount
+	}
+
+	var participants []db.ListConversationParticipantsRow
+	if len(conversationIDs) > 0 {
+		participants, err = queries.ListConversationParticipants(ctx, conversationIDs)
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get participants", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, ConversationListResponse{
+		Conversations: buildConversationResponses(conversations, unread, participants),
+	})
+}
+
+/*
+ * handleMarkConversationRead moves the read receipt of the user to now,
+ * every message in the conversation counts as read.
+ *
+ * Security:
+ * - Users can only mark conversations they are part of
+ */
+func (h *Handler) handleMarkConversationRead(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	conversationID := c.Param("id")
+	if _, err := uuid.Parse(conversationID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid conversation id", err)
+	}
+
+	rows, err := h.server.GetQueries().MarkConversationRead(c.Request().Context(), db.MarkConversationReadParams{
+		ConversationID: conversationID,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to mark conversation as read", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Conversation not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Conversation marked as read",
+	})
+}
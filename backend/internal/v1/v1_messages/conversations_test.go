@@ -0,0 +1,89 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/storage"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelatedInvestor(t *testing.T) {
+	owner := &db.User{ID: "owner", Permissions: int32(permissions.PermStartupOwner)}
+	investor := &db.User{ID: "investor", Permissions: int32(permissions.PermInvestor)}
+	otherOwner := &db.User{ID: "other", Permissions: int32(permissions.PermStartupOwner)}
+
+	testCases := []struct {
+		name      string
+		sender    *db.User
+		recipient *db.User
+		expected  string
+		ok        bool
+	}{
+		{name: "Investor to owner", sender: investor, recipient: owner, expected: "investor", ok: true},
+		{name: "Owner to investor", sender: owner, recipient: investor, expected: "investor", ok: true},
+		{name: "Owner to another startup owner", sender: owner, recipient: otherOwner},
+		{name: "Investor to another startup owner", sender: investor, recipient: otherOwner},
+		{name: "Owner to themselves", sender: owner, recipient: owner},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			investorID, ok := relatedInvestor(tc.sender, tc.recipient, owner.ID)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, investorID)
+		})
+	}
+}
+
+func TestBuildMessageResponses(t *testing.T) {
+	readAt := int64(100)
+	participants := []db.ListConversationParticipantsRow{
+		{ConversationID: "c", UserID: "alice", LastReadAt: &readAt},
+		{ConversationID: "c", UserID: "bob"},
+		{ConversationID: "c", UserID: "carol", LastReadAt: &readAt},
+	}
+	messages := []db.Message{
+		{ID: "m2", ConversationID: "c", SenderID: "bob", Body: "Later", CreatedAt: 150},
+		{ID: "m1", ConversationID: "c", SenderID: "alice", Body: "Hello", CreatedAt: 100},
+	}
+	attachments := []db.MessageAttachment{
+		{ID: "a1", MessageID: "m1", Name: "deck.pdf", StorageKey: "conversations/c/attachments/a1.pdf", MimeType: "application/pdf", Size: 42},
+	}
+
+	store := storage.NewMemoryStorage("/files/", "secret")
+	response := buildMessageResponses(context.Background(), store, messages, attachments, participants)
+	require.Len(t, response, 2)
+
+	assert.Empty(t, response[0].ReadBy, "nobody read the message yet")
+	assert.NotNil(t, response[0].Attachments)
+	assert.Empty(t, response[0].Attachments)
+
+	assert.Equal(t, []string{"carol"}, response[1].ReadBy, "the sender is not listed")
+	require.Len(t, response[1].Attachments, 1)
+	assert.Equal(t, "deck.pdf", response[1].Attachments[0].Name)
+	assert.True(t, strings.HasPrefix(response[1].Attachments[0].URL, "/files/conversations/c/attachments/a1.pdf?"))
+	assert.Contains(t, response[1].Attachments[0].URL, "signature=")
+}
+
+func TestBuildConversationResponses(t *testing.T) {
+	conversations := []db.Conversation{{ID: "c1", Subject: "Intro"}, {ID: "c2"}}
+	participants := []db.ListConversationParticipantsRow{
+		{ConversationID: "c1", UserID: "admin", Permissions: int32(permissions.PermIsAdmin)},
+		{ConversationID: "c1", UserID: "founder", Permissions: int32(permissions.PermStartupOwner)},
+	}
+
+	response := buildConversationResponses(conversations, map[string]int64{"c1": 3}, participants)
+	require.Len(t, response, 2)
+	assert.Nil(t, response[0].ProjectID)
+	assert.Equal(t, int64(3), response[0].UnreadCount)
+	require.Len(t, response[0].Participants, 2)
+	assert.True(t, response[0].Participants[0].IsAdmin)
+	assert.False(t, response[0].Participants[1].IsAdmin)
+	assert.NotNil(t, response[1].Participants)
+	assert.Zero(t, response[1].UnreadCount)
+}
@@ -0,0 +1,334 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// maxAttachmentsPerMessage limits how many files can be sent with a single message.
+const maxAttachmentsPerMessage = 5
+
+/*
+ * buildMessageResponses assembles messages with their attachments and read receipts.
+ * A message is read by a participant when their last_read_at is at or after the message.
+ * Attachments get signed URLs, the caller must be allowed to read the conversation.
+ */
+func buildMessageResponses(ctx context.Context, store storage.Storage, messages []db.Message, attachments []db.MessageAttachment, participants []db.ListConversationParticipantsRow) []MessageResponse {
+	byMessage := make(map[string][]AttachmentResponse, len(messages))
+	for _, attachment := range attachments {
+		byMessage[attachment.MessageID] = append(byMessage[attachment.MessageID], AttachmentResponse{
+			ID:       attachment.ID,
+			Name:     attachment.Name,
+			URL:      v1_common.SignedFileURL(ctx, store, attachment.StorageKey, attachment.Name),
+			MimeType: attachment.MimeType,
+			Size:     attachment.Size,
+		})
+	}
+
+	response := make([]MessageResponse, len(messages))
+	for i, message := range messages {
+		readBy := []string{}
+		for _, participant := range participants {
+			if participant.ConversationID != message.ConversationID || participant.UserID == message.SenderID {
+				continue
+			}
+			if participant.LastReadAt != nil && *participant.LastReadAt >= message.CreatedAt {
+				readBy = append(readBy, participant.UserID)
+			}
+		}
+
+		response[i] = MessageResponse{
+			ID:             message.ID,
+			ConversationID: message.ConversationID,
+			SenderID:       message.SenderID,
+			Body:           message.Body,
+			Attachments:    byMessage[message.ID],
+			ReadBy:         readBy,
+			CreatedAt:      message.CreatedAt,
+		}
+		if response[i].Attachments == nil {
+			response[i].Attachments = []AttachmentResponse{}
+		}
+	}
+
+	return response
+}
+
+/*
+ * messagePage loads a page of the messages of a conversation, newest first.
+ */
+func messagePage(ctx context.Context, queries *db.Queries, store storage.Storage, conversation db.Conversation, page int, limit int) (MessageListResponse, error) {
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 50
+	}
+
+	total, err := queries.CountMessages(ctx, conversation.ID)
+	if err != nil {
+		return MessageListResponse{}, err
+	}
+
+	messages, err := queries.ListMessages(ctx, db.ListMessagesParams{
+		ConversationID: conversation.ID,
+		PageSize:       int32(limit),
+		PageOffset:     int32((page - 1) * limit),
+	})
+	if err != nil {
+		return MessageListResponse{}, err
+	}
+
+	var attachments []db.MessageAttachment
+	if len(messages) > 0 {
+		messageIDs := make([]string, len(messages))
+		for i, message := range messages {
+			messageIDs[i] = message.ID
+		}
+		attachments, err = queries.ListMessageAttachments(ctx, messageIDs)
+		if err != nil {
+			return MessageListResponse{}, err
+		}
+	}
+
+	participants, err := queries.ListConversationParticipants(ctx, []string{conversation.ID})
+	if err != nil {
+		return MessageListResponse{}, err
+	}
+
+	return MessageListResponse{
+		Conversation: buildConversationResponses([]db.Conversation{conversation}, nil, participants)[0],
+		Messages:     buildMessageResponses(ctx, store, messages, attachments, participants),
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}, nil
+}
+
+/*
+ * getParticipantConversation reads the conversation id path parameter and loads the
+ * conversation if the user is part of it.
+ */
+func (h *Handler) getParticipantConversation(c echo.Context, queries *db.Queries, user *db.User) (db.Conversation, error) {
+	conversationID := c.Param("id")
+	if _, err := uuid.Parse(conversationID); err != nil {
+		return db.Conversation{}, v1_common.Fail(c, http.StatusBadRequest, "Invalid conversation id", err)
+	}
+
+	conversation, err := queries.GetConversationForParticipant(c.Request().Context(), db.GetConversationForParticipantParams{
+		ID:     conversationID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return db.Conversation{}, v1_common.Fail(c, http.StatusNotFound, "Conversation not found", err)
+		}
+		return db.Conversation{}, v1_common.Fail(c, http.StatusInternalServerError, "Failed to get conversation", err)
+	}
+
+	return conversation, nil
+}
+
+/*
+ * handleListMessages lists the messages of a conversation, newest first, with their
+ * attachments and read receipts. Reading messages doesn't mark them as read, the client
+ * calls POST /conversations/:id/read once they are shown.
+ *
+ * parameters (all optional):
+ * - page, limit: pagination (default: page 1, 50 per page)
+ *
+ * Security:
+ * - Users can only read the conversations they are part of
+ */
+func (h *Handler) handleListMessages(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req ListMessagesRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+
+	queries := h.server.GetQueries()
+	conversation, err := h.getParticipantConversation(c, queries, user)
+	if err != nil {
+		return err
+	}
+
+	response, err := messagePage(c.Request().Context(), queries, h.server.GetStorage(), conversation, req.Page, req.Limit)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get messages", err)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleSendMessage sends a message to a conversation.
+ *
+ * Security:
+ * - Users can only send messages to the conversations they are part of
+ */
+func (h *Handler) handleSendMessage(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req SendMessageRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Message can not be empty", nil)
+	}
+
+	ctx := c.Request().Context()
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to begin transaction", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+	conversation, err := h.getParticipantConversation(c, queries, user)
+	if err != nil {
+		return err
+	}
+
+	message, err := sendMessage(queries, ctx, conversation.ID, user.ID, body)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to send message", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to commit transaction", err)
+	}
+
+	return c.JSON(http.StatusCreated, buildMessageResponses(ctx, h.server.GetStorage(), []db.Message{message}, nil, nil)[0])
+}
+
+/*
+ * handleSendAttachments sends a message with files attached. The files are uploaded
+ * to storage before the message is stored and removed again if storing it fails.
+ *
+ * form fields:
+ * - attachments: up to maxAttachmentsPerMessage files
+ * - body: optional text of the message
+ *
+ * Security:
+ * - Users can only send messages to the conversations they are part of
+ * - File size and type are checked by the FileCheck middleware
+ */
+func (h *Handler) handleSendAttachments(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req SendAttachmentsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	form := c.Request().MultipartForm
+	if form == nil || len(form.File["attachments"]) == 0 {
+		return v1_common.Fail(c, http.StatusBadRequest, "At least one attachment is required", nil)
+	}
+	files := form.File["attachments"]
+	if len(files) > maxAttachmentsPerMessage {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("At most %d attachments can be sent at once", maxAttachmentsPerMessage), nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	conversation, err := h.getParticipantConversation(c, queries, user)
+	if err != nil {
+		return err
+	}
+
+	uploaded := make([]db.CreateMessageAttachmentParams, 0, len(files))
+	cleanup := func() {
+		for _, attachment := range uploaded {
+			if err := h.server.GetStorage().DeleteFile(context.Background(), attachment.StorageKey); err != nil {
+				log.Error().Err(err).Str("key", attachment.StorageKey).Msg("Failed to delete message attachment.")
+			}
+		}
+	}
+
+	for _, file := range files {
+		src, err := file.Open()
+		if err != nil {
+			cleanup()
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to open file", err)
+		}
+		content, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			cleanup()
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read file", err)
+		}
+
+		key := fmt.Sprintf("conversations/%s/attachments/%s%s", conversation.ID, uuid.New().String(), filepath.Ext(file.Filename))
+		if _, err := h.server.GetStorage().UploadFile(ctx, key, file.Header.Get("Content-Type"), content); err != nil {
+			cleanup()
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to upload file", err)
+		}
+
+		uploaded = append(uploaded, db.CreateMessageAttachmentParams{
+			Name:       filepath.Base(file.Filename),
+			StorageKey: key,
+			MimeType:   file.Header.Get("Content-Type"),
+			Size:       file.Size,
+		})
+	}
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		cleanup()
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to begin transaction", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	qtx := queries.WithTx(tx)
+	message, err := sendMessage(qtx, ctx, conversation.ID, user.ID, strings.TrimSpace(req.Body))
+	if err != nil {
+		cleanup()
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to send message", err)
+	}
+
+	attachments := make([]db.MessageAttachment, len(uploaded))
+	for i, params := range uploaded {
+		params.MessageID = message.ID
+		attachments[i], err = qtx.CreateMessageAttachment(ctx, params)
+		if err != nil {
+			cleanup()
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to save attachment", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		cleanup()
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to commit transaction", err)
+	}
+
+	return c.JSON(http.StatusCreated, buildMessageResponses(ctx, h.server.GetStorage(), []db.Message{message}, attachments, nil)[0])
+}
@@ -0,0 +1,41 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupMessageRoutes(g *echo.Group, s interfaces.CoreServer) {
+	h := &Handler{server: s}
+
+	// Direct messages - any authenticated user, access is checked per conversation
+	conversations := g.Group("/conversations", middleware.Auth(s.GetDB()))
+	conversations.GET("", h.handleListConversations)
+	conversations.POST("", h.handleStartConversation)
+	conversations.GET("/:id/messages", h.handleListMessages)
+	conversations.POST("/:id/messages", h.handleSendMessage)
+	conversations.POST("/:id/attachments", h.handleSendAttachments, middleware.FileCheck(middleware.FileConfig{
+		MinSize: 1,
+		MaxSize: 10 * 1024 * 1024, // 10MB maximum
+		AllowedTypes: []string{
+			"application/pdf",
+			"application/msword",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			"application/vnd.ms-excel",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"image/jpeg",
+			"image/png",
+		},
+		StrictValidation: true,
+		DeepInspection:   true,
+	}))
+	conversations.POST("/:id/read", h.handleMarkConversationRead)
+
+	// Admins can read any conversation, e.g. when a dispute arises. Reads are recorded.
+	admin := g.Group("/admin/conversations", middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+	admin.GET("", h.handleAdminListConversations)
+	admin.GET("/:id/messages", h.handleAdminReadConversation)
+}
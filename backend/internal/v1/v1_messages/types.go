@@ -0,0 +1,95 @@
+package v1_messages
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+)
+
+type Handler struct {
+	server interfaces.CoreServer
+}
+
+type StartConversationRequest struct {
+	RecipientID string `json:"recipient_id" validate:"required,uuid"`
+	// ProjectID is required unless the sender or the recipient is an admin
+	ProjectID string `json:"project_id" validate:"omitempty,uuid"`
+	Subject   string `json:"subject" validate:"max=255"`
+	Body      string `json:"body" validate:"required,max=10000"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+type SendAttachmentsRequest struct {
+	// Body is optional, the attachments are the message
+	Body string `form:"body" validate:"max=10000"`
+}
+
+type ListMessagesRequest struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ListAllConversationsRequest struct {
+	ProjectID string `query:"project_id" validate:"omitempty,uuid"`
+	UserID    string `query:"user_id" validate:"omitempty,uuid"`
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type AdminReadConversationRequest struct {
+	// Reason is recorded with every read, e.g. the dispute being settled
+	Reason string `query:"reason" validate:"required,max=1000"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ParticipantResponse struct {
+	UserID     string  `json:"user_id"`
+	FirstName  *string `json:"first_name"`
+	LastName   *string `json:"last_name"`
+	IsAdmin    bool    `json:"is_admin"`
+	LastReadAt *int64  `json:"last_read_at"`
+}
+
+type ConversationResponse struct {
+	ID            string                `json:"id"`
+	ProjectID     *string               `json:"project_id"`
+	Subject       string                `json:"subject"`
+	CreatedBy     *string               `json:"created_by"`
+	Participants  []ParticipantResponse `json:"participants"`
+	UnreadCount   int64                 `json:"unread_count"`
+	LastMessageAt int64                 `json:"last_message_at"`
+	CreatedAt     int64                 `json:"created_at"`
+}
+
+type ConversationListResponse struct {
+	Conversations []ConversationResponse `json:"conversations"`
+}
+
+type AttachmentResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+type MessageResponse struct {
+	ID             string               `json:"id"`
+	ConversationID string               `json:"conversation_id"`
+	SenderID       string               `json:"sender_id"`
+	Body           string               `json:"body"`
+	Attachments    []AttachmentResponse `json:"attachments"`
+	// ReadBy lists the other participants that have read the message
+	ReadBy    []string `json:"read_by"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+type MessageListResponse struct {
+	Conversation ConversationResponse `json:"conversation"`
+	Messages     []MessageResponse    `json:"messages"`
+	Total        int64                `json:"total"`
+	Page         int                  `json:"page"`
+	Limit        int                  `json:"limit"`
+}
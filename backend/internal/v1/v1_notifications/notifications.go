@@ -0,0 +1,138 @@
+package v1_notifications
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func notificationToResponse(notification db.Notification) NotificationResponse {
+	var projectID *string
+	if notification.ProjectID.Valid {
+		id := uuid.UUID(notification.ProjectID.Bytes).String()
+		projectID = &id
+	}
+
+	return NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		ProjectID: projectID,
+		Message:   notification.Message,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+/*
+ * handleListNotifications lists the user's notifications, newest first.
+ *
+ * parameters (all optional):
+ * - unread_only: only list notifications that haven't been read
+ * - page, limit: pagination (default: page 1, 20 per page)
+ *
+ * Security:
+ * - Users only see their own notifications
+ */
+func (h *Handler) handleListNotifications(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req ListNotificationsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	counts, err := queries.CountNotifications(ctx, db.CountNotificationsParams{
+		UnreadOnly: req.UnreadOnly,
+		UserID:     user.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to count notifications", err)
+	}
+
+	notifications, err := queries.ListNotifications(ctx, db.ListNotificationsParams{
+		UserID:     user.ID,
+		UnreadOnly: req.UnreadOnly,
+		PageSize:   int32(req.Limit),
+		PageOffset: int32((req.Page - 1) * req.Limit),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get notifications", err)
+	}
+
+	response := NotificationListResponse{
+		Notifications: make([]NotificationResponse, len(notifications)),
+		Total:         counts.Total,
+		Unread:        counts.Unread,
+		Page:          req.Page,
+		Limit:         req.Limit,
+	}
+	for i, notification := range notifications {
+		response.Notifications[i] = notificationToResponse(notification)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleMarkNotificationRead marks one of the user's notifications as read.
+ *
+ * Security:
+ * - Users can only update their own notifications
+ */
+func (h *Handler) handleMarkNotificationRead(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return v1_common.NewValidationError("Invalid notification id")
+	}
+
+	updated, err := h.server.GetQueries().MarkNotificationRead(c.Request().Context(), db.MarkNotificationReadParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if updated == 0 {
+		return v1_common.NewNotFoundError("Notification")
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Notification marked as read")
+}
+
+/*
+ * handleMarkAllNotificationsRead marks all of the user's notifications as read.
+ */
+func (h *Handler) handleMarkAllNotificationsRead(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	if err := h.server.GetQueries().MarkAllNotificationsRead(c.Request().Context(), user.ID); err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Notifications marked as read")
+}
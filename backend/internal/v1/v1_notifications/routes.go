@@ -0,0 +1,18 @@
+package v1_notifications
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+	"KonferCA/SPUR/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupNotificationRoutes(g *echo.Group, s interfaces.CoreServer) {
+	h := &Handler{server: s}
+
+	// In-app notifications - any signed in user, each user only sees their own
+	notifications := g.Group("/notifications", middleware.Auth(s.GetDB()))
+	notifications.GET("", h.handleListNotifications)
+	notifications.POST("/read", h.handleMarkAllNotificationsRead)
+	notifications.POST("/:id/read", h.handleMarkNotificationRead)
+}
@@ -0,0 +1,34 @@
+package v1_notifications
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/interfaces"
+)
+
+type Handler struct {
+	server interfaces.CoreServer
+}
+
+type ListNotificationsRequest struct {
+	UnreadOnly bool `query:"unread_only"`
+	Page       int  `query:"page" validate:"omitempty,min=1"`
+	Limit      int  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type NotificationResponse struct {
+	ID        string              `json:"id"`
+	Type      db.NotificationType `json:"type"`
+	ProjectID *string             `json:"project_id"`
+	Message   string              `json:"message"`
+	Read      bool                `json:"read"`
+	ReadAt    *int64              `json:"read_at"`
+	CreatedAt int64               `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Unread        int64                  `json:"unread"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+}
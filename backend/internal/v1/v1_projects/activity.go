@@ -0,0 +1,113 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * handleGetProjectActivity returns the activity timeline of a project, newest first.
+ * The timeline merges status transitions, snapshots, comments, documents, team member
+ * changes and transactions into a single paginated stream.
+ *
+ * parameters (all optional):
+ * - page: page number starting at 1 (default: 1)
+ * - limit: number of activities per page (default: 20, max: 100)
+ *
+ * Security:
+ * - Project owners can only see the timeline of their own projects
+ * - Investors can see the timeline of submitted projects, like the data room
+ * - Activities about internal comments are only included for admins
+ * - Investors only see the activities of documents they can open in the data room
+ */
+func (h *Handler) handleGetProjectActivity(c echo.Context) error {
+	projectID := c.Param("id")
+	if projectID == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Project ID is required", nil)
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req ListProjectActivityRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	_, role, _, err := getProjectWithRole(queries, ctx, user, projectID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project activity", err)
+	}
+
+	includeInternal := canViewInternalComments(user)
+
+	total, err := queries.CountProjectActivities(ctx, db.CountProjectActivitiesParams{
+		ProjectID:           projectID,
+		IncludeInternal:     includeInternal,
+		IncludeAllDocuments: role >= projectRoleFounder,
+		InvestorID:          user.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to count project activity", err)
+	}
+
+	activities, err := queries.ListProjectActivities(ctx, db.ListProjectActivitiesParams{
+		ProjectID:           projectID,
+		IncludeInternal:     includeInternal,
+		IncludeAllDocuments: role >= projectRoleFounder,
+		InvestorID:          user.ID,
+		PageSize:            int32(req.Limit),
+		PageOffset:          int32((req.Page - 1) * req.Limit),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project activity", err)
+	}
+
+	response := make([]ActivityResponse, len(activities))
+	for i, activity := range activities {
+		var actor *ActivityActorResponse
+		if activity.ActorID.Valid {
+			actor = &ActivityActorResponse{
+				ID:        activity.ActorID.String(),
+				FirstName: activity.ActorFirstName,
+				LastName:  activity.ActorLastName,
+			}
+		}
+
+		response[i] = ActivityResponse{
+			ID:        activity.ID,
+			ProjectID: activity.ProjectID,
+			Type:      activity.ActivityType,
+			Internal:  activity.Internal,
+			Actor:     actor,
+			Payload:   json.RawMessage(activity.Payload),
+			CreatedAt: activity.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, ActivityListResponse{
+		Activities: response,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	})
+}
@@ -81,8 +81,11 @@ func (h *Handler) handleSaveProjectDraft(c echo.Context) error {
 	// Create a map of questions that can be modified when project is in needs_review status
 	var allowedQuestionIDs map[string]bool
 	if project.Status == db.ProjectStatusNeedsreview && project.AllowEdit {
-		// Get all project comments
-		comments, err := q.GetProjectComments(ctx, projectID)
+		// Get all founder-visible project comments, internal notes never unlock a question
+		comments, err := q.GetProjectComments(ctx, db.GetProjectCommentsParams{
+			ProjectID:       projectID,
+			IncludeInternal: false,
+		})
 		if err != nil {
 			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project comments", err)
 		}
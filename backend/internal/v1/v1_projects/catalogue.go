@@ -0,0 +1,214 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	catalogueSortNewest  = "newest"
+	catalogueSortOldest  = "oldest"
+	catalogueSortUpdated = "updated"
+	catalogueSortTitle   = "title"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// catalogueCursor is the position of the last project of a catalogue page.
+// It is handed to clients as an opaque base64 string.
+type catalogueCursor struct {
+	Sort  string `json:"s"`
+	Time  int64  `json:"t,omitempty"`
+	Title string `json:"k,omitempty"`
+	ID    string `json:"id"`
+}
+
+func newCatalogueCursor(sort string, project db.ListProjectCatalogueRow) catalogueCursor {
+	cursor := catalogueCursor{Sort: sort, ID: project.ID}
+	switch sort {
+	case catalogueSortUpdated:
+		cursor.Time = project.UpdatedAt
+	case catalogueSortTitle:
+		cursor.Title = project.Title
+	default:
+		cursor.Time = project.CreatedAt
+	}
+	return cursor
+}
+
+func (cursor catalogueCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+/*
+ * decodeCatalogueCursor parses a cursor returned by a previous catalogue page.
+ * A cursor is only valid for the sort order it was created with.
+ */
+func decodeCatalogueCursor(value string, sort string) (catalogueCursor, error) {
+	var cursor catalogueCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errInvalidCursor
+	}
+	if cursor.Sort != sort {
+		return cursor, errInvalidCursor
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+/*
+ * applyTo sets the keyset parameters of the catalogue query so that it continues after the cursor.
+ */
+func (cursor catalogueCursor) applyTo(params *db.ListProjectCatalogueParams) {
+	params.CursorID = pgtype.UUID{Bytes: uuid.MustParse(cursor.ID), Valid: true}
+	if cursor.Sort == catalogueSortTitle {
+		params.CursorTitle = &cursor.Title
+	} else {
+		params.CursorTime = &cursor.Time
+	}
+}
+
+/*
+ * handleGetProjectCatalogue returns the public catalogue of projects.
+ * Pages are cursor based, pass the next_cursor of a response to get the following page.
+ * The first page also includes the industry and stage facets with the number of matching projects.
+ *
+ * parameters (all optional):
+ * - statuses: statuses to include, repeatable (pending, verified). Defaults to both.
+ * - industry: only include projects in this industry
+ * - stage: only include projects at this stage
+ * - sort: newest (default), oldest, updated or title
+ * - cursor: next_cursor from the previous page
+ * - limit: number of projects per page (default: 10, max: 50)
+ *
+ * security:
+ * - Public endpoint (no authentication required), rate limited
+ * - Only returns basic project information
+ */
+func (h *Handler) handleGetProjectCatalogue(c echo.Context) error {
+	var req ProjectCatalogueRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if len(req.Statuses) == 0 {
+		req.Statuses = []db.ProjectStatus{db.ProjectStatusPending, db.ProjectStatusVerified}
+	}
+	if req.Sort == "" {
+		req.Sort = catalogueSortNewest
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	var industry, stage *string
+	if req.Industry != "" {
+		industry = &req.Industry
+	}
+	if req.Stage != "" {
+		stage = &req.Stage
+	}
+
+	params := db.ListProjectCatalogueParams{
+		Statuses: req.Statuses,
+		Industry: industry,
+		Stage:    stage,
+		Sort:     req.Sort,
+		// fetch one extra project to know if there is another page
+		PageSize: int32(req.Limit + 1),
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeCatalogueCursor(req.Cursor, req.Sort)
+		if err != nil {
+			return v1_common.Fail(c, http.StatusBadRequest, "Invalid cursor", err)
+		}
+		cursor.applyTo(&params)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	projects, err := queries.ListProjectCatalogue(ctx, params)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get projects", err)
+	}
+
+	response := ProjectCatalogueResponse{
+		Projects: make([]ExtendedProjectResponse, 0, req.Limit),
+	}
+	if len(projects) > req.Limit {
+		projects = projects[:req.Limit]
+		response.HasMore = true
+		response.NextCursor = newCatalogueCursor(req.Sort, projects[len(projects)-1]).encode()
+	}
+
+	for _, project := range projects {
+		description := ""
+		if project.Description != nil {
+			description = *project.Description
+		}
+
+		companyName := ""
+		if project.CompanyName != nil {
+			companyName = *project.CompanyName
+		}
+
+		response.Projects = append(response.Projects, ExtendedProjectResponse{
+			ProjectResponse: ProjectResponse{
+				ID:          project.ID,
+				Title:       project.Title,
+				Description: description,
+				Status:      project.Status,
+				AllowEdit:   project.AllowEdit,
+				CreatedAt:   project.CreatedAt,
+				UpdatedAt:   project.UpdatedAt,
+			},
+			CompanyName:     companyName,
+			DocumentCount:   project.DocumentCount,
+			TeamMemberCount: project.TeamMemberCount,
+		})
+	}
+
+	// facets don't change between pages, only compute them for the first one
+	if req.Cursor == "" {
+		facets, err := queries.GetProjectCatalogueFacets(ctx, db.GetProjectCatalogueFacetsParams{
+			Statuses: req.Statuses,
+			Stage:    stage,
+			Industry: industry,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project facets", err)
+		}
+
+		response.Facets = &CatalogueFacetsResponse{
+			Industries: []CatalogueFacetResponse{},
+			Stages:     []CatalogueFacetResponse{},
+		}
+		for _, facet := range facets {
+			value := CatalogueFacetResponse{Value: facet.Value, Count: facet.ProjectCount}
+			if facet.Facet == "industry" {
+				response.Facets.Industries = append(response.Facets.Industries, value)
+			} else {
+				response.Facets.Stages = append(response.Facets.Stages, value)
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
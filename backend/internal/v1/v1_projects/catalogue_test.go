@@ -0,0 +1,58 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogueCursor(t *testing.T) {
+	project := db.ListProjectCatalogueRow{
+		ID:        uuid.New().String(),
+		Title:     "Sunbeam Solar",
+		CreatedAt: 1700000000,
+		UpdatedAt: 1700000500,
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, sort := range []string{catalogueSortNewest, catalogueSortOldest, catalogueSortUpdated, catalogueSortTitle} {
+			encoded := newCatalogueCursor(sort, project).encode()
+			cursor, err := decodeCatalogueCursor(encoded, sort)
+			require.NoError(t, err, sort)
+			assert.Equal(t, project.ID, cursor.ID)
+
+			var params db.ListProjectCatalogueParams
+			cursor.applyTo(&params)
+			assert.True(t, params.CursorID.Valid)
+
+			switch sort {
+			case catalogueSortTitle:
+				require.NotNil(t, params.CursorTitle)
+				assert.Equal(t, project.Title, *params.CursorTitle)
+				assert.Nil(t, params.CursorTime)
+			case catalogueSortUpdated:
+				require.NotNil(t, params.CursorTime)
+				assert.Equal(t, project.UpdatedAt, *params.CursorTime)
+			default:
+				require.NotNil(t, params.CursorTime)
+				assert.Equal(t, project.CreatedAt, *params.CursorTime)
+			}
+		}
+	})
+
+	t.Run("rejects cursor of another sort", func(t *testing.T) {
+		encoded := newCatalogueCursor(catalogueSortNewest, project).encode()
+		_, err := decodeCatalogueCursor(encoded, catalogueSortTitle)
+		assert.ErrorIs(t, err, errInvalidCursor)
+	})
+
+	t.Run("rejects malformed cursors", func(t *testing.T) {
+		for _, value := range []string{"not base64!", "bm90IGpzb24", newCatalogueCursor(catalogueSortNewest, db.ListProjectCatalogueRow{ID: "nope"}).encode()} {
+			_, err := decodeCatalogueCursor(value, catalogueSortNewest)
+			assert.ErrorIs(t, err, errInvalidCursor, value)
+		}
+	})
+}
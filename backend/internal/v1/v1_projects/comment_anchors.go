@@ -0,0 +1,219 @@
+package v1_projects
+
+/*
+ * package v1_projects implements comment anchoring.
+ * this file contains the helpers that pin a comment to the snapshot it was written
+ * against and to a character range of the answer, and that locate the anchored
+ * text again in the current draft.
+ *
+ * Offsets are counted in characters (unicode code points), not bytes.
+ */
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// snapshotQuestions is the subset of the project snapshot data needed to read answers.
+type snapshotQuestions struct {
+	Questions []struct {
+		ID     string `json:"id"`
+		Answer string `json:"answer"`
+	} `json:"questions"`
+}
+
+/*
+ * snapshotAnswer returns the answer to a question as it was stored in a snapshot.
+ * The second return value is false if the question is not part of the snapshot.
+ */
+func snapshotAnswer(data []byte, questionID string) (string, bool, error) {
+	var snapshot snapshotQuestions
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return "", false, err
+	}
+	for _, question := range snapshot.Questions {
+		if question.ID == questionID {
+			return question.Answer, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+/*
+ * anchorText extracts the characters in [start, end) from answer.
+ * The second return value is false if the range is empty or out of bounds.
+ */
+func anchorText(answer string, start, end int) (string, bool) {
+	runes := []rune(answer)
+	if start < 0 || end <= start || end > len(runes) {
+		return "", false
+	}
+	return string(runes[start:end]), true
+}
+
+/*
+ * locateAnchor looks for the anchored text in the current answer.
+ *
+ * If the text is still at its original position that position is returned,
+ * otherwise the occurrence closest to the original start is used.
+ * The last return value is false if the text no longer exists.
+ */
+func locateAnchor(current, text string, start int) (int, int, bool) {
+	haystack := []rune(current)
+	needle := []rune(text)
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return 0, 0, false
+	}
+
+	found := -1
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if string(haystack[i:i+len(needle)]) != text {
+			continue
+		}
+		if found == -1 || abs(i-start) < abs(found-start) {
+			found = i
+		}
+		if i == start {
+			break
+		}
+	}
+	if found == -1 {
+		return 0, 0, false
+	}
+	return found, found + len(needle), true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+/*
+ * commentAnchorToResponse builds the anchor part of a comment response and reports
+ * where the anchored text is in the current answer.
+ * Returns nil when the comment is not anchored to a text range.
+ */
+func commentAnchorToResponse(start, end *int32, text *string, current string) *CommentAnchorResponse {
+	if start == nil || end == nil || text == nil {
+		return nil
+	}
+
+	anchor := &CommentAnchorResponse{
+		Start: *start,
+		End:   *end,
+		Text:  *text,
+	}
+
+	if currentStart, currentEnd, ok := locateAnchor(current, *text, int(*start)); ok {
+		anchor.TextExists = true
+		anchor.Moved = currentStart != int(*start)
+		anchor.CurrentStart = &currentStart
+		anchor.CurrentEnd = &currentEnd
+	}
+
+	return anchor
+}
+
+/*
+ * snapshotIDToString converts a nullable snapshot id for responses.
+ */
+func snapshotIDToString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := id.String()
+	return &value
+}
+
+/*
+ * currentAnswers maps question ids to the answers of the project's current draft.
+ */
+func currentAnswers(queries *db.Queries, ctx context.Context, projectID string) (map[string]string, error) {
+	answers, err := queries.GetProjectAnswers(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(answers))
+	for _, answer := range answers {
+		result[answer.QuestionID] = answer.Answer
+	}
+	return result, nil
+}
+
+/*
+ * commentAnchor holds the anchor values stored with a new comment.
+ */
+type commentAnchor struct {
+	SnapshotID      pgtype.UUID
+	SnapshotVersion *int32
+	Start           *int32
+	End             *int32
+	Text            *string
+}
+
+/*
+ * resolveCommentAnchor pins a new comment to the latest snapshot of the project and,
+ * when a range is given, to the text of the target's answer in that snapshot.
+ *
+ * Projects that were never submitted have no snapshot, in that case the range
+ * is taken from the current draft instead.
+ *
+ * returns a validation error if the range doesn't fit the answer.
+ */
+func resolveCommentAnchor(queries *db.Queries, ctx context.Context, projectID, targetID string, start, end *int32) (commentAnchor, error) {
+	var anchor commentAnchor
+
+	var answer string
+	hasAnswer := false
+
+	snapshot, err := queries.GetLatestProjectSnapshot(ctx, projectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return anchor, err
+	}
+	if err == nil {
+		if parsed, err := uuid.Parse(snapshot.ID); err == nil {
+			anchor.SnapshotID = pgtype.UUID{Bytes: parsed, Valid: true}
+			anchor.SnapshotVersion = &snapshot.VersionNumber
+		}
+		if start != nil {
+			answer, hasAnswer, err = snapshotAnswer(snapshot.Data, targetID)
+			if err != nil {
+				return anchor, err
+			}
+		}
+	} else if start != nil {
+		answers, err := currentAnswers(queries, ctx, projectID)
+		if err != nil {
+			return anchor, err
+		}
+		answer, hasAnswer = answers[targetID]
+	}
+
+	if start == nil || end == nil {
+		return anchor, nil
+	}
+
+	if !hasAnswer {
+		return anchor, v1_common.NewValidationError("Comment target has no answer to anchor the comment to.")
+	}
+
+	text, ok := anchorText(answer, int(*start), int(*end))
+	if !ok {
+		return anchor, v1_common.NewValidationError("Anchor range is outside of the answer.")
+	}
+
+	anchor.Start = start
+	anchor.End = end
+	anchor.Text = &text
+
+	return anchor, nil
+}
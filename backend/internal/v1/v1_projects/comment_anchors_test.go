@@ -0,0 +1,98 @@
+package v1_projects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnchorText(t *testing.T) {
+	text, ok := anchorText("Our market is €2B in Canada", 14, 18)
+	assert.True(t, ok)
+	assert.Equal(t, "€2B ", text)
+
+	_, ok = anchorText("short", 2, 10)
+	assert.False(t, ok)
+
+	_, ok = anchorText("short", 3, 3)
+	assert.False(t, ok)
+}
+
+func TestLocateAnchor(t *testing.T) {
+	testCases := []struct {
+		name          string
+		current       string
+		text          string
+		start         int
+		expectedStart int
+		expectedFound bool
+	}{
+		{
+			name:          "Unchanged position",
+			current:       "We sell to hospitals.",
+			text:          "hospitals",
+			start:         11,
+			expectedStart: 11,
+			expectedFound: true,
+		},
+		{
+			name:          "Text moved after an edit",
+			current:       "Today we sell to hospitals.",
+			text:          "hospitals",
+			start:         11,
+			expectedStart: 17,
+			expectedFound: true,
+		},
+		{
+			name:          "Closest occurrence is used",
+			current:       "abc xx abc xx abc",
+			text:          "abc",
+			start:         8,
+			expectedStart: 7,
+			expectedFound: true,
+		},
+		{
+			name:          "Text removed",
+			current:       "We sell to clinics.",
+			text:          "hospitals",
+			start:         11,
+			expectedFound: false,
+		},
+		{
+			name:          "Multibyte characters",
+			current:       "Revenue: €1M, target €2B",
+			text:          "€2B",
+			start:         10,
+			expectedStart: 21,
+			expectedFound: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, found := locateAnchor(tc.current, tc.text, tc.start)
+			assert.Equal(t, tc.expectedFound, found)
+			if tc.expectedFound {
+				assert.Equal(t, tc.expectedStart, start)
+				assert.Equal(t, tc.expectedStart+len([]rune(tc.text)), end)
+			}
+		})
+	}
+}
+
+func TestSnapshotAnswer(t *testing.T) {
+	data := []byte(`{"questions": [{"id": "q1", "answer": "first"}, {"id": "q2", "answer": ""}], "documents": []}`)
+
+	answer, ok, err := snapshotAnswer(data, "q1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "first", answer)
+
+	_, ok, err = snapshotAnswer(data, "q3")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = snapshotAnswer([]byte("not json"), "q1")
+	assert.Error(t, err)
+}
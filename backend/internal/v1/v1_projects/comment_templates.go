@@ -0,0 +1,201 @@
+package v1_projects
+
+/*
+ * package v1_projects implements the comment template endpoints.
+ * this file contains the admin-managed canned feedback templates that reviewers
+ * can reuse when commenting on projects, including placeholder rendering.
+ */
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// Placeholders are written as {{name}}, whitespace inside the braces is ignored.
+var templatePlaceholderRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+/*
+ * templatePlaceholders returns the unique placeholder names used in a template body,
+ * in the order they first appear.
+ */
+func templatePlaceholders(body string) []string {
+	placeholders := []string{}
+	seen := make(map[string]bool)
+	for _, match := range templatePlaceholderRegex.FindAllStringSubmatch(body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			placeholders = append(placeholders, match[1])
+		}
+	}
+	return placeholders
+}
+
+/*
+ * renderCommentTemplate replaces every placeholder in body with its value.
+ *
+ * returns:
+ * - the rendered comment
+ * - the names of placeholders that had no value, the rendered comment
+ *   keeps those placeholders untouched
+ */
+func renderCommentTemplate(body string, values map[string]string) (string, []string) {
+	missing := []string{}
+	seen := make(map[string]bool)
+	rendered := templatePlaceholderRegex.ReplaceAllStringFunc(body, func(match string) string {
+		name := templatePlaceholderRegex.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok && value != "" {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return match
+	})
+	return rendered, missing
+}
+
+func commentTemplateToResponse(template db.CommentTemplate) CommentTemplateResponse {
+	var createdBy *string
+	if template.CreatedBy.Valid {
+		id := template.CreatedBy.String()
+		createdBy = &id
+	}
+
+	return CommentTemplateResponse{
+		ID:           template.ID,
+		Title:        template.Title,
+		Body:         template.Body,
+		Placeholders: templatePlaceholders(template.Body),
+		CreatedBy:    createdBy,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+}
+
+/*
+ * handleListCommentTemplates lists all comment templates ordered by title.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleListCommentTemplates(c echo.Context) error {
+	templates, err := h.server.GetQueries().ListCommentTemplates(c.Request().Context())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get comment templates", err)
+	}
+
+	response := make([]CommentTemplateResponse, len(templates))
+	for i, template := range templates {
+		response[i] = commentTemplateToResponse(template)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"templates": response,
+	})
+}
+
+/*
+ * handleCreateCommentTemplate creates a new comment template.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleCreateCommentTemplate(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req CommentTemplateRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	var createdBy pgtype.UUID
+	if parsed, err := uuid.Parse(user.ID); err == nil {
+		createdBy.Bytes = parsed
+		createdBy.Valid = true
+	}
+
+	template, err := h.server.GetQueries().CreateCommentTemplate(c.Request().Context(), db.CreateCommentTemplateParams{
+		Title:     req.Title,
+		Body:      req.Body,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create comment template", err)
+	}
+
+	return c.JSON(http.StatusCreated, commentTemplateToResponse(template))
+}
+
+/*
+ * handleUpdateCommentTemplate replaces the title and body of a comment template.
+ * Comments that were already created from the template are not affected.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleUpdateCommentTemplate(c echo.Context) error {
+	templateID := c.Param("template_id")
+	if _, err := uuid.Parse(templateID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid template ID", err)
+	}
+
+	var req CommentTemplateRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	template, err := h.server.GetQueries().UpdateCommentTemplate(c.Request().Context(), db.UpdateCommentTemplateParams{
+		ID:    templateID,
+		Title: req.Title,
+		Body:  req.Body,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Comment template not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update comment template", err)
+	}
+
+	return c.JSON(http.StatusOK, commentTemplateToResponse(template))
+}
+
+/*
+ * handleDeleteCommentTemplate removes a comment template.
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleDeleteCommentTemplate(c echo.Context) error {
+	templateID := c.Param("template_id")
+	if _, err := uuid.Parse(templateID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid template ID", err)
+	}
+
+	if err := h.server.GetQueries().DeleteCommentTemplate(c.Request().Context(), templateID); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to delete comment template", err)
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Comment template deleted")
+}
+
+/*
+ * missingPlaceholdersError builds the public error message used when a template
+ * can't be fully rendered.
+ */
+func missingPlaceholdersError(targetID string, missing []string) string {
+	return fmt.Sprintf("Missing values for template placeholders (%s) in comment for target %s",
+		strings.Join(missing, ", "), targetID)
+}
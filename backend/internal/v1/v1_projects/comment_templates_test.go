@@ -0,0 +1,62 @@
+package v1_projects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCommentTemplate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		values          map[string]string
+		expected        string
+		expectedMissing []string
+	}{
+		{
+			name:            "No placeholders",
+			body:            "Please add a market size source.",
+			values:          map[string]string{},
+			expected:        "Please add a market size source.",
+			expectedMissing: []string{},
+		},
+		{
+			name:            "All placeholders provided",
+			body:            "{{company_name}}: please answer \"{{ question }}\" again.",
+			values:          map[string]string{"company_name": "Acme", "question": "What is your market size?"},
+			expected:        "Acme: please answer \"What is your market size?\" again.",
+			expectedMissing: []string{},
+		},
+		{
+			name:            "Missing placeholder is kept and reported once",
+			body:            "{{founder}} and {{founder}} please upload {{document}}",
+			values:          map[string]string{"document": "your resume"},
+			expected:        "{{founder}} and {{founder}} please upload your resume",
+			expectedMissing: []string{"founder"},
+		},
+		{
+			name:            "Empty value counts as missing",
+			body:            "Hello {{company_name}}",
+			values:          map[string]string{"company_name": ""},
+			expected:        "Hello {{company_name}}",
+			expectedMissing: []string{"company_name"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, missing := renderCommentTemplate(tc.body, tc.values)
+			assert.Equal(t, tc.expected, rendered)
+			assert.Equal(t, tc.expectedMissing, missing)
+		})
+	}
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	assert.Equal(t, []string{}, templatePlaceholders("no placeholders here"))
+	assert.Equal(t,
+		[]string{"company_name", "question"},
+		templatePlaceholders("{{company_name}} {{question}} {{ company_name }}"),
+	)
+}
@@ -7,19 +7,31 @@ import (
 	"KonferCA/SPUR/internal/v1/v1_common"
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
+/*
+ * canViewInternalComments reports whether the user is allowed to see
+ * internal reviewer notes. Only admins qualify, investors can view all
+ * projects too but reviewer notes are not meant for them.
+ */
+func canViewInternalComments(user *db.User) bool {
+	return permissions.HasPermission(uint32(user.Permissions), permissions.PermIsAdmin)
+}
+
 /*
  * handleGetProjectComments retrieves all comments for a project.
  *
  * Security:
  * - Verifies project belongs to user's company
  * - Returns 404 if project not found
+ * - Internal comments are only included for users with PermIsAdmin
  */
 func (h *Handler) handleGetProjectComments(c echo.Context) error {
 	// Get project ID from URL
@@ -52,7 +64,16 @@ func (h *Handler) handleGetProjectComments(c echo.Context) error {
 		}
 	}
 
-	comments, err := h.server.GetQueries().GetProjectComments(c.Request().Context(), project.ID)
+	comments, err := h.server.GetQueries().GetProjectComments(c.Request().Context(), db.GetProjectCommentsParams{
+		ProjectID:       project.ID,
+		IncludeInternal: canViewInternalComments(user),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project comments", err)
+	}
+
+	// Current answers are needed to check whether anchored text still exists
+	answers, err := currentAnswers(h.server.GetQueries(), c.Request().Context(), project.ID)
 	if err != nil {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project comments", err)
 	}
@@ -79,6 +100,10 @@ func (h *Handler) handleGetProjectComments(c echo.Context) error {
 			Resolved:             comment.Resolved,
 			ResolvedBySnapshotID: snapshotID,
 			ResolvedBySnapshotAt: comment.ResolvedBySnapshotAt,
+			Visibility:           comment.Visibility,
+			SnapshotID:           snapshotIDToString(comment.AnchorSnapshotID),
+			SnapshotVersion:      comment.AnchorSnapshotVersion,
+			Anchor:               commentAnchorToResponse(comment.AnchorStart, comment.AnchorEnd, comment.AnchorText, answers[comment.TargetID]),
 		}
 	}
 
@@ -90,7 +115,7 @@ func (h *Handler) handleGetProjectComments(c echo.Context) error {
  *
  * Security:
  * - Verifies project belongs to user's company
- * - Returns 404 if comment not found
+ * - Returns 404 if comment not found or if it is internal and the user can't view it
  */
 func (h *Handler) handleGetProjectComment(c echo.Context) error {
 	// Get project and comment IDs from URL
@@ -124,13 +149,19 @@ func (h *Handler) handleGetProjectComment(c echo.Context) error {
 
 	// Get specific comment
 	comment, err := h.server.GetQueries().GetProjectComment(c.Request().Context(), db.GetProjectCommentParams{
-		ID:        commentID,
-		ProjectID: project.ID,
+		ID:              commentID,
+		ProjectID:       project.ID,
+		IncludeInternal: canViewInternalComments(user),
 	})
 	if err != nil {
 		return v1_common.Fail(c, http.StatusNotFound, "Comment not found", err)
 	}
 
+	answers, err := currentAnswers(h.server.GetQueries(), c.Request().Context(), project.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get comment", err)
+	}
+
 	var snapshotID *string = nil
 	if comment.ResolvedBySnapshotID.Valid {
 		uuid := comment.ResolvedBySnapshotID.String()
@@ -150,12 +181,17 @@ func (h *Handler) handleGetProjectComment(c echo.Context) error {
 		Resolved:             comment.Resolved,
 		ResolvedBySnapshotID: snapshotID,
 		ResolvedBySnapshotAt: comment.ResolvedBySnapshotAt,
+		Visibility:           comment.Visibility,
+		SnapshotID:           snapshotIDToString(comment.AnchorSnapshotID),
+		SnapshotVersion:      comment.AnchorSnapshotVersion,
+		Anchor:               commentAnchorToResponse(comment.AnchorStart, comment.AnchorEnd, comment.AnchorText, answers[comment.TargetID]),
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
 // handleCreateProjectComment handles creating comments request.
+// Internal comments are stored as reviewer notes and do not set 'allow_edit'.
 //
 // Security: only admin users are allowed
 func (h *Handler) handleCreateProjectComment(c echo.Context) error {
@@ -218,17 +254,37 @@ func (h *Handler) handleCreateProjectComment(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request data", err)
 	}
 
+	// Pin the comment to the latest submitted version and the optional text range
+	anchor, err := resolveCommentAnchor(queries, ctx, projectID, req.TargetID, req.AnchorStart, req.AnchorEnd)
+	if err != nil {
+		var apiErr *v1_common.APIError
+		if errors.As(err, &apiErr) {
+			return apiErr
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create comment", err)
+	}
+
 	// Create comment and set 'allow_edit' flag
 	comment, err := service.CreateProjectComment(queries, ctx, db.CreateProjectCommentParams{
-		ProjectID:   projectID,
-		TargetID:    req.TargetID,
-		Comment:     req.Comment,
-		CommenterID: user.ID,
+		ProjectID:        projectID,
+		TargetID:         req.TargetID,
+		Comment:          req.Comment,
+		CommenterID:      user.ID,
+		Visibility:       req.Visibility,
+		AnchorSnapshotID: anchor.SnapshotID,
+		AnchorStart:      anchor.Start,
+		AnchorEnd:        anchor.End,
+		AnchorText:       anchor.Text,
 	})
 	if err != nil {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create comment", err)
 	}
 
+	answers, err := currentAnswers(queries, ctx, projectID)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
 	// Commit changes
 	if err := tx.Commit(ctx); err != nil {
 		return v1_common.NewInternalError(err)
@@ -247,6 +303,10 @@ func (h *Handler) handleCreateProjectComment(c echo.Context) error {
 		UpdatedAt:            comment.UpdatedAt,
 		ResolvedBySnapshotID: nil,
 		ResolvedBySnapshotAt: nil,
+		Visibility:           comment.Visibility,
+		SnapshotID:           snapshotIDToString(comment.AnchorSnapshotID),
+		SnapshotVersion:      anchor.SnapshotVersion,
+		Anchor:               commentAnchorToResponse(comment.AnchorStart, comment.AnchorEnd, comment.AnchorText, answers[comment.TargetID]),
 	}
 
 	return c.JSON(http.StatusCreated, response)
@@ -287,6 +347,18 @@ func (h *Handler) handleUpdateProjectComment(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
 	}
 
+	// Users that can't see internal comments must not be able to modify them either
+	if !canViewInternalComments(user) {
+		_, err = h.server.GetQueries().GetProjectComment(c.Request().Context(), db.GetProjectCommentParams{
+			ID:              commentID,
+			ProjectID:       projectID,
+			IncludeInternal: false,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusNotFound, "Comment not found", err)
+		}
+	}
+
 	// Update the comment
 	_, err = h.server.GetQueries().UpdateProjectComment(c.Request().Context(), db.UpdateProjectCommentParams{
 		ID:      commentID,
@@ -345,8 +417,9 @@ func (h *Handler) handleResolveComment(c echo.Context) error {
 	}
 
 	oldComment, err := queries.GetProjectComment(ctx, db.GetProjectCommentParams{
-		ID:        commentID,
-		ProjectID: projectID,
+		ID:              commentID,
+		ProjectID:       projectID,
+		IncludeInternal: canViewInternalComments(user),
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -370,6 +443,16 @@ func (h *Handler) handleResolveComment(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to resolve comment", err)
 	}
 
+	// The comment is already resolved at this point, a missing timeline entry must not fail the request
+	err = service.RecordProjectActivity(queries, ctx, projectID, user.ID, db.ProjectActivityTypeCommentResolved,
+		comment.Visibility == db.CommentVisibilityEnumInternal, service.ActivityPayload{
+			"comment_id": comment.ID,
+			"target_id":  comment.TargetID,
+		})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", projectID).Str("comment_id", comment.ID).Msg("Failed to record project activity.")
+	}
+
 	return c.JSON(http.StatusOK, CommentResponse{
 		ID:          comment.ID,
 		ProjectID:   comment.ProjectID,
@@ -379,6 +462,7 @@ func (h *Handler) handleResolveComment(c echo.Context) error {
 		Resolved:    comment.Resolved,
 		CreatedAt:   comment.CreatedAt,
 		UpdatedAt:   comment.UpdatedAt,
+		Visibility:  comment.Visibility,
 	})
 }
 
@@ -426,8 +510,9 @@ func (h *Handler) handleUnresolveComment(c echo.Context) error {
 	}
 
 	oldComment, err := queries.GetProjectComment(ctx, db.GetProjectCommentParams{
-		ID:        commentID,
-		ProjectID: projectID,
+		ID:              commentID,
+		ProjectID:       projectID,
+		IncludeInternal: canViewInternalComments(user),
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -451,6 +536,16 @@ func (h *Handler) handleUnresolveComment(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to unresolve comment", err)
 	}
 
+	// The comment is already unresolved at this point, a missing timeline entry must not fail the request
+	err = service.RecordProjectActivity(queries, ctx, projectID, user.ID, db.ProjectActivityTypeCommentUnresolved,
+		comment.Visibility == db.CommentVisibilityEnumInternal, service.ActivityPayload{
+			"comment_id": comment.ID,
+			"target_id":  comment.TargetID,
+		})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", projectID).Str("comment_id", comment.ID).Msg("Failed to record project activity.")
+	}
+
 	return c.JSON(http.StatusOK, CommentResponse{
 		ID:          comment.ID,
 		ProjectID:   comment.ProjectID,
@@ -460,5 +555,279 @@ func (h *Handler) handleUnresolveComment(c echo.Context) error {
 		Resolved:    comment.Resolved,
 		CreatedAt:   comment.CreatedAt,
 		UpdatedAt:   comment.UpdatedAt,
+		Visibility:  comment.Visibility,
 	})
 }
+
+// handleBulkCreateProjectComments creates several comments on the questions of a project
+// in a single transaction. Each comment is either written out or rendered from a comment
+// template, and goes through service.CreateProjectComment so that 'allow_edit' and the
+// project status are handled exactly like a single comment.
+//
+// Security: only admin users are allowed
+func (h *Handler) handleBulkCreateProjectComments(c echo.Context) error {
+	projectID := c.Param("id")
+	if projectID == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Project ID is required", nil)
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	if user.Permissions&int32(permissions.PermIsAdmin) == 0 {
+		return v1_common.NewAuthError("Unauthorized to create new comments on project.")
+	}
+
+	var req BulkCreateCommentsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request data", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Minute)
+	defer cancel()
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	project, err := queries.GetProjectByIDAsAdmin(ctx, projectID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	company, err := queries.GetCompanyByID(ctx, project.CompanyID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+	}
+
+	// Bulk comments have no text range, they are only pinned to the latest submitted version
+	anchor, err := resolveCommentAnchor(queries, ctx, project.ID, "", nil, nil)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
+	templates := make(map[string]db.CommentTemplate)
+	comments := make([]CommentResponse, 0, len(req.Comments))
+	for _, item := range req.Comments {
+		text := item.Comment
+
+		if item.TemplateID != "" {
+			template, ok := templates[item.TemplateID]
+			if !ok {
+				template, err = queries.GetCommentTemplate(ctx, item.TemplateID)
+				if err != nil {
+					if err == pgx.ErrNoRows {
+						return v1_common.Fail(c, http.StatusNotFound, "Comment template not found", err)
+					}
+					return v1_common.NewInternalError(err)
+				}
+				templates[item.TemplateID] = template
+			}
+
+			values := map[string]string{
+				"company_name":  company.Name,
+				"project_title": project.Title,
+			}
+			// The target might not be a question, in that case there is simply no question placeholder value
+			if question, err := queries.GetProjectQuestion(ctx, item.TargetID); err == nil {
+				values["question"] = question.Question
+			}
+			for key, value := range item.Variables {
+				values[key] = value
+			}
+
+			rendered, missing := renderCommentTemplate(template.Body, values)
+			if len(missing) > 0 {
+				return v1_common.Fail(c, http.StatusBadRequest, missingPlaceholdersError(item.TargetID, missing), nil)
+			}
+			text = rendered
+		}
+
+		comment, err := service.CreateProjectComment(queries, ctx, db.CreateProjectCommentParams{
+			ProjectID:        project.ID,
+			TargetID:         item.TargetID,
+			Comment:          text,
+			CommenterID:      user.ID,
+			Visibility:       item.Visibility,
+			AnchorSnapshotID: anchor.SnapshotID,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create comments", err)
+		}
+
+		comments = append(comments, CommentResponse{
+			ID:                 comment.ID,
+			ProjectID:          comment.ProjectID,
+			TargetID:           comment.TargetID,
+			Comment:            comment.Comment,
+			CommenterID:        comment.CommenterID,
+			CommenterFirstName: user.FirstName,
+			CommenterLastName:  user.LastName,
+			Resolved:           comment.Resolved,
+			CreatedAt:          comment.CreatedAt,
+			UpdatedAt:          comment.UpdatedAt,
+			Visibility:         comment.Visibility,
+			SnapshotID:         snapshotIDToString(comment.AnchorSnapshotID),
+			SnapshotVersion:    anchor.SnapshotVersion,
+		})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
+	return c.JSON(http.StatusCreated, CommentsResponse{Comments: comments})
+}
+
+func (h *Handler) handleBulkResolveComments(c echo.Context) error {
+	return h.setProjectCommentsResolved(c, true)
+}
+
+func (h *Handler) handleBulkUnresolveComments(c echo.Context) error {
+	return h.setProjectCommentsResolved(c, false)
+}
+
+/*
+ * setProjectCommentsResolved resolves or unresolves several comments of a project at once.
+ * Either all comments are updated or none of them are.
+ *
+ * Security:
+ * - Non admin users must own the project
+ * - Internal comments are only reachable by users with PermIsAdmin
+ * - Comments resolved by a previous submission can't be modified
+ */
+func (h *Handler) setProjectCommentsResolved(c echo.Context, resolved bool) error {
+	projectID := c.Param("id")
+	if projectID == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Project ID is required", nil)
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req BulkCommentIDsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request data", err)
+	}
+
+	// Remove duplicated ids so that the number of matched comments can be compared
+	seen := make(map[string]bool)
+	commentIDs := make([]string, 0, len(req.CommentIDs))
+	for _, id := range req.CommentIDs {
+		if !seen[id] {
+			seen[id] = true
+			commentIDs = append(commentIDs, id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Minute)
+	defer cancel()
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	// Check if user has admin permission
+	if uint32(user.Permissions)&permissions.PermIsAdmin == 0 {
+		// If regular startup owner, check for ownership of the project
+		company, err := queries.GetCompanyByOwnerID(ctx, user.ID)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return v1_common.Fail(c, http.StatusBadRequest, "Company missing in database. Please contact support.", err)
+			}
+			return v1_common.NewInternalError(err)
+		}
+
+		_, err = queries.GetProjectByID(ctx, db.GetProjectByIDParams{
+			ID:        projectID,
+			CompanyID: company.ID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return v1_common.Fail(c, http.StatusBadRequest, "Can't resolve comments for non-existing project.", err)
+			}
+			return v1_common.NewInternalError(err)
+		}
+	}
+
+	existing, err := queries.GetProjectCommentsByIDs(ctx, db.GetProjectCommentsByIDsParams{
+		ProjectID:       projectID,
+		Ids:             commentIDs,
+		IncludeInternal: canViewInternalComments(user),
+	})
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if len(existing) != len(commentIDs) {
+		return v1_common.Fail(c, http.StatusNotFound, "One or more comments not found", nil)
+	}
+	for _, comment := range existing {
+		if comment.ResolvedBySnapshotID.Valid {
+			return v1_common.Fail(c, http.StatusBadRequest, "Comments resolved by a previous submission can't be modified.", nil)
+		}
+	}
+
+	updated, err := queries.SetProjectCommentsResolved(ctx, db.SetProjectCommentsResolvedParams{
+		Resolved:  resolved,
+		ProjectID: projectID,
+		Ids:       commentIDs,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update comments", err)
+	}
+
+	activityType := db.ProjectActivityTypeCommentUnresolved
+	if resolved {
+		activityType = db.ProjectActivityTypeCommentResolved
+	}
+	// The comments are updated even when the timeline entries can't be recorded, like a single comment
+	err = service.RecordActivityInTx(ctx, tx, func(queries *db.Queries) error {
+		for _, comment := range updated {
+			err := service.RecordProjectActivity(queries, ctx, projectID, user.ID, activityType,
+				comment.Visibility == db.CommentVisibilityEnumInternal, service.ActivityPayload{
+					"comment_id": comment.ID,
+					"target_id":  comment.TargetID,
+				})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", projectID).Msg("Failed to record project activity.")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.NewInternalError(err)
+	}
+
+	response := make([]CommentResponse, len(updated))
+	for i, comment := range updated {
+		response[i] = CommentResponse{
+			ID:          comment.ID,
+			ProjectID:   comment.ProjectID,
+			TargetID:    comment.TargetID,
+			Comment:     comment.Comment,
+			CommenterID: comment.CommenterID,
+			Resolved:    comment.Resolved,
+			CreatedAt:   comment.CreatedAt,
+			UpdatedAt:   comment.UpdatedAt,
+			Visibility:  comment.Visibility,
+		}
+	}
+
+	return c.JSON(http.StatusOK, CommentsResponse{Comments: response})
+}
@@ -0,0 +1,560 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * The data room shares project documents with investors. Documents that aren't confidential
+ * are available to every investor, confidential documents only to the investors the founder
+ * granted access to. Files are only served through short-lived signed URLs and every view
+ * or download by someone other than the founder is logged.
+ */
+
+// dataRoomURLExpiry is how long a signed data room URL stays valid.
+const dataRoomURLExpiry = 5 * time.Minute
+
+func optionalUUIDString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	s := uuid.UUID(id.Bytes).String()
+	return &s
+}
+
+func parseOptionalUUID(id string) pgtype.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: parsed, Valid: true}
+}
+
+/*
+ * dataRoomGrantToResponse converts a grant, a grant is expired once its expiry is at or before now.
+ */
+func dataRoomGrantToResponse(grant db.ListDataRoomGrantsRow, now int64) DataRoomGrantResponse {
+	return DataRoomGrantResponse{
+		ID:                grant.ID,
+		DocumentID:        optionalUUIDString(grant.DocumentID),
+		DocumentName:      grant.DocumentName,
+		InvestorID:        optionalUUIDString(grant.InvestorID),
+		InvestorEmail:     grant.InvestorEmail,
+		InvestorFirstName: grant.InvestorFirstName,
+		InvestorLastName:  grant.InvestorLastName,
+		AllCommitted:      grant.AllCommitted,
+		ExpiresAt:         grant.ExpiresAt,
+		Expired:           grant.ExpiresAt != nil && *grant.ExpiresAt <= now,
+		CreatedAt:         grant.CreatedAt,
+	}
+}
+
+/*
+ * watermarkedDocumentKey returns the storage key of the copy of a PDF stamped with the name
+ * and email of the user. The copy is stamped on the first download and served again until
+ * a new version of the document is uploaded, copies of older versions are removed then.
+ */
+func (h *Handler) watermarkedDocumentKey(ctx context.Context, queries *db.Queries, doc db.ProjectDocument, user *db.User) (string, error) {
+	version := int64(doc.Version)
+
+	cached, err := queries.GetDocumentWatermark(ctx, db.GetDocumentWatermarkParams{
+		DocumentID:      doc.ID,
+		UserID:          user.ID,
+		DocumentVersion: version,
+	})
+	if err == nil {
+		return cached.StorageKey, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", err
+	}
+
+	store := h.server.GetStorage()
+	content, err := store.DownloadFile(ctx, doc.StorageKey)
+	if err != nil {
+		return "", err
+	}
+
+	stamped, err := service.WatermarkPDF(content, service.WatermarkText(user, time.Now()))
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("projects/%s/watermarks/%s/%d/%s.pdf", doc.ProjectID, doc.ID, version, user.ID)
+	if _, err := store.UploadFile(ctx, key, "application/pdf", stamped); err != nil {
+		return "", err
+	}
+
+	err = queries.CreateDocumentWatermark(ctx, db.CreateDocumentWatermarkParams{
+		DocumentID:      doc.ID,
+		UserID:          user.ID,
+		DocumentVersion: version,
+		StorageKey:      key,
+		Size:            int64(len(stamped)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	stale, err := queries.DeleteStaleDocumentWatermarks(ctx, db.DeleteStaleDocumentWatermarksParams{
+		DocumentID:      doc.ID,
+		UserID:          user.ID,
+		DocumentVersion: version,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("document_id", doc.ID).Msg("Failed to remove stale watermarked copies.")
+	}
+	for _, staleKey := range stale {
+		if err := store.DeleteFile(ctx, staleKey); err != nil {
+			log.Warn().Err(err).Str("key", staleKey).Msg("Failed to delete stale watermarked copy.")
+		}
+	}
+
+	return key, nil
+}
+
+/*
+ * handleUpdateProjectDocument changes the data room settings of a document: whether it is
+ * confidential and whether downloads are watermarked. Settings that are not set are left unchanged.
+ *
+ * Security:
+ * - Only the founder of the project can change its documents
+ */
+func (h *Handler) handleUpdateProjectDocument(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req UpdateDocumentRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	if req.Confidential == nil && req.Watermark == nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Nothing to update", nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role != projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	rows, err := queries.UpdateProjectDocumentAccess(ctx, db.UpdateProjectDocumentAccessParams{
+		Confidential: req.Confidential,
+		Watermark:    req.Watermark,
+		ID:           c.Param("document_id"),
+		ProjectID:    project.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update document", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Document not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Document updated successfully",
+	})
+}
+
+/*
+ * handleListDataRoom lists the documents of the data room the user can access.
+ *
+ * Security:
+ * - Investors see the documents that aren't confidential and the ones they were granted access to
+ * - The founder and admins see every document
+ * - Investors can't access the data room of drafts
+ */
+func (h *Handler) handleListDataRoom(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+
+	docs, err := queries.ListDataRoomDocuments(ctx, db.ListDataRoomDocumentsParams{
+		ProjectID:        project.ID,
+		IncludeAll:       role >= projectRoleFounder,
+		InvestorID:       user.ID,
+		IncludeUnscanned: user.ID == founderID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get documents", err)
+	}
+
+	response := DataRoomResponse{Documents: make([]DataRoomDocumentResponse, len(docs))}
+	for i, doc := range docs {
+		response.Documents[i] = DataRoomDocumentResponse{
+			ID:           doc.ID,
+			Name:         doc.Name,
+			Section:      doc.Section,
+			SubSection:   doc.SubSection,
+			MimeType:     doc.MimeType,
+			Size:         doc.Size,
+			Confidential: doc.Confidential,
+			Watermark:    doc.Watermark,
+			ScanStatus:   doc.ScanStatus,
+			CreatedAt:    doc.CreatedAt,
+			UpdatedAt:    doc.UpdatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleGetDataRoomDocumentURL returns a signed URL to view or download a data room document.
+ * The URL expires after dataRoomURLExpiry. PDFs with watermarking turned on are served as a
+ * copy stamped for the user. Every view or download by someone other than the founder is
+ * logged, no URL is returned when logging fails.
+ *
+ * parameters:
+ * - disposition: "inline" to view the document, "attachment" to download it (default)
+ *
+ * Security:
+ * - Same access rules as handleListDataRoom
+ */
+func (h *Handler) handleGetDataRoomDocumentURL(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req DataRoomURLRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+
+	documentID := c.Param("document_id")
+	if _, err := uuid.Parse(documentID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid document id", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+
+	doc, err := queries.GetDataRoomDocument(ctx, db.GetDataRoomDocumentParams{
+		ID:               documentID,
+		ProjectID:        project.ID,
+		IncludeAll:       role >= projectRoleFounder,
+		InvestorID:       user.ID,
+		IncludeUnscanned: user.ID == founderID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Document not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get document", err)
+	}
+
+	key := doc.StorageKey
+	if doc.Watermark && doc.MimeType == "application/pdf" && role != projectRoleFounder {
+		key, err = h.watermarkedDocumentKey(ctx, queries, doc, user)
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to watermark document", err)
+		}
+	}
+
+	inline := req.Disposition == "inline"
+	expiresAt := time.Now().Add(dataRoomURLExpiry)
+	url, err := h.server.GetStorage().GetSignedDownloadURL(ctx, key, doc.Name, inline, dataRoomURLExpiry)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to sign document URL", err)
+	}
+
+	if role != projectRoleFounder {
+		action := db.DataRoomActionDownload
+		if inline {
+			action = db.DataRoomActionView
+		}
+
+		err = queries.CreateDataRoomAccessLog(ctx, db.CreateDataRoomAccessLogParams{
+			ProjectID:    project.ID,
+			DocumentID:   parseOptionalUUID(doc.ID),
+			DocumentName: doc.Name,
+			UserID:       user.ID,
+			Action:       action,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to log document access", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, DataRoomURLResponse{
+		URL:       url,
+		ExpiresAt: expiresAt.Unix(),
+	})
+}
+
+/*
+ * handleListDataRoomGrants lists who was granted access to the confidential documents, newest first.
+ *
+ * Security:
+ * - Only the founder and admins can see the grants
+ */
+func (h *Handler) handleListDataRoomGrants(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role < projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	grants, err := queries.ListDataRoomGrants(ctx, project.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get grants", err)
+	}
+
+	now := time.Now().Unix()
+	response := DataRoomGrantsResponse{Grants: make([]DataRoomGrantResponse, len(grants))}
+	for i, grant := range grants {
+		response.Grants[i] = dataRoomGrantToResponse(grant, now)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleCreateDataRoomGrant grants an investor, or every investor with a transaction on the
+ * project, access to one confidential document or to all of them. Investors granted access
+ * by name are notified.
+ *
+ * Security:
+ * - Only the founder can grant access
+ * - The investor must be able to view all projects and can not be the founder
+ */
+func (h *Handler) handleCreateDataRoomGrant(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req CreateDataRoomGrantRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	if req.AllCommitted == (req.InvestorID != "") {
+		return v1_common.Fail(c, http.StatusBadRequest, "Either investor_id or all_committed must be set", nil)
+	}
+	if req.ExpiresAt != nil && *req.ExpiresAt <= time.Now().Unix() {
+		return v1_common.Fail(c, http.StatusBadRequest, "Expiry must be in the future", nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role != projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	if req.InvestorID != "" {
+		investor, err := queries.GetUserByID(ctx, req.InvestorID)
+		if err != nil || projectRoleFor(&investor, user.ID) != projectRoleInvestor {
+			return v1_common.Fail(c, http.StatusNotFound, "Investor not found", err)
+		}
+	}
+
+	var documentName string
+	if req.DocumentID != "" {
+		doc, err := queries.GetDataRoomDocument(ctx, db.GetDataRoomDocumentParams{
+			ID:               req.DocumentID,
+			ProjectID:        project.ID,
+			IncludeAll:       true,
+			InvestorID:       user.ID,
+			IncludeUnscanned: true,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusNotFound, "Document not found", err)
+		}
+		documentName = doc.Name
+	}
+
+	grant, err := queries.CreateDataRoomGrant(ctx, db.CreateDataRoomGrantParams{
+		ProjectID:    project.ID,
+		DocumentID:   parseOptionalUUID(req.DocumentID),
+		InvestorID:   parseOptionalUUID(req.InvestorID),
+		AllCommitted: req.AllCommitted,
+		ExpiresAt:    req.ExpiresAt,
+		GrantedBy:    parseOptionalUUID(user.ID),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create grant", err)
+	}
+
+	if req.InvestorID != "" {
+		message := fmt.Sprintf("You were granted access to the data room of %s.", project.Title)
+		if documentName != "" {
+			message = fmt.Sprintf("You were granted access to %s in the data room of %s.", documentName, project.Title)
+		}
+		err = service.NotifyUser(queries, ctx, req.InvestorID, db.NotificationTypeDataRoomAccessGranted, project.ID, message)
+		if err != nil {
+			middleware.GetLogger(c).Error(err, "Failed to notify investor about data room access.")
+		}
+	}
+
+	var name *string
+	if documentName != "" {
+		name = &documentName
+	}
+
+	return c.JSON(http.StatusCreated, dataRoomGrantToResponse(db.ListDataRoomGrantsRow{
+		ID:           grant.ID,
+		ProjectID:    grant.ProjectID,
+		DocumentID:   grant.DocumentID,
+		InvestorID:   grant.InvestorID,
+		AllCommitted: grant.AllCommitted,
+		ExpiresAt:    grant.ExpiresAt,
+		GrantedBy:    grant.GrantedBy,
+		CreatedAt:    grant.CreatedAt,
+		DocumentName: name,
+	}, time.Now().Unix()))
+}
+
+/*
+ * handleDeleteDataRoomGrant revokes a grant. Signed URLs that were already handed out
+ * stay valid until they expire.
+ *
+ * Security:
+ * - Only the founder can revoke access
+ */
+func (h *Handler) handleDeleteDataRoomGrant(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role != projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	rows, err := queries.DeleteDataRoomGrant(ctx, db.DeleteDataRoomGrantParams{
+		ID:        c.Param("grant_id"),
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to revoke grant", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Grant not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Grant revoked successfully",
+	})
+}
+
+/*
+ * handleGetDataRoomAccessLog lists who viewed or downloaded data room documents, newest first.
+ *
+ * parameters (all optional):
+ * - page, limit: pagination (default: page 1, 50 per page)
+ *
+ * Security:
+ * - Only the founder and admins can see the log
+ */
+func (h *Handler) handleGetDataRoomAccessLog(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req DataRoomAccessLogRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role < projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	total, err := queries.CountDataRoomAccessLogs(ctx, project.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to count access log", err)
+	}
+
+	entries, err := queries.ListDataRoomAccessLogs(ctx, db.ListDataRoomAccessLogsParams{
+		ProjectID:  project.ID,
+		PageSize:   int32(req.Limit),
+		PageOffset: int32((req.Page - 1) * req.Limit),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get access log", err)
+	}
+
+	response := DataRoomAccessLogResponse{
+		Entries: make([]DataRoomAccessLogEntryResponse, len(entries)),
+		Total:   total,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	}
+	for i, entry := range entries {
+		response.Entries[i] = DataRoomAccessLogEntryResponse{
+			ID:            entry.ID,
+			DocumentID:    optionalUUIDString(entry.DocumentID),
+			DocumentName:  entry.DocumentName,
+			UserID:        entry.UserID,
+			UserEmail:     entry.UserEmail,
+			UserFirstName: entry.UserFirstName,
+			UserLastName:  entry.UserLastName,
+			Action:        entry.Action,
+			CreatedAt:     entry.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
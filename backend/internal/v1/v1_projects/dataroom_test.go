@@ -0,0 +1,40 @@
+package v1_projects
+
+import (
+	"testing"
+
+	"KonferCA/SPUR/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataRoomGrantToResponse(t *testing.T) {
+	investorID := uuid.New()
+	now := int64(1_700_000_000)
+	past := now - 60
+	future := now + 60
+
+	grant := db.ListDataRoomGrantsRow{
+		ID:         uuid.NewString(),
+		InvestorID: pgtype.UUID{Bytes: investorID, Valid: true},
+		CreatedAt:  now - 3600,
+	}
+
+	response := dataRoomGrantToResponse(grant, now)
+	require.NotNil(t, response.InvestorID)
+	assert.Equal(t, investorID.String(), *response.InvestorID)
+	assert.Nil(t, response.DocumentID, "grant covers the whole data room")
+	assert.False(t, response.Expired, "grants without expiry never expire")
+
+	grant.ExpiresAt = &future
+	assert.False(t, dataRoomGrantToResponse(grant, now).Expired)
+
+	grant.ExpiresAt = &now
+	assert.True(t, dataRoomGrantToResponse(grant, now).Expired)
+
+	grant.ExpiresAt = &past
+	assert.True(t, dataRoomGrantToResponse(grant, now).Expired)
+}
@@ -0,0 +1,349 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// documentUploadExpiry is how long the client has to upload the file and complete a direct upload
+const documentUploadExpiry = 15 * time.Minute
+
+/*
+ * validateDocumentUpload checks the declared type and size of a direct upload against
+ * the rules of the question. Returns the status code and public error message,
+ * the message is empty when the upload is valid.
+ */
+func validateDocumentUpload(mimeType string, size int64, config middleware.FileConfig) (int, string) {
+	if size > config.MaxSize {
+		return http.StatusRequestEntityTooLarge, fmt.Sprintf("file size %d exceeds maximum allowed size of %d", size, config.MaxSize)
+	}
+	if size < config.MinSize {
+		return http.StatusBadRequest, fmt.Sprintf("file size %d below minimum required size of %d", size, config.MinSize)
+	}
+	for _, allowed := range config.AllowedTypes {
+		if strings.EqualFold(mimeType, allowed) {
+			return 0, ""
+		}
+	}
+	return http.StatusBadRequest, fmt.Sprintf("file type %s not allowed. Allowed types: %v", mimeType, config.AllowedTypes)
+}
+
+/*
+ * newDocumentUpload validates a request to start a direct or resumable upload and returns the
+ * upload to create, without its storage details. Errors are returned with v1_common.Fail.
+ * queries must be bound to the transaction creating the upload, the size is only reserved
+ * once the upload is created.
+ *
+ * Security:
+ * - Verifies project belongs to user's company
+ * - The declared type and size follow the rules of the question, a new file must not exceed its
+ *   maximum number of files
+ * - The declared size must fit in the storage quota, pending uploads count towards it
+ */
+func (h *Handler) newDocumentUpload(c echo.Context, queries *db.Queries, userID string, expiresAt time.Time) (db.CreateDocumentUploadParams, error) {
+	var req CreateDocumentUploadRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	ctx := c.Request().Context()
+	projectID := c.Param("id")
+
+	rules, err := getQuestionFileRules(ctx, queries, req.QuestionID)
+	if err != nil {
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusInternalServerError, "Failed to get question", err)
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(strings.Split(req.MimeType, ";")[0]))
+	if code, message := validateDocumentUpload(mimeType, req.Size, rules.FileConfig); message != "" {
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, code, message, nil)
+	}
+
+	company, err := queries.GetCompanyByUserID(ctx, userID)
+	if err != nil {
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+	}
+
+	if _, err := queries.GetProjectByID(ctx, db.GetProjectByIDParams{ID: projectID, CompanyID: company.ID}); err != nil {
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	// Check the document now rather than after the client uploaded the whole file
+	if req.DocumentID != "" {
+		doc, err := queries.GetProjectDocument(ctx, db.GetProjectDocumentParams{
+			ID:        req.DocumentID,
+			ProjectID: projectID,
+			CompanyID: company.ID,
+		})
+		if err != nil {
+			return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusNotFound, "Document not found", err)
+		}
+		if doc.QuestionID != req.QuestionID {
+			return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusBadRequest, "A new version must answer the same question", errDocumentQuestionMismatch)
+		}
+	} else {
+		message, err := checkQuestionFileCount(ctx, queries, projectID, req.QuestionID, rules)
+		if err != nil {
+			return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusInternalServerError, "Failed to count question files", err)
+		}
+		if message != "" {
+			return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusBadRequest, message, nil)
+		}
+	}
+
+	// The declared size is reserved by the upload until it completes or expires
+	if err := service.CheckStorageQuota(ctx, queries, company.ID, projectID, req.Size, 0); err != nil {
+		if errors.Is(err, service.ErrStorageQuotaExceeded) {
+			return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusRequestEntityTooLarge, err.Error(), err)
+		}
+		return db.CreateDocumentUploadParams{}, v1_common.Fail(c, http.StatusInternalServerError, "Failed to check storage quota", err)
+	}
+
+	return db.CreateDocumentUploadParams{
+		ProjectID:  projectID,
+		QuestionID: req.QuestionID,
+		DocumentID: parseOptionalUUID(req.DocumentID),
+		UploadedBy: userID,
+		StorageKey: fmt.Sprintf("projects/%s/documents/%s%s", projectID, uuid.New().String(), filepath.Ext(req.Name)),
+		Name:       req.Name,
+		Section:    req.Section,
+		SubSection: req.SubSection,
+		MimeType:   mimeType,
+		Size:       req.Size,
+		ExpiresAt:  expiresAt.Unix(),
+	}, nil
+}
+
+/*
+ * handleCreateDocumentUpload starts a direct upload of a project document. The response holds
+ * a presigned PUT URL bound to the declared content type and size, the file never goes
+ * through the server. The upload must be completed before it expires.
+ *
+ * Security: see newDocumentUpload
+ */
+func (h *Handler) handleCreateDocumentUpload(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	params, err := h.newDocumentUpload(c, queries, user.ID, time.Now().Add(documentUploadExpiry))
+	if err != nil {
+		return err
+	}
+
+	url, err := h.server.GetStorage().GetPresignedURL(ctx, params.StorageKey, params.MimeType, params.Size, documentUploadExpiry)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload URL", err)
+	}
+
+	upload, err := queries.CreateDocumentUpload(ctx, params)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+
+	return c.JSON(http.StatusCreated, DocumentUploadResponse{
+		ID:        upload.ID,
+		UploadURL: url,
+		Method:    http.MethodPut,
+		Headers: map[string]string{
+			echo.HeaderContentType:   upload.MimeType,
+			echo.HeaderContentLength: fmt.Sprintf("%d", upload.Size),
+		},
+		ExpiresAt: upload.ExpiresAt,
+	})
+}
+
+/*
+ * handleCompleteDocumentUpload creates the document of a direct upload once the client
+ * uploaded the file.
+ *
+ * Flow:
+ * 1. Loads the upload started by the user
+ * 2. Assembles the chunks of a resumable upload into the file, once they were all uploaded
+ * 3. Reads the size and type of the stored file, they must match the declared ones
+ * 4. Validates the content of the file like FileCheck, with the rules of the question
+ * 5. Images answering an image question are replaced by their standard sizes
+ * 6. Creates the document record, or the next version of the document, and deletes the upload
+ *    in the same transaction. An upload that was already completed or has expired gets 409
+ * 7. Starts the malware scan
+ * 8. Returns document details
+ *
+ * Cleanup:
+ * - A file that doesn't match the upload or fails validation is deleted together with the upload
+ * - Expired uploads are removed by the upload cleaner
+ */
+func (h *Handler) handleCompleteDocumentUpload(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	uploadID := c.Param("upload_id")
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid upload id", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+	store := h.server.GetStorage()
+
+	company, err := queries.GetCompanyByUserID(ctx, user.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Company not found", err)
+	}
+
+	upload, err := queries.GetDocumentUpload(ctx, db.GetDocumentUploadParams{
+		ID:         uploadID,
+		ProjectID:  c.Param("id"),
+		UploadedBy: user.ID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Upload not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get upload", err)
+	}
+
+	if upload.ExpiresAt < time.Now().Unix() {
+		return v1_common.Fail(c, http.StatusBadRequest, "Upload expired, please upload the file again", nil)
+	}
+
+	if upload.MultipartID != nil {
+		if err := h.assembleResumableUpload(c, upload); err != nil {
+			return err
+		}
+	}
+
+	info, err := store.HeadFile(ctx, upload.StorageKey)
+	if err != nil {
+		if err == storage.ErrFileNotFound {
+			return v1_common.Fail(c, http.StatusBadRequest, "The file has not been uploaded yet", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to check uploaded file", err)
+	}
+
+	// The client has to start over with a new upload
+	discardUpload := func() {
+		if err := store.DeleteFile(ctx, upload.StorageKey); err != nil {
+			log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete rejected upload.")
+		} else if _, err := queries.DeleteDocumentUpload(ctx, upload.ID); err != nil {
+			log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete upload.")
+		}
+	}
+
+	// The type the file was stored with, the content itself is checked by ValidateFileContent
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(info.ContentType, ";")[0]))
+	if info.Size != upload.Size || contentType != upload.MimeType {
+		discardUpload()
+		return v1_common.Fail(c, http.StatusBadRequest, "The uploaded file doesn't match the declared size or type",
+			fmt.Errorf("declared %s of %d bytes, uploaded %s of %d bytes", upload.MimeType, upload.Size, contentType, info.Size))
+	}
+
+	rules, err := getQuestionFileRules(ctx, queries, upload.QuestionID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get question", err)
+	}
+
+	content, err := store.DownloadFile(ctx, upload.StorageKey)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read uploaded file", err)
+	}
+
+	// The file never went through FileCheck, the same rules apply now that it is stored
+	if err := middleware.ValidateFileContent(upload.Name, upload.MimeType, content, rules.FileConfig); err != nil {
+		discardUpload()
+		var inspectionErr *middleware.ContentInspectionError
+		if errors.As(err, &inspectionErr) {
+			return v1_common.FailWithData(c, http.StatusBadRequest, inspectionErr.Error(), inspectionErr, err)
+		}
+		return err
+	}
+
+	var documentID string
+	if id := optionalUUIDString(upload.DocumentID); id != nil {
+		documentID = *id
+	}
+
+	file := db.ProjectDocument{
+		ProjectID:  upload.ProjectID,
+		QuestionID: upload.QuestionID,
+		StorageKey: upload.StorageKey,
+		Name:       upload.Name,
+		Section:    upload.Section,
+		SubSection: upload.SubSection,
+		MimeType:   upload.MimeType,
+		Size:       info.Size,
+	}
+
+	imageQuestion, err := isImageQuestion(ctx, queries, upload.QuestionID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get question", err)
+	}
+
+	if imageQuestion {
+		file, content, err = storeDocumentImage(ctx, store, file, content)
+		if err != nil {
+			if err == service.ErrInvalidImage || err == service.ErrImageTooLarge {
+				return v1_common.Fail(c, http.StatusBadRequest, err.Error(), err)
+			}
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to process image", err)
+		}
+	}
+
+	doc, err := h.saveDocumentVersion(ctx, user, company.ID, documentID, file, upload.ID)
+	if err != nil {
+		if imageQuestion {
+			_ = service.DeleteStoredFile(ctx, store, file.StorageKey)
+		}
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Document not found", err)
+		}
+		if err == errUploadClaimed {
+			return v1_common.Fail(c, http.StatusConflict, "Upload already completed or expired", err)
+		}
+		if err == errDocumentQuestionMismatch {
+			return v1_common.Fail(c, http.StatusBadRequest, "A new version must answer the same question", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to save document record", err)
+	}
+
+	// The uploaded image was replaced by its sizes
+	if imageQuestion {
+		if err := store.DeleteFile(ctx, upload.StorageKey); err != nil {
+			log.Error().Err(err).Str("upload_id", upload.ID).Msg("Failed to delete original image.")
+		}
+	}
+
+	go service.ScanProjectDocument(queries, store, h.server.GetScanner(), doc, content)
+
+	return c.JSON(http.StatusCreated, buildDocumentResponse(ctx, store, doc))
+}
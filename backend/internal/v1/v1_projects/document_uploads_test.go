@@ -0,0 +1,43 @@
+package v1_projects
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDocumentUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		mimeType string
+		size     int64
+		code     int
+	}{
+		{name: "pdf within limits", mimeType: "application/pdf", size: 2048},
+		{name: "type is case insensitive", mimeType: "Application/PDF", size: 2048},
+		{name: "too small", mimeType: "application/pdf", size: 10, code: http.StatusBadRequest},
+		{name: "too large", mimeType: "application/pdf", size: 11 * 1024 * 1024, code: http.StatusRequestEntityTooLarge},
+		{name: "type not allowed", mimeType: "application/zip", size: 2048, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message := validateDocumentUpload(tt.mimeType, tt.size, documentFileConfig)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.code == 0, message == "")
+		})
+	}
+}
+
+func TestValidateDocumentUploadQuestionLimit(t *testing.T) {
+	config := documentFileConfig
+	config.MaxSize = 100 * 1024 * 1024
+
+	code, message := validateDocumentUpload("application/pdf", 60*1024*1024, config)
+	assert.Zero(t, code)
+	assert.Empty(t, message)
+
+	code, _ = validateDocumentUpload("application/pdf", 101*1024*1024, config)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+}
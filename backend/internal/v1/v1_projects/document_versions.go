@@ -0,0 +1,216 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+var errDocumentQuestionMismatch = errors.New("document answers another question")
+
+var errUploadClaimed = errors.New("upload already completed or expired")
+
+/*
+ * saveDocumentVersion stores an uploaded file as a new document, or as the next version of
+ * the document with documentID. Either way the file is recorded in the version history and
+ * the upload in the activity timeline of the project.
+ * A file sent through the upload with uploadID had its size reserved when the upload was created,
+ * the upload is claimed in the same transaction so it is completed once and the upload cleaner
+ * never deletes the file of the document. Any other file must fit in the storage quota, checked
+ * in the same transaction so concurrent uploads can't both take the space left for one.
+ * Returns pgx.ErrNoRows when documentID is not a document of the project owned by the company,
+ * errUploadClaimed when the upload is gone and a *service.StorageQuotaError when the file doesn't fit.
+ */
+func (h *Handler) saveDocumentVersion(ctx context.Context, user *db.User, companyID string, documentID string, file db.ProjectDocument, uploadID string) (db.ProjectDocument, error) {
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return db.ProjectDocument{}, err
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	if uploadID != "" {
+		claimed, err := queries.ClaimDocumentUpload(ctx, uploadID)
+		if err != nil {
+			return db.ProjectDocument{}, err
+		}
+		if claimed == 0 {
+			return db.ProjectDocument{}, errUploadClaimed
+		}
+	} else if err := service.CheckStorageQuota(ctx, queries, companyID, file.ProjectID, file.Size, 0); err != nil {
+		return db.ProjectDocument{}, err
+	}
+
+	var doc db.ProjectDocument
+	if documentID == "" {
+		doc, err = queries.CreateProjectDocument(ctx, db.CreateProjectDocumentParams{
+			ProjectID:  file.ProjectID,
+			QuestionID: file.QuestionID,
+			Name:       file.Name,
+			StorageKey: file.StorageKey,
+			Section:    file.Section,
+			SubSection: file.SubSection,
+			MimeType:   file.MimeType,
+			Size:       file.Size,
+		})
+	} else {
+		var previous db.ProjectDocument
+		previous, err = queries.GetProjectDocument(ctx, db.GetProjectDocumentParams{
+			ID:        documentID,
+			ProjectID: file.ProjectID,
+			CompanyID: companyID,
+		})
+		if err != nil {
+			return db.ProjectDocument{}, err
+		}
+		if previous.QuestionID != file.QuestionID {
+			return db.ProjectDocument{}, errDocumentQuestionMismatch
+		}
+
+		doc, err = queries.UpdateProjectDocumentFile(ctx, db.UpdateProjectDocumentFileParams{
+			Name:       file.Name,
+			StorageKey: file.StorageKey,
+			MimeType:   file.MimeType,
+			Size:       file.Size,
+			ID:         previous.ID,
+			ProjectID:  previous.ProjectID,
+		})
+	}
+	if err != nil {
+		return db.ProjectDocument{}, err
+	}
+
+	_, err = queries.CreateProjectDocumentVersion(ctx, db.CreateProjectDocumentVersionParams{
+		DocumentID: doc.ID,
+		ProjectID:  doc.ProjectID,
+		QuestionID: doc.QuestionID,
+		Version:    doc.Version,
+		Name:       doc.Name,
+		StorageKey: doc.StorageKey,
+		MimeType:   doc.MimeType,
+		Size:       doc.Size,
+		UploadedBy: parseOptionalUUID(user.ID),
+	})
+	if err != nil {
+		return db.ProjectDocument{}, err
+	}
+
+	err = service.RecordActivityInTx(ctx, tx, func(queries *db.Queries) error {
+		return service.RecordProjectActivity(queries, ctx, doc.ProjectID, user.ID, db.ProjectActivityTypeDocumentUploaded, false, service.ActivityPayload{
+			"document_id": doc.ID,
+			"question_id": doc.QuestionID,
+			"name":        doc.Name,
+			"version":     doc.Version,
+		})
+	})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", doc.ProjectID).Str("document_id", doc.ID).Msg("Failed to record project activity.")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.ProjectDocument{}, err
+	}
+
+	return doc, nil
+}
+
+/*
+ * buildDocumentVersionResponses assembles the versions of a document with the snapshots
+ * they were submitted with. Every version gets a signed URL to download its file.
+ */
+func buildDocumentVersionResponses(ctx context.Context, store storage.Storage, versions []db.ListProjectDocumentVersionsRow, snapshots []db.ListDocumentVersionSnapshotsRow) []DocumentVersionResponse {
+	byVersion := make(map[string][]DocumentVersionSnapshotResponse, len(versions))
+	for _, snapshot := range snapshots {
+		byVersion[snapshot.VersionID] = append(byVersion[snapshot.VersionID], DocumentVersionSnapshotResponse{
+			SnapshotID:    snapshot.SnapshotID,
+			VersionNumber: snapshot.VersionNumber,
+			CreatedAt:     snapshot.CreatedAt,
+		})
+	}
+
+	response := make([]DocumentVersionResponse, len(versions))
+	for i, version := range versions {
+		response[i] = DocumentVersionResponse{
+			ID:                  version.ID,
+			DocumentID:          version.DocumentID,
+			Version:             version.Version,
+			Name:                version.Name,
+			URL:                 v1_common.SignedFileURL(ctx, store, version.StorageKey, version.Name),
+			MimeType:            version.MimeType,
+			Size:                version.Size,
+			UploadedBy:          optionalUUIDString(version.UploadedBy),
+			UploadedByFirstName: version.UploadedByFirstName,
+			UploadedByLastName:  version.UploadedByLastName,
+			Snapshots:           byVersion[version.ID],
+			CreatedAt:           version.CreatedAt,
+		}
+		if response[i].Snapshots == nil {
+			response[i].Snapshots = []DocumentVersionSnapshotResponse{}
+		}
+	}
+
+	return response
+}
+
+/*
+ * handleListProjectDocumentVersions lists every version of a document, newest first, with who
+ * uploaded it and the submissions it was part of. The history is kept after the document is
+ * deleted, so reviewers can always find the files they evaluated.
+ *
+ * Security:
+ * - Only the founder and admins can see the history
+ * - Admins don't see the current version until it was scanned clean
+ */
+func (h *Handler) handleListProjectDocumentVersions(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	documentID := c.Param("document_id")
+	if _, err := uuid.Parse(documentID); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid document id", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil || role < projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	versions, err := queries.ListProjectDocumentVersions(ctx, db.ListProjectDocumentVersionsParams{
+		DocumentID:       documentID,
+		ProjectID:        project.ID,
+		IncludeUnscanned: user.ID == founderID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get versions", err)
+	}
+	if len(versions) == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Document not found", nil)
+	}
+
+	versionIDs := make([]string, len(versions))
+	for i, version := range versions {
+		versionIDs[i] = version.ID
+	}
+	snapshots, err := queries.ListDocumentVersionSnapshots(ctx, versionIDs)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get snapshots", err)
+	}
+
+	return c.JSON(http.StatusOK, DocumentVersionsResponse{
+		Versions: buildDocumentVersionResponses(ctx, h.server.GetStorage(), versions, snapshots),
+	})
+}
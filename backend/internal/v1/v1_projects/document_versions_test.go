@@ -0,0 +1,41 @@
+package v1_projects
+
+import (
+	"context"
+	"testing"
+
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDocumentVersionResponses(t *testing.T) {
+	versions := []db.ListProjectDocumentVersionsRow{
+		{ID: "version-2", DocumentID: "deck", Version: 2, Name: "deck-v2.pdf", StorageKey: "projects/p/documents/v2.pdf"},
+		{ID: "version-1", DocumentID: "deck", Version: 1, Name: "deck-v1.pdf", StorageKey: "projects/p/documents/v1.pdf"},
+	}
+	snapshots := []db.ListDocumentVersionSnapshotsRow{
+		{VersionID: "version-1", SnapshotID: "snapshot-1", VersionNumber: 1},
+		{VersionID: "version-1", SnapshotID: "snapshot-2", VersionNumber: 2},
+	}
+
+	store := storage.NewMemoryStorage("/files/", "secret")
+	response := buildDocumentVersionResponses(context.Background(), store, versions, snapshots)
+	require.Len(t, response, 2)
+
+	// Each version is downloaded through its own signed URL, under the name it was uploaded with
+	assert.Contains(t, response[0].URL, "/files/projects/p/documents/v2.pdf?")
+	assert.Contains(t, response[0].URL, "signature=")
+	assert.Contains(t, response[1].URL, "deck-v1.pdf")
+
+	assert.Equal(t, int32(2), response[0].Version)
+	assert.NotNil(t, response[0].Snapshots, "versions without snapshots have an empty list")
+	assert.Empty(t, response[0].Snapshots)
+	assert.Nil(t, response[0].UploadedBy)
+
+	require.Len(t, response[1].Snapshots, 2)
+	assert.Equal(t, "snapshot-1", response[1].Snapshots[0].SnapshotID)
+	assert.Equal(t, int32(2), response[1].Snapshots[1].VersionNumber)
+}
@@ -2,32 +2,108 @@ package v1_projects
 
 import (
 	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
 	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
+// featuredImagesQuestionKey is the question whose files are images, processed like profile pictures
+const featuredImagesQuestionKey = "company_featured_images"
+
+/*
+ * isImageQuestion reports if the files answering a question are processed as images, see
+ * storeDocumentImage. Unknown questions are not, saving the document rejects them.
+ */
+func isImageQuestion(ctx context.Context, queries *db.Queries, questionID string) (bool, error) {
+	question, err := queries.GetProjectQuestion(ctx, questionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return question.QuestionKey != nil && *question.QuestionKey == featuredImagesQuestionKey, nil
+}
+
+/*
+ * storeDocumentImage stores the standard sizes of an image uploaded for an image question in
+ * place of the original, see service.StoreImage. Returns the file to save as document with
+ * the content of its full size.
+ */
+func storeDocumentImage(ctx context.Context, store storage.Storage, file db.ProjectDocument, content []byte) (db.ProjectDocument, []byte, error) {
+	dir := fmt.Sprintf("projects/%s/documents/%s", file.ProjectID, uuid.New().String())
+	key, full, err := service.StoreImage(ctx, store, dir, content)
+	if err != nil {
+		return db.ProjectDocument{}, nil, err
+	}
+
+	file.StorageKey = key
+	file.Name = strings.TrimSuffix(file.Name, filepath.Ext(file.Name)) + ".jpg"
+	file.MimeType = "image/jpeg"
+	file.Size = int64(len(full))
+	return file, full, nil
+}
+
+/*
+ * buildDocumentResponse converts a document to its response, with a signed URL to download
+ * the current file. Only call it for users allowed to see the document.
+ */
+func buildDocumentResponse(ctx context.Context, store storage.Storage, doc db.ProjectDocument) DocumentResponse {
+	return DocumentResponse{
+		ID:            doc.ID,
+		Name:          doc.Name,
+		URL:           v1_common.SignedFileURL(ctx, store, doc.StorageKey, doc.Name),
+		URLs:          documentImageURLs(ctx, store, doc),
+		Section:       doc.Section,
+		Confidential:  doc.Confidential,
+		Watermark:     doc.Watermark,
+		Version:       doc.Version,
+		ScanStatus:    doc.ScanStatus,
+		ScanSignature: doc.ScanSignature,
+		CreatedAt:     doc.CreatedAt,
+		UpdatedAt:     doc.UpdatedAt,
+	}
+}
+
+// documentImageURLs returns a signed URL per size of the document when it is a processed image
+func documentImageURLs(ctx context.Context, store storage.Storage, doc db.ProjectDocument) map[string]string {
+	if !service.IsStoredImage(doc.StorageKey) {
+		return nil
+	}
+	return v1_common.SignedImageURLs(ctx, store, doc.StorageKey)
+}
+
 /*
  * handleUploadProjectDocument handles file uploads for a project.
  *
  * Flow:
  * 1. Validates file presence
- * 2. Verifies project ownership
- * 3. Uploads file to S3
- * 4. Creates document record in database
- * 5. Returns document details
+ * 2. Verifies project ownership and the file rules of the question: allowed types, size bounds and
+ *    number of files. Files larger than the route allows use resumable uploads
+ * 3. Uploads file to S3, images answering an image question are stored as standard sizes instead
+ * 4. Checks the storage quota of the company and the project, the previous versions stay in storage
+ * 5. Creates the document record, or moves an existing document to the new file when document_id is set
+ * 6. Records the file as a new version of the document
+ * 7. Starts the malware scan, the document is only shown to others once it is clean
+ * 8. Returns document details
  *
  * Cleanup:
- * - Deletes S3 file if database insert fails
+ * - Deletes S3 file if it doesn't fit in the quota or the database insert fails
  */
 func (h *Handler) handleUploadProjectDocument(c echo.Context) error {
 	user, err := getUserFromContext(c)
@@ -85,41 +161,93 @@ func (h *Handler) handleUploadProjectDocument(c echo.Context) error {
 		return v1_common.Fail(c, 500, "Failed to read file", err)
 	}
 
-	// Generate S3 key
-	fileExt := filepath.Ext(file.Filename)
-	s3Key := fmt.Sprintf("projects/%s/documents/%s%s", projectID, uuid.New().String(), fileExt)
-
-	// Upload to S3
-	fileURL, err := h.server.GetStorage().UploadFile(c.Request().Context(), s3Key, fileContent)
+	// FileCheck only bounds the size of the request, the file follows the rules of its question
+	rules, err := getQuestionFileRules(c.Request().Context(), h.server.GetQueries(), req.QuestionID)
 	if err != nil {
-		return v1_common.Fail(c, 500, "Failed to upload file", err)
+		return v1_common.Fail(c, 500, "Failed to get question", err)
+	}
+	if err := middleware.ValidateFileContent(file.Filename, mimeType, fileContent, rules.FileConfig); err != nil {
+		var inspectionErr *middleware.ContentInspectionError
+		if errors.As(err, &inspectionErr) {
+			return v1_common.FailWithData(c, 400, inspectionErr.Error(), inspectionErr, err)
+		}
+		return err
+	}
+
+	// Check the document before the file is stored
+	if req.DocumentID != "" {
+		_, err := h.server.GetQueries().GetProjectDocument(c.Request().Context(), db.GetProjectDocumentParams{
+			ID:        req.DocumentID,
+			ProjectID: projectID,
+			CompanyID: company.ID,
+		})
+		if err != nil {
+			return v1_common.Fail(c, 404, "Document not found", err)
+		}
+	} else {
+		message, err := checkQuestionFileCount(c.Request().Context(), h.server.GetQueries(), projectID, req.QuestionID, rules)
+		if err != nil {
+			return v1_common.Fail(c, 500, "Failed to count question files", err)
+		}
+		if message != "" {
+			return v1_common.Fail(c, 400, message, nil)
+		}
 	}
 
-	// Save document record in database
-	doc, err := h.server.GetQueries().CreateProjectDocument(c.Request().Context(), db.CreateProjectDocumentParams{
+	upload := db.ProjectDocument{
 		ProjectID:  projectID,
 		QuestionID: req.QuestionID,
-		Url:        fileURL,
 		Name:       req.Name,
 		Section:    req.Section,
 		SubSection: req.SubSection,
 		MimeType:   mimeType,
 		Size:       int64(len(fileContent)),
-	})
+	}
+
+	imageQuestion, err := isImageQuestion(c.Request().Context(), h.server.GetQueries(), req.QuestionID)
+	if err != nil {
+		return v1_common.Fail(c, 500, "Failed to get question", err)
+	}
+
+	if imageQuestion {
+		upload, fileContent, err = storeDocumentImage(c.Request().Context(), h.server.GetStorage(), upload, fileContent)
+		if err != nil {
+			if err == service.ErrInvalidImage || err == service.ErrImageTooLarge {
+				return v1_common.Fail(c, 400, err.Error(), err)
+			}
+			return v1_common.Fail(c, 500, "Failed to upload file", err)
+		}
+	} else {
+		// Generate S3 key
+		fileExt := filepath.Ext(file.Filename)
+		upload.StorageKey = fmt.Sprintf("projects/%s/documents/%s%s", projectID, uuid.New().String(), fileExt)
+
+		// Upload to S3
+		if _, err := h.server.GetStorage().UploadFile(c.Request().Context(), upload.StorageKey, mimeType, fileContent); err != nil {
+			return v1_common.Fail(c, 500, "Failed to upload file", err)
+		}
+	}
+
+	// Save document record in database, once the file fits in the storage quota
+	doc, err := h.saveDocumentVersion(c.Request().Context(), user, company.ID, req.DocumentID, upload, "")
 	if err != nil {
 		// Try to cleanup the uploaded file if database insert fails
-		_ = h.server.GetStorage().DeleteFile(c.Request().Context(), s3Key)
+		_ = service.DeleteStoredFile(c.Request().Context(), h.server.GetStorage(), upload.StorageKey)
+		if errors.Is(err, service.ErrStorageQuotaExceeded) {
+			return v1_common.Fail(c, http.StatusRequestEntityTooLarge, err.Error(), err)
+		}
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, 404, "Document not found", err)
+		}
+		if err == errDocumentQuestionMismatch {
+			return v1_common.Fail(c, 400, "A new version must answer the same question", err)
+		}
 		return v1_common.Fail(c, 500, "Failed to save document record", err)
 	}
 
-	return c.JSON(201, DocumentResponse{
-		ID:        doc.ID,
-		Name:      doc.Name,
-		URL:       doc.Url,
-		Section:   doc.Section,
-		CreatedAt: doc.CreatedAt,
-		UpdatedAt: doc.UpdatedAt,
-	})
+	go service.ScanProjectDocument(h.server.GetQueries(), h.server.GetStorage(), h.server.GetScanner(), doc, fileContent)
+
+	return c.JSON(201, buildDocumentResponse(c.Request().Context(), h.server.GetStorage(), doc))
 }
 
 /*
@@ -132,6 +260,7 @@ func (h *Handler) handleUploadProjectDocument(c echo.Context) error {
  *
  * Security:
  * - Verifies project belongs to user's company
+ * - Users that view all projects only see documents that were scanned clean, unless they own the project
  */
 func (h *Handler) handleGetProjectDocuments(c echo.Context) error {
 	user, err := getUserFromContext(c)
@@ -148,7 +277,15 @@ func (h *Handler) handleGetProjectDocuments(c echo.Context) error {
 	// Check if user is admin
 	isAdmin := permissions.HasAllPermissions(uint32(user.Permissions), permissions.PermViewAllProjects)
 
-	if !isAdmin {
+	// Documents waiting for their scan, or found infected, are only shown to the founder
+	showUnscanned := !isAdmin
+	if isAdmin {
+		_, _, founderID, err := getProjectWithRole(h.server.GetQueries(), c.Request().Context(), user, projectID)
+		if err != nil {
+			return v1_common.Fail(c, 404, "Project not found", err)
+		}
+		showUnscanned = founderID == user.ID
+	} else {
 		// Get company owned by user
 		company, err := h.server.GetQueries().GetCompanyByUserID(c.Request().Context(), user.ID)
 		if err != nil {
@@ -172,16 +309,12 @@ func (h *Handler) handleGetProjectDocuments(c echo.Context) error {
 	}
 
 	// Convert to response format
-	response := make([]DocumentResponse, len(docs))
-	for i, doc := range docs {
-		response[i] = DocumentResponse{
-			ID:        doc.ID,
-			Name:      doc.Name,
-			URL:       doc.Url,
-			Section:   doc.Section,
-			CreatedAt: doc.CreatedAt,
-			UpdatedAt: doc.UpdatedAt,
+	response := make([]DocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		if !showUnscanned && doc.ScanStatus != db.ScanStatusClean {
+			continue
 		}
+		response = append(response, buildDocumentResponse(c.Request().Context(), h.server.GetStorage(), doc))
 	}
 
 	return c.JSON(200, map[string]interface{}{
@@ -194,8 +327,8 @@ func (h *Handler) handleGetProjectDocuments(c echo.Context) error {
  *
  * Flow:
  * 1. Verifies document ownership
- * 2. Deletes file from S3
- * 3. Removes database record
+ * 2. Deletes its watermarked copies from S3
+ * 3. Removes database record, the versions and their files are kept
  *
  * Security:
  * - Verifies document belongs to user's project
@@ -232,15 +365,28 @@ func (h *Handler) handleDeleteProjectDocument(c echo.Context) error {
 		return v1_common.Fail(c, 500, "Failed to get document", nil)
 	}
 
-	// Delete from S3 first
-	s3Key := strings.TrimPrefix(doc.Url, "https://"+os.Getenv("AWS_S3_BUCKET")+".s3.us-east-1.amazonaws.com/")
-	err = h.server.GetStorage().DeleteFile(c.Request().Context(), s3Key)
+	// Watermarked copies are removed with the document record, keep their keys to delete the files
+	watermarkKeys, err := h.server.GetQueries().ListDocumentWatermarkKeys(c.Request().Context(), doc.ID)
 	if err != nil {
-		return v1_common.Fail(c, 500, "Failed to delete file from storage", nil)
+		return v1_common.Fail(c, 500, "Failed to get document copies", nil)
 	}
 
-	// Then delete from database
-	deletedID, err := h.server.GetQueries().DeleteProjectDocument(c.Request().Context(), db.DeleteProjectDocumentParams{
+	// The files of the versions stay in storage, they are part of the history of the project
+	for _, key := range watermarkKeys {
+		if err := h.server.GetStorage().DeleteFile(c.Request().Context(), key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to delete watermarked copy.")
+		}
+	}
+
+	// Then delete from database, together with the entry of the activity timeline
+	ctx := c.Request().Context()
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, 500, "Failed to delete document", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	deletedID, err := h.server.GetQueries().WithTx(tx).DeleteProjectDocument(ctx, db.DeleteProjectDocumentParams{
 		ID:        documentID,
 		ProjectID: projectID,
 		CompanyID: company.ID,
@@ -256,6 +402,21 @@ func (h *Handler) handleDeleteProjectDocument(c echo.Context) error {
 		return v1_common.Fail(c, 404, "Document not found or already deleted", nil)
 	}
 
+	err = service.RecordActivityInTx(ctx, tx, func(queries *db.Queries) error {
+		return service.RecordProjectActivity(queries, ctx, projectID, user.ID, db.ProjectActivityTypeDocumentDeleted, false, service.ActivityPayload{
+			"document_id": doc.ID,
+			"question_id": doc.QuestionID,
+			"name":        doc.Name,
+		})
+	})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", projectID).Str("document_id", doc.ID).Msg("Failed to record project activity.")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, 500, "Failed to delete document", err)
+	}
+
 	return c.JSON(200, map[string]string{
 		"message": "Document deleted successfully",
 	})
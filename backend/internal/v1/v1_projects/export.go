@@ -0,0 +1,107 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+/*
+ * dossierFilename builds the download name of a dossier, e.g. acme-corp-v2.pdf
+ */
+func dossierFilename(title string, version int32, format db.DossierFormat) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if name == "" {
+		name = "application"
+	}
+	return fmt.Sprintf("%s-v%d.%s", name, version, format)
+}
+
+/*
+ * handleExportProject exports a submitted application as a single document.
+ * The dossier is rendered from a project snapshot, so it shows the application exactly as it
+ * was submitted: the answers by section, the funding structure, the team and the documents.
+ *
+ * parameters (all optional):
+ * - format: html (default) or pdf
+ * - version: snapshot version to export (default: latest submitted version)
+ *
+ * Security:
+ * - Only the founder and admins can export a project, the dossier lists every document
+ *   including the confidential ones
+ */
+func (h *Handler) handleExportProject(c echo.Context) error {
+	projectID := c.Param("id")
+	if projectID == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Project ID is required", nil)
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req ExportProjectRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	format := db.DossierFormatHtml
+	if req.Format != "" {
+		format = req.Format
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, projectID)
+	if err != nil && err != pgx.ErrNoRows {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to export project", err)
+	}
+	if err != nil || role < projectRoleFounder {
+		return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+	}
+
+	var snapshot db.ProjectSnapshot
+	if req.Version > 0 {
+		snapshot, err = queries.GetProjectSnapshotByVersion(ctx, db.GetProjectSnapshotByVersionParams{
+			ProjectID:     project.ID,
+			VersionNumber: req.Version,
+		})
+	} else {
+		snapshot, err = service.GetLatestProjectSnapshot(queries, ctx, project.ID)
+	}
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project has no submitted version to export", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to export project", err)
+	}
+
+	companyName := ""
+	if company, err := queries.GetCompanyByID(ctx, project.CompanyID); err == nil {
+		companyName = company.Name
+	}
+
+	content, err := service.GetProjectDossier(queries, ctx, snapshot, companyName, format)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to export project", err)
+	}
+
+	contentType := echo.MIMETextHTMLCharsetUTF8
+	if format == db.DossierFormatPdf {
+		contentType = "application/pdf"
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=%q", dossierFilename(snapshot.Title, snapshot.VersionNumber, format)))
+	return c.Blob(http.StatusOK, contentType, content)
+}
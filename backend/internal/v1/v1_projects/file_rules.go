@@ -0,0 +1,144 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// questionFileRules are the rules for the files answering a question
+type questionFileRules struct {
+	middleware.FileConfig
+	// MaxFiles is the number of documents that can answer the question, 0 means no limit
+	MaxFiles int64
+}
+
+// defaultFileRules apply to the questions without rules of their own
+var defaultFileRules = questionFileRules{FileConfig: documentFileConfig}
+
+/*
+ * fileRulesOf applies the rule of a question over the defaults of documentFileConfig,
+ * the columns left NULL keep the default.
+ */
+func fileRulesOf(rule db.ProjectQuestionFileRule) questionFileRules {
+	rules := defaultFileRules
+	if rule.AllowedTypes != nil {
+		rules.AllowedTypes = rule.AllowedTypes
+	}
+	if rule.MinSize != nil {
+		rules.MinSize = *rule.MinSize
+	}
+	if rule.MaxSize != nil {
+		rules.MaxSize = *rule.MaxSize
+	}
+	if rule.MaxFiles != nil {
+		rules.MaxFiles = int64(*rule.MaxFiles)
+	}
+	return rules
+}
+
+func questionFileRulesResponse(questionID string, rules questionFileRules) QuestionFileRulesResponse {
+	return QuestionFileRulesResponse{
+		QuestionID:   questionID,
+		AllowedTypes: rules.AllowedTypes,
+		MinSize:      rules.MinSize,
+		MaxSize:      rules.MaxSize,
+		MaxFiles:     rules.MaxFiles,
+	}
+}
+
+// getQuestionFileRules returns the rules for the files answering a question, the defaults when it has none
+func getQuestionFileRules(ctx context.Context, queries *db.Queries, questionID string) (questionFileRules, error) {
+	rule, err := queries.GetProjectQuestionFileRule(ctx, questionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return defaultFileRules, nil
+		}
+		return questionFileRules{}, err
+	}
+	return fileRulesOf(rule), nil
+}
+
+// listQuestionFileRules returns the rules of the questions that have their own, by question id
+func listQuestionFileRules(ctx context.Context, queries *db.Queries) (map[string]questionFileRules, error) {
+	rows, err := queries.ListProjectQuestionFileRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(map[string]questionFileRules, len(rows))
+	for _, row := range rows {
+		rules[row.QuestionID] = fileRulesOf(row)
+	}
+	return rules, nil
+}
+
+/*
+ * checkQuestionFileCount returns a public error message when the question already has as many
+ * files as it accepts, counting the uploads in progress. New versions don't add a file.
+ */
+func checkQuestionFileCount(ctx context.Context, queries *db.Queries, projectID string, questionID string, rules questionFileRules) (string, error) {
+	if rules.MaxFiles == 0 {
+		return "", nil
+	}
+
+	files, err := queries.CountQuestionFiles(ctx, db.CountQuestionFilesParams{ProjectID: projectID, QuestionID: questionID})
+	if err != nil {
+		return "", err
+	}
+	if files >= rules.MaxFiles {
+		return fmt.Sprintf("This question accepts at most %d files, upload a new version of a file instead", rules.MaxFiles), nil
+	}
+	return "", nil
+}
+
+/*
+ * validateQuestionDocuments checks the documents answering a file question against its rules,
+ * documents uploaded before the rules changed may not follow them anymore.
+ */
+func validateQuestionDocuments(question db.GetQuestionsByProjectRow, documents []db.ProjectDocument, rules questionFileRules) []ValidationError {
+	var errors []ValidationError
+
+	if question.Required && len(documents) == 0 {
+		return append(errors, ValidationError{
+			Question: question.Question,
+			Message:  "This question requires a file",
+		})
+	}
+
+	if rules.MaxFiles > 0 && int64(len(documents)) > rules.MaxFiles {
+		errors = append(errors, ValidationError{
+			Question: question.Question,
+			Message:  fmt.Sprintf("At most %d files are allowed, remove %d", rules.MaxFiles, int64(len(documents))-rules.MaxFiles),
+		})
+	}
+
+	for _, doc := range documents {
+		if doc.Size > rules.MaxSize || doc.Size < rules.MinSize {
+			errors = append(errors, ValidationError{
+				Question: question.Question,
+				Message:  fmt.Sprintf("%s must be between %d and %d bytes", doc.Name, rules.MinSize, rules.MaxSize),
+			})
+		}
+
+		allowed := false
+		for _, allowedType := range rules.AllowedTypes {
+			if strings.EqualFold(doc.MimeType, allowedType) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			errors = append(errors, ValidationError{
+				Question: question.Question,
+				Message:  fmt.Sprintf("%s is a %s file, allowed types: %s", doc.Name, doc.MimeType, strings.Join(rules.AllowedTypes, ", ")),
+			})
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,68 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRulesOf(t *testing.T) {
+	maxSize := int64(100 * 1024 * 1024)
+	maxFiles := int32(1)
+
+	rules := fileRulesOf(db.ProjectQuestionFileRule{
+		QuestionID:   "pitch-deck",
+		MaxSize:      &maxSize,
+		AllowedTypes: []string{"application/pdf"},
+		MaxFiles:     &maxFiles,
+	})
+	assert.Equal(t, []string{"application/pdf"}, rules.AllowedTypes)
+	assert.Equal(t, maxSize, rules.MaxSize)
+	assert.Equal(t, int64(1), rules.MaxFiles)
+	// Columns left NULL keep the defaults
+	assert.Equal(t, documentFileConfig.MinSize, rules.MinSize)
+	assert.True(t, rules.DeepInspection)
+
+	rules = fileRulesOf(db.ProjectQuestionFileRule{QuestionID: "business-plan"})
+	assert.Equal(t, defaultFileRules, rules)
+	assert.Zero(t, rules.MaxFiles)
+}
+
+func TestValidateProjectFiles(t *testing.T) {
+	pitchDeck := db.GetQuestionsByProjectRow{ID: "pitch-deck", Question: "Please upload a pitch deck.", InputType: db.InputTypeEnumFile, Required: true}
+	contracts := db.GetQuestionsByProjectRow{ID: "contracts", Question: "Contracts", InputType: db.InputTypeEnumFile}
+	questions := []db.GetQuestionsByProjectRow{pitchDeck, contracts}
+
+	pitchDeckRules := defaultFileRules
+	pitchDeckRules.AllowedTypes = []string{"application/pdf"}
+	pitchDeckRules.MaxFiles = 1
+	rules := map[string]questionFileRules{"pitch-deck": pitchDeckRules}
+
+	deck := db.ProjectDocument{QuestionID: "pitch-deck", Name: "deck.pdf", MimeType: "application/pdf", Size: 4096}
+	sheet := db.ProjectDocument{QuestionID: "pitch-deck", Name: "deck.xlsx", MimeType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Size: 4096}
+	infectedDeck := deck
+	infectedDeck.ScanStatus = db.ScanStatusInfected
+	contract := db.ProjectDocument{QuestionID: "contracts", Name: "lease.docx", MimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Size: 4096}
+
+	tests := []struct {
+		name      string
+		documents []db.ProjectDocument
+		errors    int
+	}{
+		{name: "files follow the rules", documents: []db.ProjectDocument{deck, contract}},
+		{name: "required file missing", documents: []db.ProjectDocument{contract}, errors: 1},
+		{name: "type not allowed by the question", documents: []db.ProjectDocument{sheet}, errors: 1},
+		{name: "too many files", documents: []db.ProjectDocument{deck, deck}, errors: 1},
+		{name: "infected file doesn't answer the question", documents: []db.ProjectDocument{infectedDeck, contract}, errors: 1},
+		{name: "infected file doesn't take a place", documents: []db.ProjectDocument{infectedDeck, deck}},
+		{name: "defaults apply without rules", documents: []db.ProjectDocument{deck, {QuestionID: "contracts", Name: "big.pdf", MimeType: "application/pdf", Size: 20 * 1024 * 1024}}, errors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateProjectFiles(questions, tt.documents, rules)
+			assert.Len(t, errors, tt.errors, errors)
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxImportFileSize bounds the size of an uploaded import file
+	maxImportFileSize = 5 * 1024 * 1024
+	// maxImportRows bounds the number of applications in a single import
+	maxImportRows = 500
+)
+
+const (
+	importRowCreated = "created"
+	importRowValid   = "valid"
+	importRowFailed  = "failed"
+)
+
+var importFormats = map[string]db.ExportFormat{
+	".csv":  db.ExportFormatCsv,
+	".json": db.ExportFormatJson,
+	".xlsx": db.ExportFormatXlsx,
+}
+
+// errImportRowInvalid rolls back a row that failed validation inside its transaction
+var errImportRowInvalid = errors.New("import row is invalid")
+
+/*
+ * buildImportAnswers maps the answers of an imported application, keyed by question key,
+ * onto the project form. The returned questions carry the answers the same way
+ * GetQuestionsByProject does, so they can be checked with validateProjectFormAnswers.
+ */
+func buildImportAnswers(questions []db.GetProjectQuestionsRow, record service.ProgramExportRecord) ([]db.GetQuestionsByProjectRow, []ValidationError) {
+	var validationErrors []ValidationError
+
+	byKey := make(map[string]int, len(questions))
+	rows := make([]db.GetQuestionsByProjectRow, len(questions))
+	for i, question := range questions {
+		rows[i] = db.GetQuestionsByProjectRow(question)
+		rows[i].Answer = ""
+		rows[i].Choices = []string{}
+		if question.QuestionKey != nil {
+			byKey[*question.QuestionKey] = i
+		}
+	}
+
+	keys := make([]string, 0, len(record.Answers))
+	for key := range record.Answers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		i, ok := byKey[key]
+		if !ok {
+			validationErrors = append(validationErrors, ValidationError{
+				Question: key,
+				Message:  "Unknown question key",
+			})
+			continue
+		}
+
+		row := &rows[i]
+		answer := strings.TrimSpace(record.Answers[key])
+		switch row.InputType {
+		case db.InputTypeEnumSelect, db.InputTypeEnumMultiselect:
+			for _, choice := range service.SplitImportList(answer) {
+				if len(row.Options) > 0 && !slices.Contains(row.Options, choice) {
+					validationErrors = append(validationErrors, ValidationError{
+						Question: row.Question,
+						Message:  fmt.Sprintf("%q is not one of the options", choice),
+					})
+					continue
+				}
+				row.Choices = append(row.Choices, choice)
+			}
+			if row.InputType == db.InputTypeEnumSelect && len(row.Choices) > 1 {
+				validationErrors = append(validationErrors, ValidationError{
+					Question: row.Question,
+					Message:  "Only one option can be selected",
+				})
+			}
+		case db.InputTypeEnumFile, db.InputTypeEnumTeam:
+			validationErrors = append(validationErrors, ValidationError{
+				Question: row.Question,
+				Message:  "Documents and team members can't be imported",
+			})
+		default:
+			row.Answer = answer
+		}
+	}
+
+	if record.Funding != nil {
+		funding := *record.Funding
+		for i := range funding.Tiers {
+			if funding.Tiers[i].ID == "" {
+				funding.Tiers[i].ID = uuid.NewString()
+			}
+		}
+		found := false
+		for i := range rows {
+			if rows[i].InputType != db.InputTypeEnumFundingstructure {
+				continue
+			}
+			content, _ := json.Marshal(funding)
+			rows[i].Answer = string(content)
+			found = true
+			break
+		}
+		if !found {
+			validationErrors = append(validationErrors, ValidationError{
+				Question: "funding_structure",
+				Message:  "The form has no funding structure question",
+			})
+		}
+	}
+
+	return rows, append(validationErrors, validateProjectFormAnswers(rows)...)
+}
+
+/*
+ * validateImportOwner checks the fields of an imported application that are not form answers.
+ */
+func validateImportOwner(record service.ProgramExportRecord) []ValidationError {
+	var validationErrors []ValidationError
+
+	if record.Company.OwnerEmail == "" {
+		validationErrors = append(validationErrors, ValidationError{Question: "owner_email", Message: "The owner email is required"})
+	} else if address, err := mail.ParseAddress(record.Company.OwnerEmail); err != nil || address.Address != record.Company.OwnerEmail {
+		validationErrors = append(validationErrors, ValidationError{Question: "owner_email", Message: "Must be a valid email address"})
+	}
+
+	if record.Company.Name == "" && record.Answers["company_name"] == "" {
+		validationErrors = append(validationErrors, ValidationError{Question: "company_name", Message: "The company name is required"})
+	}
+
+	return validationErrors
+}
+
+/*
+ * randomImportPassword returns a password hash nobody knows the password of.
+ * Imported owners set their own password through the forgot password flow.
+ */
+func randomImportPassword() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+/*
+ * importApplication creates the owner, the company and the submitted project of an imported
+ * application in a single transaction. In a dry run the transaction is always rolled back, so
+ * the report shows exactly what a real import would do.
+ */
+func (h *Handler) importApplication(ctx context.Context, adminID string, questions []db.GetProjectQuestionsRow, record service.ProgramExportRecord, dryRun bool) (string, []ValidationError, error) {
+	validationErrors := validateImportOwner(record)
+	answers, answerValidationErrors := buildImportAnswers(questions, record)
+	validationErrors = append(validationErrors, answerValidationErrors...)
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	q := h.server.GetQueries().WithTx(tx)
+
+	if record.Company.OwnerEmail != "" {
+		exists, err := q.UserExistsByEmail(ctx, record.Company.OwnerEmail)
+		if err != nil {
+			return "", nil, err
+		}
+		if exists {
+			validationErrors = append(validationErrors, ValidationError{Question: "owner_email", Message: "A user with this email already exists"})
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return "", validationErrors, errImportRowInvalid
+	}
+
+	password, err := randomImportPassword()
+	if err != nil {
+		return "", nil, err
+	}
+
+	owner, err := q.NewUser(ctx, db.NewUserParams{
+		Email:       record.Company.OwnerEmail,
+		Password:    password,
+		Permissions: int32(permissions.PermStartupOwner),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	companyName := record.Company.Name
+	if companyName == "" {
+		companyName = record.Answers["company_name"]
+	}
+	dateFounded := record.Company.DateFounded
+	if dateFounded == 0 {
+		dateFounded = time.Now().Unix()
+	}
+	stages := record.Company.Stages
+	if stages == nil {
+		stages = []string{}
+	}
+
+	company, err := q.CreateCompany(ctx, db.CreateCompanyParams{
+		OwnerID:     owner.ID,
+		Name:        companyName,
+		LinkedinUrl: record.Company.LinkedinURL,
+		Description: optionalString(record.Company.Description),
+		DateFounded: dateFounded,
+		Website:     optionalString(record.Company.Website),
+		Stages:      stages,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	title := record.Title
+	if title == "" {
+		title = companyName
+	}
+	now := time.Now().Unix()
+	project, err := q.CreateProject(ctx, db.CreateProjectParams{
+		CompanyID:   company.ID,
+		Title:       title,
+		Description: optionalString(record.Description),
+		Status:      db.ProjectStatusDraft,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := []db.UpdateProjectDraftParams{}
+	for _, answer := range answers {
+		if answer.Answer == "" && len(answer.Choices) == 0 {
+			continue
+		}
+		params = append(params, db.UpdateProjectDraftParams{
+			ProjectID:  project.ID,
+			QuestionID: answer.ID,
+			Answer:     answer.Answer,
+			Choices:    answer.Choices,
+		})
+	}
+	if len(params) > 0 {
+		var batchErr error
+		batch := q.UpdateProjectDraft(ctx, params)
+		batch.Exec(func(i int, err error) {
+			if err != nil && batchErr == nil {
+				batchErr = err
+			}
+		})
+		batch.Close()
+		if batchErr != nil {
+			return "", nil, batchErr
+		}
+	}
+
+	if err := service.SubmitProject(q, ctx, project.ID, adminID); err != nil {
+		return "", nil, err
+	}
+
+	if dryRun {
+		return project.ID, nil, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", nil, err
+	}
+
+	return project.ID, nil, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+/*
+ * handleImportApplications creates startups from a structured file, e.g. when onboarding the
+ * cohort of a partner accelerator. Every application creates an owner account, a company and a
+ * submitted project with its answers keyed by question key. The file is laid out like a program
+ * export (csv, json or xlsx), so an export can be edited and imported again.
+ *
+ * Each application is imported on its own: invalid rows are reported and skipped, the others are
+ * created. Answers are checked with the same rules as a project submission.
+ *
+ * Lists in csv and xlsx cells (selected options, company stages and funding tiers) are JSON arrays
+ * of strings, e.g. ["Energy","Hardware"]. A cell holding a single item may be written as is.
+ *
+ * form:
+ * - file: the csv, json or xlsx file
+ * - dry_run: when true, every row is validated and rolled back, nothing is created
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleImportApplications(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	logger := middleware.GetLogger(c)
+
+	var req ImportApplicationsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "An import file is required", err)
+	}
+	if fileHeader.Size > maxImportFileSize {
+		return v1_common.Fail(c, http.StatusBadRequest, "The import file is too large", nil)
+	}
+	format, ok := importFormats[strings.ToLower(filepath.Ext(fileHeader.Filename))]
+	if !ok {
+		return v1_common.Fail(c, http.StatusBadRequest, "The import file must be a .csv, .json or .xlsx file", nil)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read import file", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read import file", err)
+	}
+	if len(content) > maxImportFileSize {
+		return v1_common.Fail(c, http.StatusBadRequest, "The import file is too large", nil)
+	}
+
+	records, err := service.ParseProgramImport(content, format)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, err.Error(), err)
+	}
+	if len(records) == 0 {
+		return v1_common.Fail(c, http.StatusBadRequest, "The import file has no applications", nil)
+	}
+	if len(records) > maxImportRows {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("At most %d applications can be imported at once", maxImportRows), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Minute)
+	defer cancel()
+
+	questions, err := h.server.GetQueries().GetProjectQuestions(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project questions", err)
+	}
+
+	response := ImportApplicationsResponse{
+		DryRun: req.DryRun,
+		Total:  len(records),
+		Rows:   make([]ImportRowResult, len(records)),
+	}
+	seenEmails := map[string]bool{}
+	for i, record := range records {
+		result := ImportRowResult{
+			Row:        i + 1,
+			OwnerEmail: record.Company.OwnerEmail,
+			Title:      record.Title,
+			Errors:     []ValidationError{},
+		}
+
+		email := strings.ToLower(record.Company.OwnerEmail)
+		if email != "" && seenEmails[email] {
+			result.Errors = append(result.Errors, ValidationError{Question: "owner_email", Message: "The email is used by another row of the file"})
+		} else {
+			seenEmails[email] = true
+			projectID, validationErrors, err := h.importApplication(ctx, user.ID, questions, record, req.DryRun)
+			switch {
+			case err == errImportRowInvalid:
+				result.Errors = validationErrors
+			case err != nil:
+				// a database error only fails its own row, the report of the others is kept
+				logger.Error(err, fmt.Sprintf("Failed to import application at row %d", i+1))
+				result.Errors = append(result.Errors, ValidationError{Question: "", Message: "Failed to import the application, please try again"})
+			case !req.DryRun:
+				result.ProjectID = &projectID
+			}
+		}
+
+		switch {
+		case len(result.Errors) > 0:
+			result.Status = importRowFailed
+			response.Failed++
+		case req.DryRun:
+			result.Status = importRowValid
+			response.Valid++
+		default:
+			result.Status = importRowCreated
+			response.Created++
+		}
+		response.Rows[i] = result
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,97 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/service"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func importQuestion(id, key string, inputType db.InputTypeEnum, required bool) db.GetProjectQuestionsRow {
+	return db.GetProjectQuestionsRow{
+		ID:          id,
+		Question:    key + "?",
+		InputType:   inputType,
+		Required:    required,
+		QuestionKey: &key,
+	}
+}
+
+func TestBuildImportAnswers(t *testing.T) {
+	website := importQuestion("q1", "company_website", db.InputTypeEnumTextinput, true)
+	website.Validations = []string{"url"}
+	industries := importQuestion("q2", "company_industries", db.InputTypeEnumMultiselect, true)
+	industries.Options = []string{"Energy", "Food; Farming", "Software"}
+	stage := importQuestion("q3", "company_stage", db.InputTypeEnumSelect, false)
+	funding := importQuestion("q4", "funding_structure", db.InputTypeEnumFundingstructure, true)
+	questions := []db.GetProjectQuestionsRow{website, industries, stage, funding}
+
+	validFunding := &db.FundingStructureModel{Type: "target", Amount: "50000", EquityPercentage: "10"}
+
+	t.Run("valid application", func(t *testing.T) {
+		rows, errors := buildImportAnswers(questions, service.ProgramExportRecord{
+			Answers: map[string]string{
+				"company_website":    "https://acme.dev",
+				"company_industries": `["Energy", "Food; Farming"]`,
+			},
+			Funding: validFunding,
+		})
+		require.Empty(t, errors)
+		require.Len(t, rows, 4)
+		assert.Equal(t, "https://acme.dev", rows[0].Answer)
+		assert.Equal(t, []string{"Energy", "Food; Farming"}, rows[1].Choices)
+
+		var model db.FundingStructureModel
+		require.NoError(t, json.Unmarshal([]byte(rows[3].Answer), &model))
+		assert.Equal(t, "50000", model.Amount)
+	})
+
+	t.Run("uses the submission rules", func(t *testing.T) {
+		_, errors := buildImportAnswers(questions, service.ProgramExportRecord{
+			Answers: map[string]string{"company_website": "acme.dev"},
+		})
+		// invalid url, missing industries and missing funding structure
+		assert.Len(t, errors, 3)
+	})
+
+	t.Run("rejects unknown keys and options", func(t *testing.T) {
+		_, errors := buildImportAnswers(questions, service.ProgramExportRecord{
+			Answers: map[string]string{
+				"company_website":    "https://acme.dev",
+				"company_industries": `["Energy", "Farming"]`,
+				"company_stage":      `["Seed", "Growth"]`,
+				"company_mascot":     "Otter",
+			},
+			Funding: validFunding,
+		})
+		messages := []string{}
+		for _, err := range errors {
+			messages = append(messages, err.Message)
+		}
+		assert.ElementsMatch(t, []string{
+			"Unknown question key",
+			`"Farming" is not one of the options`,
+			"Only one option can be selected",
+		}, messages)
+	})
+}
+
+func TestValidateImportOwner(t *testing.T) {
+	record := service.ProgramExportRecord{
+		Company: service.ProgramExportCompany{Name: "Acme", OwnerEmail: "founder@acme.dev"},
+	}
+	assert.Empty(t, validateImportOwner(record))
+
+	record.Company.OwnerEmail = "Founder <founder@acme.dev>"
+	assert.Len(t, validateImportOwner(record), 1)
+
+	record.Company.OwnerEmail = ""
+	record.Company.Name = ""
+	assert.Len(t, validateImportOwner(record), 2)
+
+	record.Answers = map[string]string{"company_name": "Acme"}
+	assert.Len(t, validateImportOwner(record), 1)
+}
@@ -15,7 +15,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"sort"
 	"strings"
 	"time"
 
@@ -48,6 +47,78 @@ func getUserFromContext(c echo.Context) (*db.User, error) {
 	return user, nil
 }
 
+/*
+ * getProjectForUser gets a project the user has access to.
+ * Users with PermViewAllProjects can access any project, everyone else only the projects of their company.
+ *
+ * returns pgx.ErrNoRows if the project doesn't exist or the user can't access it
+ */
+func getProjectForUser(queries *db.Queries, ctx context.Context, user *db.User, projectID string) (db.Project, error) {
+	if permissions.HasPermission(uint32(user.Permissions), permissions.PermViewAllProjects) {
+		return queries.GetProjectByIDAsAdmin(ctx, projectID)
+	}
+
+	company, err := queries.GetCompanyByOwnerID(ctx, user.ID)
+	if err != nil {
+		return db.Project{}, err
+	}
+
+	return queries.GetProjectByID(ctx, db.GetProjectByIDParams{
+		ID:        projectID,
+		CompanyID: company.ID,
+	})
+}
+
+type projectRole int
+
+const (
+	projectRoleNone projectRole = iota
+	projectRoleInvestor
+	projectRoleFounder
+	projectRoleAdmin
+)
+
+/*
+ * projectRoleFor returns the role of a user towards a project owned by ownerID.
+ * Anyone that can view all projects and is neither an admin nor the owner is treated
+ * as an investor.
+ */
+func projectRoleFor(user *db.User, ownerID string) projectRole {
+	perms := uint32(user.Permissions)
+	switch {
+	case permissions.HasPermission(perms, permissions.PermIsAdmin):
+		return projectRoleAdmin
+	case user.ID == ownerID:
+		return projectRoleFounder
+	case permissions.HasPermission(perms, permissions.PermViewAllProjects):
+		return projectRoleInvestor
+	}
+	return projectRoleNone
+}
+
+/*
+ * getProjectWithRole loads a project together with the role of the user and the id of the founder.
+ * pgx.ErrNoRows is returned when the user has no role in the project, investors never see drafts.
+ */
+func getProjectWithRole(queries *db.Queries, ctx context.Context, user *db.User, projectID string) (db.Project, projectRole, string, error) {
+	project, err := queries.GetProjectByIDAsAdmin(ctx, projectID)
+	if err != nil {
+		return db.Project{}, projectRoleNone, "", err
+	}
+
+	company, err := queries.GetCompanyByID(ctx, project.CompanyID)
+	if err != nil {
+		return db.Project{}, projectRoleNone, "", err
+	}
+
+	role := projectRoleFor(user, company.OwnerID)
+	if role == projectRoleNone || (role == projectRoleInvestor && project.Status == db.ProjectStatusDraft) {
+		return db.Project{}, projectRoleNone, "", pgx.ErrNoRows
+	}
+
+	return project, role, company.OwnerID, nil
+}
+
 /*
  * handleCreateProject creates a new project for a company.
  *
@@ -397,6 +468,15 @@ func (h *Handler) handleSubmitProject(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusBadRequest, "Only draft projects can be submitted", nil)
 	}
 
+	// Reviewers only see clean documents, wait for every scan and for infected files to be replaced
+	unscanned, err := h.server.GetQueries().CountUnscannedProjectDocuments(ctx, project.ID)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if unscanned > 0 {
+		return v1_common.Fail(c, http.StatusBadRequest, "Some documents are still being scanned or were found infected, try again once they are clean.", nil)
+	}
+
 	// Get all questions
 	questions, err := h.server.GetQueries().GetQuestionsByProject(c.Request().Context(), db.GetQuestionsByProjectParams{
 		ProjectID: projectID,
@@ -407,6 +487,17 @@ func (h *Handler) handleSubmitProject(c echo.Context) error {
 	}
 	// Validate the answers
 	validationErrors := validateProjectFormAnswers(questions)
+
+	// Validate the files answering the file questions against the rules of each question
+	documents, err := h.server.GetQueries().GetProjectDocuments(ctx, project.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project documents", err)
+	}
+	fileRules, err := listQuestionFileRules(ctx, h.server.GetQueries())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get question file rules", err)
+	}
+	validationErrors = append(validationErrors, validateProjectFiles(questions, documents, fileRules)...)
 	// If there are any validation errors, return them
 	if len(validationErrors) > 0 {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
@@ -423,7 +514,7 @@ func (h *Handler) handleSubmitProject(c echo.Context) error {
 
 	qTx := h.server.GetQueries().WithTx(tx)
 
-	err = service.SubmitProject(qTx, ctx, project.ID)
+	err = service.SubmitProject(qTx, ctx, project.ID, user.ID)
 	if err != nil {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to submit project", err)
 	}
@@ -517,113 +608,37 @@ func (h *Handler) handleUpdateProjectStatus(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
 	}
 
-	queries := h.server.GetQueries()
-
-	project, err := queries.GetProjectByIDAsAdmin(c.Request().Context(), projectID)
-	if err != nil {
-		return v1_common.Fail(c, http.StatusBadRequest, "Failed to find project to update status", err)
-	}
-
-	err = queries.UpdateProjectStatus(c.Request().Context(), db.UpdateProjectStatusParams{Status: req.Status, ID: project.ID})
+	user, err := getUserFromContext(c)
 	if err != nil {
-		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update project status", err)
-	}
-
-	return v1_common.Success(c, http.StatusOK, "Project status updated")
-}
-
-/*
- * handleGetNewProjects retrieves the most recently created projects.
- *
- * parameters (all optional):
- * - count: Number of projects to return (default: 10)
- * - status: Type of projects to return (draft, pending, verified, etc.). If not provided, returns projects of any status.
- *
- * security:
- * - Public endpoint (no authentication required)
- * - Only returns basic project information
- *
- * returns array of ExtendedProjectResponse objects ordered by creation date (newest first)
- */
-func (h *Handler) handleGetNewProjects(c echo.Context) error {
-	var req GetNewProjectsRequest
-	if err := v1_common.BindandValidate(c, &req); err != nil {
-		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
-	}
-
-	// set default count if not provided
-	count := 10
-	if req.Count > 0 {
-		count = req.Count
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
 	}
 
-	var projects []db.GetNewProjectsAnyStatusRow
+	ctx := c.Request().Context()
 
-	// if no statuses provided, default to pending and verified
-	if len(req.Statuses) == 0 {
-		req.Statuses = []db.ProjectStatus{db.ProjectStatusPending, db.ProjectStatusVerified}
+	// The status change and its activity entry must be saved together
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.NewInternalError(err)
 	}
+	defer tx.Rollback(ctx)
 
-	allProjects := []db.GetNewProjectsAnyStatusRow{}
-
-	// fetch projects for each requested status
-	for _, status := range req.Statuses {
-		statusProjects, err := h.server.GetQueries().GetNewProjectsByStatus(c.Request().Context(), db.GetNewProjectsByStatusParams{
-			Status: status,
-			Limit:  int32(count),
-		})
+	queries := h.server.GetQueries().WithTx(tx)
 
-		if err == nil {
-			for _, project := range statusProjects {
-				allProjects = append(allProjects, db.GetNewProjectsAnyStatusRow(project))
-			}
-		}
+	project, err := queries.GetProjectByIDAsAdmin(ctx, projectID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Failed to find project to update status", err)
 	}
 
-	// sort by created_at in descending order (newest first)
-	sort.Slice(allProjects, func(i, j int) bool {
-		return allProjects[i].CreatedAt > allProjects[j].CreatedAt
-	})
-
-	// limit to requested count
-	if len(allProjects) > count {
-		allProjects = allProjects[:count]
+	err = service.UpdateProjectStatus(queries, ctx, project.ID, user.ID, req.Status)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update project status", err)
 	}
 
-	projects = allProjects
-
-	// convert to response format
-	response := make([]ExtendedProjectResponse, 0, len(projects))
-	for _, project := range projects {
-		description := ""
-		if project.Description != nil {
-			description = *project.Description
-		}
-
-		companyName := ""
-		if project.CompanyName != nil {
-			companyName = *project.CompanyName
-		}
-
-		response = append(response, ExtendedProjectResponse{
-			ProjectResponse: ProjectResponse{
-				ID:          project.ID,
-				Title:       project.Title,
-				Description: description,
-				Status:      project.Status,
-				AllowEdit:   project.AllowEdit,
-				CreatedAt:   project.CreatedAt,
-				UpdatedAt:   project.UpdatedAt,
-			},
-			CompanyName:     companyName,
-			DocumentCount:   project.DocumentCount,
-			TeamMemberCount: project.TeamMemberCount,
-		})
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.NewInternalError(err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"projects": response,
-	})
+	return v1_common.Success(c, http.StatusOK, "Project status updated")
 }
 
 /*
@@ -0,0 +1,512 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * Investor Q&A lets investors ask founders due diligence questions about a project.
+ * It is separate from review comments: questions and answers never change allow_edit
+ * or require a resubmission. A thread is private to the asker, the founder and admins
+ * until the founder answers it publicly, then every investor can read it without
+ * learning who asked.
+ */
+
+const (
+	qaAuthorFounder  = "founder"
+	qaAuthorInvestor = "investor"
+)
+
+// canAskQuestions reports whether the user may open new Q&A threads.
+func canAskQuestions(user *db.User, role projectRole) bool {
+	return role == projectRoleInvestor && permissions.HasPermission(uint32(user.Permissions), permissions.PermCommentOnProjects)
+}
+
+/*
+ * buildQAThreads assembles the threads visible to the viewer. The identity of investors
+ * is only revealed to themselves, the founder and admins; moderation details are only
+ * included for admins.
+ */
+func buildQAThreads(role projectRole, viewerID string, founderID string, questions []db.ListInvestorQuestionsRow, replies []db.ListInvestorQuestionRepliesRow) []QAThreadResponse {
+	repliesByQuestion := make(map[string][]QAReplyResponse, len(questions))
+	askers := make(map[string]string, len(questions))
+	for _, question := range questions {
+		askers[question.ID] = question.AskerID
+	}
+
+	for _, reply := range replies {
+		authorRole := qaAuthorInvestor
+		if reply.AuthorID == founderID {
+			authorRole = qaAuthorFounder
+		}
+
+		response := QAReplyResponse{
+			ID:         reply.ID,
+			Body:       reply.Body,
+			AuthorRole: authorRole,
+			CreatedAt:  reply.CreatedAt,
+		}
+		if authorRole == qaAuthorFounder || role >= projectRoleFounder || askers[reply.QuestionID] == viewerID {
+			response.Author = &QAParticipantResponse{
+				ID:        reply.AuthorID,
+				FirstName: reply.AuthorFirstName,
+				LastName:  reply.AuthorLastName,
+			}
+		}
+		if role == projectRoleAdmin {
+			response.HiddenAt = reply.HiddenAt
+		}
+		repliesByQuestion[reply.QuestionID] = append(repliesByQuestion[reply.QuestionID], response)
+	}
+
+	threads := make([]QAThreadResponse, len(questions))
+	for i, question := range questions {
+		thread := QAThreadResponse{
+			ID:         question.ID,
+			ProjectID:  question.ProjectID,
+			Body:       question.Body,
+			Visibility: question.Visibility,
+			AnsweredAt: question.AnsweredAt,
+			Mine:       question.AskerID == viewerID,
+			Replies:    repliesByQuestion[question.ID],
+			CreatedAt:  question.CreatedAt,
+			UpdatedAt:  question.UpdatedAt,
+		}
+		if thread.Replies == nil {
+			thread.Replies = []QAReplyResponse{}
+		}
+		if thread.Mine || role >= projectRoleFounder {
+			thread.Asker = &QAParticipantResponse{
+				ID:        question.AskerID,
+				FirstName: question.AskerFirstName,
+				LastName:  question.AskerLastName,
+			}
+		}
+		if role == projectRoleAdmin {
+			thread.HiddenAt = question.HiddenAt
+		}
+		threads[i] = thread
+	}
+
+	return threads
+}
+
+/*
+ * handleListProjectQuestions returns the Q&A threads of a project, newest first, with their replies.
+ *
+ * Security:
+ * - Investors see their own threads and the threads the founder answered publicly
+ * - The founder sees every thread of the project that is not hidden
+ * - Admins see every thread, including hidden threads and replies
+ */
+func (h *Handler) handleListProjectQuestions(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	_, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+
+	includeHidden := role == projectRoleAdmin
+	questions, err := queries.ListInvestorQuestions(ctx, db.ListInvestorQuestionsParams{
+		ProjectID:     c.Param("id"),
+		IncludeHidden: includeHidden,
+		IncludeAll:    role >= projectRoleFounder,
+		ViewerID:      user.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get questions", err)
+	}
+
+	var replies []db.ListInvestorQuestionRepliesRow
+	if len(questions) > 0 {
+		questionIDs := make([]string, len(questions))
+		for i, question := range questions {
+			questionIDs[i] = question.ID
+		}
+
+		replies, err = queries.ListInvestorQuestionReplies(ctx, db.ListInvestorQuestionRepliesParams{
+			QuestionIds:   questionIDs,
+			IncludeHidden: includeHidden,
+		})
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get replies", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, QAThreadsResponse{
+		Threads: buildQAThreads(role, user.ID, founderID, questions, replies),
+	})
+}
+
+/*
+ * handleAskProjectQuestion opens a new Q&A thread. The thread starts private and
+ * the founder is notified.
+ *
+ * Security:
+ * - Requires PermCommentOnProjects, the founder and admins can not ask questions
+ * - Questions can not be asked on drafts
+ */
+func (h *Handler) handleAskProjectQuestion(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req CreateQuestionRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Question can not be empty", nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+	if !canAskQuestions(user, role) {
+		return v1_common.Fail(c, http.StatusForbidden, "Only investors can ask questions", nil)
+	}
+
+	question, err := queries.CreateInvestorQuestion(ctx, db.CreateInvestorQuestionParams{
+		ProjectID: project.ID,
+		AskerID:   user.ID,
+		Body:      body,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create question", err)
+	}
+
+	message := fmt.Sprintf("An investor asked a question about %s.", project.Title)
+	if err := service.NotifyUser(queries, ctx, founderID, db.NotificationTypeQaQuestionAsked, project.ID, message); err != nil {
+		middleware.GetLogger(c).Error(err, "Failed to notify founder about investor question.")
+	}
+
+	return c.JSON(http.StatusCreated, QAThreadResponse{
+		ID:         question.ID,
+		ProjectID:  question.ProjectID,
+		Body:       question.Body,
+		Visibility: question.Visibility,
+		Mine:       true,
+		Asker: &QAParticipantResponse{
+			ID:        user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		},
+		Replies:   []QAReplyResponse{},
+		CreatedAt: question.CreatedAt,
+		UpdatedAt: question.UpdatedAt,
+	})
+}
+
+/*
+ * handleReplyToProjectQuestion adds a reply to a Q&A thread.
+ * A reply of the founder answers the thread, the founder may set its visibility
+ * with the same request. The asker can follow up in their own thread.
+ * The other party is notified.
+ *
+ * Security:
+ * - Only the founder and the asker can reply
+ * - Hidden threads can not be replied to
+ */
+func (h *Handler) handleReplyToProjectQuestion(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req CreateQuestionReplyRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return v1_common.Fail(c, http.StatusBadRequest, "Reply can not be empty", nil)
+	}
+
+	ctx := c.Request().Context()
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to begin transaction", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	project, role, founderID, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+
+	question, err := queries.GetInvestorQuestion(ctx, db.GetInvestorQuestionParams{
+		ID:        c.Param("question_id"),
+		ProjectID: project.ID,
+	})
+	if err != nil || question.HiddenAt != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Question not found", err)
+	}
+
+	isFounder := role == projectRoleFounder
+	if !isFounder && question.AskerID != user.ID {
+		return v1_common.Fail(c, http.StatusForbidden, "Only the founder and the asker can reply", nil)
+	}
+	if req.Visibility != "" && !isFounder {
+		return v1_common.Fail(c, http.StatusForbidden, "Only the founder can change the visibility", nil)
+	}
+
+	reply, err := queries.CreateInvestorQuestionReply(ctx, db.CreateInvestorQuestionReplyParams{
+		QuestionID: question.ID,
+		AuthorID:   user.ID,
+		Body:       body,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create reply", err)
+	}
+
+	err = queries.UpdateInvestorQuestionOnReply(ctx, db.UpdateInvestorQuestionOnReplyParams{
+		Answered: isFounder,
+		Visibility: db.NullQaVisibility{
+			QaVisibility: req.Visibility,
+			Valid:        req.Visibility != "",
+		},
+		ID: question.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update question", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to commit transaction", err)
+	}
+
+	// Notify the other party of the thread
+	notifyID := founderID
+	notificationType := db.NotificationTypeQaFollowUp
+	message := fmt.Sprintf("An investor followed up on their question about %s.", project.Title)
+	if isFounder {
+		notifyID = question.AskerID
+		notificationType = db.NotificationTypeQaQuestionAnswered
+		message = fmt.Sprintf("The founder of %s replied to your question.", project.Title)
+	}
+	err = service.NotifyUser(h.server.GetQueries(), ctx, notifyID, notificationType, project.ID, message)
+	if err != nil {
+		middleware.GetLogger(c).Error(err, "Failed to notify about investor question reply.")
+	}
+
+	authorRole := qaAuthorInvestor
+	if isFounder {
+		authorRole = qaAuthorFounder
+	}
+
+	return c.JSON(http.StatusCreated, QAReplyResponse{
+		ID:         reply.ID,
+		Body:       reply.Body,
+		AuthorRole: authorRole,
+		Author: &QAParticipantResponse{
+			ID:        user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		},
+		CreatedAt: reply.CreatedAt,
+	})
+}
+
+/*
+ * handleUpdateProjectQuestion changes the visibility of a Q&A thread.
+ * Public threads are shown to every investor once they have been answered.
+ *
+ * Security:
+ * - Only the founder and admins can change the visibility
+ */
+func (h *Handler) handleUpdateProjectQuestion(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req UpdateQuestionRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, role, _, err := getProjectWithRole(queries, ctx, user, c.Param("id"))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.Fail(c, http.StatusNotFound, "Project not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get project", err)
+	}
+	if role < projectRoleFounder {
+		return v1_common.Fail(c, http.StatusForbidden, "Only the founder can change the visibility", nil)
+	}
+
+	question, err := queries.GetInvestorQuestion(ctx, db.GetInvestorQuestionParams{
+		ID:        c.Param("question_id"),
+		ProjectID: project.ID,
+	})
+	if err != nil || (question.HiddenAt != nil && role != projectRoleAdmin) {
+		return v1_common.Fail(c, http.StatusNotFound, "Question not found", err)
+	}
+
+	err = queries.UpdateInvestorQuestionVisibility(ctx, db.UpdateInvestorQuestionVisibilityParams{
+		Visibility: req.Visibility,
+		ID:         question.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update question", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Question updated successfully",
+	})
+}
+
+/*
+ * handleModerateProjectQuestion hides or restores a Q&A thread.
+ * Hidden threads are only visible to admins.
+ *
+ * Security:
+ * - Requires PermIsAdmin
+ */
+func (h *Handler) handleModerateProjectQuestion(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req ModerateQARequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	rows, err := h.server.GetQueries().SetInvestorQuestionHidden(c.Request().Context(), db.SetInvestorQuestionHiddenParams{
+		Hidden:      *req.Hidden,
+		ModeratorID: qaModeratorID(user),
+		ID:          c.Param("question_id"),
+		ProjectID:   c.Param("id"),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to moderate question", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Question not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Question moderated successfully",
+	})
+}
+
+/*
+ * handleModerateProjectQuestionReply hides or restores a single reply of a Q&A thread.
+ *
+ * Security:
+ * - Requires PermIsAdmin
+ */
+func (h *Handler) handleModerateProjectQuestionReply(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	var req ModerateQARequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	question, err := queries.GetInvestorQuestion(ctx, db.GetInvestorQuestionParams{
+		ID:        c.Param("question_id"),
+		ProjectID: c.Param("id"),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusNotFound, "Question not found", err)
+	}
+
+	rows, err := queries.SetInvestorQuestionReplyHidden(ctx, db.SetInvestorQuestionReplyHiddenParams{
+		Hidden:      *req.Hidden,
+		ModeratorID: qaModeratorID(user),
+		ID:          c.Param("reply_id"),
+		QuestionID:  question.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to moderate reply", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Reply not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Reply moderated successfully",
+	})
+}
+
+/*
+ * handleDeleteProjectQuestion removes a Q&A thread with all its replies.
+ *
+ * Security:
+ * - Requires PermIsAdmin
+ */
+func (h *Handler) handleDeleteProjectQuestion(c echo.Context) error {
+	rows, err := h.server.GetQueries().DeleteInvestorQuestion(c.Request().Context(), db.DeleteInvestorQuestionParams{
+		ID:        c.Param("question_id"),
+		ProjectID: c.Param("id"),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to delete question", err)
+	}
+	if rows == 0 {
+		return v1_common.Fail(c, http.StatusNotFound, "Question not found", nil)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Question deleted successfully",
+	})
+}
+
+func qaModeratorID(user *db.User) pgtype.UUID {
+	id, err := uuid.Parse(user.ID)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}
+}
@@ -0,0 +1,102 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/permissions"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRoleFor(t *testing.T) {
+	ownerID := "owner"
+
+	testCases := []struct {
+		name     string
+		user     db.User
+		expected projectRole
+		canAsk   bool
+	}{
+		{
+			name:     "Founder",
+			user:     db.User{ID: ownerID, Permissions: int32(permissions.PermStartupOwner)},
+			expected: projectRoleFounder,
+		},
+		{
+			name:     "Investor",
+			user:     db.User{ID: "investor", Permissions: int32(permissions.PermInvestor)},
+			expected: projectRoleInvestor,
+			canAsk:   true,
+		},
+		{
+			name:     "Investor without comment permission",
+			user:     db.User{ID: "viewer", Permissions: int32(permissions.PermViewAllProjects)},
+			expected: projectRoleInvestor,
+		},
+		{
+			name:     "Admin",
+			user:     db.User{ID: "admin", Permissions: int32(permissions.PermIsAdmin | permissions.PermViewAllProjects | permissions.PermCommentOnProjects)},
+			expected: projectRoleAdmin,
+		},
+		{
+			name:     "Other startup owner",
+			user:     db.User{ID: "other", Permissions: int32(permissions.PermStartupOwner)},
+			expected: projectRoleNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			role := projectRoleFor(&tc.user, ownerID)
+			assert.Equal(t, tc.expected, role)
+			assert.Equal(t, tc.canAsk, canAskQuestions(&tc.user, role))
+		})
+	}
+}
+
+func TestBuildQAThreads(t *testing.T) {
+	name := "Ada"
+	answeredAt := int64(20)
+	hiddenAt := int64(30)
+	questions := []db.ListInvestorQuestionsRow{
+		{ID: "q1", ProjectID: "p", AskerID: "asker", Body: "Churn?", Visibility: db.QaVisibilityPublic, AnsweredAt: &answeredAt, AskerFirstName: &name},
+		{ID: "q2", ProjectID: "p", AskerID: "viewer", Body: "Runway?", Visibility: db.QaVisibilityPrivate},
+	}
+	replies := []db.ListInvestorQuestionRepliesRow{
+		{ID: "r1", QuestionID: "q1", AuthorID: "founder", Body: "2%"},
+		{ID: "r2", QuestionID: "q1", AuthorID: "asker", Body: "Thanks", HiddenAt: &hiddenAt},
+	}
+
+	t.Run("Investors don't learn who asked", func(t *testing.T) {
+		threads := buildQAThreads(projectRoleInvestor, "viewer", "founder", questions, replies)
+		require.Len(t, threads, 2)
+
+		assert.Nil(t, threads[0].Asker)
+		assert.False(t, threads[0].Mine)
+		require.Len(t, threads[0].Replies, 2)
+		assert.Equal(t, qaAuthorFounder, threads[0].Replies[0].AuthorRole)
+		assert.NotNil(t, threads[0].Replies[0].Author)
+		assert.Equal(t, qaAuthorInvestor, threads[0].Replies[1].AuthorRole)
+		assert.Nil(t, threads[0].Replies[1].Author)
+		assert.Nil(t, threads[0].Replies[1].HiddenAt)
+
+		assert.True(t, threads[1].Mine)
+		require.NotNil(t, threads[1].Asker)
+		assert.Equal(t, "viewer", threads[1].Asker.ID)
+		assert.Empty(t, threads[1].Replies)
+		assert.NotNil(t, threads[1].Replies)
+	})
+
+	t.Run("Founders see the askers", func(t *testing.T) {
+		threads := buildQAThreads(projectRoleFounder, "founder", "founder", questions, replies)
+		require.NotNil(t, threads[0].Asker)
+		assert.Equal(t, &name, threads[0].Asker.FirstName)
+		assert.NotNil(t, threads[0].Replies[1].Author)
+	})
+
+	t.Run("Admins see moderation details", func(t *testing.T) {
+		threads := buildQAThreads(projectRoleAdmin, "admin", "founder", questions, replies)
+		assert.Equal(t, &hiddenAt, threads[0].Replies[1].HiddenAt)
+	})
+}
@@ -6,8 +6,10 @@ import (
 	"KonferCA/SPUR/internal/v1/v1_common"
 	"KonferCA/SPUR/internal/v1/v1_teams"
 	"net/http"
+	"sort"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
 )
 
@@ -21,13 +23,14 @@ import (
  * Returns:
  * - Array of questions with their details
  * - Each question includes: ID, text, section, required flag, validation rules
+ * - The files accepted by the file questions: allowed types, size bounds and number of files
  */
 func (h *Handler) handleGetQuestions(c echo.Context) error {
 	projectID := c.QueryParam("project_id")
 
 	var (
 		questions   any
-		documents   []db.ProjectDocument
+		documents   = []QuestionDocumentResponse{}
 		teamMembers []db.TeamMember
 		err         error
 	)
@@ -61,10 +64,28 @@ func (h *Handler) handleGetQuestions(c echo.Context) error {
 			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get questions", err)
 		}
 
-		documents, err = q.GetProjectDocuments(c.Request().Context(), projectID)
-		if err != nil && err.Error() != "no rows in result set" {
+		// The files are only listed for the founder and admins, investors read them in the data room
+		_, role, founderID, err := getProjectWithRole(q, c.Request().Context(), user, projectID)
+		if err != nil && err != pgx.ErrNoRows {
 			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get questions", err)
 		}
+		if err == nil && role >= projectRoleFounder {
+			docs, err := q.GetProjectDocuments(c.Request().Context(), projectID)
+			if err != nil && err.Error() != "no rows in result set" {
+				return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get questions", err)
+			}
+			for _, doc := range docs {
+				// Documents waiting for their scan, or found infected, are only shown to the founder
+				if user.ID != founderID && doc.ScanStatus != db.ScanStatusClean {
+					continue
+				}
+				documents = append(documents, QuestionDocumentResponse{
+					ProjectDocument: doc,
+					URL:             v1_common.SignedFileURL(c.Request().Context(), h.server.GetStorage(), doc.StorageKey, doc.Name),
+					URLs:            documentImageURLs(c.Request().Context(), h.server.GetStorage(), doc),
+				})
+			}
+		}
 	} else {
 		// Get all questions from database
 		questions, err = q.GetProjectQuestions(c.Request().Context())
@@ -94,58 +115,26 @@ func (h *Handler) handleGetQuestions(c echo.Context) error {
 	// convert team members to proper response objects
 	teamMemberResponses := make([]v1_teams.TeamMemberResponse, 0, len(teamMembers))
 	for _, member := range teamMembers {
-		// Process thhe social links with the common helper function
-		socialLinks := v1_common.ProcessSocialLinks(member)
-
-		// convert nullable fields to strings
-		previousWorkStr := ""
-		if member.PreviousWork != nil {
-			previousWorkStr = *member.PreviousWork
-		}
-		resumeExternalUrlStr := ""
-		if member.ResumeExternalUrl != nil {
-			resumeExternalUrlStr = *member.ResumeExternalUrl
-		}
-		resumeInternalUrlStr := ""
-		if member.ResumeInternalUrl != nil {
-			resumeInternalUrlStr = *member.ResumeInternalUrl
-		}
-		foundersAgreementExternalUrlStr := ""
-		if member.FoundersAgreementExternalUrl != nil {
-			foundersAgreementExternalUrlStr = *member.FoundersAgreementExternalUrl
-		}
-		foundersAgreementInternalUrlStr := ""
-		if member.FoundersAgreementInternalUrl != nil {
-			foundersAgreementInternalUrlStr = *member.FoundersAgreementInternalUrl
-		}
+		teamMemberResponses = append(teamMemberResponses, v1_teams.BuildTeamMemberResponse(c.Request().Context(), h.server.GetStorage(), member))
+	}
 
-		// create team member response
-		teamMemberResponses = append(teamMemberResponses, v1_teams.TeamMemberResponse{
-			ID:                           member.ID,
-			CompanyID:                    member.CompanyID,
-			FirstName:                    member.FirstName,
-			LastName:                     member.LastName,
-			Title:                        member.Title,
-			SocialLinks:                  socialLinks,
-			IsAccountOwner:               member.IsAccountOwner,
-			CommitmentType:               member.CommitmentType,
-			Introduction:                 member.Introduction,
-			IndustryExperience:           member.IndustryExperience,
-			DetailedBiography:            member.DetailedBiography,
-			PreviousWork:                 previousWorkStr,
-			ResumeExternalUrl:            resumeExternalUrlStr,
-			ResumeInternalUrl:            resumeInternalUrlStr,
-			FoundersAgreementExternalUrl: foundersAgreementExternalUrlStr,
-			FoundersAgreementInternalUrl: foundersAgreementInternalUrlStr,
-			CreatedAt:                    v1_common.FormatUnixTime(member.CreatedAt),
-			UpdatedAt:                    v1_common.FormatUnixTime(member.UpdatedAt),
-		})
+	// File questions without rules of their own accept the files of default_file_rules
+	rules, err := listQuestionFileRules(c.Request().Context(), q)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get questions", err)
+	}
+	fileRules := make([]QuestionFileRulesResponse, 0, len(rules))
+	for questionID, questionRules := range rules {
+		fileRules = append(fileRules, questionFileRulesResponse(questionID, questionRules))
 	}
+	sort.Slice(fileRules, func(i, j int) bool { return fileRules[i].QuestionID < fileRules[j].QuestionID })
 
 	// return questions array
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"questions":    questions,
-		"documents":    documents,
-		"team_members": teamMemberResponses,
+		"questions":          questions,
+		"documents":          documents,
+		"team_members":       teamMemberResponses,
+		"file_rules":         fileRules,
+		"default_file_rules": questionFileRulesResponse("", defaultFileRules),
 	})
 }
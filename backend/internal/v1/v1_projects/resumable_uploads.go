@@ -0,0 +1,285 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// resumableUploadChunkSize is the size of the chunks of a resumable upload, each one is a part
+	// of the multipart upload of the storage so it can't be below storage.MinPartSize
+	resumableUploadChunkSize = 8 * 1024 * 1024
+	// resumableUploadExpiry is how long a resumable upload is kept after its last chunk
+	resumableUploadExpiry = 24 * time.Hour
+	// headerUploadOffset holds the offset of a chunk in requests and the offset to resume from in responses
+	headerUploadOffset = "Upload-Offset"
+)
+
+func resumableUploadResponse(upload db.DocumentUpload) ResumableUploadResponse {
+	return ResumableUploadResponse{
+		ID:        upload.ID,
+		ChunkSize: resumableUploadChunkSize,
+		Offset:    upload.UploadedSize,
+		Size:      upload.Size,
+		ExpiresAt: upload.ExpiresAt,
+	}
+}
+
+/*
+ * getResumableUpload loads a resumable upload started by the user that can still receive
+ * chunks. Errors are returned with v1_common.Fail.
+ */
+func (h *Handler) getResumableUpload(c echo.Context, userID string) (db.DocumentUpload, error) {
+	uploadID := c.Param("upload_id")
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return db.DocumentUpload{}, v1_common.Fail(c, http.StatusBadRequest, "Invalid upload id", err)
+	}
+
+	upload, err := h.server.GetQueries().GetDocumentUpload(c.Request().Context(), db.GetDocumentUploadParams{
+		ID:         uploadID,
+		ProjectID:  c.Param("id"),
+		UploadedBy: userID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return db.DocumentUpload{}, v1_common.Fail(c, http.StatusNotFound, "Upload not found", err)
+		}
+		return db.DocumentUpload{}, v1_common.Fail(c, http.StatusInternalServerError, "Failed to get upload", err)
+	}
+
+	// Direct uploads and resumable uploads that were assembled don't take chunks
+	if upload.MultipartID == nil {
+		return db.DocumentUpload{}, v1_common.Fail(c, http.StatusNotFound, "Upload not found", nil)
+	}
+	if upload.ExpiresAt < time.Now().Unix() {
+		return db.DocumentUpload{}, v1_common.Fail(c, http.StatusBadRequest, "Upload expired, please upload the file again", nil)
+	}
+
+	return upload, nil
+}
+
+/*
+ * handleCreateResumableUpload starts a resumable upload of a project document, for files too
+ * large to send in one request. The client sends the file in chunks with PATCH, resumes from
+ * the offset returned by GET after an interruption, then completes the upload like a direct
+ * upload. Each chunk extends the expiry of the upload.
+ *
+ * Security: see newDocumentUpload
+ */
+func (h *Handler) handleCreateResumableUpload(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	ctx := c.Request().Context()
+	store := h.server.GetStorage()
+
+	tx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	queries := h.server.GetQueries().WithTx(tx)
+
+	params, err := h.newDocumentUpload(c, queries, user.ID, time.Now().Add(resumableUploadExpiry))
+	if err != nil {
+		return err
+	}
+
+	multipartID, err := store.CreateMultipartUpload(ctx, params.StorageKey, params.MimeType)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+	params.MultipartID = &multipartID
+
+	upload, err := queries.CreateDocumentUpload(ctx, params)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		if err := store.AbortMultipartUpload(ctx, params.StorageKey, multipartID); err != nil {
+			log.Error().Err(err).Str("storage_key", params.StorageKey).Msg("Failed to abort multipart upload.")
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create upload", err)
+	}
+
+	c.Response().Header().Set(headerUploadOffset, "0")
+	return c.JSON(http.StatusCreated, resumableUploadResponse(upload))
+}
+
+/*
+ * handleGetResumableUpload returns the offset a resumable upload resumes from, also in the
+ * Upload-Offset header.
+ */
+func (h *Handler) handleGetResumableUpload(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	upload, err := h.getResumableUpload(c, user.ID)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(headerUploadOffset, strconv.FormatInt(upload.UploadedSize, 10))
+	return c.JSON(http.StatusOK, resumableUploadResponse(upload))
+}
+
+/*
+ * handleUploadResumableChunk stores the next chunk of a resumable upload. The request sends
+ * the offset of the chunk in the Upload-Offset header and the chunk as body: chunk_size bytes,
+ * or the rest of the file for the last chunk.
+ *
+ * A chunk sent at another offset than the one the upload resumes from is rejected with 409 and
+ * the current offset in the Upload-Offset header, e.g. when a retry arrives after the chunk was
+ * stored. Responds 204 with the new offset in the Upload-Offset header.
+ */
+func (h *Handler) handleUploadResumableChunk(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	upload, err := h.getResumableUpload(c, user.ID)
+	if err != nil {
+		return err
+	}
+
+	offset, err := strconv.ParseInt(c.Request().Header.Get(headerUploadOffset), 10, 64)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Upload-Offset header required", err)
+	}
+	if offset != upload.UploadedSize || offset >= upload.Size {
+		c.Response().Header().Set(headerUploadOffset, strconv.FormatInt(upload.UploadedSize, 10))
+		return v1_common.Fail(c, http.StatusConflict, fmt.Sprintf("the upload resumes from offset %d", upload.UploadedSize), nil)
+	}
+
+	expected := min(int64(resumableUploadChunkSize), upload.Size-offset)
+	if length := c.Request().ContentLength; length > expected {
+		return v1_common.Fail(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("the chunk must be %d bytes", expected), nil)
+	}
+
+	// Read one byte more than expected to reject larger bodies sent without Content-Length
+	chunk, err := io.ReadAll(io.LimitReader(c.Request().Body, expected+1))
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Failed to read chunk", err)
+	}
+	if int64(len(chunk)) != expected {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("the chunk must be %d bytes", expected), nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+	number := int32(offset/resumableUploadChunkSize + 1)
+
+	etag, err := h.server.GetStorage().UploadPart(ctx, upload.StorageKey, *upload.MultipartID, number, chunk)
+	if err != nil {
+		if err == storage.ErrUploadNotFound {
+			return v1_common.Fail(c, http.StatusNotFound, "Upload not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to store chunk", err)
+	}
+
+	err = queries.UpsertDocumentUploadPart(ctx, db.UpsertDocumentUploadPartParams{
+		UploadID:   upload.ID,
+		PartNumber: number,
+		Etag:       etag,
+		Size:       expected,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to store chunk", err)
+	}
+
+	advanced, err := queries.AdvanceDocumentUploadOffset(ctx, db.AdvanceDocumentUploadOffsetParams{
+		UploadedSize: offset + expected,
+		ExpiresAt:    time.Now().Add(resumableUploadExpiry).Unix(),
+		ID:           upload.ID,
+		Offset:       offset,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to store chunk", err)
+	}
+	// Another request stored the chunk at this offset first, the part holds the same bytes
+	if advanced == 0 {
+		return v1_common.Fail(c, http.StatusConflict, "the chunk was already uploaded", nil)
+	}
+
+	c.Response().Header().Set(headerUploadOffset, strconv.FormatInt(offset+expected, 10))
+	return c.NoContent(http.StatusNoContent)
+}
+
+/*
+ * handleDeleteResumableUpload cancels a resumable upload, the chunks stored so far are discarded.
+ */
+func (h *Handler) handleDeleteResumableUpload(c echo.Context) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "Unauthorized", err)
+	}
+
+	upload, err := h.getResumableUpload(c, user.ID)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if err := h.server.GetStorage().AbortMultipartUpload(ctx, upload.StorageKey, *upload.MultipartID); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to cancel upload", err)
+	}
+	if _, err := h.server.GetQueries().DeleteDocumentUpload(ctx, upload.ID); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to cancel upload", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+/*
+ * assembleResumableUpload assembles the chunks of a resumable upload into its file once they
+ * were all uploaded. The upload is then completed like a direct upload, a retry after a later
+ * step failed finds the file already assembled. Errors are returned with v1_common.Fail.
+ */
+func (h *Handler) assembleResumableUpload(c echo.Context, upload db.DocumentUpload) error {
+	if upload.UploadedSize != upload.Size {
+		c.Response().Header().Set(headerUploadOffset, strconv.FormatInt(upload.UploadedSize, 10))
+		return v1_common.Fail(c, http.StatusBadRequest,
+			fmt.Sprintf("The file has not been fully uploaded yet, %d of %d bytes received", upload.UploadedSize, upload.Size), nil)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	uploadParts, err := queries.ListDocumentUploadParts(ctx, upload.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get uploaded chunks", err)
+	}
+
+	parts := make([]storage.CompletedPart, 0, len(uploadParts))
+	for _, part := range uploadParts {
+		parts = append(parts, storage.CompletedPart{Number: part.PartNumber, ETag: part.Etag})
+	}
+
+	if err := h.server.GetStorage().CompleteMultipartUpload(ctx, upload.StorageKey, *upload.MultipartID, parts); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to assemble uploaded file", err)
+	}
+
+	if err := queries.ClearDocumentUploadMultipart(ctx, upload.ID); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to assemble uploaded file", err)
+	}
+
+	return nil
+}
@@ -12,6 +12,26 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// documentFileConfig holds the default rules for project documents, a question can set its own, see getQuestionFileRules
+var documentFileConfig = middleware.FileConfig{
+	MinSize: 1024,             // 1KB minimum
+	MaxSize: 10 * 1024 * 1024, // 10MB maximum
+	AllowedTypes: []string{
+		"application/pdf",
+		"application/msword",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.ms-excel",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"image/jpeg",
+		"image/png",
+	},
+	StrictValidation: true,
+	DeepInspection:   true,
+}
+
+// documentRouteFileConfig bounds the requests of the project documents route, the handler checks the file against the rules of its question
+var documentRouteFileConfig = middleware.FileConfig{MaxSize: documentFileConfig.MaxSize}
+
 func SetupRoutes(g *echo.Group, s interfaces.CoreServer) {
 	h := &Handler{server: s}
 
@@ -38,8 +58,14 @@ func SetupRoutes(g *echo.Group, s interfaces.CoreServer) {
 
 	g.GET("/project/list/all", h.handleListAllProjects, middleware.Auth(s.GetDB(), permissions.PermAdmin))
 
-	// Get new projects
-	g.GET("/project/latest", h.handleGetNewProjects, publicProjectsLimiter.RateLimit())
+	// Full-text search over projects, their answers and company names
+	g.GET("/project/search", h.handleSearchProjects, middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+
+	// Bulk import of applications, e.g. the cohort of a partner accelerator
+	g.POST("/project/import", h.handleImportApplications, middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+
+	// Public project catalogue
+	g.GET("/project/catalogue", h.handleGetProjectCatalogue, publicProjectsLimiter.RateLimit())
 
 	// Update project status
 	g.PUT("/project/:id/status", h.handleUpdateProjectStatus, middleware.Auth(s.GetDB(), permissions.PermAdmin))
@@ -67,23 +93,35 @@ func SetupRoutes(g *echo.Group, s interfaces.CoreServer) {
 
 	// Project documents - require project submission permission
 	docs := projectSubmitGroup.Group("/:id/documents")
-	docs.POST("", h.handleUploadProjectDocument, middleware.FileCheck(middleware.FileConfig{
-		MinSize: 1024,             // 1KB minimum
-		MaxSize: 10 * 1024 * 1024, // 10MB maximum
-		AllowedTypes: []string{
-			"application/pdf",
-			"application/msword",
-			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-			"application/vnd.ms-excel",
-			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-			"image/jpeg",
-			"image/png",
-		},
-		StrictValidation: true,
-	}))
+	docs.POST("", h.handleUploadProjectDocument, middleware.FileCheck(documentRouteFileConfig))
 	docs.GET("", h.handleGetProjectDocuments)
+	// Direct uploads - the client PUTs the file to storage with a presigned URL, then completes the upload
+	docs.POST("/uploads", h.handleCreateDocumentUpload)
+	docs.POST("/uploads/:upload_id/complete", h.handleCompleteDocumentUpload)
+	// Resumable uploads - the client sends large files in chunks through the server, then completes the upload
+	docs.POST("/resumable", h.handleCreateResumableUpload)
+	docs.GET("/resumable/:upload_id", h.handleGetResumableUpload)
+	docs.PATCH("/resumable/:upload_id", h.handleUploadResumableChunk)
+	docs.DELETE("/resumable/:upload_id", h.handleDeleteResumableUpload)
+	docs.PATCH("/:document_id", h.handleUpdateProjectDocument)
+	docs.GET("/:document_id/versions", h.handleListProjectDocumentVersions)
 	docs.DELETE("/:document_id", h.handleDeleteProjectDocument)
 
+	// Data room - investors access documents through signed URLs, founders manage grants and see the access log
+	dataRoom := project.Group("/:id/data-room")
+	dataRoom.GET("", h.handleListDataRoom)
+	dataRoom.GET("/documents/:document_id/url", h.handleGetDataRoomDocumentURL)
+	dataRoom.GET("/grants", h.handleListDataRoomGrants)
+	dataRoom.POST("/grants", h.handleCreateDataRoomGrant)
+	dataRoom.DELETE("/grants/:grant_id", h.handleDeleteDataRoomGrant)
+	dataRoom.GET("/access-log", h.handleGetDataRoomAccessLog)
+
+	// Application dossier export - rendered from a snapshot, available to the owner and to admins
+	project.GET("/:id/export", h.handleExportProject)
+
+	// Project activity timeline - visible to the project owner and to users that can view all projects
+	project.GET("/:id/activity", h.handleGetProjectActivity)
+
 	// Project comments - require comment permissions
 	comments := project.Group("/:id/comments", middleware.Auth(s.GetDB(),
 		permissions.PermViewAllProjects,
@@ -95,6 +133,8 @@ func SetupRoutes(g *echo.Group, s interfaces.CoreServer) {
 	comments.PUT("/:comment_id", h.handleUpdateProjectComment)
 	comments.POST("/:comment_id/resolve", h.handleResolveComment)
 	comments.POST("/:comment_id/unresolve", h.handleUnresolveComment)
+	comments.POST("/bulk/resolve", h.handleBulkResolveComments)
+	comments.POST("/bulk/unresolve", h.handleBulkUnresolveComments)
 
 	// Admin-only comment resolution endpoints - require both comment and admin permissions
 	adminComments := comments.Group("", middleware.Auth(s.GetDB(),
@@ -103,4 +143,24 @@ func SetupRoutes(g *echo.Group, s interfaces.CoreServer) {
 		permissions.PermAdmin,
 	))
 	adminComments.POST("", h.handleCreateProjectComment)
+	adminComments.POST("/bulk", h.handleBulkCreateProjectComments)
+
+	// Investor Q&A - investors ask, founders answer, admins moderate. Separate from review comments.
+	qa := project.Group("/:id/qa")
+	qa.GET("", h.handleListProjectQuestions)
+	qa.POST("", h.handleAskProjectQuestion)
+	qa.PATCH("/:question_id", h.handleUpdateProjectQuestion)
+	qa.POST("/:question_id/replies", h.handleReplyToProjectQuestion)
+
+	adminQA := qa.Group("", middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+	adminQA.DELETE("/:question_id", h.handleDeleteProjectQuestion)
+	adminQA.PATCH("/:question_id/moderation", h.handleModerateProjectQuestion)
+	adminQA.PATCH("/:question_id/replies/:reply_id/moderation", h.handleModerateProjectQuestionReply)
+
+	// Comment templates - canned feedback managed by admins
+	templates := g.Group("/comment-templates", middleware.Auth(s.GetDB(), permissions.PermIsAdmin))
+	templates.GET("", h.handleListCommentTemplates)
+	templates.POST("", h.handleCreateCommentTemplate)
+	templates.PUT("/:template_id", h.handleUpdateCommentTemplate)
+	templates.DELETE("/:template_id", h.handleDeleteCommentTemplate)
 }
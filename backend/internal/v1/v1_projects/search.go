@@ -0,0 +1,128 @@
+package v1_projects
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ts_headline wraps matches in these control characters, see the SearchProjects query
+const (
+	snippetMatchStart = "\x02"
+	snippetMatchStop  = "\x03"
+)
+
+/*
+ * highlightSnippet turns a search snippet into safe HTML. The project text is escaped
+ * first and only then are the matches wrapped in <mark> tags.
+ */
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetMatchStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetMatchStop, "</mark>")
+}
+
+/*
+ * handleSearchProjects searches projects by their title, description, company name and answers.
+ * Results are ranked by relevance, titles and company names weigh the most. Without a query
+ * the matching projects are returned newest first.
+ *
+ * parameters (all optional):
+ * - q: search terms, supports quoted phrases, "or" and "-" to exclude terms
+ * - status: project status
+ * - industry: one of the company industry options
+ * - stage: one of the company stage options
+ * - created_after / created_before: unix timestamps (seconds) bounding the creation date
+ * - page: page number starting at 1 (default: 1)
+ * - limit: number of projects per page (default: 20, max: 100)
+ *
+ * Security:
+ * - Admin only
+ */
+func (h *Handler) handleSearchProjects(c echo.Context) error {
+	var req SearchProjectsRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request parameters", err)
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && *req.CreatedAfter > *req.CreatedBefore {
+		return v1_common.Fail(c, http.StatusBadRequest, "created_after must not be later than created_before", nil)
+	}
+
+	params := db.SearchProjectsParams{
+		Query:         strings.TrimSpace(req.Query),
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		PageSize:      int32(req.Limit),
+		PageOffset:    int32((req.Page - 1) * req.Limit),
+	}
+	if req.Status != "" {
+		params.Status = db.NullProjectStatus{ProjectStatus: req.Status, Valid: true}
+	}
+	if req.Industry != "" {
+		params.Industry = &req.Industry
+	}
+	if req.Stage != "" {
+		params.Stage = &req.Stage
+	}
+
+	projects, err := h.server.GetQueries().SearchProjects(c.Request().Context(), params)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to search projects", err)
+	}
+
+	var total int64
+	if len(projects) > 0 {
+		total = projects[0].Total
+	} else if params.PageOffset > 0 {
+		// A page past the last one has no row to carry the total, it is read from the first page
+		params.PageSize = 1
+		params.PageOffset = 0
+		first, err := h.server.GetQueries().SearchProjects(c.Request().Context(), params)
+		if err != nil {
+			return v1_common.Fail(c, http.StatusInternalServerError, "Failed to search projects", err)
+		}
+		if len(first) > 0 {
+			total = first[0].Total
+		}
+	}
+
+	response := make([]SearchProjectResponse, len(projects))
+	for i, project := range projects {
+		description := ""
+		if project.Description != nil {
+			description = *project.Description
+		}
+
+		response[i] = SearchProjectResponse{
+			ProjectResponse: ProjectResponse{
+				ID:          project.ID,
+				Title:       project.Title,
+				Description: description,
+				Status:      project.Status,
+				AllowEdit:   project.AllowEdit,
+				CreatedAt:   project.CreatedAt,
+				UpdatedAt:   project.UpdatedAt,
+			},
+			CompanyName: project.CompanyName,
+			Rank:        project.Rank,
+			Snippet:     highlightSnippet(project.Snippet),
+		}
+	}
+
+	return c.JSON(http.StatusOK, SearchProjectsResponse{
+		Projects: response,
+		Total:    total,
+		Page:     req.Page,
+		Limit:    req.Limit,
+	})
+}
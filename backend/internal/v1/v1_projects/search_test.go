@@ -0,0 +1,47 @@
+package v1_projects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	testCases := []struct {
+		name     string
+		snippet  string
+		expected string
+	}{
+		{
+			name:     "no matches",
+			snippet:  "A platform for farmers",
+			expected: "A platform for farmers",
+		},
+		{
+			name:     "single match",
+			snippet:  "A platform for \x02farmers\x03",
+			expected: "A platform for <mark>farmers</mark>",
+		},
+		{
+			name:     "multiple fragments",
+			snippet:  "\x02Solar\x03 panels ... cheaper \x02solar\x03 energy",
+			expected: "<mark>Solar</mark> panels ... cheaper <mark>solar</mark> energy",
+		},
+		{
+			name:     "project text is escaped",
+			snippet:  "<script>alert('x')</script> \x02AI\x03 & robotics",
+			expected: "&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt; <mark>AI</mark> &amp; robotics",
+		},
+		{
+			name:     "empty",
+			snippet:  "",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, highlightSnippet(tc.snippet))
+		})
+	}
+}
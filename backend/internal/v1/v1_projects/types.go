@@ -3,6 +3,7 @@ package v1_projects
 import (
 	"KonferCA/SPUR/db"
 	"KonferCA/SPUR/internal/interfaces"
+	"encoding/json"
 )
 
 type Handler struct {
@@ -39,9 +40,31 @@ type ProjectAnswerResponse struct {
 	Section    string `json:"section"`
 }
 
-type GetNewProjectsRequest struct {
-	Count    int                `query:"count" validate:"omitempty,min=1,max=50"`
+type ProjectCatalogueRequest struct {
 	Statuses []db.ProjectStatus `query:"statuses" validate:"omitempty,dive,oneof=pending verified"`
+	Industry string             `query:"industry" validate:"max=128"`
+	Stage    string             `query:"stage" validate:"max=128"`
+	Sort     string             `query:"sort" validate:"omitempty,oneof=newest oldest updated title"`
+	Cursor   string             `query:"cursor" validate:"max=512"`
+	Limit    int                `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type CatalogueFacetResponse struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type CatalogueFacetsResponse struct {
+	Industries []CatalogueFacetResponse `json:"industries"`
+	Stages     []CatalogueFacetResponse `json:"stages"`
+}
+
+type ProjectCatalogueResponse struct {
+	Projects   []ExtendedProjectResponse `json:"projects"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+	HasMore    bool                      `json:"has_more"`
+	// Facets are only included in the first page
+	Facets *CatalogueFacetsResponse `json:"facets,omitempty"`
 }
 
 type PatchAnswerRequest struct {
@@ -54,15 +77,102 @@ type UploadDocumentRequest struct {
 	Name       string `form:"name" validate:"required"`
 	Section    string `form:"section" validate:"required"`
 	SubSection string `form:"sub_section" validate:"required"`
+	// DocumentID uploads a new version of an existing document instead of creating a new one
+	DocumentID string `form:"document_id" validate:"omitempty,uuid"`
 }
 
-type DocumentResponse struct {
+type CreateDocumentUploadRequest struct {
+	QuestionID string `json:"question_id" validate:"required,uuid"`
+	Name       string `json:"name" validate:"required"`
+	Section    string `json:"section" validate:"required"`
+	SubSection string `json:"sub_section" validate:"required"`
+	// DocumentID uploads a new version of an existing document instead of creating a new one
+	DocumentID string `json:"document_id" validate:"omitempty,uuid"`
+	MimeType   string `json:"mime_type" validate:"required"`
+	Size       int64  `json:"size" validate:"required,gt=0"`
+}
+
+// DocumentUploadResponse tells the client how to upload the file, the request must send Headers as they are
+type DocumentUploadResponse struct {
+	ID        string            `json:"id"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+// ResumableUploadResponse tells the client where to resume, chunks are ChunkSize bytes but the last one
+type ResumableUploadResponse struct {
 	ID        string `json:"id"`
-	Name      string `json:"name"`
-	URL       string `json:"url"`
-	Section   string `json:"section"`
-	CreatedAt int64  `json:"created_at"`
-	UpdatedAt int64  `json:"updated_at"`
+	ChunkSize int64  `json:"chunk_size"`
+	Offset    int64  `json:"offset"`
+	Size      int64  `json:"size"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type DocumentResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// URLs has a URL per size when the document is an image, see service.ImageVariants
+	URLs          map[string]string `json:"urls,omitempty"`
+	Section       string            `json:"section"`
+	Confidential  bool              `json:"confidential"`
+	Watermark     bool              `json:"watermark"`
+	Version       int32             `json:"version"`
+	ScanStatus    db.ScanStatus     `json:"scan_status"`
+	ScanSignature *string           `json:"scan_signature,omitempty"`
+	CreatedAt     int64             `json:"created_at"`
+	UpdatedAt     int64             `json:"updated_at"`
+}
+
+// QuestionDocumentResponse is a document listed with the questions it answers
+// QuestionFileRulesResponse describes the files a file question accepts, MaxFiles is 0 without a limit
+type QuestionFileRulesResponse struct {
+	QuestionID   string   `json:"question_id,omitempty"`
+	AllowedTypes []string `json:"allowed_types"`
+	MinSize      int64    `json:"min_size"`
+	MaxSize      int64    `json:"max_size"`
+	MaxFiles     int64    `json:"max_files"`
+}
+
+type QuestionDocumentResponse struct {
+	db.ProjectDocument
+	URL  string            `json:"url"`
+	URLs map[string]string `json:"urls,omitempty"`
+}
+
+type DocumentVersionSnapshotResponse struct {
+	SnapshotID    string `json:"snapshot_id"`
+	VersionNumber int32  `json:"version_number"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+type DocumentVersionResponse struct {
+	ID                  string  `json:"id"`
+	DocumentID          string  `json:"document_id"`
+	Version             int32   `json:"version"`
+	Name                string  `json:"name"`
+	URL                 string  `json:"url"`
+	MimeType            string  `json:"mime_type"`
+	Size                int64   `json:"size"`
+	UploadedBy          *string `json:"uploaded_by"`
+	UploadedByFirstName *string `json:"uploaded_by_first_name"`
+	UploadedByLastName  *string `json:"uploaded_by_last_name"`
+	// Snapshots are the submissions the version was part of, i.e. what reviewers evaluated
+	Snapshots []DocumentVersionSnapshotResponse `json:"snapshots"`
+	CreatedAt int64                             `json:"created_at"`
+}
+
+type DocumentVersionsResponse struct {
+	Versions []DocumentVersionResponse `json:"versions"`
+}
+
+// UpdateDocumentRequest changes the data room settings of a document, fields that are not set are left unchanged
+type UpdateDocumentRequest struct {
+	Confidential *bool `json:"confidential"`
+	// Watermark stamps the name and email of the investor on every download, PDFs only
+	Watermark *bool `json:"watermark"`
 }
 
 type ValidationResult struct {
@@ -107,18 +217,39 @@ type AnswerResponse struct {
 }
 
 type CommentResponse struct {
-	ID                   string  `json:"id"`
-	ProjectID            string  `json:"project_id"`
-	TargetID             string  `json:"target_id"`
-	Comment              string  `json:"comment"`
-	CommenterID          string  `json:"commenter_id"`
-	Resolved             bool    `json:"resolved"`
-	CreatedAt            int64   `json:"created_at"`
-	UpdatedAt            int64   `json:"updated_at"`
-	CommenterFirstName   *string `json:"commenter_first_name"`
-	CommenterLastName    *string `json:"commenter_last_name"`
-	ResolvedBySnapshotID *string `json:"resolved_by_snapshot_id"`
-	ResolvedBySnapshotAt *int64  `json:"resolved_by_snapshot_at"`
+	ID                   string                   `json:"id"`
+	ProjectID            string                   `json:"project_id"`
+	TargetID             string                   `json:"target_id"`
+	Comment              string                   `json:"comment"`
+	CommenterID          string                   `json:"commenter_id"`
+	Resolved             bool                     `json:"resolved"`
+	CreatedAt            int64                    `json:"created_at"`
+	UpdatedAt            int64                    `json:"updated_at"`
+	CommenterFirstName   *string                  `json:"commenter_first_name"`
+	CommenterLastName    *string                  `json:"commenter_last_name"`
+	ResolvedBySnapshotID *string                  `json:"resolved_by_snapshot_id"`
+	ResolvedBySnapshotAt *int64                   `json:"resolved_by_snapshot_at"`
+	Visibility           db.CommentVisibilityEnum `json:"visibility"`
+	// Snapshot the comment was written against, nil if the project was never submitted
+	SnapshotID      *string                `json:"snapshot_id"`
+	SnapshotVersion *int32                 `json:"snapshot_version"`
+	Anchor          *CommentAnchorResponse `json:"anchor"`
+}
+
+/*
+ * CommentAnchorResponse describes the part of the answer a comment refers to.
+ * Start and End are character offsets in the answer of the snapshot the comment
+ * was written against. TextExists reports whether the anchored text can still be
+ * found in the current draft, CurrentStart and CurrentEnd locate it there.
+ */
+type CommentAnchorResponse struct {
+	Start        int32  `json:"start"`
+	End          int32  `json:"end"`
+	Text         string `json:"text"`
+	TextExists   bool   `json:"text_exists"`
+	Moved        bool   `json:"moved"`
+	CurrentStart *int   `json:"current_start"`
+	CurrentEnd   *int   `json:"current_end"`
 }
 
 type CommentsResponse struct {
@@ -128,6 +259,11 @@ type CommentsResponse struct {
 type CreateCommentRequest struct {
 	Comment  string `json:"comment" validate:"required"`
 	TargetID string `json:"target_id" validate:"required,uuid"`
+	// Visibility defaults to "public". Internal comments are only visible to reviewers.
+	Visibility db.CommentVisibilityEnum `json:"visibility" validate:"omitempty,oneof=public internal"`
+	// Optional character range [anchor_start, anchor_end) within the target's answer
+	AnchorStart *int32 `json:"anchor_start" validate:"required_with=AnchorEnd,omitempty,min=0"`
+	AnchorEnd   *int32 `json:"anchor_end" validate:"required_with=AnchorStart,omitempty,min=1"`
 }
 
 type UpdateCommentRequest struct {
@@ -148,3 +284,258 @@ type SaveProjectDraftRequest struct {
 type UpdateProjectStatusRequest struct {
 	Status db.ProjectStatus `json:"status" validate:"required,oneof=draft pending verified declined withdrawn"`
 }
+
+type CommentTemplateResponse struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Body         string   `json:"body"`
+	Placeholders []string `json:"placeholders"`
+	CreatedBy    *string  `json:"created_by"`
+	CreatedAt    int64    `json:"created_at"`
+	UpdatedAt    int64    `json:"updated_at"`
+}
+
+type CommentTemplateRequest struct {
+	Title string `json:"title" validate:"required,max=255"`
+	Body  string `json:"body" validate:"required"`
+}
+
+type BulkCreateCommentItem struct {
+	TargetID string `json:"target_id" validate:"required,uuid"`
+	// Either comment or template_id must be provided. When template_id is set,
+	// the template body is rendered with the project values and the given variables.
+	Comment    string                   `json:"comment" validate:"required_without=TemplateID"`
+	TemplateID string                   `json:"template_id" validate:"omitempty,uuid"`
+	Variables  map[string]string        `json:"variables"`
+	Visibility db.CommentVisibilityEnum `json:"visibility" validate:"omitempty,oneof=public internal"`
+}
+
+type BulkCreateCommentsRequest struct {
+	Comments []BulkCreateCommentItem `json:"comments" validate:"required,min=1,max=100,dive"`
+}
+
+type BulkCommentIDsRequest struct {
+	CommentIDs []string `json:"comment_ids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+type ListProjectActivityRequest struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type ActivityActorResponse struct {
+	ID        string  `json:"id"`
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+}
+
+/*
+ * ActivityResponse is a single entry of a project's activity timeline.
+ * The payload depends on the activity type, e.g. "from" and "to" for status changes
+ * or "comment_id" and "target_id" for comment activities.
+ */
+type ActivityResponse struct {
+	ID        string                 `json:"id"`
+	ProjectID string                 `json:"project_id"`
+	Type      db.ProjectActivityType `json:"type"`
+	Internal  bool                   `json:"internal"`
+	Actor     *ActivityActorResponse `json:"actor"`
+	Payload   json.RawMessage        `json:"payload"`
+	CreatedAt int64                  `json:"created_at"`
+}
+
+type ActivityListResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+}
+
+type SearchProjectsRequest struct {
+	Query         string           `query:"q" validate:"max=256"`
+	Status        db.ProjectStatus `query:"status" validate:"omitempty,oneof=draft pending verified declined withdrawn 'needs review'"`
+	Industry      string           `query:"industry" validate:"max=128"`
+	Stage         string           `query:"stage" validate:"max=128"`
+	CreatedAfter  *int64           `query:"created_after" validate:"omitempty,min=0"`
+	CreatedBefore *int64           `query:"created_before" validate:"omitempty,min=0"`
+	Page          int              `query:"page" validate:"omitempty,min=1"`
+	Limit         int              `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type SearchProjectResponse struct {
+	ProjectResponse
+	CompanyName string  `json:"company_name"`
+	Rank        float32 `json:"rank"`
+	// Snippet is HTML with the matched terms wrapped in <mark> tags, empty without a query
+	Snippet string `json:"snippet"`
+}
+
+type SearchProjectsResponse struct {
+	Projects []SearchProjectResponse `json:"projects"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	Limit    int                     `json:"limit"`
+}
+
+type ExportProjectRequest struct {
+	Format  db.DossierFormat `query:"format" validate:"omitempty,oneof=html pdf"`
+	Version int32            `query:"version" validate:"omitempty,min=1"`
+}
+
+type ImportApplicationsRequest struct {
+	DryRun bool `query:"dry_run" form:"dry_run"`
+}
+
+type ImportRowResult struct {
+	// Row is the position of the application in the file, starting at 1
+	Row        int               `json:"row"`
+	OwnerEmail string            `json:"owner_email"`
+	Title      string            `json:"title"`
+	Status     string            `json:"status"`
+	ProjectID  *string           `json:"project_id"`
+	Errors     []ValidationError `json:"errors"`
+}
+
+type ImportApplicationsResponse struct {
+	DryRun bool `json:"dry_run"`
+	Total  int  `json:"total"`
+	// Valid counts the rows that passed validation in a dry run, nothing is created then
+	Valid   int               `json:"valid"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+type QAParticipantResponse struct {
+	ID        string  `json:"id"`
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+}
+
+type QAReplyResponse struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+	// AuthorRole is "founder" or "investor"
+	AuthorRole string `json:"author_role"`
+	// Author is nil when the viewer is not allowed to know who the investor is
+	Author    *QAParticipantResponse `json:"author"`
+	HiddenAt  *int64                 `json:"hidden_at,omitempty"`
+	CreatedAt int64                  `json:"created_at"`
+}
+
+type QAThreadResponse struct {
+	ID         string          `json:"id"`
+	ProjectID  string          `json:"project_id"`
+	Body       string          `json:"body"`
+	Visibility db.QaVisibility `json:"visibility"`
+	AnsweredAt *int64          `json:"answered_at"`
+	// Mine is set when the viewer asked the question
+	Mine bool `json:"mine"`
+	// Asker is nil when the viewer is not allowed to know who asked
+	Asker     *QAParticipantResponse `json:"asker"`
+	HiddenAt  *int64                 `json:"hidden_at,omitempty"`
+	Replies   []QAReplyResponse      `json:"replies"`
+	CreatedAt int64                  `json:"created_at"`
+	UpdatedAt int64                  `json:"updated_at"`
+}
+
+type QAThreadsResponse struct {
+	Threads []QAThreadResponse `json:"threads"`
+}
+
+type CreateQuestionRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type CreateQuestionReplyRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+	// Visibility can only be set by the founder, it is left unchanged when empty
+	Visibility db.QaVisibility `json:"visibility" validate:"omitempty,oneof=private public"`
+}
+
+type UpdateQuestionRequest struct {
+	Visibility db.QaVisibility `json:"visibility" validate:"required,oneof=private public"`
+}
+
+type ModerateQARequest struct {
+	Hidden *bool `json:"hidden" validate:"required"`
+}
+
+type DataRoomDocumentResponse struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Section      string        `json:"section"`
+	SubSection   string        `json:"sub_section"`
+	MimeType     string        `json:"mime_type"`
+	Size         int64         `json:"size"`
+	Confidential bool          `json:"confidential"`
+	Watermark    bool          `json:"watermark"`
+	ScanStatus   db.ScanStatus `json:"scan_status"`
+	CreatedAt    int64         `json:"created_at"`
+	UpdatedAt    int64         `json:"updated_at"`
+}
+
+type DataRoomResponse struct {
+	Documents []DataRoomDocumentResponse `json:"documents"`
+}
+
+type DataRoomURLRequest struct {
+	// Disposition "inline" views the document in the browser, "attachment" downloads it (default)
+	Disposition string `query:"disposition" validate:"omitempty,oneof=inline attachment"`
+}
+
+type DataRoomURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type CreateDataRoomGrantRequest struct {
+	// Either investor_id or all_committed must be set
+	InvestorID   string `json:"investor_id" validate:"omitempty,uuid"`
+	AllCommitted bool   `json:"all_committed"`
+	// DocumentID limits the grant to one document, the grant covers the whole data room without it
+	DocumentID string `json:"document_id" validate:"omitempty,uuid"`
+	ExpiresAt  *int64 `json:"expires_at" validate:"omitempty,min=1"`
+}
+
+type DataRoomGrantResponse struct {
+	ID                string  `json:"id"`
+	DocumentID        *string `json:"document_id"`
+	DocumentName      *string `json:"document_name"`
+	InvestorID        *string `json:"investor_id"`
+	InvestorEmail     *string `json:"investor_email"`
+	InvestorFirstName *string `json:"investor_first_name"`
+	InvestorLastName  *string `json:"investor_last_name"`
+	AllCommitted      bool    `json:"all_committed"`
+	ExpiresAt         *int64  `json:"expires_at"`
+	Expired           bool    `json:"expired"`
+	CreatedAt         int64   `json:"created_at"`
+}
+
+type DataRoomGrantsResponse struct {
+	Grants []DataRoomGrantResponse `json:"grants"`
+}
+
+type DataRoomAccessLogRequest struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type DataRoomAccessLogEntryResponse struct {
+	ID            string            `json:"id"`
+	DocumentID    *string           `json:"document_id"`
+	DocumentName  string            `json:"document_name"`
+	UserID        string            `json:"user_id"`
+	UserEmail     string            `json:"user_email"`
+	UserFirstName *string           `json:"user_first_name"`
+	UserLastName  *string           `json:"user_last_name"`
+	Action        db.DataRoomAction `json:"action"`
+	CreatedAt     int64             `json:"created_at"`
+}
+
+type DataRoomAccessLogResponse struct {
+	Entries []DataRoomAccessLogEntryResponse `json:"entries"`
+	Total   int64                            `json:"total"`
+	Page    int                              `json:"page"`
+	Limit   int                              `json:"limit"`
+}
@@ -208,6 +208,39 @@ func validateProjectFormAnswers(questions []db.GetQuestionsByProjectRow) (valida
 	return validationErrors
 }
 
+// validateProjectFiles validates the documents answering the file questions, questions without rules use the defaults.
+// Infected documents don't answer their question, their files were deleted by the scan.
+//
+// Returns: A list of validation errors.
+func validateProjectFiles(questions []db.GetQuestionsByProjectRow, documents []db.ProjectDocument, rules map[string]questionFileRules) (validationErrors []ValidationError) {
+	byQuestion := make(map[string][]db.ProjectDocument)
+	for _, doc := range documents {
+		if doc.ScanStatus == db.ScanStatusInfected {
+			continue
+		}
+		byQuestion[doc.QuestionID] = append(byQuestion[doc.QuestionID], doc)
+	}
+
+	for _, question := range questions {
+		if question.InputType != db.InputTypeEnumFile {
+			continue
+		}
+
+		// Skip validation if condition is not met
+		if question.ConditionType.Valid && !shouldValidateQuestion(question, questions) {
+			continue
+		}
+
+		questionRules, ok := rules[question.ID]
+		if !ok {
+			questionRules = defaultFileRules
+		}
+		validationErrors = append(validationErrors, validateQuestionDocuments(question, byQuestion[question.ID], questionRules)...)
+	}
+
+	return validationErrors
+}
+
 // shouldValidateQuestion determines if a question should be validated based on its conditions.
 func shouldValidateQuestion(question db.GetQuestionsByProjectRow, questions []db.GetQuestionsByProjectRow) bool {
 	// Get the dependent question's answer
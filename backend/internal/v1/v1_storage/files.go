@@ -0,0 +1,111 @@
+package v1_storage
+
+import (
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * fileServer returns the storage when it serves its files through the API, and the key of
+ * the requested file.
+ */
+func (h *Handler) fileServer(c echo.Context) (storage.FileServer, string, error) {
+	fileServer, ok := h.server.GetStorage().(storage.FileServer)
+	if !ok {
+		return nil, "", v1_common.Fail(c, http.StatusNotFound, "File not found", nil)
+	}
+
+	key, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return nil, "", v1_common.Fail(c, http.StatusBadRequest, "Invalid file key", err)
+	}
+
+	return fileServer, key, nil
+}
+
+/*
+ * handleDownloadFile serves a file through a signed download URL.
+ *
+ * Security:
+ * - The signature covers the key, expiry and Content-Disposition
+ * - The content type is detected from the file, never taken from the URL
+ */
+func (h *Handler) handleDownloadFile(c echo.Context) error {
+	fileServer, key, err := h.fileServer(c)
+	if err != nil {
+		return err
+	}
+
+	disposition, err := fileServer.VerifyDownload(key, c.QueryParams())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusForbidden, "Invalid or expired link", err)
+	}
+
+	data, err := fileServer.DownloadFile(c.Request().Context(), key)
+	if err != nil {
+		if err == storage.ErrFileNotFound || err == storage.ErrInvalidKey {
+			return v1_common.Fail(c, http.StatusNotFound, "File not found", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read file", err)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, disposition)
+	header.Set("Cache-Control", "private, no-store")
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Blob(http.StatusOK, mimetype.Detect(data).String(), data)
+}
+
+/*
+ * handleUploadFile stores the body of a PUT request through a signed upload URL, the
+ * counterpart of a presigned S3 upload.
+ *
+ * Security:
+ * - The signature covers the key, expiry, Content-Type and size
+ * - The request must send the signed Content-Type, the body must be exactly the signed size
+ */
+func (h *Handler) handleUploadFile(c echo.Context) error {
+	fileServer, key, err := h.fileServer(c)
+	if err != nil {
+		return err
+	}
+
+	contentType, size, err := fileServer.VerifyUpload(key, c.QueryParams())
+	if err != nil {
+		return v1_common.Fail(c, http.StatusForbidden, "Invalid or expired link", err)
+	}
+
+	if !strings.EqualFold(c.Request().Header.Get(echo.HeaderContentType), contentType) {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("Content-Type must be %s", contentType), nil)
+	}
+
+	if c.Request().ContentLength > size {
+		return v1_common.Fail(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file must be %d bytes", size), nil)
+	}
+
+	// One more byte than signed is read to catch bodies without a Content-Length
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, size+1))
+	if err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Failed to read file", err)
+	}
+	if int64(len(data)) != size {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("file must be %d bytes", size), nil)
+	}
+
+	if _, err := fileServer.UploadFile(c.Request().Context(), key, contentType, data); err != nil {
+		if err == storage.ErrInvalidKey {
+			return v1_common.Fail(c, http.StatusBadRequest, "Invalid file key", err)
+		}
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to store file", err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,19 @@
+package v1_storage
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+
+	"github.com/labstack/echo/v4"
+)
+
+/*
+Sets up the routes serving the files of the storage backends that don't have their own URLs,
+e.g. the local filesystem. Requests are authorized by the signature of the URL, not a session.
+*/
+func SetupStorageRoutes(g *echo.Group, s interfaces.CoreServer) {
+	h := Handler{server: s}
+
+	files := g.Group("/storage/files")
+	files.GET("/*", h.handleDownloadFile)
+	files.PUT("/*", h.handleUploadFile)
+}
@@ -0,0 +1,12 @@
+package v1_storage
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+)
+
+/*
+Main Handler struct for V1 storage routes.
+*/
+type Handler struct {
+	server interfaces.CoreServer
+}
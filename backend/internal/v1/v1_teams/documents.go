@@ -3,7 +3,10 @@ package v1_teams
 import (
 	"KonferCA/SPUR/db"
 	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -62,42 +65,72 @@ func (h *Handler) handleUploadTeamMemberDocument(c echo.Context) error {
 		return v1_common.Fail(c, 500, "Failed to read file", err)
 	}
 
+	// get the team member
+	member, err := queries.GetTeamMember(c.Request().Context(), db.GetTeamMemberParams{ID: memberID, CompanyID: company.ID})
+	if err != nil {
+		return v1_common.Fail(c, 400, "Failed to get team member", err)
+	}
+
+	// The new document replaces the current one of the same type
+	replaced := member.ResumeInternalSize
+	if docType == docTypeFoundersAgreement {
+		replaced = member.FoundersAgreementInternalSize
+	}
+
+	// The storage of the company stays locked until the document is recorded
+	tx, err := h.server.GetDB().Begin(c.Request().Context())
+	if err != nil {
+		return v1_common.Fail(c, 500, "Failed to save document record", err)
+	}
+	defer tx.Rollback(context.Background())
+	queries = queries.WithTx(tx)
+
+	err = service.CheckStorageQuota(c.Request().Context(), queries, company.ID, "", int64(len(fileContent)), replaced)
+	if err != nil {
+		if errors.Is(err, service.ErrStorageQuotaExceeded) {
+			return v1_common.Fail(c, http.StatusRequestEntityTooLarge, err.Error(), err)
+		}
+		return v1_common.Fail(c, 500, "Failed to check storage quota", err)
+	}
+
 	// Generate S3 key
 	fileExt := filepath.Ext(file.Filename)
 	s3Key := fmt.Sprintf("member/%s/documents/%s/%s%s", memberID, docType, uuid.New().String(), fileExt)
 
 	// Upload to S3
-	fileURL, err := h.server.GetStorage().UploadFile(c.Request().Context(), s3Key, fileContent)
-	if err != nil {
+	if _, err := h.server.GetStorage().UploadFile(c.Request().Context(), s3Key, file.Header.Get("Content-Type"), fileContent); err != nil {
 		return v1_common.Fail(c, 500, "Failed to upload file", err)
 	}
 
-	// get the team member
-	member, err := queries.GetTeamMember(c.Request().Context(), db.GetTeamMemberParams{ID: memberID, CompanyID: company.ID})
-	if err != nil {
-		return v1_common.Fail(c, 400, "Failed to get team member", err)
-	}
-
 	uploadArg := db.UpdateTeamMemberDocumentsParams{
-		ID:                           member.ID,
-		CompanyID:                    member.CompanyID,
-		ResumeInternalUrl:            member.ResumeInternalUrl,
-		FoundersAgreementInternalUrl: member.FoundersAgreementInternalUrl,
+		ID:                            member.ID,
+		CompanyID:                     member.CompanyID,
+		ResumeInternalKey:             member.ResumeInternalKey,
+		ResumeInternalSize:            member.ResumeInternalSize,
+		FoundersAgreementInternalKey:  member.FoundersAgreementInternalKey,
+		FoundersAgreementInternalSize: member.FoundersAgreementInternalSize,
 	}
 
 	switch docType {
 	case docTypeFoundersAgreement:
-		uploadArg.FoundersAgreementInternalUrl = &fileURL
+		uploadArg.FoundersAgreementInternalKey = &s3Key
+		uploadArg.FoundersAgreementInternalSize = int64(len(fileContent))
 	default:
 		// default upload as resume
-		uploadArg.ResumeInternalUrl = &fileURL
+		uploadArg.ResumeInternalKey = &s3Key
+		uploadArg.ResumeInternalSize = int64(len(fileContent))
 	}
 
 	err = queries.UpdateTeamMemberDocuments(c.Request().Context(), uploadArg)
+	if err == nil {
+		err = tx.Commit(c.Request().Context())
+	}
 	if err != nil {
 		_ = h.server.GetStorage().DeleteFile(c.Request().Context(), s3Key)
 		return v1_common.Fail(c, 500, "Failed to save document record", err)
 	}
 
-	return c.JSON(http.StatusCreated, UploadTeamMemberDocumentResponse{Url: fileURL})
+	return c.JSON(http.StatusCreated, UploadTeamMemberDocumentResponse{
+		Url: v1_common.SignedFileURL(c.Request().Context(), h.server.GetStorage(), s3Key, file.Filename),
+	})
 }
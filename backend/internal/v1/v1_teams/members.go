@@ -2,21 +2,36 @@ package v1_teams
 
 import (
 	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
 	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"KonferCA/SPUR/storage"
+	"context"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
+// helper function to build the activity payload of a team member change
+func teamMemberActivityPayload(member db.TeamMember) service.ActivityPayload {
+	return service.ActivityPayload{
+		"team_member_id": member.ID,
+		"first_name":     member.FirstName,
+		"last_name":      member.LastName,
+		"title":          member.Title,
+	}
+}
+
 /*
  * Formats Unix timestamp to RFC3339 string
  * Used for consistent date formatting in responses
  */
 
-// helper function to convert db.TeamMember to TeamMemberResponse
-func buildTeamMemberResponse(member db.TeamMember) TeamMemberResponse {
+// BuildTeamMemberResponse converts db.TeamMember to TeamMemberResponse, the uploaded documents get signed URLs
+func BuildTeamMemberResponse(ctx context.Context, store storage.Storage, member db.TeamMember) TeamMemberResponse {
 	// process social links
 	socialLinks := v1_common.ProcessSocialLinks(member)
 
@@ -30,16 +45,16 @@ func buildTeamMemberResponse(member db.TeamMember) TeamMemberResponse {
 		resumeExternalUrlStr = *member.ResumeExternalUrl
 	}
 	resumeInternalUrlStr := ""
-	if member.ResumeInternalUrl != nil {
-		resumeInternalUrlStr = *member.ResumeInternalUrl
+	if member.ResumeInternalKey != nil {
+		resumeInternalUrlStr = v1_common.SignedFileURL(ctx, store, *member.ResumeInternalKey, "")
 	}
 	foundersAgreementExternalUrlStr := ""
 	if member.FoundersAgreementExternalUrl != nil {
 		foundersAgreementExternalUrlStr = *member.FoundersAgreementExternalUrl
 	}
 	foundersAgreementInternalUrlStr := ""
-	if member.FoundersAgreementInternalUrl != nil {
-		foundersAgreementInternalUrlStr = *member.FoundersAgreementInternalUrl
+	if member.FoundersAgreementInternalKey != nil {
+		foundersAgreementInternalUrlStr = v1_common.SignedFileURL(ctx, store, *member.FoundersAgreementInternalKey, "")
 	}
 
 	// build response object
@@ -72,6 +87,11 @@ func buildTeamMemberResponse(member db.TeamMember) TeamMemberResponse {
  * Response: TeamMemberResponse
  */
 func (h *Handler) handleAddTeamMember(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "unauthorized", err)
+	}
+
 	// Get company ID from path
 	companyID := c.Param("company_id")
 	if _, err := uuid.Parse(companyID); err != nil {
@@ -112,16 +132,20 @@ func (h *Handler) handleAddTeamMember(c echo.Context) error {
 		DetailedBiography:            req.DetailedBiography,
 		PreviousWork:                 req.PreviousWork,
 		ResumeExternalUrl:            req.ResumeExternalUrl,
-		ResumeInternalUrl:            req.ResumeInternalUrl,
 		FoundersAgreementExternalUrl: req.FoundersAgreementExternalUrl,
-		FoundersAgreementInternalUrl: req.FoundersAgreementInternalUrl,
 	})
 	if err != nil {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create team member", err)
 	}
 
+	// Team members are part of every project of the company
+	err = service.RecordCompanyActivity(queries, c.Request().Context(), companyID, user.ID, db.ProjectActivityTypeTeamMemberAdded, teamMemberActivityPayload(member))
+	if err != nil {
+		log.Error().Err(err).Str("company_id", companyID).Str("member_id", member.ID).Msg("Failed to record project activity.")
+	}
+
 	// Use helper function to build response
-	response := buildTeamMemberResponse(member)
+	response := BuildTeamMemberResponse(c.Request().Context(), h.server.GetStorage(), member)
 	return c.JSON(http.StatusCreated, response)
 }
 
@@ -152,7 +176,7 @@ func (h *Handler) handleGetTeamMembers(c echo.Context) error {
 	// Convert to response type using helper function
 	var teamMembers []TeamMemberResponse
 	for _, member := range members {
-		teamMembers = append(teamMembers, buildTeamMemberResponse(member))
+		teamMembers = append(teamMembers, BuildTeamMemberResponse(c.Request().Context(), h.server.GetStorage(), member))
 	}
 
 	return c.JSON(http.StatusOK, TeamMembersResponse{TeamMembers: teamMembers})
@@ -194,7 +218,7 @@ func (h *Handler) handleGetTeamMember(c echo.Context) error {
 	}
 
 	// Use helper function to build response
-	response := buildTeamMemberResponse(member)
+	response := BuildTeamMemberResponse(c.Request().Context(), h.server.GetStorage(), member)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -205,6 +229,11 @@ func (h *Handler) handleGetTeamMember(c echo.Context) error {
  * Response: TeamMemberResponse
  */
 func (h *Handler) handleUpdateTeamMember(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "unauthorized", err)
+	}
+
 	// Get and validate IDs from path
 	companyID := c.Param("company_id")
 	if _, err := uuid.Parse(companyID); err != nil {
@@ -252,39 +281,33 @@ func (h *Handler) handleUpdateTeamMember(c echo.Context) error {
 		resumeExternalUrlStr = *req.ResumeExternalUrl
 	}
 
-	resumeInternalUrlStr := ""
-	if req.ResumeInternalUrl != nil {
-		resumeInternalUrlStr = *req.ResumeInternalUrl
-	}
-
 	foundersAgreementExternalUrlStr := ""
 	if req.FoundersAgreementExternalUrl != nil {
 		foundersAgreementExternalUrlStr = *req.FoundersAgreementExternalUrl
 	}
 
-	foundersAgreementInternalUrlStr := ""
-	if req.FoundersAgreementInternalUrl != nil {
-		foundersAgreementInternalUrlStr = *req.FoundersAgreementInternalUrl
-	}
+	// Uploaded documents are only set by the upload endpoint, sending no URL removes them
+	clearResumeInternal := req.ResumeInternalUrl == nil || *req.ResumeInternalUrl == ""
+	clearFoundersAgreementInternal := req.FoundersAgreementInternalUrl == nil || *req.FoundersAgreementInternalUrl == ""
 
 	member, err := queries.UpdateTeamMember(c.Request().Context(), db.UpdateTeamMemberParams{
-		ID:                           memberID,
-		CompanyID:                    companyID,
-		FirstName:                    req.FirstName,
-		LastName:                     req.LastName,
-		Title:                        req.Title,
-		DetailedBiography:            req.DetailedBiography,
-		LinkedinUrl:                  req.LinkedinUrl,
-		SocialLinks:                  socialLinksJSON,
-		PersonalWebsite:              personalWebsiteStr,
-		CommitmentType:               req.CommitmentType,
-		Introduction:                 req.Introduction,
-		IndustryExperience:           req.IndustryExperience,
-		PreviousWork:                 previousWorkStr,
-		ResumeExternalUrl:            resumeExternalUrlStr,
-		ResumeInternalUrl:            resumeInternalUrlStr,
-		FoundersAgreementExternalUrl: foundersAgreementExternalUrlStr,
-		FoundersAgreementInternalUrl: foundersAgreementInternalUrlStr,
+		ID:                             memberID,
+		CompanyID:                      companyID,
+		FirstName:                      req.FirstName,
+		LastName:                       req.LastName,
+		Title:                          req.Title,
+		DetailedBiography:              req.DetailedBiography,
+		LinkedinUrl:                    req.LinkedinUrl,
+		SocialLinks:                    socialLinksJSON,
+		PersonalWebsite:                personalWebsiteStr,
+		CommitmentType:                 req.CommitmentType,
+		Introduction:                   req.Introduction,
+		IndustryExperience:             req.IndustryExperience,
+		PreviousWork:                   previousWorkStr,
+		ResumeExternalUrl:              resumeExternalUrlStr,
+		ClearResumeInternal:            clearResumeInternal,
+		FoundersAgreementExternalUrl:   foundersAgreementExternalUrlStr,
+		ClearFoundersAgreementInternal: clearFoundersAgreementInternal,
 	})
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -293,8 +316,13 @@ func (h *Handler) handleUpdateTeamMember(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update team member", err)
 	}
 
+	err = service.RecordCompanyActivity(queries, c.Request().Context(), companyID, user.ID, db.ProjectActivityTypeTeamMemberUpdated, teamMemberActivityPayload(member))
+	if err != nil {
+		log.Error().Err(err).Str("company_id", companyID).Str("member_id", member.ID).Msg("Failed to record project activity.")
+	}
+
 	// Use helper function to build response
-	response := buildTeamMemberResponse(member)
+	response := BuildTeamMemberResponse(c.Request().Context(), h.server.GetStorage(), member)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -304,6 +332,11 @@ func (h *Handler) handleUpdateTeamMember(c echo.Context) error {
  * Response: Success message
  */
 func (h *Handler) handleDeleteTeamMember(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusUnauthorized, "unauthorized", err)
+	}
+
 	companyID := c.Param("company_id")
 	if _, err := uuid.Parse(companyID); err != nil {
 		return v1_common.Fail(c, http.StatusBadRequest, "Invalid company ID format", err)
@@ -330,7 +363,7 @@ func (h *Handler) handleDeleteTeamMember(c echo.Context) error {
 	qtx := db.New(tx)
 
 	// Check if member exists
-	_, err = qtx.GetTeamMember(ctx, db.GetTeamMemberParams{
+	member, err := qtx.GetTeamMember(ctx, db.GetTeamMemberParams{
 		ID:        memberID,
 		CompanyID: companyID,
 	})
@@ -352,6 +385,13 @@ func (h *Handler) handleDeleteTeamMember(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to delete team member", err)
 	}
 
+	err = service.RecordActivityInTx(ctx, tx, func(queries *db.Queries) error {
+		return service.RecordCompanyActivity(queries, ctx, companyID, user.ID, db.ProjectActivityTypeTeamMemberRemoved, teamMemberActivityPayload(member))
+	})
+	if err != nil {
+		log.Error().Err(err).Str("company_id", companyID).Str("member_id", member.ID).Msg("Failed to record project activity.")
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to commit transaction", err)
@@ -45,5 +45,6 @@ func SetupRoutes(e *echo.Group, s interfaces.CoreServer) {
 			"image/png",
 		},
 		StrictValidation: true,
+		DeepInspection:   true,
 	}))
 }
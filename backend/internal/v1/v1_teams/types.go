@@ -25,7 +25,8 @@ type AddTeamMemberRequest struct {
 	PreviousWork       *string                `json:"previous_work"`
 
 	// These fields have to be validated in the handler because
-	// one of the two being defined makes the input valid
+	// one of the two being defined makes the input valid.
+	// The internal documents are set through the upload endpoint, their URLs are ignored here.
 	ResumeExternalUrl            *string `json:"resume_external_url"`
 	ResumeInternalUrl            *string `json:"resume_internal_url"`
 	FoundersAgreementExternalUrl *string `json:"founders_agreement_external_url"`
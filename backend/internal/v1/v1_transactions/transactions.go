@@ -3,12 +3,15 @@ package v1_transactions
 import (
 	"KonferCA/SPUR/db"
 	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/v1/v1_common"
+	"context"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
 func (h *Handler) handleCreateTransaction(c echo.Context) error {
@@ -42,8 +45,15 @@ func (h *Handler) handleCreateTransaction(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusBadRequest, "Invalid value amount", err)
 	}
 
-	// Create transaction
-	tx, err := h.server.GetQueries().AddTransaction(c.Request().Context(), db.AddTransactionParams{
+	// Create transaction, together with the entry of the activity timeline
+	ctx := c.Request().Context()
+	dbTx, err := h.server.GetDB().Begin(ctx)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create transaction", err)
+	}
+	defer dbTx.Rollback(context.Background())
+
+	tx, err := h.server.GetQueries().WithTx(dbTx).AddTransaction(ctx, db.AddTransactionParams{
 		ID:          uuid.New().String(),
 		ProjectID:   req.ProjectID,
 		CompanyID:   project.CompanyID,
@@ -57,6 +67,23 @@ func (h *Handler) handleCreateTransaction(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create transaction", err)
 	}
 
+	err = service.RecordActivityInTx(ctx, dbTx, func(queries *db.Queries) error {
+		return service.RecordProjectActivity(queries, ctx, tx.ProjectID, user.ID, db.ProjectActivityTypeTransactionCreated, false, service.ActivityPayload{
+			"transaction_id": tx.ID,
+			"tx_hash":        tx.TxHash,
+			"from_address":   tx.FromAddress,
+			"to_address":     tx.ToAddress,
+			"value_amount":   req.ValueAmount,
+		})
+	})
+	if err != nil {
+		log.Error().Err(err).Str("project_id", tx.ProjectID).Str("transaction_id", tx.ID).Msg("Failed to record project activity.")
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to create transaction", err)
+	}
+
 	// Format response
 	return c.JSON(http.StatusCreated, TransactionResponse{
 		ID:          tx.ID,
@@ -35,7 +35,7 @@ func SetupUserRoutes(e *echo.Group, s interfaces.CoreServer) {
 		middleware.FileCheck(middleware.FileConfig{
 			MinSize:          1024,            // 1KB minimum
 			MaxSize:          5 * 1024 * 1024, // 5MB maximum
-			AllowedTypes:     []string{"image/jpeg", "image/png"},
+			AllowedTypes:     []string{"image/jpeg", "image/png", "image/webp"},
 			StrictValidation: true,
 		}),
 	)
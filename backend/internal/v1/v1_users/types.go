@@ -30,8 +30,10 @@ type UserDetailsResponse struct {
 	Bio               string          `json:"bio"`
 	Socials           []db.UserSocial `json:"socials"`
 	ProfilePictureUrl *string         `json:"profile_picture_url"`
-	CreatedAt         string          `json:"created_at"`
-	UpdatedAt         *string         `json:"updated_at,omitempty"`
+	// ProfilePictureUrls has a URL per size of the picture, see service.ImageVariants
+	ProfilePictureUrls map[string]string `json:"profile_picture_urls,omitempty"`
+	CreatedAt          string            `json:"created_at"`
+	UpdatedAt          *string           `json:"updated_at,omitempty"`
 }
 
 type ListUsersRequest struct {
@@ -69,5 +71,7 @@ type UpdateUsersRoleRequest struct {
 }
 
 type UploadProfilePictureResponse struct {
-	URL string `json:"url"`
+	// URL is the full size, URLs has a URL per size, see service.ImageVariants
+	URL  string            `json:"url"`
+	URLs map[string]string `json:"urls"`
 }
@@ -4,13 +4,13 @@ import (
 	"KonferCA/SPUR/db"
 	"KonferCA/SPUR/internal/middleware"
 	"KonferCA/SPUR/internal/permissions"
+	"KonferCA/SPUR/internal/service"
 	"KonferCA/SPUR/internal/v1/v1_common"
 	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
-	"path/filepath"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -135,15 +135,16 @@ func (h *Handler) handleGetUserDetails(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, UserDetailsResponse{
-		ID:                details.ID,
-		FirstName:         details.FirstName,
-		LastName:          details.LastName,
-		Title:             details.Title,
-		Bio:               details.Bio,
-		ProfilePictureUrl: details.ProfilePictureUrl,
-		Socials:           socials,
-		CreatedAt:         createdAt,
-		UpdatedAt:         updatedAt,
+		ID:                 details.ID,
+		FirstName:          details.FirstName,
+		LastName:           details.LastName,
+		Title:              details.Title,
+		Bio:                details.Bio,
+		ProfilePictureUrl:  v1_common.OptionalSignedFileURL(c.Request().Context(), h.server.GetStorage(), details.ProfilePictureKey),
+		ProfilePictureUrls: v1_common.OptionalSignedImageURLs(c.Request().Context(), h.server.GetStorage(), details.ProfilePictureKey),
+		Socials:            socials,
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
 	})
 }
 
@@ -355,29 +356,29 @@ func (h *Handler) handleUploadProfilePicture(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to read file", err)
 	}
 
-	// Generate S3 key
-	fileExt := filepath.Ext(file.Filename)
-	s3Key := fmt.Sprintf("users/%s/profile-picture/%s%s", userID, uuid.New().String(), fileExt)
-
-	// Upload to S3
-	fileURL, err := h.server.GetStorage().UploadFile(c.Request().Context(), s3Key, fileContent)
+	// The picture is stored as standard sizes, without the metadata of the original
+	dir := fmt.Sprintf("users/%s/profile-picture/%s", userID, uuid.New().String())
+	s3Key, _, err := service.StoreImage(c.Request().Context(), h.server.GetStorage(), dir, fileContent)
 	if err != nil {
+		if err == service.ErrInvalidImage || err == service.ErrImageTooLarge {
+			return v1_common.Fail(c, http.StatusBadRequest, err.Error(), err)
+		}
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to upload file", err)
 	}
 
-	// Update user's profile picture URL in database
+	// Update user's profile picture key in database
 	err = h.server.GetQueries().UpdateUserProfilePicture(c.Request().Context(), db.UpdateUserProfilePictureParams{
-		ProfilePictureUrl: &fileURL,
+		ProfilePictureKey: &s3Key,
 		ID:                userID,
 	})
 	if err != nil {
-		// Try to cleanup the uploaded file if database update fails
-		_ = h.server.GetStorage().DeleteFile(c.Request().Context(), s3Key)
+		// The files are left to the storage reconciliation, see service.ReconcileStorage
 		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to update profile picture", err)
 	}
 
 	return c.JSON(http.StatusOK, UploadProfilePictureResponse{
-		URL: fileURL,
+		URL:  v1_common.SignedFileURL(c.Request().Context(), h.server.GetStorage(), s3Key, ""),
+		URLs: v1_common.SignedImageURLs(c.Request().Context(), h.server.GetStorage(), s3Key),
 	})
 }
 
@@ -398,9 +399,9 @@ func (h *Handler) handleRemoveProfilePicture(c echo.Context) error {
 		return v1_common.Fail(c, http.StatusForbidden, "Cannot remove another user's profile picture", nil)
 	}
 
-	// Update user's profile picture URL in database to null
+	// Update user's profile picture key in database to null
 	err = h.server.GetQueries().UpdateUserProfilePicture(c.Request().Context(), db.UpdateUserProfilePictureParams{
-		ProfilePictureUrl: nil,
+		ProfilePictureKey: nil,
 		ID:                userID,
 	})
 	if err != nil {
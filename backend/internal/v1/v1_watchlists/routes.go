@@ -0,0 +1,26 @@
+package v1_watchlists
+
+import (
+	"KonferCA/SPUR/internal/interfaces"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/permissions"
+
+	"github.com/labstack/echo/v4"
+)
+
+func SetupWatchlistRoutes(g *echo.Group, s interfaces.CoreServer) {
+	h := &Handler{server: s}
+
+	// Watchlists and saved searches - investors and other users that can view all projects
+	watchlist := g.Group("/watchlist", middleware.Auth(s.GetDB(), permissions.PermViewAllProjects))
+	watchlist.GET("", h.handleListWatchlist)
+	watchlist.POST("", h.handleAddToWatchlist)
+	watchlist.PATCH("/:project_id", h.handleUpdateWatchlistNote)
+	watchlist.DELETE("/:project_id", h.handleRemoveFromWatchlist)
+
+	searches := g.Group("/saved-searches", middleware.Auth(s.GetDB(), permissions.PermViewAllProjects))
+	searches.GET("", h.handleListSavedSearches)
+	searches.POST("", h.handleCreateSavedSearch)
+	searches.PUT("/:id", h.handleUpdateSavedSearch)
+	searches.DELETE("/:id", h.handleDeleteSavedSearch)
+}
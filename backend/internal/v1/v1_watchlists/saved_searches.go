@@ -0,0 +1,197 @@
+package v1_watchlists
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// maxSavedSearches is the number of searches a user can save
+const maxSavedSearches = 25
+
+func savedSearchToResponse(search db.SavedSearch) SavedSearchResponse {
+	return SavedSearchResponse{
+		ID:        search.ID,
+		Name:      search.Name,
+		Industry:  search.Industry,
+		Stage:     search.Stage,
+		Notify:    search.Notify,
+		CreatedAt: search.CreatedAt,
+		UpdatedAt: search.UpdatedAt,
+	}
+}
+
+// optionalFilter returns nil for an empty filter, so it doesn't restrict the search
+func optionalFilter(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (req SavedSearchRequest) notify() bool {
+	return req.Notify == nil || *req.Notify
+}
+
+/*
+ * getSavedSearchID reads and validates the saved search id path parameter
+ */
+func getSavedSearchID(c echo.Context) (string, error) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", v1_common.NewValidationError("Invalid saved search id")
+	}
+	return id, nil
+}
+
+/*
+ * handleListSavedSearches lists the user's saved searches, newest first.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users only see their own searches
+ */
+func (h *Handler) handleListSavedSearches(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	searches, err := h.server.GetQueries().ListSavedSearches(c.Request().Context(), user.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get saved searches", err)
+	}
+
+	response := SavedSearchListResponse{SavedSearches: make([]SavedSearchResponse, len(searches))}
+	for i, search := range searches {
+		response.SavedSearches[i] = savedSearchToResponse(search)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleCreateSavedSearch saves project catalogue filters. Unless notify is false, the user
+ * is notified whenever a project that matches the filters is verified.
+ *
+ * body:
+ * - name: name of the search
+ * - industry, stage: catalogue filters, empty matches any value
+ * - notify: optional, defaults to true
+ *
+ * Security:
+ * - Requires PermViewAllProjects
+ */
+func (h *Handler) handleCreateSavedSearch(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req SavedSearchRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	count, err := queries.CountSavedSearches(ctx, user.ID)
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if count >= maxSavedSearches {
+		return v1_common.Fail(c, http.StatusBadRequest, fmt.Sprintf("At most %d searches can be saved", maxSavedSearches), nil)
+	}
+
+	search, err := queries.CreateSavedSearch(ctx, db.CreateSavedSearchParams{
+		UserID:   user.ID,
+		Name:     req.Name,
+		Industry: optionalFilter(req.Industry),
+		Stage:    optionalFilter(req.Stage),
+		Notify:   req.notify(),
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to save search", err)
+	}
+
+	return c.JSON(http.StatusCreated, savedSearchToResponse(search))
+}
+
+/*
+ * handleUpdateSavedSearch replaces the name, filters and notification setting of a saved search.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users can only update their own searches
+ */
+func (h *Handler) handleUpdateSavedSearch(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	id, err := getSavedSearchID(c)
+	if err != nil {
+		return err
+	}
+
+	var req SavedSearchRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
+	}
+
+	search, err := h.server.GetQueries().UpdateSavedSearch(c.Request().Context(), db.UpdateSavedSearchParams{
+		Name:     req.Name,
+		Industry: optionalFilter(req.Industry),
+		Stage:    optionalFilter(req.Stage),
+		Notify:   req.notify(),
+		ID:       id,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.NewNotFoundError("Saved search")
+		}
+		return v1_common.NewInternalError(err)
+	}
+
+	return c.JSON(http.StatusOK, savedSearchToResponse(search))
+}
+
+/*
+ * handleDeleteSavedSearch deletes a saved search.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users can only delete their own searches
+ */
+func (h *Handler) handleDeleteSavedSearch(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	id, err := getSavedSearchID(c)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := h.server.GetQueries().DeleteSavedSearch(c.Request().Context(), db.DeleteSavedSearchParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if deleted == 0 {
+		return v1_common.NewNotFoundError("Saved search")
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Saved search deleted")
+}
@@ -0,0 +1,58 @@
+package v1_watchlists
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/interfaces"
+)
+
+type Handler struct {
+	server interfaces.CoreServer
+}
+
+type AddToWatchlistRequest struct {
+	ProjectID string `json:"project_id" validate:"required,uuid"`
+	Note      string `json:"note" validate:"max=2000"`
+}
+
+type UpdateWatchlistNoteRequest struct {
+	Note string `json:"note" validate:"max=2000"`
+}
+
+type WatchlistItemResponse struct {
+	ID           string           `json:"id"`
+	ProjectID    string           `json:"project_id"`
+	Title        string           `json:"title"`
+	CompanyName  string           `json:"company_name"`
+	Status       db.ProjectStatus `json:"status"`
+	FundingTotal string           `json:"funding_total"`
+	Note         string           `json:"note"`
+	CreatedAt    int64            `json:"created_at"`
+	UpdatedAt    int64            `json:"updated_at"`
+}
+
+type WatchlistResponse struct {
+	Items []WatchlistItemResponse `json:"items"`
+}
+
+// SavedSearchRequest holds the project catalogue filters of a saved search
+type SavedSearchRequest struct {
+	Name     string `json:"name" validate:"required,max=128"`
+	Industry string `json:"industry" validate:"max=128"`
+	Stage    string `json:"stage" validate:"max=128"`
+	// Notify defaults to true, set it to false to keep the search without alerts
+	Notify *bool `json:"notify"`
+}
+
+type SavedSearchResponse struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Industry  *string `json:"industry"`
+	Stage     *string `json:"stage"`
+	Notify    bool    `json:"notify"`
+	CreatedAt int64   `json:"created_at"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+type SavedSearchListResponse struct {
+	SavedSearches []SavedSearchResponse `json:"saved_searches"`
+}
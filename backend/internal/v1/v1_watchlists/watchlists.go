@@ -0,0 +1,177 @@
+package v1_watchlists
+
+import (
+	"KonferCA/SPUR/db"
+	"KonferCA/SPUR/internal/middleware"
+	"KonferCA/SPUR/internal/service"
+	"KonferCA/SPUR/internal/v1/v1_common"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * getWatchedProjectID reads and validates the project id path parameter
+ */
+func getWatchedProjectID(c echo.Context) (string, error) {
+	id := c.Param("project_id")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", v1_common.NewValidationError("Invalid project id")
+	}
+	return id, nil
+}
+
+/*
+ * handleListWatchlist lists the projects on the user's watchlist, most recently added first.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users only see their own watchlist
+ */
+func (h *Handler) handleListWatchlist(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	items, err := h.server.GetQueries().ListWatchlistItems(c.Request().Context(), user.ID)
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to get watchlist", err)
+	}
+
+	response := WatchlistResponse{Items: make([]WatchlistItemResponse, len(items))}
+	for i, item := range items {
+		response.Items[i] = WatchlistItemResponse{
+			ID:           item.ID,
+			ProjectID:    item.ProjectID,
+			Title:        item.Title,
+			CompanyName:  item.CompanyName,
+			Status:       item.Status,
+			FundingTotal: service.FormatFundingTotal(item.FundingTotal),
+			Note:         item.Note,
+			CreatedAt:    item.CreatedAt,
+			UpdatedAt:    item.UpdatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+/*
+ * handleAddToWatchlist adds a project to the user's watchlist. Adding a project that is
+ * already watched only replaces its note. The user is notified when the status or the
+ * funding progress of a watched project changes.
+ *
+ * body:
+ * - project_id: the project to watch
+ * - note: optional private note about the project
+ *
+ * Security:
+ * - Requires PermViewAllProjects
+ * - Draft projects can't be watched
+ */
+func (h *Handler) handleAddToWatchlist(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	var req AddToWatchlistRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
+	}
+
+	ctx := c.Request().Context()
+	queries := h.server.GetQueries()
+
+	project, err := queries.GetProjectByIDAsAdmin(ctx, req.ProjectID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return v1_common.NewNotFoundError("Project")
+		}
+		return v1_common.NewInternalError(err)
+	}
+	if project.Status == db.ProjectStatusDraft {
+		return v1_common.NewNotFoundError("Project")
+	}
+
+	_, err = queries.AddWatchlistItem(ctx, db.AddWatchlistItemParams{
+		UserID:    user.ID,
+		Note:      req.Note,
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		return v1_common.Fail(c, http.StatusInternalServerError, "Failed to add project to watchlist", err)
+	}
+
+	return v1_common.Success(c, http.StatusCreated, "Project added to watchlist")
+}
+
+/*
+ * handleUpdateWatchlistNote replaces the note of a watched project.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users can only update their own watchlist
+ */
+func (h *Handler) handleUpdateWatchlistNote(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	projectID, err := getWatchedProjectID(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateWatchlistNoteRequest
+	if err := v1_common.BindandValidate(c, &req); err != nil {
+		return v1_common.Fail(c, http.StatusBadRequest, "Invalid request body", err)
+	}
+
+	updated, err := h.server.GetQueries().UpdateWatchlistItemNote(c.Request().Context(), db.UpdateWatchlistItemNoteParams{
+		Note:      req.Note,
+		UserID:    user.ID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if updated == 0 {
+		return v1_common.NewNotFoundError("Watchlist item")
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Watchlist note updated")
+}
+
+/*
+ * handleRemoveFromWatchlist removes a project from the user's watchlist.
+ *
+ * Security:
+ * - Requires PermViewAllProjects, users can only update their own watchlist
+ */
+func (h *Handler) handleRemoveFromWatchlist(c echo.Context) error {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return v1_common.NewAuthError("Missing user information in request.")
+	}
+
+	projectID, err := getWatchedProjectID(c)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := h.server.GetQueries().DeleteWatchlistItem(c.Request().Context(), db.DeleteWatchlistItemParams{
+		UserID:    user.ID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return v1_common.NewInternalError(err)
+	}
+	if deleted == 0 {
+		return v1_common.NewNotFoundError("Watchlist item")
+	}
+
+	return v1_common.Success(c, http.StatusOK, "Project removed from watchlist")
+}
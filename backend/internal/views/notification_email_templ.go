@@ -0,0 +1,63 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.857
+package views
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// NotificationEmail creates an email template for a notification about a project
+func NotificationEmail(message string, url string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>SPUR Notification</title><link rel=\"preconnect\" href=\"https://fonts.googleapis.com\"><link rel=\"preconnect\" href=\"https://fonts.gstatic.com\" crossorigin><link href=\"https://fonts.googleapis.com/css2?family=Kanit:ital,wght@0,100;0,200;0,300;0,400;0,500;0,600;0,700;0,800;0,900;1,100;1,200;1,300;1,400;1,500;1,600;1,700;1,800;1,900&amp;display=swap\" rel=\"stylesheet\"><style>\n                * {\n                    margin: 0;\n                    padding: 0;\n                    font-family: 'Kanit', sans-serif;\n                    box-sizing: border-box;\n                }\n                body {\n                    background-color: #f8fafc;\n                    margin: 0;\n                    padding: 0;\n                    color: #4b5563;\n                    font-family: 'Kanit', sans-serif;   \n                }\n                .container {\n                    max-width: 600px;\n                    margin: 40px auto;\n                    padding: 0;\n                }\n                .card {\n                    padding: 2.5rem;\n                    border-radius: 0.5rem;\n                    background-color: white;\n                    box-shadow: 0 4px 6px -1px rgb(0 0 0 / 0.1), 0 2px 4px -2px rgb(0 0 0 / 0.1);\n                    width: 100%;\n                }\n                .logo {\n                    text-align: center;\n                    margin-bottom: 1.5rem;\n                }\n                .logo svg {\n                    height: 2.5rem;\n                }\n                .title {\n                    font-size: 1.5rem;\n                    line-height: 2rem;\n                    font-weight: 600;\n                    color: #111827;\n                    margin-bottom: 1rem;\n                    text-align: center;\n                }\n                .content {\n                    text-align: center;\n                    color: #4b5563;\n                    margin-top: 1rem;\n                    line-height: 1.5rem;\n                }\n                .space-y > * + * {\n                    margin-top: 1rem;\n                }\n                .button-container {\n                    text-align: center;\n                    margin-top: 2rem;\n                    margin-bottom: 1.5rem;\n                }\n                .button {\n                    text-decoration: none;\n                    color: white !important;\n                    padding: 0.75rem 1.5rem;\n                    background-color: #F4802F;\n                    border-radius: 0.375rem;\n                    font-weight: 500;\n                    display: inline-block;\n                    transition: background-color 0.2s;\n                }\n                .button:hover {\n                    background-color: #D2691F;\n                }\n                .note {\n                    font-size: 0.875rem;\n                    color: #6b7280;\n                    margin-top: 1.5rem;\n                }\n                .link-fallback {\n                    word-break: break-all;\n                    margin-top: 1rem;\n                    margin-bottom: 1rem;\n                    padding: 0.75rem;\n                    background-color: #f3f4f6;\n                    border-radius: 0.25rem;\n                    font-size: 0.875rem;\n                    color: #374151;\n                }\n                .footer {\n                    margin-top: 2rem;\n                    text-align: center;\n                    font-size: 0.875rem;\n                    color: #6b7280;\n                }\n            </style></head><body><div class=\"container\"><div class=\"card\"><div class=\"logo\"><svg width=\"45\" height=\"40\" viewBox=\"0 0 45 40\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><path d=\"M16.0284 10.6147C12.4164 16.9225 8.95484 23.2302 5.34281 29.5379C5.1923 29.384 5.0418 29.2302 5.0418 29.0763C3.38629 26.3071 1.88127 23.5379 0.225752 20.6148C-0.0752508 20.1532 -0.0752508 19.6917 0.225752 19.2302C1.73077 16.6148 3.23578 13.9994 4.5903 11.384C4.8913 10.7686 5.34281 10.6147 6.09531 10.6147C9.10534 10.6147 12.1154 10.6147 15.1254 10.6147C15.4264 10.6147 15.5769 10.6147 16.0284 10.6147Z\" fill=\"#1A1A1A\"></path> <path d=\"M39.6572 10.4619C39.8077 10.7696 39.9582 10.9235 40.1087 11.0773C41.6137 13.8465 43.1187 16.4619 44.7742 19.2312C45.0752 19.8466 45.0752 20.3081 44.7742 20.7696C43.2692 23.385 41.7642 26.0004 40.4097 28.6158C40.1087 29.0774 39.8077 29.385 39.3562 29.385C36.1957 29.385 32.8846 29.385 29.7241 29.385C29.5736 29.385 29.5736 29.385 29.2726 29.385C32.4331 23.0773 36.0452 16.7696 39.6572 10.4619Z\" fill=\"#1A1A1A\"></path> <path d=\"M17.8344 30.4619C24.908 30.4619 31.9816 30.4619 39.0551 30.4619C38.6036 31.385 38.1521 32.1542 37.5501 33.0773C36.3461 35.0773 35.1421 37.2312 34.0886 39.2312C33.7876 39.6927 33.4866 39.8466 33.0351 39.8466C30.025 39.8466 26.8645 39.8466 23.8545 39.8466C23.403 39.8466 22.9515 39.6927 22.801 39.2312C21.2959 36.4619 19.6404 33.6927 18.1354 30.9235C17.9849 30.9235 17.9849 30.7696 17.8344 30.4619Z\" fill=\"#1A1A1A\"></path> <path d=\"M22.9515 0C23.2525 0 23.403 0 23.704 0C26.714 0 29.7241 0 32.7341 0C33.3361 0 33.7876 0.307693 34.0886 0.769233C35.5936 3.38463 36.9482 6.00002 38.4532 8.61541C38.7542 9.07695 38.7542 9.53849 38.4532 10C36.7977 12.9231 35.1421 15.8462 33.6371 18.6154C33.6371 18.6154 33.6371 18.6154 33.4866 18.7693C30.0251 12.6154 26.5635 6.30771 22.9515 0Z\" fill=\"#1A1A1A\"></path> <path d=\"M5.94479 9.38465C6.0953 9.07695 6.09529 8.92311 6.2458 8.76926C7.75081 6.15387 9.25583 3.38463 10.7608 0.769233C11.0618 0.153847 11.5134 0 12.1154 0C14.9749 0 17.9849 0 20.8445 0C21.4465 0 21.898 0.153847 22.199 0.769233C23.8545 3.69232 25.51 6.46156 27.1655 9.53849C20.0919 9.38465 13.0184 9.38465 5.94479 9.38465Z\" fill=\"#1A1A1A\"></path> <path d=\"M21.8979 39.9998C21.4464 39.9998 21.1454 39.9998 20.6939 39.9998C17.6839 39.9998 14.8243 39.9998 11.8143 39.9998C11.3628 39.9998 10.9113 39.846 10.7608 39.3844C9.25577 36.7691 7.75076 34.1537 6.24574 31.3844C5.94474 30.9229 5.94474 30.4613 6.24574 29.846C7.75076 27.0767 9.25577 24.3075 10.9113 21.5382C11.0618 21.3844 11.0618 21.2305 11.2123 20.9229C14.8243 27.3844 18.2859 33.5383 21.8979 39.9998Z\" fill=\"#1A1A1A\"></path></svg></div><h1 class=\"title\">Project Update</h1><div class=\"content space-y\"><p>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(message)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/notification_email.templ`, Line: 122, Col: 18}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</p></div><div class=\"button-container\"><a href=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 templ.SafeURL = templ.SafeURL(url)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var3)))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" class=\"button\">View Project</a></div><div class=\"content\"><p class=\"note\">You are receiving this email because of your watchlist or saved searches on SPUR.</p></div><div class=\"footer\"><p>If you need further assistance, please contact support.</p><p style=\"margin-top: 0.5rem;\">© SPUR x KONFER</p></div></div></div></body></html>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
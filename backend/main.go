@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"os"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 
 	"KonferCA/SPUR/common"
 	"KonferCA/SPUR/internal/server"
+	"KonferCA/SPUR/internal/service"
 
 	"github.com/joho/godotenv"
 )
@@ -30,6 +33,11 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to initialized server")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "reconcile-storage" {
+		reconcileStorage(s, os.Args[2:])
+		return
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -39,5 +47,23 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Str("PORT", port).Msg("failed to bind server to start accepting connections.")
 	}
+}
+
+/*
+reconcileStorage runs the storage reconciliation once and exits, e.g. to see what the scheduled
+run would delete:
 
+	go run . reconcile-storage -dry-run
+*/
+func reconcileStorage(s *server.Server, args []string) {
+	options := service.StorageReconcileOptionsFromEnv()
+
+	flags := flag.NewFlagSet("reconcile-storage", flag.ExitOnError)
+	flags.BoolVar(&options.DryRun, "dry-run", options.DryRun, "only report orphans and dangling references")
+	flags.DurationVar(&options.GracePeriod, "grace-period", options.GracePeriod, "minimum age of the orphans to delete")
+	_ = flags.Parse(args)
+
+	if _, err := service.ReconcileStorage(context.Background(), s.GetQueries(), s.GetStorage(), options); err != nil {
+		log.Fatal().Err(err).Msg("failed to reconcile storage")
+	}
 }
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamAVChunkSize is the size of the chunks a file is streamed to clamd in. It must stay
+// below the StreamMaxLength of the daemon.
+const clamAVChunkSize = 64 * 1024
+
+// ClamAVScanner scans files with a clamd daemon using the INSTREAM command
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a scanner talking to the clamd daemon at address (host:port).
+// timeout bounds a single scan, including the connection to the daemon.
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{address: address, timeout: timeout}
+}
+
+/*
+Scan streams data to clamd and parses its verdict.
+
+The protocol is: "zINSTREAM\0", then the file in chunks, each prefixed with its length as
+a 4 byte big endian integer, and a zero length chunk to end the stream. clamd answers with
+a single null terminated line, "stream: OK", "stream: <signature> FOUND" or "<reason> ERROR".
+*/
+func (s *ClamAVScanner) Scan(ctx context.Context, data []byte) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("couldn't connect to clamd: %v", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	writer := bufio.NewWriter(conn)
+	if _, err := writer.WriteString("zINSTREAM\x00"); err != nil {
+		return Result{}, fmt.Errorf("couldn't send command to clamd: %v", err)
+	}
+
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += clamAVChunkSize {
+		end := min(start+clamAVChunkSize, len(data))
+		binary.BigEndian.PutUint32(size, uint32(end-start))
+		if _, err := writer.Write(size); err != nil {
+			return Result{}, fmt.Errorf("couldn't stream file to clamd: %v", err)
+		}
+		if _, err := writer.Write(data[start:end]); err != nil {
+			return Result{}, fmt.Errorf("couldn't stream file to clamd: %v", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := writer.Write(size); err != nil {
+		return Result{}, fmt.Errorf("couldn't stream file to clamd: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return Result{}, fmt.Errorf("couldn't stream file to clamd: %v", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return Result{}, fmt.Errorf("couldn't read clamd reply: %v", err)
+	}
+
+	return parseClamAVReply(reply)
+}
+
+// parseClamAVReply turns the reply of clamd to an INSTREAM command into a result
+func parseClamAVReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	verdict := strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return Result{}, fmt.Errorf("clamd couldn't scan the file: %s", reply)
+	}
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startFakeClamd accepts a single INSTREAM command, sends the streamed file to received
+// and answers with reply.
+func startFakeClamd(t *testing.T, reply string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		command, err := reader.ReadString(0)
+		if err != nil || command != "zINSTREAM\x00" {
+			return
+		}
+
+		var data bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(reader, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, reader, int64(n)); err != nil {
+				return
+			}
+		}
+
+		received <- data.Bytes()
+		conn.Write([]byte(reply + "\x00"))
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestClamAVScanner(t *testing.T) {
+	// Larger than a chunk, so the file is streamed in several
+	data := bytes.Repeat([]byte("pitch deck "), clamAVChunkSize/5)
+
+	t.Run("Clean file", func(t *testing.T) {
+		address, received := startFakeClamd(t, "stream: OK")
+
+		result, err := NewClamAVScanner(address, 5*time.Second).Scan(context.Background(), data)
+		require.NoError(t, err)
+		assert.False(t, result.Infected)
+		assert.Equal(t, data, <-received)
+	})
+
+	t.Run("Infected file", func(t *testing.T) {
+		address, _ := startFakeClamd(t, "stream: Win.Test.EICAR_HDB-1 FOUND")
+
+		result, err := NewClamAVScanner(address, 5*time.Second).Scan(context.Background(), []byte(eicarSignature))
+		require.NoError(t, err)
+		assert.True(t, result.Infected)
+		assert.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+	})
+
+	t.Run("Scan error", func(t *testing.T) {
+		address, _ := startFakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+
+		_, err := NewClamAVScanner(address, 5*time.Second).Scan(context.Background(), data)
+		assert.ErrorContains(t, err, "size limit exceeded")
+	})
+
+	t.Run("Daemon unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		_, err = NewClamAVScanner(address, time.Second).Scan(context.Background(), data)
+		assert.Error(t, err)
+	})
+}
+
+func TestFakeScanner(t *testing.T) {
+	scanner := NewFakeScanner()
+
+	result, err := scanner.Scan(context.Background(), []byte("%PDF-1.7 pitch deck"))
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = scanner.Scan(context.Background(), []byte("prefix "+eicarSignature))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.NotEmpty(t, result.Signature)
+}
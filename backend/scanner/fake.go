@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+)
+
+// eicarSignature is the standard antivirus test file, every scanner reports it as infected
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// FakeScanner is a local stand-in for clamd. It reports files containing the EICAR test
+// string as infected and everything else as clean.
+type FakeScanner struct{}
+
+func NewFakeScanner() *FakeScanner {
+	return &FakeScanner{}
+}
+
+func (s *FakeScanner) Scan(ctx context.Context, data []byte) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	if bytes.Contains(data, []byte(eicarSignature)) {
+		return Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return Result{}, nil
+}
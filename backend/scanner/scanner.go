@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"KonferCA/SPUR/common"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Result is the outcome of scanning a file. Signature names the malware found in infected files.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner checks uploaded files for malware before they are served to other users
+type Scanner interface {
+	Scan(ctx context.Context, data []byte) (Result, error)
+}
+
+// NewScanner creates the scanner configured by the environment. CLAMAV_ADDRESS is the
+// host:port of a clamd daemon. Tests, and development without clamd, use the fake scanner.
+func NewScanner() (Scanner, error) {
+	if os.Getenv("APP_ENV") == common.TEST_ENV {
+		return NewFakeScanner(), nil
+	}
+
+	address := os.Getenv("CLAMAV_ADDRESS")
+	if address == "" {
+		if os.Getenv("APP_ENV") == common.DEVELOPMENT_ENV {
+			log.Warn().Msg("CLAMAV_ADDRESS not set, uploaded files are checked by the fake scanner.")
+			return NewFakeScanner(), nil
+		}
+		return nil, fmt.Errorf("CLAMAV_ADDRESS environment variable not set")
+	}
+
+	return NewClamAVScanner(address, 2*time.Minute), nil
+}
@@ -0,0 +1,361 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// multipartDir is the directory under the root keeping the parts of multipart uploads, by upload id
+const multipartDir = ".multipart"
+
+// contentTypesDir is the directory under the root keeping the content type of every file, by key
+const contentTypesDir = ".contenttypes"
+
+// uploadContentTypeFile is the file of a multipart upload directory keeping the type of the file
+const uploadContentTypeFile = "content-type"
+
+// LocalStorage keeps files in a directory, they are served by the API through signed URLs
+type LocalStorage struct {
+	*urlSigner
+	root string
+}
+
+/*
+NewLocalStorage creates a storage under the root directory, which is created when missing.
+STORAGE_SIGNING_SECRET should be set so the signed URLs survive restarts and work across
+instances sharing the directory.
+*/
+func NewLocalStorage(root string, baseURL string, secret string) (*LocalStorage, error) {
+	if root == "" {
+		return nil, fmt.Errorf("STORAGE_LOCAL_DIR environment variable not set")
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage directory: %v", err)
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("couldn't create storage directory: %v", err)
+	}
+
+	return &LocalStorage{urlSigner: newURLSigner(baseURL, secret), root: root}, nil
+}
+
+/*
+validateKey rejects keys that aren't clean relative paths, so a key can never point outside
+the storage.
+*/
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// contentTypePath returns where the type of the file with key is kept, key must be valid
+func (s *LocalStorage) contentTypePath(key string) string {
+	return filepath.Join(s.root, contentTypesDir, filepath.FromSlash(key))
+}
+
+// writeContentType records the type of a file, before the file is renamed in place so it never lacks one
+func (s *LocalStorage) writeContentType(key string, contentType string) error {
+	typePath := s.contentTypePath(key)
+	if err := os.MkdirAll(filepath.Dir(typePath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(typePath, []byte(storedContentType(contentType)), 0o640)
+}
+
+func (s *LocalStorage) GetPresignedURL(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return s.uploadURL(key, contentType, size, expires), nil
+}
+
+func (s *LocalStorage) GetSignedDownloadURL(ctx context.Context, key string, filename string, inline bool, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return s.downloadURL(key, filename, inline, expires), nil
+}
+
+func (s *LocalStorage) UploadFile(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return "", fmt.Errorf("couldn't create directory: %v", err)
+	}
+
+	// Written next to the file then renamed, readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+	if err := s.writeContentType(key, contentType); err != nil {
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+
+	return s.URLFromKey(key), nil
+}
+
+func (s *LocalStorage) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("couldn't download file: %v", err)
+	}
+
+	return data, nil
+}
+
+func (s *LocalStorage) HeadFile(ctx context.Context, key string) (FileInfo, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return FileInfo{}, ErrFileNotFound
+		}
+		return FileInfo{}, fmt.Errorf("couldn't get file info: %v", err)
+	}
+	if stat.IsDir() {
+		return FileInfo{}, ErrFileNotFound
+	}
+
+	// Files stored before their types were kept have none
+	contentType, err := os.ReadFile(s.contentTypePath(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return FileInfo{}, fmt.Errorf("couldn't get file info: %v", err)
+	}
+
+	return FileInfo{
+		Size:        stat.Size(),
+		ContentType: storedContentType(string(contentType)),
+	}, nil
+}
+
+func (s *LocalStorage) DeleteFile(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("couldn't delete file: %v", err)
+	}
+	if err := os.Remove(s.contentTypePath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("couldn't delete file: %v", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) ListFiles(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// The parts of multipart uploads aren't files until the upload completes, the types aren't files
+			if filePath == filepath.Join(s.root, multipartDir) || filePath == filepath.Join(s.root, contentTypesDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Files still being written by UploadFile aren't files of the storage yet
+		if strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list files: %v", err)
+	}
+
+	return objects, nil
+}
+
+// uploadPath returns the directory of the parts of a multipart upload, ids are generated by newUploadID
+func (s *LocalStorage) uploadPath(uploadID string) (string, error) {
+	if _, err := hex.DecodeString(uploadID); err != nil || uploadID == "" {
+		return "", ErrUploadNotFound
+	}
+	return filepath.Join(s.root, multipartDir, uploadID), nil
+}
+
+func (s *LocalStorage) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	uploadID, err := newUploadID()
+	if err != nil {
+		return "", err
+	}
+	dir, err := s.uploadPath(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("couldn't create multipart upload: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, uploadContentTypeFile), []byte(contentType), 0o640); err != nil {
+		return "", fmt.Errorf("couldn't create multipart upload: %v", err)
+	}
+
+	return uploadID, nil
+}
+
+func (s *LocalStorage) UploadPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error) {
+	dir, err := s.uploadPath(uploadID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", ErrUploadNotFound
+		}
+		return "", fmt.Errorf("couldn't upload part: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(int(number))), data, 0o640); err != nil {
+		return "", fmt.Errorf("couldn't upload part: %v", err)
+	}
+
+	return partETag(data), nil
+}
+
+func (s *LocalStorage) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	dir, err := s.uploadPath(uploadID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrUploadNotFound
+		}
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return fmt.Errorf("couldn't create directory: %v", err)
+	}
+
+	// Assembled next to the file then renamed, like UploadFile
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	for _, part := range parts {
+		data, err := os.ReadFile(filepath.Join(dir, strconv.Itoa(int(part.Number))))
+		if err != nil || partETag(data) != part.ETag {
+			tmp.Close()
+			return fmt.Errorf("couldn't complete multipart upload: invalid part %d", part.Number)
+		}
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			return fmt.Errorf("couldn't complete multipart upload: %v", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+	contentType, err := os.ReadFile(filepath.Join(dir, uploadContentTypeFile))
+	if err != nil {
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+	if err := s.writeContentType(key, string(contentType)); err != nil {
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("couldn't remove the parts of the upload: %v", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	dir, err := s.uploadPath(uploadID)
+	if err != nil {
+		return nil
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("couldn't abort multipart upload: %v", err)
+	}
+	return nil
+}
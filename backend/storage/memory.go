@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+MemoryStorage keeps files in memory, for tests. The files are served by the API like the files
+of LocalStorage, so signed URLs can be used end to end.
+*/
+type MemoryStorage struct {
+	*urlSigner
+	mu      sync.RWMutex
+	files   map[string]memoryFile
+	uploads map[string]memoryUpload
+}
+
+type memoryFile struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// memoryUpload is a multipart upload in progress, its parts by number
+type memoryUpload struct {
+	key         string
+	contentType string
+	parts       map[int32][]byte
+}
+
+// NewMemoryStorage creates an empty memory storage, see newURLSigner for the secret
+func NewMemoryStorage(baseURL string, secret string) *MemoryStorage {
+	return &MemoryStorage{
+		urlSigner: newURLSigner(baseURL, secret),
+		files:     make(map[string]memoryFile),
+		uploads:   make(map[string]memoryUpload),
+	}
+}
+
+func (s *MemoryStorage) GetPresignedURL(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return s.uploadURL(key, contentType, size, expires), nil
+}
+
+func (s *MemoryStorage) GetSignedDownloadURL(ctx context.Context, key string, filename string, inline bool, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return s.downloadURL(key, filename, inline, expires), nil
+}
+
+func (s *MemoryStorage) UploadFile(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	// The caller may reuse its buffer
+	stored := make([]byte, len(data))
+	copy(stored, data)
+
+	s.mu.Lock()
+	s.files[key] = memoryFile{data: stored, contentType: storedContentType(contentType), modified: time.Now()}
+	s.mu.Unlock()
+
+	return s.URLFromKey(key), nil
+}
+
+func (s *MemoryStorage) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	file, ok := s.files[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+
+	content := make([]byte, len(file.data))
+	copy(content, file.data)
+	return content, nil
+}
+
+func (s *MemoryStorage) HeadFile(ctx context.Context, key string) (FileInfo, error) {
+	s.mu.RLock()
+	file, ok := s.files[key]
+	s.mu.RUnlock()
+	if !ok {
+		return FileInfo{}, ErrFileNotFound
+	}
+
+	return FileInfo{
+		Size:        int64(len(file.data)),
+		ContentType: file.contentType,
+	}, nil
+}
+
+func (s *MemoryStorage) DeleteFile(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.files, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStorage) ListFiles(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var objects []ObjectInfo
+	for key, file := range s.files {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: int64(len(file.data)), LastModified: file.modified})
+		}
+	}
+	return objects, nil
+}
+
+func (s *MemoryStorage) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	uploadID, err := newUploadID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.uploads[uploadID] = memoryUpload{key: key, contentType: storedContentType(contentType), parts: make(map[int32][]byte)}
+	s.mu.Unlock()
+
+	return uploadID, nil
+}
+
+func (s *MemoryStorage) UploadPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error) {
+	stored := make([]byte, len(data))
+	copy(stored, data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.key != key {
+		return "", ErrUploadNotFound
+	}
+	upload.parts[number] = stored
+
+	return partETag(stored), nil
+}
+
+func (s *MemoryStorage) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.key != key {
+		return ErrUploadNotFound
+	}
+
+	var data []byte
+	for _, part := range parts {
+		content, ok := upload.parts[part.Number]
+		if !ok || partETag(content) != part.ETag {
+			return fmt.Errorf("couldn't complete multipart upload: invalid part %d", part.Number)
+		}
+		data = append(data, content...)
+	}
+
+	s.files[key] = memoryFile{data: data, contentType: upload.contentType, modified: time.Now()}
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+func (s *MemoryStorage) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	s.mu.Lock()
+	if upload, ok := s.uploads[uploadID]; ok && upload.key == key {
+		delete(s.uploads, uploadID)
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// defaultS3Region is used when AWS_REGION is not set
+const defaultS3Region = "us-east-1"
+
+/*
+S3Config configures the S3 backend. Endpoint is only set for S3-compatible services like MinIO,
+which usually also need PathStyle, i.e. URLs like <endpoint>/<bucket>/<key>.
+*/
+type S3Config struct {
+	Bucket    string
+	Region    string
+	Endpoint  string
+	PathStyle bool
+}
+
+/*
+S3ConfigFromEnv reads the S3 configuration:
+
+AWS_S3_BUCKET AWS_REGION AWS_S3_ENDPOINT AWS_S3_FORCE_PATH_STYLE
+
+Credentials are read by the AWS SDK, e.g. from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+*/
+func S3ConfigFromEnv() S3Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = defaultS3Region
+	}
+	pathStyle, _ := strconv.ParseBool(os.Getenv("AWS_S3_FORCE_PATH_STYLE"))
+
+	return S3Config{
+		Bucket:    os.Getenv("AWS_S3_BUCKET"),
+		Region:    region,
+		Endpoint:  strings.TrimSuffix(os.Getenv("AWS_S3_ENDPOINT"), "/"),
+		PathStyle: pathStyle,
+	}
+}
+
+// BaseURL returns the prefix of the URLs of the files in the bucket
+func (c S3Config) BaseURL() string {
+	if c.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", c.Bucket, c.Region)
+	}
+
+	if c.PathStyle {
+		return fmt.Sprintf("%s/%s/", c.Endpoint, c.Bucket)
+	}
+
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return fmt.Sprintf("%s/%s/", c.Endpoint, c.Bucket)
+	}
+	return fmt.Sprintf("%s://%s.%s/", endpoint.Scheme, c.Bucket, endpoint.Host)
+}
+
+// S3Storage keeps files in an S3 bucket
+type S3Storage struct {
+	urlMapper
+	s3Client  *s3.Client
+	presigner *s3.PresignClient
+	bucket    string
+}
+
+// NewS3Storage creates a S3 storage, the credentials are loaded by the AWS SDK
+func NewS3Storage(s3Config S3Config) (*S3Storage, error) {
+	if s3Config.Bucket == "" {
+		return nil, fmt.Errorf("AWS_S3_BUCKET environment variable not set")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(s3Config.Region))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %v", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if s3Config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s3Config.Endpoint)
+		}
+		o.UsePathStyle = s3Config.PathStyle
+	})
+
+	return &S3Storage{
+		urlMapper: urlMapper{
+			baseURL: s3Config.BaseURL(),
+			// Files used to be stored with us-east-1 URLs whatever the region of the bucket
+			aliases: []string{fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s3Config.Bucket, defaultS3Region)},
+		},
+		s3Client:  client,
+		presigner: s3.NewPresignClient(client),
+		bucket:    s3Config.Bucket,
+	}, nil
+}
+
+func (s *S3Storage) GetPresignedURL(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	presignedReq, err := s.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", fmt.Errorf("couldn't get presigned URL: %v", err)
+	}
+
+	return presignedReq.URL, nil
+}
+
+func (s *S3Storage) GetSignedDownloadURL(ctx context.Context, key string, filename string, inline bool, expires time.Duration) (string, error) {
+	presignedReq, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(contentDisposition(filename, inline)),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", fmt.Errorf("couldn't get signed download URL: %v", err)
+	}
+
+	return presignedReq.URL, nil
+}
+
+func (s *S3Storage) UploadFile(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't upload file: %v", err)
+	}
+
+	return s.URLFromKey(key), nil
+}
+
+func (s *S3Storage) DownloadFile(ctx context.Context, key string) ([]byte, error) {
+	output, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("couldn't download file: %v", err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file: %v", err)
+	}
+
+	return data, nil
+}
+
+func (s *S3Storage) HeadFile(ctx context.Context, key string) (FileInfo, error) {
+	output, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return FileInfo{}, ErrFileNotFound
+		}
+		return FileInfo{}, fmt.Errorf("couldn't get file info: %v", err)
+	}
+
+	return FileInfo{
+		Size:        aws.ToInt64(output.ContentLength),
+		ContentType: aws.ToString(output.ContentType),
+	}, nil
+}
+
+func (s *S3Storage) DeleteFile(ctx context.Context, key string) error {
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete file: %v", err)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) ListFiles(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	var objects []ObjectInfo
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list files: %v", err)
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *S3Storage) CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error) {
+	output, err := s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't create multipart upload: %v", err)
+	}
+
+	return aws.ToString(output.UploadId), nil
+}
+
+func (s *S3Storage) UploadPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error) {
+	output, err := s.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(number),
+		Body:       bytes.NewReader(data),
+	})
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			return "", ErrUploadNotFound
+		}
+		return "", fmt.Errorf("couldn't upload part: %v", err)
+	}
+
+	return aws.ToString(output.ETag), nil
+}
+
+func (s *S3Storage) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(part.Number),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err := s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			return ErrUploadNotFound
+		}
+		return fmt.Errorf("couldn't complete multipart upload: %v", err)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	_, err := s.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			return nil
+		}
+		return fmt.Errorf("couldn't abort multipart upload: %v", err)
+	}
+
+	return nil
+}
+
+// contentDisposition asks the browser to display the file when inline is set, otherwise to save it as filename
+func contentDisposition(filename string, inline bool) string {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+}
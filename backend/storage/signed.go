@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+FileServer is implemented by the backends whose files are served by the API itself, under
+FilesRoute. The signed URLs they generate are checked by the route before reading or writing.
+*/
+type FileServer interface {
+	Storage
+
+	// VerifyDownload checks a signed download URL, returns the Content-Disposition to send
+	VerifyDownload(key string, query url.Values) (string, error)
+	// VerifyUpload checks a signed upload URL, returns the Content-Type and size the upload must have
+	VerifyUpload(key string, query url.Values) (string, int64, error)
+}
+
+/*
+urlSigner generates and checks the signed URLs of the files served under FilesRoute. The
+signature is a HMAC of the method, key, expiry and constraints of the request, so a URL can't
+be reused for another file or with other headers.
+*/
+type urlSigner struct {
+	urlMapper
+	secret []byte
+}
+
+/*
+newURLSigner creates a signer for files under baseURL. A random secret is used when secret is
+empty, the URLs are then only valid until the server restarts.
+*/
+func newURLSigner(baseURL string, secret string) *urlSigner {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("couldn't generate storage signing secret: %v", err))
+		}
+	}
+
+	return &urlSigner{urlMapper: urlMapper{baseURL: baseURL}, secret: key}
+}
+
+func (s *urlSigner) sign(method, key string, expires int64, constraints ...string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	for _, constraint := range constraints {
+		fmt.Fprintf(mac, "\n%s", constraint)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *urlSigner) signedURL(key string, query url.Values) string {
+	return s.URLFromKey(key) + "?" + query.Encode()
+}
+
+func (s *urlSigner) downloadURL(key string, filename string, inline bool, expires time.Duration) string {
+	expiresAt := time.Now().Add(expires).Unix()
+	disposition := contentDisposition(filename, inline)
+
+	return s.signedURL(key, url.Values{
+		"expires":     {strconv.FormatInt(expiresAt, 10)},
+		"disposition": {disposition},
+		"signature":   {s.sign("GET", key, expiresAt, disposition)},
+	})
+}
+
+func (s *urlSigner) uploadURL(key string, contentType string, size int64, expires time.Duration) string {
+	expiresAt := time.Now().Add(expires).Unix()
+	sizeValue := strconv.FormatInt(size, 10)
+
+	return s.signedURL(key, url.Values{
+		"expires":      {strconv.FormatInt(expiresAt, 10)},
+		"content_type": {contentType},
+		"size":         {sizeValue},
+		"signature":    {s.sign("PUT", key, expiresAt, contentType, sizeValue)},
+	})
+}
+
+func (s *urlSigner) verify(method, key string, query url.Values, constraints ...string) error {
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || expiresAt < time.Now().Unix() {
+		return ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected, _ := hex.DecodeString(s.sign(method, key, expiresAt, constraints...))
+	if !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *urlSigner) VerifyDownload(key string, query url.Values) (string, error) {
+	disposition := query.Get("disposition")
+	if err := s.verify("GET", key, query, disposition); err != nil {
+		return "", err
+	}
+	return disposition, nil
+}
+
+func (s *urlSigner) VerifyUpload(key string, query url.Values) (string, int64, error) {
+	contentType := query.Get("content_type")
+	sizeValue := query.Get("size")
+	if err := s.verify("PUT", key, query, contentType, sizeValue); err != nil {
+		return "", 0, err
+	}
+
+	size, err := strconv.ParseInt(sizeValue, 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidSignature
+	}
+	return contentType, size, nil
+}
@@ -2,92 +2,217 @@ package storage
 
 import (
 	"KonferCA/SPUR/common"
-	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+)
+
+// Backends that can be selected with STORAGE_BACKEND
+const (
+	BackendS3     = "s3"
+	BackendLocal  = "local"
+	BackendMemory = "memory"
+)
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+// FilesRoute is where the API serves the files of the backends that don't have their own URLs
+const FilesRoute = "/api/v1/storage/files/"
+
+var (
+	// ErrFileNotFound is returned when a file doesn't exist in the storage
+	ErrFileNotFound = errors.New("file not found")
+	// ErrInvalidKey is returned for keys that would escape the storage, e.g. with ".."
+	ErrInvalidKey = errors.New("invalid file key")
+	// ErrInvalidSignature is returned for signed URLs that were tampered with or expired
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+	// ErrUploadNotFound is returned for multipart uploads that don't exist, were completed or aborted
+	ErrUploadNotFound = errors.New("multipart upload not found")
 )
 
-type Storage struct {
-	s3Client *s3.Client
-	bucket   string
+// MinPartSize is the minimum size of the parts of a multipart upload but the last one, as S3 requires
+const MinPartSize = 5 * 1024 * 1024
+
+// FileInfo describes a stored file
+type FileInfo struct {
+	Size int64
+	// ContentType is the type the file was stored with, not one detected from the content
+	ContentType string
 }
 
-// NewStorage creates a new Storage instance with S3 client
-func NewStorage() (*Storage, error) {
-	bucket := os.Getenv("AWS_S3_BUCKET")
-	if bucket == "" {
-		return nil, fmt.Errorf("AWS_S3_BUCKET environment variable not set")
-	}
+// defaultContentType is the type of files stored without one, as S3 does
+const defaultContentType = "application/octet-stream"
 
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("unable to load SDK config: %v", err)
+// storedContentType returns the type a file is stored with, defaultContentType when none was given
+func storedContentType(contentType string) string {
+	if contentType == "" {
+		return defaultContentType
 	}
+	return contentType
+}
 
-	var client *s3.Client
-	if os.Getenv("APP_ENV") != common.TEST_ENV {
-		client = s3.NewFromConfig(cfg)
-	} else {
-		client = nil
-	}
+// CompletedPart is a part of a multipart upload, with the ETag UploadPart returned for it
+type CompletedPart struct {
+	Number int32
+	ETag   string
+}
 
-	return &Storage{
-		s3Client: client,
-		bucket:   bucket,
-	}, nil
+// ObjectInfo describes a file found by ListFiles
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
 }
 
-// ValidateFileURL checks if a file URL points to our S3 bucket
-func (s *Storage) ValidateFileURL(url string) bool {
-	expectedPrefix := fmt.Sprintf("https://%s.s3.us-east-1.amazonaws.com/", s.bucket)
-	return url != "" && len(url) > len(expectedPrefix) && url[:len(expectedPrefix)] == expectedPrefix
+/*
+Storage keeps the uploaded files. Files are addressed by key, e.g. projects/<id>/documents/<file>,
+the key of a file is what gets stored in the database. Files are private, a short-lived signed URL
+is generated from the key every time a file is read.
+*/
+type Storage interface {
+	// UploadFile stores a file with its content type and returns its URL, callers keep the key instead
+	UploadFile(ctx context.Context, key string, contentType string, data []byte) (string, error)
+	// DownloadFile reads a file, ErrFileNotFound when there is no such file
+	DownloadFile(ctx context.Context, key string) ([]byte, error)
+	// HeadFile returns the size of a file and the content type it was stored with, by UploadFile, a
+	// presigned URL or CreateMultipartUpload. The content isn't read, callers that need to trust the
+	// type check the content themselves. ErrFileNotFound when there is no such file.
+	HeadFile(ctx context.Context, key string) (FileInfo, error)
+	// DeleteFile deletes a file, deleting a file that doesn't exist succeeds
+	DeleteFile(ctx context.Context, key string) error
+	// ListFiles returns every file whose key starts with prefix
+	ListFiles(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// CreateMultipartUpload starts the upload of a file in parts and returns the id of the upload.
+	// The parts are numbered from 1, every part but the last must be at least MinPartSize.
+	CreateMultipartUpload(ctx context.Context, key string, contentType string) (string, error)
+	// UploadPart stores a part of a multipart upload and returns its ETag, uploading a part again replaces it
+	UploadPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error)
+	// CompleteMultipartUpload assembles the parts, in the given order, into the file
+	CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error
+	// AbortMultipartUpload discards the parts of an upload, aborting an upload that doesn't exist succeeds
+	AbortMultipartUpload(ctx context.Context, key string, uploadID string) error
+
+	// GetSignedDownloadURL generates a short-lived URL to read a file. The browser is asked
+	// to display the file when inline is set, otherwise to save it as filename.
+	GetSignedDownloadURL(ctx context.Context, key string, filename string, inline bool, expires time.Duration) (string, error)
+	// GetPresignedURL generates a short-lived URL to upload a file with a PUT request. The upload
+	// must use the given Content-Type and Content-Length, they are part of the signature.
+	GetPresignedURL(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error)
+
+	// URLFromKey returns the URL of a file, as UploadFile does
+	URLFromKey(key string) string
+	// KeyFromURL returns the key of a file from its URL
+	KeyFromURL(url string) string
+	// ValidateFileURL checks if a URL points to a file of this storage
+	ValidateFileURL(url string) bool
 }
 
-// GetPresignedURL generates a presigned URL for uploading a file
-func (s *Storage) GetPresignedURL(ctx context.Context, key string) (string, error) {
-	presignClient := s3.NewPresignClient(s.s3Client)
+/*
+NewStorage creates the storage backend selected by STORAGE_BACKEND:
+  - s3 (default): AWS S3 or a S3-compatible service like MinIO, see NewS3Storage
+  - local: files on disk, served by the API through signed URLs, see NewLocalStorage
+  - memory: files in memory, served like local files. The default in tests.
+*/
+func NewStorage() (Storage, error) {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" {
+		backend = BackendS3
+		if os.Getenv("APP_ENV") == common.TEST_ENV {
+			backend = BackendMemory
+		}
+	}
+
+	switch backend {
+	case BackendS3:
+		return NewS3Storage(S3ConfigFromEnv())
+	case BackendLocal:
+		return NewLocalStorage(os.Getenv("STORAGE_LOCAL_DIR"), FilesBaseURL(), os.Getenv("STORAGE_SIGNING_SECRET"))
+	case BackendMemory:
+		return NewMemoryStorage(FilesBaseURL(), os.Getenv("STORAGE_SIGNING_SECRET")), nil
+	}
+
+	return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+}
 
-	putObjectInput := &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+/*
+BaseURLFromEnv returns the prefix of the URLs of the files of the configured storage backend,
+or "" when the backend isn't configured.
+*/
+func BaseURLFromEnv() string {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case BackendLocal, BackendMemory:
+		return FilesBaseURL()
+	case "":
+		if os.Getenv("APP_ENV") == common.TEST_ENV {
+			return FilesBaseURL()
+		}
 	}
 
-	presignedReq, err := presignClient.PresignPutObject(ctx, putObjectInput)
-	if err != nil {
-		return "", fmt.Errorf("couldn't get presigned URL: %v", err)
+	config := S3ConfigFromEnv()
+	if config.Bucket == "" {
+		return ""
 	}
+	return config.BaseURL()
+}
+
+// FilesBaseURL returns the URL of FilesRoute on this server, relative when BACKEND_URL isn't set
+func FilesBaseURL() string {
+	return strings.TrimSuffix(os.Getenv("BACKEND_URL"), "/") + FilesRoute
+}
+
+/*
+urlMapper converts between the keys and URLs of files that share a base URL. URLs with one of
+the aliases, e.g. stored before the base URL changed, are still recognized.
+*/
+type urlMapper struct {
+	baseURL string
+	aliases []string
+}
 
-	return presignedReq.URL, nil
+func (m urlMapper) URLFromKey(key string) string {
+	return m.baseURL + key
 }
 
-// UploadFile uploads a file to S3 and returns its URL
-func (s *Storage) UploadFile(ctx context.Context, key string, data []byte) (string, error) {
-	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(data),
-	})
-	if err != nil {
-		return "", fmt.Errorf("couldn't upload file: %v", err)
+func (m urlMapper) KeyFromURL(url string) string {
+	if prefix := m.prefixOf(url); prefix != "" {
+		return strings.TrimPrefix(url, prefix)
 	}
+	return url
+}
+
+func (m urlMapper) ValidateFileURL(url string) bool {
+	prefix := m.prefixOf(url)
+	return prefix != "" && len(url) > len(prefix)
+}
 
-	return fmt.Sprintf("https://%s.s3.us-east-1.amazonaws.com/%s", s.bucket, key), nil
+func (m urlMapper) prefixOf(url string) string {
+	if strings.HasPrefix(url, m.baseURL) {
+		return m.baseURL
+	}
+	for _, alias := range m.aliases {
+		if strings.HasPrefix(url, alias) {
+			return alias
+		}
+	}
+	return ""
 }
 
-// DeleteFile deletes a file from S3
-func (s *Storage) DeleteFile(ctx context.Context, key string) error {
-	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
-	})
-	if err != nil {
-		return fmt.Errorf("couldn't delete file: %v", err)
+// newUploadID generates the id of a multipart upload of the backends that keep the parts themselves
+func newUploadID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("couldn't create multipart upload: %v", err)
 	}
+	return hex.EncodeToString(id), nil
+}
 
-	return nil
+// partETag is the ETag of a part, the quoted md5 of its content like S3 returns
+func partETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
 }
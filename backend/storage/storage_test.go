@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBaseURL = "http://localhost:6969/api/v1/storage/files/"
+
+// signedQuery returns the query of a signed URL
+func signedQuery(t *testing.T, signedURL string) url.Values {
+	parsed, err := url.Parse(signedURL)
+	require.NoError(t, err)
+	return parsed.Query()
+}
+
+func TestBackends(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir(), testBaseURL, "secret")
+	require.NoError(t, err)
+
+	backends := map[string]FileServer{
+		"memory": NewMemoryStorage(testBaseURL, "secret"),
+		"local":  local,
+	}
+
+	for name, store := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			content := []byte("%PDF-1.4 quarterly numbers")
+
+			fileURL, err := store.UploadFile(ctx, "projects/1/deck.pdf", "application/pdf", content)
+			require.NoError(t, err)
+			assert.Equal(t, testBaseURL+"projects/1/deck.pdf", fileURL)
+			assert.Equal(t, "projects/1/deck.pdf", store.KeyFromURL(fileURL))
+			assert.True(t, store.ValidateFileURL(fileURL))
+			assert.False(t, store.ValidateFileURL("https://example.com/projects/1/deck.pdf"))
+
+			data, err := store.DownloadFile(ctx, "projects/1/deck.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+
+			info, err := store.HeadFile(ctx, "projects/1/deck.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(content)), info.Size)
+			assert.Equal(t, "application/pdf", info.ContentType)
+
+			// The type is the one the file was stored with, the content isn't read
+			_, err = store.UploadFile(ctx, "users/1/avatar.png", "image/png", []byte("avatar"))
+			require.NoError(t, err)
+			info, err = store.HeadFile(ctx, "users/1/avatar.png")
+			require.NoError(t, err)
+			assert.Equal(t, "image/png", info.ContentType)
+			objects, err := store.ListFiles(ctx, "projects/")
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+			assert.Equal(t, "projects/1/deck.pdf", objects[0].Key)
+			assert.Equal(t, int64(len(content)), objects[0].Size)
+			assert.WithinDuration(t, time.Now(), objects[0].LastModified, time.Minute)
+
+			require.NoError(t, store.DeleteFile(ctx, "projects/1/deck.pdf"))
+			require.NoError(t, store.DeleteFile(ctx, "projects/1/deck.pdf"))
+
+			_, err = store.DownloadFile(ctx, "projects/1/deck.pdf")
+			assert.Equal(t, ErrFileNotFound, err)
+			_, err = store.HeadFile(ctx, "projects/1/deck.pdf")
+			assert.Equal(t, ErrFileNotFound, err)
+
+			for _, key := range []string{"", "/etc/passwd", "../secret", "projects/../../secret", "projects//deck.pdf", "projects\\deck.pdf"} {
+				_, err := store.UploadFile(ctx, key, "application/pdf", content)
+				assert.Equal(t, ErrInvalidKey, err, key)
+				_, err = store.GetSignedDownloadURL(ctx, key, "deck.pdf", false, time.Minute)
+				assert.Equal(t, ErrInvalidKey, err, key)
+			}
+		})
+	}
+}
+
+func TestMultipartUploads(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir(), testBaseURL, "secret")
+	require.NoError(t, err)
+
+	backends := map[string]FileServer{
+		"memory": NewMemoryStorage(testBaseURL, "secret"),
+		"local":  local,
+	}
+
+	for name, store := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			first := []byte("%PDF-1.4 first part ")
+			second := []byte("second part")
+
+			uploadID, err := store.CreateMultipartUpload(ctx, "projects/1/plan.pdf", "application/pdf")
+			require.NoError(t, err)
+
+			// Uploading a part again replaces it
+			_, err = store.UploadPart(ctx, "projects/1/plan.pdf", uploadID, 1, []byte("stale"))
+			require.NoError(t, err)
+			firstETag, err := store.UploadPart(ctx, "projects/1/plan.pdf", uploadID, 1, first)
+			require.NoError(t, err)
+			secondETag, err := store.UploadPart(ctx, "projects/1/plan.pdf", uploadID, 2, second)
+			require.NoError(t, err)
+
+			// The parts aren't files until the upload completes
+			objects, err := store.ListFiles(ctx, "")
+			require.NoError(t, err)
+			assert.Empty(t, objects)
+
+			err = store.CompleteMultipartUpload(ctx, "projects/1/plan.pdf", uploadID, []CompletedPart{
+				{Number: 1, ETag: secondETag},
+				{Number: 2, ETag: secondETag},
+			})
+			assert.Error(t, err)
+
+			err = store.CompleteMultipartUpload(ctx, "projects/1/plan.pdf", uploadID, []CompletedPart{
+				{Number: 1, ETag: firstETag},
+				{Number: 2, ETag: secondETag},
+			})
+			require.NoError(t, err)
+
+			data, err := store.DownloadFile(ctx, "projects/1/plan.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, append(first, second...), data)
+
+			objects, err = store.ListFiles(ctx, "")
+			require.NoError(t, err)
+			assert.Len(t, objects, 1)
+
+			info, err := store.HeadFile(ctx, "projects/1/plan.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, "application/pdf", info.ContentType)
+
+			_, err = store.UploadPart(ctx, "projects/1/plan.pdf", uploadID, 3, second)
+			assert.Equal(t, ErrUploadNotFound, err)
+
+			uploadID, err = store.CreateMultipartUpload(ctx, "projects/1/other.pdf", "application/pdf")
+			require.NoError(t, err)
+			_, err = store.UploadPart(ctx, "projects/1/other.pdf", uploadID, 1, first)
+			require.NoError(t, err)
+			require.NoError(t, store.AbortMultipartUpload(ctx, "projects/1/other.pdf", uploadID))
+			require.NoError(t, store.AbortMultipartUpload(ctx, "projects/1/other.pdf", uploadID))
+
+			err = store.CompleteMultipartUpload(ctx, "projects/1/other.pdf", uploadID, nil)
+			assert.Equal(t, ErrUploadNotFound, err)
+			_, err = store.DownloadFile(ctx, "projects/1/other.pdf")
+			assert.Equal(t, ErrFileNotFound, err)
+		})
+	}
+}
+
+func TestLocalStorageStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStorage(filepath.Join(root, "files"), testBaseURL, "secret")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret"), []byte("secret"), 0o600))
+
+	_, err = store.DownloadFile(context.Background(), "../secret")
+	assert.Equal(t, ErrInvalidKey, err)
+	assert.Equal(t, ErrInvalidKey, store.DeleteFile(context.Background(), "../secret"))
+
+	_, err = os.Stat(filepath.Join(root, "secret"))
+	assert.NoError(t, err)
+}
+
+func TestSignedDownloadURL(t *testing.T) {
+	store := NewMemoryStorage(testBaseURL, "secret")
+	ctx := context.Background()
+
+	signed, err := store.GetSignedDownloadURL(ctx, "projects/1/deck.pdf", "deck.pdf", true, time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(signed, testBaseURL+"projects/1/deck.pdf?"))
+
+	query := signedQuery(t, signed)
+	disposition, err := store.VerifyDownload("projects/1/deck.pdf", query)
+	require.NoError(t, err)
+	assert.Equal(t, "inline; filename=deck.pdf", disposition)
+
+	t.Run("Bound to the key", func(t *testing.T) {
+		_, err := store.VerifyDownload("projects/2/deck.pdf", query)
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("Bound to the disposition", func(t *testing.T) {
+		tampered := signedQuery(t, signed)
+		tampered.Set("disposition", "attachment; filename=deck.pdf")
+		_, err := store.VerifyDownload("projects/1/deck.pdf", tampered)
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("Bound to the secret", func(t *testing.T) {
+		_, err := NewMemoryStorage(testBaseURL, "other").VerifyDownload("projects/1/deck.pdf", query)
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		expired, err := store.GetSignedDownloadURL(ctx, "projects/1/deck.pdf", "deck.pdf", true, -time.Minute)
+		require.NoError(t, err)
+		_, err = store.VerifyDownload("projects/1/deck.pdf", signedQuery(t, expired))
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+
+	t.Run("Download URLs can't upload", func(t *testing.T) {
+		_, _, err := store.VerifyUpload("projects/1/deck.pdf", query)
+		assert.Equal(t, ErrInvalidSignature, err)
+	})
+}
+
+func TestSignedUploadURL(t *testing.T) {
+	store := NewMemoryStorage(testBaseURL, "secret")
+
+	signed, err := store.GetPresignedURL(context.Background(), "projects/1/deck.pdf", "application/pdf", 2048, time.Minute)
+	require.NoError(t, err)
+
+	contentType, size, err := store.VerifyUpload("projects/1/deck.pdf", signedQuery(t, signed))
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+	assert.Equal(t, int64(2048), size)
+
+	tampered := signedQuery(t, signed)
+	tampered.Set("size", "4096")
+	_, _, err = store.VerifyUpload("projects/1/deck.pdf", tampered)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	tampered = signedQuery(t, signed)
+	tampered.Set("content_type", "text/html")
+	_, _, err = store.VerifyUpload("projects/1/deck.pdf", tampered)
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func TestS3ConfigBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   S3Config
+		expected string
+	}{
+		{"AWS", S3Config{Bucket: "spur", Region: "ca-central-1"}, "https://spur.s3.ca-central-1.amazonaws.com/"},
+		{"Path style endpoint", S3Config{Bucket: "spur", Region: "us-east-1", Endpoint: "http://localhost:9000", PathStyle: true}, "http://localhost:9000/spur/"},
+		{"Virtual hosted endpoint", S3Config{Bucket: "spur", Region: "auto", Endpoint: "https://storage.example.com"}, "https://spur.storage.example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.BaseURL())
+		})
+	}
+}
+
+func TestS3StorageKeepsLegacyURLs(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	store, err := NewS3Storage(S3Config{Bucket: "spur", Region: "ca-central-1"})
+	require.NoError(t, err)
+
+	legacy := "https://spur.s3.us-east-1.amazonaws.com/projects/1/deck.pdf"
+	assert.True(t, store.ValidateFileURL(legacy))
+	assert.Equal(t, "projects/1/deck.pdf", store.KeyFromURL(legacy))
+	assert.Equal(t, "https://spur.s3.ca-central-1.amazonaws.com/projects/1/deck.pdf", store.URLFromKey("projects/1/deck.pdf"))
+
+	signed, err := store.GetPresignedURL(context.Background(), "projects/1/deck.pdf", "application/pdf", 2048, time.Minute)
+	require.NoError(t, err)
+	assert.Contains(t, signed, "X-Amz-Signature=")
+}